@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/imarrche/jwt-auth-example/internal/service"
+)
+
+const keysUsage = `usage: jwt keys <command> [arguments]
+
+commands:
+  list                                       lists all signing keys
+  add -alg <algorithm> [-key <path>] [-kid <id>]
+                                             introduces a new pending key, HS256
+                                             secret is read from JWT_SECRET
+  promote <kid>                              makes the key active, the active key
+                                             is retired
+  retire <kid>                               retires the pending key
+  purge                                      deletes keys retired longer than the
+                                             grace period ago`
+
+// runKeys runs signing key rotation command.
+func runKeys(ctx context.Context, keys service.Keys, args []string) error {
+	if len(args) == 0 {
+		return errors.New(keysUsage)
+	}
+
+	switch args[0] {
+	case "list":
+		sks, err := keys.GetAll(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "KID\tALGORITHM\tSTATUS\tCREATED\tRETIRED")
+		for _, sk := range sks {
+			retiredAt := "-"
+			if sk.RetiredAt != nil {
+				retiredAt = sk.RetiredAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(
+				w, "%s\t%s\t%s\t%s\t%s\n",
+				sk.ID, sk.Algorithm, sk.Status, sk.CreatedAt.Format(time.RFC3339), retiredAt,
+			)
+		}
+		return w.Flush()
+	case "add":
+		fs := flag.NewFlagSet("add", flag.ContinueOnError)
+		algorithm := fs.String("alg", "", "signing algorithm: HS256, RS256, ES256 or EdDSA")
+		keyPath := fs.String("key", "", "path to PEM encoded private key")
+		kid := fs.String("kid", "", "key ID, defaults to key thumbprint")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		material := []byte(os.Getenv("JWT_SECRET"))
+		if *algorithm != "HS256" {
+			m, err := ioutil.ReadFile(*keyPath)
+			if err != nil {
+				return err
+			}
+			material = m
+		}
+		sk, err := keys.Add(ctx, *kid, *algorithm, material)
+		if err != nil {
+			return err
+		}
+		fmt.Println(sk.ID)
+		return nil
+	case "promote":
+		if len(args) != 2 {
+			return errors.New(keysUsage)
+		}
+		return keys.Promote(ctx, args[1])
+	case "retire":
+		if len(args) != 2 {
+			return errors.New(keysUsage)
+		}
+		return keys.Retire(ctx, args[1])
+	case "purge":
+		return keys.Purge(ctx)
+	}
+
+	return errors.New(keysUsage)
+}
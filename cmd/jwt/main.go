@@ -1,11 +1,23 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.uber.org/zap"
+
 	"github.com/imarrche/jwt-auth-example/internal/config"
 	"github.com/imarrche/jwt-auth-example/internal/logger"
+	"github.com/imarrche/jwt-auth-example/internal/mail"
+	"github.com/imarrche/jwt-auth-example/internal/notify"
+	"github.com/imarrche/jwt-auth-example/internal/ratelimit"
 	"github.com/imarrche/jwt-auth-example/internal/server"
 	"github.com/imarrche/jwt-auth-example/internal/service/app"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+	"github.com/imarrche/jwt-auth-example/internal/store/memory"
 	"github.com/imarrche/jwt-auth-example/internal/store/pg"
+	"github.com/imarrche/jwt-auth-example/internal/store/sqlite"
 )
 
 func main() {
@@ -15,21 +27,87 @@ func main() {
 	// Reading config.
 	c := config.Get()
 
-	// Opening PostgreSQL store.
-	store := pg.Get(c.PostgreSQL)
+	// Opening store.
+	store, err := newStore(c)
+	if err != nil {
+		l.Fatal(err.Error())
+	}
 	if err := store.Open(); err != nil {
 		l.Fatal(err.Error())
 	}
-	logger.Get().Info("connected to the PostgreSQL")
+	logger.Get().Info("opened the store", zap.String("driver", c.Store.Driver))
+
+	// Creating mailer.
+	mailer, err := mail.New(c.Mail)
+	if err != nil {
+		l.Fatal(err.Error())
+	}
+
+	// Creating notifier.
+	notifier, err := notify.New(c.Notify, mailer)
+	if err != nil {
+		l.Fatal(err.Error())
+	}
 
 	// Initalizing service.
-	service := app.NewService(store)
+	service, err := app.NewService(store, mailer, notifier)
+	if err != nil {
+		l.Fatal(err.Error())
+	}
 
-	// Running the server.
-	server.New(c.Server, service).Run()
+	if len(os.Args) > 1 && (os.Args[1] == "keys" || os.Args[1] == "roles") {
+		// Running management command.
+		if err := runCommand(context.Background(), service, os.Args[1], os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	} else {
+		// Creating rate limiter.
+		limiter, err := ratelimit.New(c.RateLimit, store)
+		if err != nil {
+			l.Fatal(err.Error())
+		}
 
-	// Closing PostgreSQL store.
+		// Purging expired tokens, retired signing keys and idle rate limit buckets in
+		// background.
+		done := make(chan struct{})
+		go service.PurgeExpired(c.JWT.PurgeInterval, done)
+		go ratelimit.PurgeIdle(limiter, c.RateLimit.PurgeInterval, done)
+
+		// Running the server.
+		server.New(c.Server, service, limiter).Run()
+		close(done)
+	}
+
+	// Waiting for emails being sent and closing store.
+	service.Wait()
 	if err := store.Close(); err != nil {
 		l.Fatal(err.Error())
 	}
 }
+
+// newStore returns the store of the driver set in store config.
+func newStore(c *config.Config) (store.Store, error) {
+	switch c.Store.Driver {
+	case "pg":
+		return pg.Get(c.PostgreSQL), nil
+	case "sqlite":
+		return sqlite.New(c.SQLite), nil
+	case "memory":
+		return memory.New(), nil
+	}
+
+	return nil, fmt.Errorf("unknown store driver: %s", c.Store.Driver)
+}
+
+// runCommand runs management command with arguments.
+func runCommand(ctx context.Context, s *app.Service, command string, args []string) error {
+	switch command {
+	case "keys":
+		return runKeys(ctx, s.Keys(), args)
+	case "roles":
+		return runRoles(ctx, s.Roles(), args)
+	}
+
+	return fmt.Errorf("unknown command: %s", command)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/imarrche/jwt-auth-example/internal/service"
+)
+
+const rolesUsage = `usage: jwt roles <command> [arguments]
+
+commands:
+  list                                       lists all roles with permissions
+  grant <user id> <role>                     grants the role to the user
+  revoke <user id> <role>                    revokes the role from the user`
+
+// runRoles runs role management command.
+func runRoles(ctx context.Context, roles service.Roles, args []string) error {
+	if len(args) == 0 {
+		return errors.New(rolesUsage)
+	}
+
+	switch args[0] {
+	case "list":
+		rs, err := roles.GetAll(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ROLE\tPERMISSIONS")
+		for _, r := range rs {
+			fmt.Fprintf(w, "%s\t%s\n", r.Name, strings.Join(r.Permissions, " "))
+		}
+		return w.Flush()
+	case "grant", "revoke":
+		if len(args) != 3 {
+			return errors.New(rolesUsage)
+		}
+		userID, err := strconv.Atoi(args[1])
+		if err != nil {
+			return err
+		}
+		if args[0] == "grant" {
+			return roles.Grant(ctx, userID, args[2])
+		}
+		return roles.Revoke(ctx, userID, args[2])
+	}
+
+	return errors.New(rolesUsage)
+}
@@ -2,7 +2,10 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 )
 
 var (
@@ -13,8 +16,18 @@ var (
 // Config is global config.
 type Config struct {
 	*Server
+	*Store
 	*PostgreSQL
+	*SQLite
 	*JWT
+	*Mail
+	*Notify
+	*Account
+	*OneTimeCode
+	*Lockout
+	*RateLimit
+	*MFA
+	*WebAuthn
 }
 
 // Server is server config.
@@ -22,6 +35,12 @@ type Server struct {
 	Addr string
 }
 
+// Store is data store config. Driver is pg, sqlite or memory, data of memory store
+// is lost on restart.
+type Store struct {
+	Driver string
+}
+
 // PostgreSQL is PostgreSQL config.
 type PostgreSQL struct {
 	Host     string
@@ -32,9 +51,114 @@ type PostgreSQL struct {
 	SSLMode  string
 }
 
-// JWT is Json Web Token config.
+// SQLite is SQLite config, Path is the path of the database file.
+type SQLite struct {
+	Path string
+}
+
+// JWT is Json Web Token config. KeyEncryptionKey is the base64 encoded AES-256 key
+// signing keys are encrypted with in the store.
 type JWT struct {
-	Secret string
+	Issuer           string
+	Audience         string
+	AccessTTL        time.Duration
+	RefreshTTL       time.Duration
+	MFATTL           time.Duration
+	WebAuthnTTL      time.Duration
+	Leeway           time.Duration
+	Algorithm        string
+	Secret           string
+	PrivateKeyPath   string
+	KeyID            string
+	KeyEncryptionKey string
+	KeyGracePeriod   time.Duration
+	KeyringRefresh   time.Duration
+	PurgeInterval    time.Duration
+}
+
+// Mail is mail sender config. Driver is one of smtp, file or log.
+type Mail struct {
+	Driver   string
+	From     string
+	Host     string
+	Port     string
+	Username string
+	Password string
+	Path     string
+}
+
+// Notify is user notification config, one-time codes are delivered with it. Driver is
+// mail or webhook, notifications of webhook driver are posted as JSON to WebhookURL,
+// e.g. to relay them by SMS.
+type Notify struct {
+	Driver         string
+	WebhookURL     string
+	WebhookTimeout time.Duration
+}
+
+// Account is user account management config. URL is the base URL of links sent
+// to users by email.
+type Account struct {
+	URL                  string
+	PasswordResetTTL     time.Duration
+	EmailVerificationTTL time.Duration
+	MagicLinkTTL         time.Duration
+	RequireVerifiedEmail bool
+}
+
+// OneTimeCode is numeric one-time code config. Codes expire in TTL and are invalidated
+// after MaxAttempts attempts, a new code can be requested ResendInterval after the
+// previous one. Key is the base64 encoded HMAC-SHA256 key codes are hashed with.
+type OneTimeCode struct {
+	TTL            time.Duration
+	MaxAttempts    int
+	ResendInterval time.Duration
+	Key            string
+}
+
+// Lockout is sign in brute-force protection config. Failures older than Window are
+// forgotten, the delay before the next attempt starts with Delay and doubles with
+// every failure, MaxFailures failures lock the account and IPMaxFailures failures
+// lock the client IP for Duration.
+type Lockout struct {
+	MaxFailures   int
+	IPMaxFailures int
+	Window        time.Duration
+	Delay         time.Duration
+	Duration      time.Duration
+}
+
+// RateLimit is rate limiting config. Backend is memory or postgres, Auth rate limits
+// authorization endpoints per client IP, API rate limits other endpoints per user or
+// API key. Buckets idle longer than PurgeInterval are purged.
+type RateLimit struct {
+	Backend       string
+	Auth          Rate
+	API           Rate
+	PurgeInterval time.Duration
+}
+
+// Rate is Limit requests per Period.
+type Rate struct {
+	Limit  int
+	Period time.Duration
+}
+
+// MFA is multi-factor authentication config. Issuer is the account issuer shown by
+// authenticator apps and EncryptionKey is the base64 encoded AES-256 key TOTP secrets
+// are encrypted with.
+type MFA struct {
+	Issuer        string
+	EncryptionKey string
+}
+
+// WebAuthn is WebAuthn(passkeys) config. RPID is the relying party ID credentials are
+// scoped to, the domain of the site or its registrable suffix. Origins are origins
+// ceremonies are accepted from and RPName is the site name authenticators show.
+type WebAuthn struct {
+	RPID    string
+	RPName  string
+	Origins []string
 }
 
 // Get reads config once and returns it.
@@ -44,6 +168,9 @@ func Get() *Config {
 			Server: &Server{
 				Addr: getEnv("SERVER_ADDR", ":8080"),
 			},
+			Store: &Store{
+				Driver: getEnv("STORE_DRIVER", "pg"),
+			},
 			PostgreSQL: &PostgreSQL{
 				Host:     getEnv("POSTGRES_HOST", "locahost"),
 				Port:     getEnv("POSTGRES_PORT", "5432"),
@@ -52,8 +179,74 @@ func Get() *Config {
 				DBName:   getEnv("POSTGRES_DBNAME", "jwt"),
 				SSLMode:  getEnv("POSTGRES_SSLMODE", "disable"),
 			},
+			SQLite: &SQLite{
+				Path: getEnv("SQLITE_PATH", "jwt.db"),
+			},
 			JWT: &JWT{
-				Secret: getEnv("JWT_SECRET", "jwt_secret"),
+				Issuer:           getEnv("JWT_ISSUER", "jwt-auth-example"),
+				Audience:         getEnv("JWT_AUDIENCE", "jwt-auth-example"),
+				AccessTTL:        getEnvDuration("JWT_ACCESS_TTL", 15*time.Minute),
+				RefreshTTL:       getEnvDuration("JWT_REFRESH_TTL", 24*time.Hour),
+				MFATTL:           getEnvDuration("JWT_MFA_TTL", 5*time.Minute),
+				WebAuthnTTL:      getEnvDuration("JWT_WEBAUTHN_TTL", 5*time.Minute),
+				Leeway:           getEnvDuration("JWT_LEEWAY", 30*time.Second),
+				Algorithm:        getEnv("JWT_ALGORITHM", "HS256"),
+				Secret:           getEnv("JWT_SECRET", "jwt_secret"),
+				PrivateKeyPath:   getEnv("JWT_PRIVATE_KEY_PATH", ""),
+				KeyID:            getEnv("JWT_KEY_ID", ""),
+				KeyEncryptionKey: getEnv("JWT_KEY_ENCRYPTION_KEY", ""),
+				KeyGracePeriod:   getEnvDuration("JWT_KEY_GRACE_PERIOD", 24*time.Hour),
+				KeyringRefresh:   getEnvDuration("JWT_KEYRING_REFRESH", time.Minute),
+				PurgeInterval:    getEnvPositiveDuration("JWT_PURGE_INTERVAL", time.Hour),
+			},
+			Mail: &Mail{
+				Driver:   getEnv("MAIL_DRIVER", "log"),
+				From:     getEnv("MAIL_FROM", "no-reply@jwt-auth-example"),
+				Host:     getEnv("SMTP_HOST", "localhost"),
+				Port:     getEnv("SMTP_PORT", "587"),
+				Username: getEnv("SMTP_USERNAME", ""),
+				Password: getEnv("SMTP_PASSWORD", ""),
+				Path:     getEnv("MAIL_FILE_PATH", "mail.log"),
+			},
+			Notify: &Notify{
+				Driver:         getEnv("NOTIFY_DRIVER", "mail"),
+				WebhookURL:     getEnv("NOTIFY_WEBHOOK_URL", ""),
+				WebhookTimeout: getEnvDuration("NOTIFY_WEBHOOK_TIMEOUT", 10*time.Second),
+			},
+			Account: &Account{
+				URL:                  getEnv("ACCOUNT_URL", "http://localhost:8080"),
+				PasswordResetTTL:     getEnvDuration("ACCOUNT_PASSWORD_RESET_TTL", time.Hour),
+				EmailVerificationTTL: getEnvDuration("ACCOUNT_EMAIL_VERIFICATION_TTL", 24*time.Hour),
+				MagicLinkTTL:         getEnvDuration("ACCOUNT_MAGIC_LINK_TTL", 15*time.Minute),
+				RequireVerifiedEmail: getEnvBool("ACCOUNT_REQUIRE_VERIFIED_EMAIL", false),
+			},
+			OneTimeCode: &OneTimeCode{
+				TTL:            getEnvDuration("OTP_TTL", 10*time.Minute),
+				MaxAttempts:    getEnvInt("OTP_MAX_ATTEMPTS", 5),
+				ResendInterval: getEnvDuration("OTP_RESEND_INTERVAL", time.Minute),
+				Key:            getEnv("OTP_KEY", ""),
+			},
+			Lockout: &Lockout{
+				MaxFailures:   getEnvInt("LOCKOUT_MAX_FAILURES", 5),
+				IPMaxFailures: getEnvInt("LOCKOUT_IP_MAX_FAILURES", 20),
+				Window:        getEnvDuration("LOCKOUT_WINDOW", 15*time.Minute),
+				Delay:         getEnvDuration("LOCKOUT_DELAY", time.Second),
+				Duration:      getEnvDuration("LOCKOUT_DURATION", 15*time.Minute),
+			},
+			RateLimit: &RateLimit{
+				Backend:       getEnv("RATE_LIMIT_BACKEND", "memory"),
+				Auth:          getEnvRate("RATE_LIMIT_AUTH", Rate{Limit: 10, Period: time.Minute}),
+				API:           getEnvRate("RATE_LIMIT_API", Rate{Limit: 100, Period: time.Minute}),
+				PurgeInterval: getEnvPositiveDuration("RATE_LIMIT_PURGE_INTERVAL", time.Hour),
+			},
+			MFA: &MFA{
+				Issuer:        getEnv("MFA_ISSUER", "jwt-auth-example"),
+				EncryptionKey: getEnv("MFA_ENCRYPTION_KEY", ""),
+			},
+			WebAuthn: &WebAuthn{
+				RPID:    getEnv("WEBAUTHN_RP_ID", "localhost"),
+				RPName:  getEnv("WEBAUTHN_RP_NAME", "jwt-auth-example"),
+				Origins: getEnvList("WEBAUTHN_ORIGINS", []string{"http://localhost:8080"}),
 			},
 		}
 	})
@@ -70,3 +263,81 @@ func getEnv(key, defaultValue string) string {
 
 	return value
 }
+
+// getEnvDuration is the getEnv for time.Duration values, default value is also used
+// if environment variable couldn't be parsed.
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+
+	return value
+}
+
+// getEnvPositiveDuration is the getEnvDuration for durations which must be positive,
+// e.g. ticker intervals, default value is also used if the duration isn't positive.
+func getEnvPositiveDuration(key string, defaultValue time.Duration) time.Duration {
+	value := getEnvDuration(key, defaultValue)
+	if value <= 0 {
+		return defaultValue
+	}
+
+	return value
+}
+
+// getEnvInt is the getEnv for int values, default value is also used if environment
+// variable couldn't be parsed.
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+
+	return value
+}
+
+// getEnvBool is the getEnv for bool values, default value is also used if environment
+// variable couldn't be parsed.
+func getEnvBool(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+
+	return value
+}
+
+// getEnvList is the getEnv for comma separated lists, empty items are dropped.
+func getEnvList(key string, defaultValue []string) []string {
+	values := []string{}
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	if len(values) == 0 {
+		return defaultValue
+	}
+
+	return values
+}
+
+// getEnvRate is the getEnv for rates in "<limit>/<period>" format, e.g. "10/1m".
+// Default value is also used if environment variable couldn't be parsed.
+func getEnvRate(key string, defaultValue Rate) Rate {
+	parts := strings.SplitN(os.Getenv(key), "/", 2)
+	if len(parts) != 2 {
+		return defaultValue
+	}
+	limit, err := strconv.Atoi(parts[0])
+	if err != nil || limit <= 0 {
+		return defaultValue
+	}
+	period, err := time.ParseDuration(parts[1])
+	if err != nil || period <= 0 {
+		return defaultValue
+	}
+
+	return Rate{Limit: limit, Period: period}
+}
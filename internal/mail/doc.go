@@ -0,0 +1,2 @@
+// Package mail provides mail senders.
+package mail
@@ -0,0 +1,61 @@
+package mail
+
+import (
+	"os"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/imarrche/jwt-auth-example/internal/logger"
+)
+
+// FileMailer appends messages to a file instead of sending them, it's meant for
+// tests and local development.
+type FileMailer struct {
+	mu   sync.Mutex
+	from string
+	path string
+}
+
+// NewFileMailer creates and returns a new FileMailer instance.
+func NewFileMailer(from, path string) *FileMailer {
+	return &FileMailer{from: from, path: path}
+}
+
+// Send appends the message to the file.
+func (m *FileMailer) Send(msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := os.OpenFile(m.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(format(m.from, msg)); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// LogMailer logs messages instead of sending them, it's meant for local development.
+type LogMailer struct {
+	from string
+}
+
+// NewLogMailer creates and returns a new LogMailer instance.
+func NewLogMailer(from string) *LogMailer { return &LogMailer{from: from} }
+
+// Send logs the message.
+func (m *LogMailer) Send(msg Message) error {
+	logger.Get().Info(
+		"mail",
+		zap.String("from", m.from),
+		zap.String("to", msg.To),
+		zap.String("subject", msg.Subject),
+		zap.String("body", msg.Body),
+	)
+
+	return nil
+}
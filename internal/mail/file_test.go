@@ -0,0 +1,36 @@
+package mail
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileMailer_Send(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mail")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "mail.log")
+	m := NewFileMailer("no-reply@example.com", path)
+
+	msgs := []Message{
+		{To: "user1@example.com", Subject: "Subject1", Body: "Body1"},
+		{To: "user2@example.com", Subject: "Subject2", Body: "Body2"},
+	}
+	for _, msg := range msgs {
+		assert.NoError(t, m.Send(msg))
+	}
+
+	b, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	var exp []byte
+	for _, msg := range msgs {
+		exp = append(exp, format("no-reply@example.com", msg)...)
+	}
+	assert.Equal(t, string(exp), string(b))
+}
@@ -0,0 +1,43 @@
+package mail
+
+import (
+	"fmt"
+
+	"github.com/imarrche/jwt-auth-example/internal/config"
+)
+
+//go:generate mockgen -source=mailer.go -destination=mocks/mock.go
+
+// Message is a plain text email message.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer is the interface all mail senders must implement.
+type Mailer interface {
+	Send(Message) error
+}
+
+// New creates and returns a new mailer of the driver set in mail config.
+func New(c *config.Mail) (Mailer, error) {
+	switch c.Driver {
+	case "smtp":
+		return NewSMTPMailer(c), nil
+	case "file":
+		return NewFileMailer(c.From, c.Path), nil
+	case "log":
+		return NewLogMailer(c.From), nil
+	}
+
+	return nil, fmt.Errorf("unknown mail driver: %s", c.Driver)
+}
+
+// format formats message as RFC 5322 email.
+func format(from string, m Message) []byte {
+	return []byte(fmt.Sprintf(
+		"From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
+		from, m.To, m.Subject, m.Body,
+	))
+}
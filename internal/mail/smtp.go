@@ -0,0 +1,31 @@
+package mail
+
+import (
+	"net"
+	"net/smtp"
+
+	"github.com/imarrche/jwt-auth-example/internal/config"
+)
+
+// SMTPMailer sends messages via SMTP server.
+type SMTPMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// NewSMTPMailer creates and returns a new SMTPMailer instance. PLAIN authentication
+// is used if username is set.
+func NewSMTPMailer(c *config.Mail) *SMTPMailer {
+	m := &SMTPMailer{addr: net.JoinHostPort(c.Host, c.Port), from: c.From}
+	if c.Username != "" {
+		m.auth = smtp.PlainAuth("", c.Username, c.Password, c.Host)
+	}
+
+	return m
+}
+
+// Send sends the message.
+func (m *SMTPMailer) Send(msg Message) error {
+	return smtp.SendMail(m.addr, m.auth, m.from, []string{msg.To}, format(m.from, msg))
+}
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// Actions recorded in the audit trail.
+const (
+	AuditUserCreate         = "user.create"
+	AuditUserUpdate         = "user.update"
+	AuditUserDisable        = "user.disable"
+	AuditUserEnable         = "user.enable"
+	AuditUserDelete         = "user.delete"
+	AuditUserPasswordReset  = "user.password_reset"
+	AuditUserSessionsRevoke = "user.sessions_revoke"
+	AuditUserRoleGrant      = "user.role_grant"
+	AuditUserRoleRevoke     = "user.role_revoke"
+	AuditUserUnlock         = "user.unlock"
+)
+
+// AuditEvent model represents an action an admin performed on a user. Events outlive
+// both users, so they don't reference them.
+type AuditEvent struct {
+	ID        int       `json:"id" db:"id"`
+	ActorID   int       `json:"actor_id" db:"actor_id"`
+	Action    string    `json:"action" db:"action"`
+	UserID    int       `json:"user_id" db:"user_id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// EmailVerificationToken model represents an issued email verification token. Only
+// the SHA-256 hash of the token is kept, the token itself is sent to the user. Email
+// is the address the token was sent to, the token verifies only this address.
+type EmailVerificationToken struct {
+	Hash      string     `json:"-" db:"hash"`
+	UserID    int        `json:"user_id" db:"user_id"`
+	Email     string     `json:"email" db:"email"`
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+	UsedAt    *time.Time `json:"used_at" db:"used_at"`
+}
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// LoginThrottle model represents failed sign in attempts of an account or a client IP.
+//
+// Key identifies what's throttled, e.g. "user:1" or "ip:127.0.0.1".
+type LoginThrottle struct {
+	Key           string     `json:"key" db:"key"`
+	Failures      int        `json:"failures" db:"failures"`
+	LastFailureAt time.Time  `json:"last_failure_at" db:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until" db:"locked_until"`
+}
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// MagicLinkToken model represents an issued magic link token. Only SHA-256 hashes of
+// the token and of the device secret it's bound to are kept, the token itself is sent
+// to the user and the device secret is returned to the client requesting the link.
+type MagicLinkToken struct {
+	Hash       string     `json:"-" db:"hash"`
+	UserID     int        `json:"user_id" db:"user_id"`
+	DeviceHash string     `json:"-" db:"device_hash"`
+	ExpiresAt  time.Time  `json:"expires_at" db:"expires_at"`
+	UsedAt     *time.Time `json:"used_at" db:"used_at"`
+}
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// OneTimeCode model represents a numeric one-time code sent to the user to sign in or
+// to confirm a sensitive action with. The user has at most one code per purpose, only
+// SHA-256 hash of the code is kept.
+type OneTimeCode struct {
+	UserID    int       `json:"user_id" db:"user_id"`
+	Purpose   string    `json:"purpose" db:"purpose"`
+	Hash      string    `json:"-" db:"hash"`
+	Attempts  int       `json:"attempts" db:"attempts"`
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// Reauthentication is the proof of the user's identity required before sensitive
+// actions, either the current password or step-up one-time code.
+type Reauthentication struct {
+	Password string
+	Code     string
+}
@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// PasswordResetToken model represents an issued password reset token. Only the
+// SHA-256 hash of the token is kept, the token itself is sent to the user.
+type PasswordResetToken struct {
+	Hash      string     `json:"-" db:"hash"`
+	UserID    int        `json:"user_id" db:"user_id"`
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+	UsedAt    *time.Time `json:"used_at" db:"used_at"`
+}
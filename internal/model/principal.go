@@ -0,0 +1,21 @@
+package model
+
+// Principal model represents the authenticated user a request is made by.
+type Principal struct {
+	UserID      int
+	TokenID     string
+	Roles       []string
+	Permissions []string
+	Claims      map[string]interface{}
+}
+
+// HasPermission reports whether principal is granted specific permission.
+func (p Principal) HasPermission(permission string) bool {
+	for _, pp := range p.Permissions {
+		if pp == permission {
+			return true
+		}
+	}
+
+	return false
+}
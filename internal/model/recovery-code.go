@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+// RecoveryCode model represents a single use MFA recovery code of a user. Only the
+// SHA-256 hash of the code is stored, the code is shown to the user once.
+type RecoveryCode struct {
+	UserID int        `json:"-" db:"user_id"`
+	Hash   string     `json:"-" db:"hash"`
+	UsedAt *time.Time `json:"-" db:"used_at"`
+}
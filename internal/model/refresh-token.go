@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// RefreshToken model represents an issued refresh JSON Web Token.
+//
+// All refresh tokens obtained by rotation from the same sign in share a family ID,
+// so the whole family can be revoked when a rotated token is presented again.
+type RefreshToken struct {
+	ID        string     `json:"id" db:"id"`
+	FamilyID  string     `json:"family_id" db:"family_id"`
+	UserID    int        `json:"user_id" db:"user_id"`
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+	UsedAt    *time.Time `json:"used_at" db:"used_at"`
+	RevokedAt *time.Time `json:"revoked_at" db:"revoked_at"`
+}
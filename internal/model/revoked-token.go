@@ -0,0 +1,9 @@
+package model
+
+import "time"
+
+// RevokedToken model represents a revoked JSON Web Token.
+type RevokedToken struct {
+	ID        string    `json:"id" db:"id"`
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+}
@@ -0,0 +1,8 @@
+package model
+
+// Role model represents a role with its permissions.
+type Role struct {
+	ID          int      `json:"id" db:"id"`
+	Name        string   `json:"name" db:"name"`
+	Permissions []string `json:"permissions" db:"permissions"`
+}
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// Signing key statuses. Pending keys only verify JSON Web Tokens, the active key
+// signs and verifies them and retired keys verify them during the grace period.
+const (
+	SigningKeyPending = "pending"
+	SigningKeyActive  = "active"
+	SigningKeyRetired = "retired"
+)
+
+// SigningKey model represents a JSON Web Token signing key.
+type SigningKey struct {
+	ID          string     `json:"id" db:"id"`
+	Algorithm   string     `json:"algorithm" db:"algorithm"`
+	Material    string     `json:"-" db:"material"`
+	Status      string     `json:"status" db:"status"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	ActivatedAt *time.Time `json:"activated_at" db:"activated_at"`
+	RetiredAt   *time.Time `json:"retired_at" db:"retired_at"`
+}
@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// TOTPSecret model represents the time-based one-time password (RFC 6238) secret of a
+// user. The secret is kept encrypted, multi-factor authentication is enabled once the
+// secret is confirmed with a valid code. LastUsedStep is the time step of the last
+// accepted code, so codes can't be replayed.
+type TOTPSecret struct {
+	UserID       int        `json:"-" db:"user_id"`
+	Secret       []byte     `json:"-" db:"secret"`
+	ConfirmedAt  *time.Time `json:"confirmed_at" db:"confirmed_at"`
+	LastUsedStep int64      `json:"-" db:"last_used_step"`
+}
+
+// TOTPEnrollment is the TOTP secret issued to a user on enrollment. Secret is base32
+// encoded and URI is the otpauth:// provisioning URI authenticator apps read from QR
+// codes.
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// SignInResult is the result of signing in. Access and refresh tokens are issued to
+// authenticated users, users with multi-factor authentication enabled get MFA token
+// instead to complete signing in with.
+type SignInResult struct {
+	AccessToken  string
+	RefreshToken string
+	MFAToken     string
+}
+
+// MFAStatus is the multi-factor authentication state of a user shown on the profile.
+type MFAStatus struct {
+	TOTPEnabled   bool `json:"totp_enabled"`
+	RecoveryCodes int  `json:"recovery_codes"`
+}
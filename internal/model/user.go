@@ -1,19 +1,56 @@
 package model
 
 import (
+	"time"
+
 	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/go-ozzo/ozzo-validation/is"
 )
 
 // User model represents a user.
 type User struct {
-	ID           int    `json:"id" db:"id"`
-	Username     string `json:"username" db:"username"`
-	Email        string `json:"email" db:"email"`
-	FirstName    string `json:"first_name" db:"first_name"`
-	SecondName   string `json:"second_name" db:"second_name"`
-	Password     string `json:"password,omitempty"`
-	PasswordHash string `json:"-" db:"password_hash"`
+	ID              int        `json:"id" db:"id"`
+	Username        string     `json:"username" db:"username"`
+	Email           string     `json:"email" db:"email"`
+	FirstName       string     `json:"first_name" db:"first_name"`
+	SecondName      string     `json:"second_name" db:"second_name"`
+	Password        string     `json:"password,omitempty"`
+	PasswordHash    string     `json:"-" db:"password_hash"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at" db:"email_verified_at"`
+	DisabledAt      *time.Time `json:"disabled_at" db:"disabled_at"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Fields users can be sorted by.
+const (
+	UserSortID        = "id"
+	UserSortUsername  = "username"
+	UserSortEmail     = "email"
+	UserSortCreatedAt = "created_at"
+)
+
+// UserQuery is the query of users listing. Users are filtered by case insensitive
+// email and username prefixes, creation time and whether email is verified, empty
+// filters are ignored. Users are sorted by Sort field with ties broken by ID and
+// listed after the After user if it's set, up to Limit users are listed.
+type UserQuery struct {
+	EmailPrefix    string
+	UsernamePrefix string
+	CreatedAfter   *time.Time
+	CreatedBefore  *time.Time
+	Verified       *bool
+	Sort           string
+	Desc           bool
+	After          *User
+	Limit          int
+}
+
+// UserPage is the page of users listing. Total is the number of users matching the
+// query and NextCursor is the cursor of the next page, it's empty on the last page.
+type UserPage struct {
+	Users      []User `json:"users"`
+	Total      int    `json:"total"`
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // Validate validates user's fields.
@@ -27,3 +64,29 @@ func (u *User) Validate() error {
 		validation.Field(&u.Password, validation.Required, validation.Length(8, 256)),
 	)
 }
+
+// ValidateAccount validates user's fields admins can edit.
+func (u *User) ValidateAccount() error {
+	return validation.ValidateStruct(
+		u,
+		validation.Field(&u.Username, validation.Required, validation.Length(3, 30)),
+		validation.Field(&u.Email, validation.Required, is.Email),
+		validation.Field(&u.FirstName, validation.Required, validation.Length(0, 50)),
+		validation.Field(&u.SecondName, validation.Required, validation.Length(0, 50)),
+	)
+}
+
+// ValidateProfile validates user's profile fields.
+func (u *User) ValidateProfile() error {
+	return validation.ValidateStruct(
+		u,
+		validation.Field(&u.Username, validation.Required, validation.Length(3, 30)),
+		validation.Field(&u.FirstName, validation.Required, validation.Length(0, 50)),
+		validation.Field(&u.SecondName, validation.Required, validation.Length(0, 50)),
+	)
+}
+
+// ValidatePassword validates user's password.
+func ValidatePassword(password string) error {
+	return validation.Validate(password, validation.Required, validation.Length(8, 256))
+}
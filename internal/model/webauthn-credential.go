@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// WebAuthnCredential model represents a WebAuthn public key credential(passkey) of a
+// user. ID is the base64url encoded credential ID and PublicKey is COSE encoded.
+// SignCount is the last signature counter the authenticator reported, it must grow
+// with every assertion unless the authenticator doesn't count signatures at all.
+type WebAuthnCredential struct {
+	ID         string     `json:"id" db:"id"`
+	UserID     int        `json:"-" db:"user_id"`
+	Name       string     `json:"name" db:"name"`
+	PublicKey  []byte     `json:"-" db:"public_key"`
+	SignCount  int64      `json:"-" db:"sign_count"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at" db:"last_used_at"`
+}
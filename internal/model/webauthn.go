@@ -0,0 +1,123 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+)
+
+// Base64URL is binary data encoded in JSON as base64url string, the way WebAuthn JSON
+// serialization encodes it. Padding is omitted but tolerated.
+type Base64URL []byte
+
+// MarshalJSON encodes the data as unpadded base64url string.
+func (b Base64URL) MarshalJSON() ([]byte, error) {
+	return json.Marshal(base64.RawURLEncoding.EncodeToString(b))
+}
+
+// UnmarshalJSON decodes the data from base64url string.
+func (b *Base64URL) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return err
+	}
+	*b = decoded
+
+	return nil
+}
+
+// WebAuthnCreationOptions model represents options of navigator.credentials.create()
+// call registering a WebAuthn credential.
+type WebAuthnCreationOptions struct {
+	RP                     WebAuthnRelyingParty           `json:"rp"`
+	User                   WebAuthnUser                   `json:"user"`
+	Challenge              Base64URL                      `json:"challenge"`
+	PubKeyCredParams       []WebAuthnCredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                          `json:"timeout"`
+	ExcludeCredentials     []WebAuthnCredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection WebAuthnAuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                         `json:"attestation"`
+}
+
+// WebAuthnRequestOptions model represents options of navigator.credentials.get() call
+// asserting a WebAuthn credential. Allowed credentials are empty, so authenticators
+// offer all discoverable credentials(passkeys) of the relying party.
+type WebAuthnRequestOptions struct {
+	Challenge        Base64URL                      `json:"challenge"`
+	Timeout          int64                          `json:"timeout"`
+	RPID             string                         `json:"rpId"`
+	AllowCredentials []WebAuthnCredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                         `json:"userVerification"`
+}
+
+// WebAuthnRelyingParty model represents the relying party credentials are scoped to.
+type WebAuthnRelyingParty struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// WebAuthnUser model represents the user a credential is registered for, ID is the
+// user handle authenticators return on assertion.
+type WebAuthnUser struct {
+	ID          Base64URL `json:"id"`
+	Name        string    `json:"name"`
+	DisplayName string    `json:"displayName"`
+}
+
+// WebAuthnCredentialParameter model represents a public key algorithm credentials can
+// be created with.
+type WebAuthnCredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int    `json:"alg"`
+}
+
+// WebAuthnCredentialDescriptor model represents a registered credential.
+type WebAuthnCredentialDescriptor struct {
+	Type string    `json:"type"`
+	ID   Base64URL `json:"id"`
+}
+
+// WebAuthnAuthenticatorSelection model represents requirements to authenticators.
+type WebAuthnAuthenticatorSelection struct {
+	ResidentKey        string `json:"residentKey"`
+	RequireResidentKey bool   `json:"requireResidentKey"`
+	UserVerification   string `json:"userVerification"`
+}
+
+// WebAuthnAttestation model represents the public key credential navigator.credentials
+// .create() returns, serialized the way its toJSON() does.
+type WebAuthnAttestation struct {
+	ID       string                      `json:"id"`
+	RawID    Base64URL                   `json:"rawId"`
+	Type     string                      `json:"type"`
+	Response WebAuthnAttestationResponse `json:"response"`
+}
+
+// WebAuthnAttestationResponse model represents the authenticator response to credential
+// creation.
+type WebAuthnAttestationResponse struct {
+	ClientDataJSON    Base64URL `json:"clientDataJSON"`
+	AttestationObject Base64URL `json:"attestationObject"`
+}
+
+// WebAuthnAssertion model represents the public key credential navigator.credentials
+// .get() returns, serialized the way its toJSON() does.
+type WebAuthnAssertion struct {
+	ID       string                    `json:"id"`
+	RawID    Base64URL                 `json:"rawId"`
+	Type     string                    `json:"type"`
+	Response WebAuthnAssertionResponse `json:"response"`
+}
+
+// WebAuthnAssertionResponse model represents the authenticator response to credential
+// assertion.
+type WebAuthnAssertionResponse struct {
+	ClientDataJSON    Base64URL `json:"clientDataJSON"`
+	AuthenticatorData Base64URL `json:"authenticatorData"`
+	Signature         Base64URL `json:"signature"`
+	UserHandle        Base64URL `json:"userHandle"`
+}
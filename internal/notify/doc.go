@@ -0,0 +1,2 @@
+// Package notify provides notifiers delivering short messages to users.
+package notify
@@ -0,0 +1,54 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/imarrche/jwt-auth-example/internal/config"
+	"github.com/imarrche/jwt-auth-example/internal/mail"
+	"github.com/imarrche/jwt-auth-example/internal/model"
+)
+
+//go:generate mockgen -source=notifier.go -destination=mocks/mock.go
+
+// Message is a short plain text message for a user.
+type Message struct {
+	User    model.User
+	Subject string
+	Text    string
+}
+
+// Notifier is the interface all notifiers must implement.
+type Notifier interface {
+	Notify(context.Context, Message) error
+}
+
+// New creates and returns a new notifier of the driver set in notify config, messages
+// of mail driver are sent with the mailer.
+func New(c *config.Notify, m mail.Mailer) (Notifier, error) {
+	switch c.Driver {
+	case "mail":
+		return NewMailNotifier(m), nil
+	case "webhook":
+		if c.WebhookURL == "" {
+			return nil, errors.New("notify webhook URL is not set")
+		}
+		return NewWebhookNotifier(c.WebhookURL, c.WebhookTimeout), nil
+	}
+
+	return nil, fmt.Errorf("unknown notify driver: %s", c.Driver)
+}
+
+// MailNotifier sends messages to users' emails.
+type MailNotifier struct {
+	mailer mail.Mailer
+}
+
+// NewMailNotifier creates and returns a new MailNotifier instance.
+func NewMailNotifier(m mail.Mailer) *MailNotifier { return &MailNotifier{mailer: m} }
+
+// Notify sends the message to the user's email.
+func (n *MailNotifier) Notify(_ context.Context, msg Message) error {
+	return n.mailer.Send(mail.Message{To: msg.User.Email, Subject: msg.Subject, Body: msg.Text})
+}
@@ -0,0 +1,74 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imarrche/jwt-auth-example/internal/mail"
+	mock_mail "github.com/imarrche/jwt-auth-example/internal/mail/mocks"
+	"github.com/imarrche/jwt-auth-example/internal/model"
+)
+
+func TestMailNotifier_Notify(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	m := mock_mail.NewMockMailer(c)
+	m.EXPECT().Send(mail.Message{To: "user1@test.com", Subject: "Subject", Body: "Text"})
+	msg := Message{User: model.User{Email: "user1@test.com"}, Subject: "Subject", Text: "Text"}
+
+	assert.NoError(t, NewMailNotifier(m).Notify(context.Background(), msg))
+}
+
+func TestWebhookNotifier_Notify(t *testing.T) {
+	testcases := []struct {
+		name     string
+		status   int
+		expError bool
+	}{
+		{
+			name:   "message is posted",
+			status: http.StatusNoContent,
+		},
+		{
+			name:     "receiver fails",
+			status:   http.StatusBadGateway,
+			expError: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var payload webhookPayload
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+				w.WriteHeader(tc.status)
+			}))
+			defer server.Close()
+			msg := Message{
+				User:    model.User{ID: 1, Username: "user1", Email: "user1@test.com"},
+				Subject: "Subject",
+				Text:    "Text",
+			}
+
+			err := NewWebhookNotifier(server.URL, time.Second).Notify(context.Background(), msg)
+
+			if tc.expError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, webhookPayload{
+				UserID: 1, Username: "user1", Email: "user1@test.com", Subject: "Subject", Text: "Text",
+			}, payload)
+		})
+	}
+}
@@ -0,0 +1,2 @@
+// Package ratelimit provides token bucket rate limiters.
+package ratelimit
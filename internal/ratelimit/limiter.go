@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/imarrche/jwt-auth-example/internal/config"
+	"github.com/imarrche/jwt-auth-example/internal/logger"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// Result is the result of taking a token from a bucket.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// Limiter is the interface all rate limiters must implement.
+type Limiter interface {
+	Allow(context.Context, string, config.Rate) (Result, error)
+	Purge(context.Context, time.Time) error
+}
+
+// New creates and returns a new limiter of the backend set in rate limit config.
+func New(c *config.RateLimit, s store.Store) (Limiter, error) {
+	switch c.Backend {
+	case "memory":
+		return NewMemoryLimiter(), nil
+	case "postgres":
+		return NewStoreLimiter(s.RateLimits()), nil
+	}
+
+	return nil, fmt.Errorf("unknown rate limit backend: %s", c.Backend)
+}
+
+// newResult returns the result for the bucket with tokens left after taking a token.
+// The bucket is full once it's refilled up to the limit and the next token can be
+// taken once it's refilled up to one token.
+func newResult(rate config.Rate, tokens float64, allowed bool) Result {
+	perToken := float64(rate.Period) / float64(rate.Limit)
+	r := Result{
+		Allowed:   allowed,
+		Limit:     rate.Limit,
+		Remaining: int(math.Max(0, math.Floor(tokens))),
+		Reset:     time.Duration((float64(rate.Limit) - tokens) * perToken),
+	}
+	if !allowed {
+		r.RetryAfter = time.Duration((1 - tokens) * perToken)
+	}
+
+	return r
+}
+
+// PurgeIdle purges buckets idle longer than interval every interval until done is
+// closed. Interval shouldn't be shorter than the longest rate period.
+func PurgeIdle(l Limiter, interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := l.Purge(context.Background(), time.Now().Add(-interval)); err != nil {
+				logger.Get().Error("couldn't purge rate limit buckets", zap.Error(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/imarrche/jwt-auth-example/internal/config"
+)
+
+// bucket is a token bucket.
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// MemoryLimiter keeps buckets in memory, so limits aren't shared between instances.
+type MemoryLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// NewMemoryLimiter creates and returns a new MemoryLimiter instance.
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{buckets: map[string]*bucket{}}
+}
+
+// Allow takes a token from the bucket with specific key.
+func (l *MemoryLimiter) Allow(_ context.Context, key string, rate config.Rate) (Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(rate.Limit), updatedAt: now}
+		l.buckets[key] = b
+	}
+
+	perSecond := float64(rate.Limit) / rate.Period.Seconds()
+	tokens := math.Min(float64(rate.Limit), b.tokens+now.Sub(b.updatedAt).Seconds()*perSecond)
+	if tokens < 1 {
+		return newResult(rate, tokens, false), nil
+	}
+	b.tokens, b.updatedAt = tokens-1, now
+
+	return newResult(rate, b.tokens, true), nil
+}
+
+// Purge deletes buckets which haven't been updated since specific time.
+func (l *MemoryLimiter) Purge(_ context.Context, before time.Time) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, b := range l.buckets {
+		if b.updatedAt.Before(before) {
+			delete(l.buckets, key)
+		}
+	}
+
+	return nil
+}
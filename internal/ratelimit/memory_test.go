@@ -0,0 +1,61 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imarrche/jwt-auth-example/internal/config"
+)
+
+func TestMemoryLimiter_Allow(t *testing.T) {
+	l := NewMemoryLimiter()
+	rate := config.Rate{Limit: 2, Period: time.Minute}
+
+	r, err := l.Allow(context.Background(), "key1", rate)
+	assert.NoError(t, err)
+	assert.True(t, r.Allowed)
+	assert.Equal(t, 2, r.Limit)
+	assert.Equal(t, 1, r.Remaining)
+
+	r, err = l.Allow(context.Background(), "key1", rate)
+	assert.NoError(t, err)
+	assert.True(t, r.Allowed)
+	assert.Equal(t, 0, r.Remaining)
+
+	r, err = l.Allow(context.Background(), "key1", rate)
+	assert.NoError(t, err)
+	assert.False(t, r.Allowed)
+	assert.Equal(t, 0, r.Remaining)
+	assert.InDelta(t, float64(30*time.Second), float64(r.RetryAfter), float64(time.Second))
+	assert.InDelta(t, float64(time.Minute), float64(r.Reset), float64(time.Second))
+
+	r, err = l.Allow(context.Background(), "key2", rate)
+	assert.NoError(t, err)
+	assert.True(t, r.Allowed)
+}
+
+func TestMemoryLimiter_Allow_Refill(t *testing.T) {
+	l := NewMemoryLimiter()
+	rate := config.Rate{Limit: 2, Period: time.Minute}
+	l.buckets["key1"] = &bucket{tokens: 0, updatedAt: time.Now().Add(-45 * time.Second)}
+
+	r, err := l.Allow(context.Background(), "key1", rate)
+
+	assert.NoError(t, err)
+	assert.True(t, r.Allowed)
+	assert.Equal(t, 0, r.Remaining)
+}
+
+func TestMemoryLimiter_Purge(t *testing.T) {
+	l := NewMemoryLimiter()
+	l.buckets["stale"] = &bucket{updatedAt: time.Now().Add(-time.Hour)}
+	l.buckets["fresh"] = &bucket{updatedAt: time.Now()}
+
+	assert.NoError(t, l.Purge(context.Background(), time.Now().Add(-time.Minute)))
+
+	assert.NotContains(t, l.buckets, "stale")
+	assert.Contains(t, l.buckets, "fresh")
+}
@@ -0,0 +1,32 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/imarrche/jwt-auth-example/internal/config"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// StoreLimiter keeps buckets in the store, so limits are shared between instances.
+type StoreLimiter struct {
+	repo store.RateLimitRepo
+}
+
+// NewStoreLimiter creates and returns a new StoreLimiter instance.
+func NewStoreLimiter(r store.RateLimitRepo) *StoreLimiter { return &StoreLimiter{repo: r} }
+
+// Allow takes a token from the bucket with specific key.
+func (l *StoreLimiter) Allow(ctx context.Context, key string, rate config.Rate) (Result, error) {
+	tokens, allowed, err := l.repo.Take(ctx, key, rate.Limit, rate.Period)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return newResult(rate, tokens, allowed), nil
+}
+
+// Purge deletes buckets which haven't been updated since specific time.
+func (l *StoreLimiter) Purge(ctx context.Context, before time.Time) error {
+	return l.repo.DeleteStale(ctx, before)
+}
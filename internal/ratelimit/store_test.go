@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imarrche/jwt-auth-example/internal/config"
+	mock_store "github.com/imarrche/jwt-auth-example/internal/store/mocks"
+)
+
+func TestStoreLimiter_Allow(t *testing.T) {
+	rate := config.Rate{Limit: 10, Period: time.Minute}
+
+	testcases := []struct {
+		name      string
+		mock      func(*mock_store.MockRateLimitRepo)
+		expResult Result
+		expError  bool
+	}{
+		{
+			name: "request is allowed",
+			mock: func(r *mock_store.MockRateLimitRepo) {
+				r.EXPECT().Take(gomock.Any(), "key1", rate.Limit, rate.Period).Return(4.0, true, nil)
+			},
+			expResult: Result{Allowed: true, Limit: 10, Remaining: 4, Reset: 36 * time.Second},
+			expError:  false,
+		},
+		{
+			name: "request is limited",
+			mock: func(r *mock_store.MockRateLimitRepo) {
+				r.EXPECT().Take(gomock.Any(), "key1", rate.Limit, rate.Period).Return(0.5, false, nil)
+			},
+			expResult: Result{
+				Allowed: false, Limit: 10, Remaining: 0,
+				Reset: 57 * time.Second, RetryAfter: 3 * time.Second,
+			},
+			expError: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			repo := mock_store.NewMockRateLimitRepo(c)
+			tc.mock(repo)
+			r, err := NewStoreLimiter(repo).Allow(context.Background(), "key1", rate)
+
+			if !tc.expError {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expResult, r)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/service"
+)
+
+// listUsers returns the page of users matching the query, users are sorted by the
+// sort parameter, descending order is set with "-" prefix.
+func (s *Server) listUsers() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		values := r.URL.Query()
+		q, err := parseUserQuery(values)
+		if err != nil {
+			s.error(w, r, service.ErrMalformedRequest.Wrap(err))
+			return
+		}
+
+		page, err := s.service.Users().List(r.Context(), q, values.Get("cursor"))
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, page)
+	}
+}
+
+// userRoles returns roles of the user.
+func (s *Server) userRoles() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			s.error(w, r, service.ErrMalformedRequest.Wrap(err))
+			return
+		}
+
+		roles, err := s.service.Roles().GetByUserID(r.Context(), userID)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, roles)
+	}
+}
+
+// createUser creates a user.
+func (s *Server) createUser() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p, ok := PrincipalFromContext(r.Context())
+		if !ok {
+			s.error(w, r, service.ErrUnauthorized)
+			return
+		}
+
+		var req signUpRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.error(w, r, service.ErrMalformedRequest.Wrap(err))
+			return
+		}
+
+		u, err := s.service.Admin().CreateUser(r.Context(), p.UserID, req.user())
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, u)
+	}
+}
+
+// getUser returns the user.
+func (s *Server) getUser() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			s.error(w, r, service.ErrMalformedRequest.Wrap(err))
+			return
+		}
+
+		u, err := s.service.Users().GetByID(r.Context(), userID)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, u)
+	}
+}
+
+type updateUserRequest struct {
+	Username   string `json:"username"`
+	Email      string `json:"email"`
+	FirstName  string `json:"first_name"`
+	SecondName string `json:"second_name"`
+}
+
+// updateUser updates the user, omitted fields are left unchanged.
+func (s *Server) updateUser() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p, ok := PrincipalFromContext(r.Context())
+		if !ok {
+			s.error(w, r, service.ErrUnauthorized)
+			return
+		}
+		userID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			s.error(w, r, service.ErrMalformedRequest.Wrap(err))
+			return
+		}
+
+		var req updateUserRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.error(w, r, service.ErrMalformedRequest.Wrap(err))
+			return
+		}
+
+		u, err := s.service.Admin().UpdateUser(r.Context(), p.UserID, model.User{
+			ID:         userID,
+			Username:   req.Username,
+			Email:      req.Email,
+			FirstName:  req.FirstName,
+			SecondName: req.SecondName,
+		})
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, u)
+	}
+}
+
+// adminAction returns a handler performing the user management action on the user on
+// behalf of the authenticated admin, e.g. service.Admin.DisableUser.
+func (s *Server) adminAction(
+	action func(service.Admin, context.Context, int, int) error,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p, ok := PrincipalFromContext(r.Context())
+		if !ok {
+			s.error(w, r, service.ErrUnauthorized)
+			return
+		}
+		userID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			s.error(w, r, service.ErrMalformedRequest.Wrap(err))
+			return
+		}
+
+		if err := action(s.service.Admin(), r.Context(), p.UserID, userID); err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, nil)
+	}
+}
+
+// roleAction returns a handler performing the role management action on the user on
+// behalf of the authenticated admin, e.g. service.Admin.GrantRole.
+func (s *Server) roleAction(
+	action func(service.Admin, context.Context, int, int, string) error,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p, ok := PrincipalFromContext(r.Context())
+		if !ok {
+			s.error(w, r, service.ErrUnauthorized)
+			return
+		}
+		userID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			s.error(w, r, service.ErrMalformedRequest.Wrap(err))
+			return
+		}
+
+		role := chi.URLParam(r, "role")
+		if err := action(s.service.Admin(), r.Context(), p.UserID, userID, role); err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, nil)
+	}
+}
+
+// userAuditEvents returns the audit trail of the user.
+func (s *Server) userAuditEvents() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			s.error(w, r, service.ErrMalformedRequest.Wrap(err))
+			return
+		}
+
+		events, err := s.service.Admin().AuditEvents(r.Context(), userID)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, events)
+	}
+}
+
+// parseUserQuery returns users listing query from URL query parameters.
+func parseUserQuery(values url.Values) (model.UserQuery, error) {
+	q := model.UserQuery{
+		EmailPrefix:    values.Get("email"),
+		UsernamePrefix: values.Get("username"),
+		Sort:           strings.TrimPrefix(values.Get("sort"), "-"),
+		Desc:           strings.HasPrefix(values.Get("sort"), "-"),
+	}
+	var err error
+	if q.CreatedAfter, err = parseTimeParam(values, "created_after"); err != nil {
+		return model.UserQuery{}, err
+	}
+	if q.CreatedBefore, err = parseTimeParam(values, "created_before"); err != nil {
+		return model.UserQuery{}, err
+	}
+	if v := values.Get("verified"); v != "" {
+		verified, err := strconv.ParseBool(v)
+		if err != nil {
+			return model.UserQuery{}, err
+		}
+		q.Verified = &verified
+	}
+	if v := values.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return model.UserQuery{}, err
+		}
+		q.Limit = limit
+	}
+
+	return q, nil
+}
+
+// parseTimeParam returns time from RFC 3339 URL query parameter or nil if it's not set.
+func parseTimeParam(values url.Values, param string) (*time.Time, error) {
+	v := values.Get(param)
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
@@ -0,0 +1,401 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/service"
+	mock_service "github.com/imarrche/jwt-auth-example/internal/service/mocks"
+)
+
+// withURLParams returns request with chi URL parameters set.
+func withURLParams(r *http.Request, params map[string]string) *http.Request {
+	rctx := chi.NewRouteContext()
+	for k, v := range params {
+		rctx.URLParams.Add(k, v)
+	}
+
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestServer_listUsers(t *testing.T) {
+	server := &Server{router: chi.NewRouter()}
+	server.configureRouter()
+	createdAfter := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	verified := true
+
+	testcases := []struct {
+		name    string
+		mock    func(*gomock.Controller, *mock_service.MockService)
+		query   string
+		expCode int
+	}{
+		{
+			name: "users are listed",
+			mock: func(c *gomock.Controller, s *mock_service.MockService) {
+				us := mock_service.NewMockUsers(c)
+				us.EXPECT().List(gomock.Any(), model.UserQuery{}, "").Return(model.UserPage{
+					Users: []model.User{{ID: 1, Username: "user1"}}, Total: 1,
+				}, nil)
+				s.EXPECT().Users().Return(us)
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name: "query parameters are parsed",
+			mock: func(c *gomock.Controller, s *mock_service.MockService) {
+				us := mock_service.NewMockUsers(c)
+				us.EXPECT().List(gomock.Any(), model.UserQuery{
+					EmailPrefix: "user", UsernamePrefix: "user", CreatedAfter: &createdAfter,
+					Verified: &verified, Sort: model.UserSortCreatedAt, Desc: true, Limit: 10,
+				}, "cursor").Return(model.UserPage{}, nil)
+				s.EXPECT().Users().Return(us)
+			},
+			query: "?email=user&username=user&created_after=2021-01-01T00:00:00Z&verified=true" +
+				"&sort=-created_at&limit=10&cursor=cursor",
+			expCode: http.StatusOK,
+		},
+		{
+			name:    "creation date is invalid",
+			mock:    func(*gomock.Controller, *mock_service.MockService) {},
+			query:   "?created_before=yesterday",
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:    "limit is invalid",
+			mock:    func(*gomock.Controller, *mock_service.MockService) {},
+			query:   "?limit=all",
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name: "cursor is malformed",
+			mock: func(c *gomock.Controller, s *mock_service.MockService) {
+				us := mock_service.NewMockUsers(c)
+				us.EXPECT().List(gomock.Any(), model.UserQuery{}, "cursor").Return(
+					model.UserPage{}, service.ErrMalformedRequest,
+				)
+				s.EXPECT().Users().Return(us)
+			},
+			query:   "?cursor=cursor",
+			expCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testcases {
+		c := gomock.NewController(t)
+		defer c.Finish()
+		s := mock_service.NewMockService(c)
+		tc.mock(c, s)
+		server.service = s
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/admin/users"+tc.query, nil)
+
+		server.listUsers().ServeHTTP(w, r)
+
+		assert.Equal(t, tc.expCode, w.Code, tc.name)
+	}
+}
+
+func TestServer_createUser(t *testing.T) {
+	server := &Server{router: chi.NewRouter()}
+	server.configureRouter()
+
+	testcases := []struct {
+		name    string
+		mock    func(*gomock.Controller, *mock_service.MockService)
+		body    string
+		expCode int
+	}{
+		{
+			name: "user is created",
+			mock: func(c *gomock.Controller, s *mock_service.MockService) {
+				as := mock_service.NewMockAdmin(c)
+				as.EXPECT().CreateUser(gomock.Any(), 10, model.User{Username: "user1"}).Return(
+					model.User{ID: 1, Username: "user1"}, nil,
+				)
+				s.EXPECT().Admin().Return(as)
+			},
+			body:    `{"username": "user1"}`,
+			expCode: http.StatusOK,
+		},
+		{
+			name: "email verification and disabled time are ignored",
+			mock: func(c *gomock.Controller, s *mock_service.MockService) {
+				as := mock_service.NewMockAdmin(c)
+				as.EXPECT().CreateUser(gomock.Any(), 10, model.User{Username: "user1"}).Return(
+					model.User{ID: 1, Username: "user1"}, nil,
+				)
+				s.EXPECT().Admin().Return(as)
+			},
+			body: `{"username": "user1", "email_verified_at": "2020-01-01T00:00:00Z",` +
+				` "disabled_at": "2020-01-01T00:00:00Z"}`,
+			expCode: http.StatusOK,
+		},
+		{
+			name: "user is invalid",
+			mock: func(c *gomock.Controller, s *mock_service.MockService) {
+				as := mock_service.NewMockAdmin(c)
+				as.EXPECT().CreateUser(gomock.Any(), 10, model.User{Username: "u"}).Return(
+					model.User{}, service.NewValidationError(errors.New("username is too short")),
+				)
+				s.EXPECT().Admin().Return(as)
+			},
+			body:    `{"username": "u"}`,
+			expCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:    "body is malformed",
+			mock:    func(*gomock.Controller, *mock_service.MockService) {},
+			body:    `{"username":`,
+			expCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testcases {
+		c := gomock.NewController(t)
+		defer c.Finish()
+		s := mock_service.NewMockService(c)
+		tc.mock(c, s)
+		server.service = s
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/admin/users", bytes.NewBufferString(tc.body))
+		r = r.WithContext(WithPrincipal(r.Context(), model.Principal{UserID: 10}))
+
+		server.createUser().ServeHTTP(w, r)
+
+		assert.Equal(t, tc.expCode, w.Code, tc.name)
+	}
+}
+
+func TestServer_updateUser(t *testing.T) {
+	server := &Server{router: chi.NewRouter()}
+	server.configureRouter()
+
+	testcases := []struct {
+		name    string
+		mock    func(*gomock.Controller, *mock_service.MockService)
+		params  map[string]string
+		request updateUserRequest
+		expUser model.User
+		expCode int
+	}{
+		{
+			name: "user is updated",
+			mock: func(c *gomock.Controller, s *mock_service.MockService) {
+				as := mock_service.NewMockAdmin(c)
+				as.EXPECT().UpdateUser(gomock.Any(), 10, model.User{ID: 1, Email: "user2@test.com"}).Return(
+					model.User{ID: 1, Username: "user1", Email: "user2@test.com"}, nil,
+				)
+				s.EXPECT().Admin().Return(as)
+			},
+			params:  map[string]string{"id": "1"},
+			request: updateUserRequest{Email: "user2@test.com"},
+			expUser: model.User{ID: 1, Username: "user1", Email: "user2@test.com"},
+			expCode: http.StatusOK,
+		},
+		{
+			name:    "user ID is invalid",
+			mock:    func(*gomock.Controller, *mock_service.MockService) {},
+			params:  map[string]string{"id": "user"},
+			expCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testcases {
+		c := gomock.NewController(t)
+		defer c.Finish()
+		s := mock_service.NewMockService(c)
+		tc.mock(c, s)
+		server.service = s
+
+		body, err := json.Marshal(tc.request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPatch, "/api/v1/admin/users/1", bytes.NewBuffer(body))
+		r = withURLParams(r, tc.params)
+		r = r.WithContext(WithPrincipal(r.Context(), model.Principal{UserID: 10}))
+
+		server.updateUser().ServeHTTP(w, r)
+
+		assert.Equal(t, tc.expCode, w.Code, tc.name)
+		if tc.expCode == http.StatusOK {
+			var u model.User
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(&u))
+			assert.Equal(t, tc.expUser, u)
+		}
+	}
+}
+
+func TestServer_adminAction(t *testing.T) {
+	server := &Server{router: chi.NewRouter()}
+	server.configureRouter()
+
+	testcases := []struct {
+		name      string
+		mock      func(*gomock.Controller, *mock_service.MockService)
+		params    map[string]string
+		principal *model.Principal
+		expCode   int
+	}{
+		{
+			name: "user is disabled",
+			mock: func(c *gomock.Controller, s *mock_service.MockService) {
+				as := mock_service.NewMockAdmin(c)
+				as.EXPECT().DisableUser(gomock.Any(), 10, 1).Return(nil)
+				s.EXPECT().Admin().Return(as)
+			},
+			params:    map[string]string{"id": "1"},
+			principal: &model.Principal{UserID: 10},
+			expCode:   http.StatusOK,
+		},
+		{
+			name: "user is not found",
+			mock: func(c *gomock.Controller, s *mock_service.MockService) {
+				as := mock_service.NewMockAdmin(c)
+				as.EXPECT().DisableUser(gomock.Any(), 10, 2).Return(service.ErrNotFound)
+				s.EXPECT().Admin().Return(as)
+			},
+			params:    map[string]string{"id": "2"},
+			principal: &model.Principal{UserID: 10},
+			expCode:   http.StatusNotFound,
+		},
+		{
+			name:      "user ID is invalid",
+			mock:      func(*gomock.Controller, *mock_service.MockService) {},
+			params:    map[string]string{"id": "user"},
+			principal: &model.Principal{UserID: 10},
+			expCode:   http.StatusBadRequest,
+		},
+		{
+			name:    "admin isn't authenticated",
+			mock:    func(*gomock.Controller, *mock_service.MockService) {},
+			params:  map[string]string{"id": "1"},
+			expCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testcases {
+		c := gomock.NewController(t)
+		defer c.Finish()
+		s := mock_service.NewMockService(c)
+		tc.mock(c, s)
+		server.service = s
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPut, "/api/v1/admin/users/1/disabled", nil)
+		r = withURLParams(r, tc.params)
+		if tc.principal != nil {
+			r = r.WithContext(WithPrincipal(r.Context(), *tc.principal))
+		}
+
+		server.adminAction(service.Admin.DisableUser).ServeHTTP(w, r)
+
+		assert.Equal(t, tc.expCode, w.Code, tc.name)
+	}
+}
+
+func TestServer_roleAction(t *testing.T) {
+	server := &Server{router: chi.NewRouter()}
+	server.configureRouter()
+
+	testcases := []struct {
+		name      string
+		mock      func(*gomock.Controller, *mock_service.MockService)
+		params    map[string]string
+		principal *model.Principal
+		expCode   int
+	}{
+		{
+			name: "role is granted",
+			mock: func(c *gomock.Controller, s *mock_service.MockService) {
+				as := mock_service.NewMockAdmin(c)
+				as.EXPECT().GrantRole(gomock.Any(), 10, 1, "admin").Return(nil)
+				s.EXPECT().Admin().Return(as)
+			},
+			params:    map[string]string{"id": "1", "role": "admin"},
+			principal: &model.Principal{UserID: 10},
+			expCode:   http.StatusOK,
+		},
+		{
+			name: "role is not found",
+			mock: func(c *gomock.Controller, s *mock_service.MockService) {
+				as := mock_service.NewMockAdmin(c)
+				as.EXPECT().GrantRole(gomock.Any(), 10, 1, "root").Return(service.ErrNotFound)
+				s.EXPECT().Admin().Return(as)
+			},
+			params:    map[string]string{"id": "1", "role": "root"},
+			principal: &model.Principal{UserID: 10},
+			expCode:   http.StatusNotFound,
+		},
+		{
+			name:      "user ID is invalid",
+			mock:      func(*gomock.Controller, *mock_service.MockService) {},
+			params:    map[string]string{"id": "user", "role": "admin"},
+			principal: &model.Principal{UserID: 10},
+			expCode:   http.StatusBadRequest,
+		},
+		{
+			name:    "admin isn't authenticated",
+			mock:    func(*gomock.Controller, *mock_service.MockService) {},
+			params:  map[string]string{"id": "1", "role": "admin"},
+			expCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testcases {
+		c := gomock.NewController(t)
+		defer c.Finish()
+		s := mock_service.NewMockService(c)
+		tc.mock(c, s)
+		server.service = s
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPut, "/api/v1/admin/users/1/roles/admin", nil)
+		r = withURLParams(r, tc.params)
+		if tc.principal != nil {
+			r = r.WithContext(WithPrincipal(r.Context(), *tc.principal))
+		}
+
+		server.roleAction(service.Admin.GrantRole).ServeHTTP(w, r)
+
+		assert.Equal(t, tc.expCode, w.Code, tc.name)
+	}
+}
+
+func TestServer_userAuditEvents(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+	events := []model.AuditEvent{{ID: 1, ActorID: 10, Action: model.AuditUserCreate, UserID: 1}}
+	s := mock_service.NewMockService(c)
+	as := mock_service.NewMockAdmin(c)
+	as.EXPECT().AuditEvents(gomock.Any(), 1).Return(events, nil)
+	s.EXPECT().Admin().Return(as)
+	server := &Server{router: chi.NewRouter(), service: s}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/admin/users/1/audit-events", nil)
+	r = withURLParams(r, map[string]string{"id": "1"})
+
+	server.userAuditEvents().ServeHTTP(w, r)
+	var got []model.AuditEvent
+	err := json.NewDecoder(w.Body).Decode(&got)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, events, got)
+}
@@ -5,20 +5,40 @@ import (
 	"net/http"
 
 	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/service"
 )
 
+type signUpRequest struct {
+	Username   string `json:"username"`
+	Email      string `json:"email"`
+	FirstName  string `json:"first_name"`
+	SecondName string `json:"second_name"`
+	Password   string `json:"password"`
+}
+
+// user returns the user to sign up.
+func (req signUpRequest) user() model.User {
+	return model.User{
+		Username:   req.Username,
+		Email:      req.Email,
+		FirstName:  req.FirstName,
+		SecondName: req.SecondName,
+		Password:   req.Password,
+	}
+}
+
 // signUp signs up a user.
 func (s *Server) signUp() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		var u model.User
-		if err := json.NewDecoder(r.Body).Decode(&u); err != nil {
-			s.error(w, r, http.StatusBadRequest, err)
+		var req signUpRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.error(w, r, service.ErrMalformedRequest.Wrap(err))
 			return
 		}
 
-		u, err := s.service.Auth().SignUp(u)
+		u, err := s.service.Auth().SignUp(r.Context(), req.user())
 		if err != nil {
-			s.error(w, r, http.StatusBadRequest, err)
+			s.error(w, r, err)
 			return
 		}
 
@@ -32,26 +52,63 @@ type signInRequest struct {
 }
 
 type signInResponse struct {
-	AccessToken  string `json:"access"`
-	RefreshToken string `json:"refresh"`
+	AccessToken  string `json:"access,omitempty"`
+	RefreshToken string `json:"refresh,omitempty"`
+	MFAToken     string `json:"mfa_token,omitempty"`
 }
 
-// signIn returns access and refresh JWTs for user.
+// signIn returns access and refresh JWTs for user, users with MFA enabled get MFA JWT
+// to pass MFA verification with instead.
 func (s *Server) signIn() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req signInRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			s.error(w, r, http.StatusBadRequest, err)
+			s.error(w, r, service.ErrMalformedRequest.Wrap(err))
 			return
 		}
 
-		accessJWT, refreshJWT, err := s.service.Auth().SignIn(req.Email, req.Password)
+		result, err := s.service.Auth().SignIn(r.Context(), req.Email, req.Password, clientIP(r))
 		if err != nil {
-			s.error(w, r, http.StatusBadRequest, err)
+			s.error(w, r, err)
 			return
 		}
 
-		res := signInResponse{AccessToken: accessJWT, RefreshToken: refreshJWT}
+		res := signInResponse{
+			AccessToken:  result.AccessToken,
+			RefreshToken: result.RefreshToken,
+			MFAToken:     result.MFAToken,
+		}
+		s.respond(w, r, http.StatusOK, res)
+	}
+}
+
+type verifyMFARequest struct {
+	MFAToken string `json:"mfa_token"`
+	Code     string `json:"code"`
+}
+
+type verifyMFAResponse struct {
+	AccessToken  string `json:"access"`
+	RefreshToken string `json:"refresh"`
+}
+
+// verifyMFA returns access and refresh JWTs for user if valid MFA JWT and TOTP code
+// are provided.
+func (s *Server) verifyMFA() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req verifyMFARequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.error(w, r, service.ErrMalformedRequest.Wrap(err))
+			return
+		}
+
+		accessJWT, refreshJWT, err := s.service.Auth().VerifyMFA(r.Context(), req.MFAToken, req.Code)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		res := verifyMFAResponse{AccessToken: accessJWT, RefreshToken: refreshJWT}
 		s.respond(w, r, http.StatusOK, res)
 	}
 }
@@ -61,29 +118,125 @@ type refreshRequest struct {
 }
 
 type refreshResponse struct {
-	AccessToken string `json:"access"`
+	AccessToken  string `json:"access"`
+	RefreshToken string `json:"refresh"`
 }
 
-// refresh returns new access JWT for user if valid refresh JWT is provided.
+// refresh returns new access and refresh JWTs for user if valid refresh JWT is provided.
 func (s *Server) refresh() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req refreshRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			s.error(w, r, http.StatusBadRequest, err)
+			s.error(w, r, service.ErrMalformedRequest.Wrap(err))
 			return
 		}
 
-		accessJWT, err := s.service.Auth().RefreshAccessJWT(req.RefreshToken)
+		accessJWT, refreshJWT, err := s.service.Auth().Refresh(r.Context(), req.RefreshToken)
 		if err != nil {
-			s.error(w, r, http.StatusBadRequest, err)
+			s.error(w, r, err)
 			return
 		}
 
-		res := refreshResponse{AccessToken: accessJWT}
+		res := refreshResponse{AccessToken: accessJWT, RefreshToken: refreshJWT}
 		s.respond(w, r, http.StatusOK, res)
 	}
 }
 
+type signOutRequest struct {
+	RefreshToken string `json:"refresh"`
+}
+
+// signOut revokes access JWT from Authorization header and provided refresh JWT.
+func (s *Server) signOut() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req signOutRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.error(w, r, service.ErrMalformedRequest.Wrap(err))
+			return
+		}
+
+		accessJWT, _ := bearerToken(r)
+		if err := s.service.Auth().SignOut(r.Context(), accessJWT, req.RefreshToken); err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, nil)
+	}
+}
+
+type forgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+// forgotPassword sends password reset link to user's email. The response doesn't
+// depend on whether the email is registered.
+func (s *Server) forgotPassword() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req forgotPasswordRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.error(w, r, service.ErrMalformedRequest.Wrap(err))
+			return
+		}
+
+		if err := s.service.Auth().ForgotPassword(r.Context(), req.Email); err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, nil)
+	}
+}
+
+type resetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+// resetPassword sets a new password for user if valid password reset token is provided.
+func (s *Server) resetPassword() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req resetPasswordRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.error(w, r, service.ErrMalformedRequest.Wrap(err))
+			return
+		}
+
+		if err := s.service.Auth().ResetPassword(r.Context(), req.Token, req.NewPassword); err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, nil)
+	}
+}
+
+// verifyEmail verifies user's email if valid email verification token is provided.
+func (s *Server) verifyEmail() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := s.service.Auth().VerifyEmail(r.Context(), r.URL.Query().Get("token")); err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, nil)
+	}
+}
+
+// jwks returns the set of public keys to verify JWTs with.
+func (s *Server) jwks() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		set, err := s.service.Auth().JWKS(r.Context())
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		s.respond(w, r, http.StatusOK, set)
+	}
+}
+
 // public is a public route to test JWT authorization.
 func (s *Server) public() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
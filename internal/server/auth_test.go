@@ -6,12 +6,14 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/service"
 	mock_service "github.com/imarrche/jwt-auth-example/internal/service/mocks"
 	service_mock "github.com/imarrche/jwt-auth-example/internal/service/mocks"
 )
@@ -19,6 +21,7 @@ import (
 func TestServer_signUp(t *testing.T) {
 	server := &Server{router: chi.NewRouter()}
 	server.configureRouter()
+	now := time.Now()
 
 	testcases := []struct {
 		name    string
@@ -31,13 +34,26 @@ func TestServer_signUp(t *testing.T) {
 			name: "user is signed up",
 			mock: func(c *gomock.Controller, s *mock_service.MockService, u model.User) {
 				as := mock_service.NewMockAuth(c)
-				as.EXPECT().SignUp(u).Return(u, nil)
+				as.EXPECT().SignUp(gomock.Any(), u).Return(u, nil)
 				s.EXPECT().Auth().Return(as)
 			},
 			user:    model.User{Username: "user1"},
 			expUser: model.User{Username: "user1"},
 			expCode: http.StatusOK,
 		},
+		{
+			name: "email verification and disabled time are ignored",
+			mock: func(c *gomock.Controller, s *mock_service.MockService, u model.User) {
+				as := mock_service.NewMockAuth(c)
+				as.EXPECT().SignUp(gomock.Any(), model.User{Username: "user1"}).Return(
+					model.User{Username: "user1"}, nil,
+				)
+				s.EXPECT().Auth().Return(as)
+			},
+			user:    model.User{Username: "user1", EmailVerifiedAt: &now, DisabledAt: &now},
+			expUser: model.User{Username: "user1"},
+			expCode: http.StatusOK,
+		},
 	}
 
 	for _, tc := range testcases {
@@ -77,8 +93,8 @@ func TestServer_signIn(t *testing.T) {
 			name: "user is signed in",
 			mock: func(c *gomock.Controller, s *mock_service.MockService, r signInRequest) {
 				as := mock_service.NewMockAuth(c)
-				as.EXPECT().SignIn(r.Email, r.Password).Return(
-					"access_token", "refresh_token", nil,
+				as.EXPECT().SignIn(gomock.Any(), r.Email, r.Password, "192.0.2.1").Return(
+					model.SignInResult{AccessToken: "access_token", RefreshToken: "refresh_token"}, nil,
 				)
 				s.EXPECT().Auth().Return(as)
 			},
@@ -90,6 +106,51 @@ func TestServer_signIn(t *testing.T) {
 			},
 			expCode: http.StatusOK,
 		},
+		{
+			name: "MFA is required",
+			mock: func(c *gomock.Controller, s *mock_service.MockService, r signInRequest) {
+				as := mock_service.NewMockAuth(c)
+				as.EXPECT().SignIn(gomock.Any(), r.Email, r.Password, "192.0.2.1").Return(
+					model.SignInResult{MFAToken: "mfa_token"}, nil,
+				)
+				s.EXPECT().Auth().Return(as)
+			},
+			request: signInRequest{
+				Email: "user@test.com", Password: "password",
+			},
+			expResponse: signInResponse{MFAToken: "mfa_token"},
+			expCode:     http.StatusOK,
+		},
+		{
+			name: "account is locked",
+			mock: func(c *gomock.Controller, s *mock_service.MockService, r signInRequest) {
+				as := mock_service.NewMockAuth(c)
+				as.EXPECT().SignIn(gomock.Any(), r.Email, r.Password, "192.0.2.1").Return(
+					model.SignInResult{}, service.ErrAccountLocked,
+				)
+				s.EXPECT().Auth().Return(as)
+			},
+			request: signInRequest{
+				Email: "user@test.com", Password: "password",
+			},
+			expResponse: signInResponse{},
+			expCode:     http.StatusLocked,
+		},
+		{
+			name: "too many attempts",
+			mock: func(c *gomock.Controller, s *mock_service.MockService, r signInRequest) {
+				as := mock_service.NewMockAuth(c)
+				as.EXPECT().SignIn(gomock.Any(), r.Email, r.Password, "192.0.2.1").Return(
+					model.SignInResult{}, service.ErrTooManyAttempts,
+				)
+				s.EXPECT().Auth().Return(as)
+			},
+			request: signInRequest{
+				Email: "user@test.com", Password: "password",
+			},
+			expResponse: signInResponse{},
+			expCode:     http.StatusTooManyRequests,
+		},
 	}
 
 	for _, tc := range testcases {
@@ -114,6 +175,69 @@ func TestServer_signIn(t *testing.T) {
 	}
 }
 
+func TestServer_verifyMFA(t *testing.T) {
+	server := &Server{router: chi.NewRouter()}
+	server.configureRouter()
+
+	testcases := []struct {
+		name        string
+		mock        func(*gomock.Controller, *mock_service.MockService, verifyMFARequest)
+		request     verifyMFARequest
+		expResponse verifyMFAResponse
+		expCode     int
+	}{
+		{
+			name: "MFA is verified",
+			mock: func(c *gomock.Controller, s *mock_service.MockService, r verifyMFARequest) {
+				as := mock_service.NewMockAuth(c)
+				as.EXPECT().VerifyMFA(gomock.Any(), r.MFAToken, r.Code).Return(
+					"access_token", "refresh_token", nil,
+				)
+				s.EXPECT().Auth().Return(as)
+			},
+			request: verifyMFARequest{MFAToken: "mfa_token", Code: "123456"},
+			expResponse: verifyMFAResponse{
+				AccessToken: "access_token", RefreshToken: "refresh_token",
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name: "code is invalid",
+			mock: func(c *gomock.Controller, s *mock_service.MockService, r verifyMFARequest) {
+				as := mock_service.NewMockAuth(c)
+				as.EXPECT().VerifyMFA(gomock.Any(), r.MFAToken, r.Code).Return(
+					"", "", service.ErrInvalidMFACode,
+				)
+				s.EXPECT().Auth().Return(as)
+			},
+			request:     verifyMFARequest{MFAToken: "mfa_token", Code: "000000"},
+			expResponse: verifyMFAResponse{},
+			expCode:     http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tc := range testcases {
+		c := gomock.NewController(t)
+		defer c.Finish()
+		s := service_mock.NewMockService(c)
+		tc.mock(c, s, tc.request)
+		server.service = s
+
+		b := &bytes.Buffer{}
+		json.NewEncoder(b).Encode(tc.request)
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/auth/mfa/verify", b)
+
+		server.verifyMFA().ServeHTTP(w, r)
+		var response verifyMFAResponse
+		err := json.NewDecoder(w.Body).Decode(&response)
+
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expCode, w.Code)
+		assert.Equal(t, tc.expResponse, response)
+	}
+}
+
 func TestServer_refresh(t *testing.T) {
 	server := &Server{router: chi.NewRouter()}
 	server.configureRouter()
@@ -129,12 +253,16 @@ func TestServer_refresh(t *testing.T) {
 			name: "token is refreshed",
 			mock: func(c *gomock.Controller, s *mock_service.MockService, r refreshRequest) {
 				as := mock_service.NewMockAuth(c)
-				as.EXPECT().RefreshAccessJWT(r.RefreshToken).Return("new_access_token", nil)
+				as.EXPECT().Refresh(gomock.Any(), r.RefreshToken).Return(
+					"new_access_token", "new_refresh_token", nil,
+				)
 				s.EXPECT().Auth().Return(as)
 			},
-			request:     refreshRequest{RefreshToken: "refresh_token"},
-			expResponse: refreshResponse{AccessToken: "new_access_token"},
-			expCode:     http.StatusOK,
+			request: refreshRequest{RefreshToken: "refresh_token"},
+			expResponse: refreshResponse{
+				AccessToken: "new_access_token", RefreshToken: "new_refresh_token",
+			},
+			expCode: http.StatusOK,
 		},
 	}
 
@@ -160,6 +288,233 @@ func TestServer_refresh(t *testing.T) {
 	}
 }
 
+func TestServer_signOut(t *testing.T) {
+	server := &Server{router: chi.NewRouter()}
+	server.configureRouter()
+
+	testcases := []struct {
+		name        string
+		mock        func(*gomock.Controller, *mock_service.MockService, signOutRequest)
+		accessToken string
+		request     signOutRequest
+		expCode     int
+	}{
+		{
+			name: "user is signed out",
+			mock: func(c *gomock.Controller, s *mock_service.MockService, r signOutRequest) {
+				as := mock_service.NewMockAuth(c)
+				as.EXPECT().SignOut(gomock.Any(), "access_token", r.RefreshToken).Return(nil)
+				s.EXPECT().Auth().Return(as)
+			},
+			accessToken: "access_token",
+			request:     signOutRequest{RefreshToken: "refresh_token"},
+			expCode:     http.StatusOK,
+		},
+	}
+
+	for _, tc := range testcases {
+		c := gomock.NewController(t)
+		defer c.Finish()
+		s := service_mock.NewMockService(c)
+		tc.mock(c, s, tc.request)
+		server.service = s
+
+		b := &bytes.Buffer{}
+		json.NewEncoder(b).Encode(tc.request)
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/auth/sign-out", b)
+		r.Header.Set("Authorization", "Bearer "+tc.accessToken)
+
+		server.signOut().ServeHTTP(w, r)
+
+		assert.Equal(t, tc.expCode, w.Code)
+	}
+}
+
+func TestServer_forgotPassword(t *testing.T) {
+	server := &Server{router: chi.NewRouter()}
+	server.configureRouter()
+
+	testcases := []struct {
+		name    string
+		mock    func(*gomock.Controller, *mock_service.MockService, forgotPasswordRequest)
+		request forgotPasswordRequest
+		expCode int
+	}{
+		{
+			name: "password reset link is sent",
+			mock: func(c *gomock.Controller, s *mock_service.MockService, r forgotPasswordRequest) {
+				as := mock_service.NewMockAuth(c)
+				as.EXPECT().ForgotPassword(gomock.Any(), r.Email).Return(nil)
+				s.EXPECT().Auth().Return(as)
+			},
+			request: forgotPasswordRequest{Email: "user1@test.com"},
+			expCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testcases {
+		c := gomock.NewController(t)
+		defer c.Finish()
+		s := service_mock.NewMockService(c)
+		tc.mock(c, s, tc.request)
+		server.service = s
+
+		b := &bytes.Buffer{}
+		json.NewEncoder(b).Encode(tc.request)
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/auth/password/forgot", b)
+
+		server.forgotPassword().ServeHTTP(w, r)
+
+		assert.Equal(t, tc.expCode, w.Code)
+	}
+}
+
+func TestServer_resetPassword(t *testing.T) {
+	server := &Server{router: chi.NewRouter()}
+	server.configureRouter()
+
+	testcases := []struct {
+		name    string
+		mock    func(*gomock.Controller, *mock_service.MockService, resetPasswordRequest)
+		request resetPasswordRequest
+		expCode int
+	}{
+		{
+			name: "password is reset",
+			mock: func(c *gomock.Controller, s *mock_service.MockService, r resetPasswordRequest) {
+				as := mock_service.NewMockAuth(c)
+				as.EXPECT().ResetPassword(gomock.Any(), r.Token, r.NewPassword).Return(nil)
+				s.EXPECT().Auth().Return(as)
+			},
+			request: resetPasswordRequest{Token: "token", NewPassword: "password2"},
+			expCode: http.StatusOK,
+		},
+		{
+			name: "token is invalid",
+			mock: func(c *gomock.Controller, s *mock_service.MockService, r resetPasswordRequest) {
+				as := mock_service.NewMockAuth(c)
+				as.EXPECT().ResetPassword(gomock.Any(), r.Token, r.NewPassword).Return(
+					service.ErrInvalidOneTimeToken,
+				)
+				s.EXPECT().Auth().Return(as)
+			},
+			request: resetPasswordRequest{Token: "token", NewPassword: "password2"},
+			expCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tc := range testcases {
+		c := gomock.NewController(t)
+		defer c.Finish()
+		s := service_mock.NewMockService(c)
+		tc.mock(c, s, tc.request)
+		server.service = s
+
+		b := &bytes.Buffer{}
+		json.NewEncoder(b).Encode(tc.request)
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/auth/password/reset", b)
+
+		server.resetPassword().ServeHTTP(w, r)
+
+		assert.Equal(t, tc.expCode, w.Code)
+	}
+}
+
+func TestServer_verifyEmail(t *testing.T) {
+	server := &Server{router: chi.NewRouter()}
+	server.configureRouter()
+
+	testcases := []struct {
+		name    string
+		mock    func(*gomock.Controller, *mock_service.MockService, string)
+		token   string
+		expCode int
+	}{
+		{
+			name: "email is verified",
+			mock: func(c *gomock.Controller, s *mock_service.MockService, token string) {
+				as := mock_service.NewMockAuth(c)
+				as.EXPECT().VerifyEmail(gomock.Any(), token).Return(nil)
+				s.EXPECT().Auth().Return(as)
+			},
+			token:   "token",
+			expCode: http.StatusOK,
+		},
+		{
+			name: "token is invalid",
+			mock: func(c *gomock.Controller, s *mock_service.MockService, token string) {
+				as := mock_service.NewMockAuth(c)
+				as.EXPECT().VerifyEmail(gomock.Any(), token).Return(service.ErrInvalidOneTimeToken)
+				s.EXPECT().Auth().Return(as)
+			},
+			token:   "token",
+			expCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tc := range testcases {
+		c := gomock.NewController(t)
+		defer c.Finish()
+		s := service_mock.NewMockService(c)
+		tc.mock(c, s, tc.token)
+		server.service = s
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/auth/verify-email?token="+tc.token, nil)
+
+		server.verifyEmail().ServeHTTP(w, r)
+
+		assert.Equal(t, tc.expCode, w.Code)
+	}
+}
+
+func TestServer_jwks(t *testing.T) {
+	server := &Server{router: chi.NewRouter()}
+	server.configureRouter()
+
+	testcases := []struct {
+		name    string
+		mock    func(*gomock.Controller, *mock_service.MockService, model.JWKSet)
+		set     model.JWKSet
+		expCode int
+	}{
+		{
+			name: "key set is returned",
+			mock: func(c *gomock.Controller, s *mock_service.MockService, set model.JWKSet) {
+				as := mock_service.NewMockAuth(c)
+				as.EXPECT().JWKS(gomock.Any()).Return(set, nil)
+				s.EXPECT().Auth().Return(as)
+			},
+			set: model.JWKSet{Keys: []model.JWK{
+				{KeyType: "OKP", KeyID: "key1", Use: "sig", Algorithm: "EdDSA", Curve: "Ed25519", X: "x"},
+			}},
+			expCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testcases {
+		c := gomock.NewController(t)
+		defer c.Finish()
+		s := service_mock.NewMockService(c)
+		tc.mock(c, s, tc.set)
+		server.service = s
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+
+		server.jwks().ServeHTTP(w, r)
+		var set model.JWKSet
+		err := json.NewDecoder(w.Body).Decode(&set)
+
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expCode, w.Code)
+		assert.Equal(t, tc.set, set)
+	}
+}
+
 func TestServer_public(t *testing.T) {
 	server := &Server{router: chi.NewRouter()}
 	server.configureRouter()
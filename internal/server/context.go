@@ -0,0 +1,24 @@
+package server
+
+import (
+	"context"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+)
+
+// contextKey is the type of keys of values server puts into request context.
+type contextKey int
+
+const principalKey contextKey = iota
+
+// WithPrincipal returns a copy of the context with the authenticated principal.
+func WithPrincipal(ctx context.Context, p model.Principal) context.Context {
+	return context.WithValue(ctx, principalKey, p)
+}
+
+// PrincipalFromContext returns the authenticated principal from the context.
+func PrincipalFromContext(ctx context.Context) (model.Principal, bool) {
+	p, ok := ctx.Value(principalKey).(model.Principal)
+
+	return p, ok
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/imarrche/jwt-auth-example/internal/service"
+)
+
+type sendMagicLinkRequest struct {
+	Email string `json:"email"`
+}
+
+type sendMagicLinkResponse struct {
+	Device string `json:"device"`
+}
+
+// sendMagicLink sends sign in link to user's email and returns the device secret the
+// link is bound to. The response doesn't depend on whether the email is registered.
+func (s *Server) sendMagicLink() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req sendMagicLinkRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.error(w, r, service.ErrMalformedRequest.Wrap(err))
+			return
+		}
+
+		device, err := s.service.Auth().SendMagicLink(r.Context(), req.Email)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, sendMagicLinkResponse{Device: device})
+	}
+}
+
+type consumeMagicLinkRequest struct {
+	Token  string `json:"token"`
+	Device string `json:"device"`
+}
+
+// consumeMagicLink returns access and refresh JWTs for user if valid magic link token
+// is provided along with the device secret, users with MFA enabled get MFA JWT instead.
+func (s *Server) consumeMagicLink() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req consumeMagicLinkRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.error(w, r, service.ErrMalformedRequest.Wrap(err))
+			return
+		}
+
+		result, err := s.service.Auth().ConsumeMagicLink(r.Context(), req.Token, req.Device)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		res := signInResponse{
+			AccessToken:  result.AccessToken,
+			RefreshToken: result.RefreshToken,
+			MFAToken:     result.MFAToken,
+		}
+		s.respond(w, r, http.StatusOK, res)
+	}
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/service"
+	mock_service "github.com/imarrche/jwt-auth-example/internal/service/mocks"
+)
+
+func TestServer_sendMagicLink(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	s := mock_service.NewMockService(c)
+	as := mock_service.NewMockAuth(c)
+	as.EXPECT().SendMagicLink(gomock.Any(), "user1@test.com").Return("device", nil)
+	s.EXPECT().Auth().Return(as)
+	server := &Server{router: chi.NewRouter(), service: s}
+
+	b := &bytes.Buffer{}
+	json.NewEncoder(b).Encode(sendMagicLinkRequest{Email: "user1@test.com"})
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/auth/magic-link", b)
+
+	server.sendMagicLink().ServeHTTP(w, r)
+	var response sendMagicLinkResponse
+	err := json.NewDecoder(w.Body).Decode(&response)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "device", response.Device)
+}
+
+func TestServer_consumeMagicLink(t *testing.T) {
+	testcases := []struct {
+		name        string
+		mock        func(*gomock.Controller, *mock_service.MockService)
+		expCode     int
+		expResponse signInResponse
+	}{
+		{
+			name: "tokens are issued",
+			mock: func(c *gomock.Controller, s *mock_service.MockService) {
+				as := mock_service.NewMockAuth(c)
+				as.EXPECT().ConsumeMagicLink(gomock.Any(), "token", "device").Return(
+					model.SignInResult{AccessToken: "access", RefreshToken: "refresh"}, nil,
+				)
+				s.EXPECT().Auth().Return(as)
+			},
+			expCode:     http.StatusOK,
+			expResponse: signInResponse{AccessToken: "access", RefreshToken: "refresh"},
+		},
+		{
+			name: "MFA is required",
+			mock: func(c *gomock.Controller, s *mock_service.MockService) {
+				as := mock_service.NewMockAuth(c)
+				as.EXPECT().ConsumeMagicLink(gomock.Any(), "token", "device").Return(
+					model.SignInResult{MFAToken: "mfa_token"}, nil,
+				)
+				s.EXPECT().Auth().Return(as)
+			},
+			expCode:     http.StatusOK,
+			expResponse: signInResponse{MFAToken: "mfa_token"},
+		},
+		{
+			name: "token is invalid",
+			mock: func(c *gomock.Controller, s *mock_service.MockService) {
+				as := mock_service.NewMockAuth(c)
+				as.EXPECT().ConsumeMagicLink(gomock.Any(), "token", "device").Return(
+					model.SignInResult{}, service.ErrInvalidOneTimeToken,
+				)
+				s.EXPECT().Auth().Return(as)
+			},
+			expCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tc := range testcases {
+		c := gomock.NewController(t)
+		defer c.Finish()
+		s := mock_service.NewMockService(c)
+		tc.mock(c, s)
+		server := &Server{router: chi.NewRouter(), service: s}
+
+		b := &bytes.Buffer{}
+		json.NewEncoder(b).Encode(consumeMagicLinkRequest{Token: "token", Device: "device"})
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/auth/magic-link/consume", b)
+
+		server.consumeMagicLink().ServeHTTP(w, r)
+
+		assert.Equal(t, tc.expCode, w.Code, tc.name)
+		if tc.expCode == http.StatusOK {
+			var response signInResponse
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(&response), tc.name)
+			assert.Equal(t, tc.expResponse, response, tc.name)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/imarrche/jwt-auth-example/internal/service"
+)
+
+// enrollTOTP generates a new TOTP secret for the authenticated user and returns it
+// along with its provisioning URI.
+func (s *Server) enrollTOTP() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p, ok := PrincipalFromContext(r.Context())
+		if !ok {
+			s.error(w, r, service.ErrUnauthorized)
+			return
+		}
+
+		enrollment, err := s.service.MFA().EnrollTOTP(r.Context(), p.UserID)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, enrollment)
+	}
+}
+
+type totpCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type recoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// confirmTOTP enables MFA for the authenticated user if valid TOTP code is provided
+// and returns recovery codes.
+func (s *Server) confirmTOTP() http.HandlerFunc {
+	return s.recoveryCodesAction(service.MFA.ConfirmTOTP)
+}
+
+// disableTOTP disables MFA for the authenticated user if valid TOTP or recovery code
+// is provided.
+func (s *Server) disableTOTP() http.HandlerFunc {
+	return s.totpAction(service.MFA.DisableTOTP)
+}
+
+// regenerateRecoveryCodes returns a new set of recovery codes of the authenticated user
+// if valid TOTP or recovery code is provided, the old set is invalidated.
+func (s *Server) regenerateRecoveryCodes() http.HandlerFunc {
+	return s.recoveryCodesAction(service.MFA.RegenerateRecoveryCodes)
+}
+
+// totpAction performs the action requiring TOTP code of the authenticated user.
+func (s *Server) totpAction(
+	action func(service.MFA, context.Context, int, string) error,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p, ok := PrincipalFromContext(r.Context())
+		if !ok {
+			s.error(w, r, service.ErrUnauthorized)
+			return
+		}
+
+		var req totpCodeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.error(w, r, service.ErrMalformedRequest.Wrap(err))
+			return
+		}
+
+		if err := action(s.service.MFA(), r.Context(), p.UserID, req.Code); err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, nil)
+	}
+}
+
+// recoveryCodesAction performs the action requiring TOTP code of the authenticated user
+// which results in a new set of recovery codes.
+func (s *Server) recoveryCodesAction(
+	action func(service.MFA, context.Context, int, string) ([]string, error),
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p, ok := PrincipalFromContext(r.Context())
+		if !ok {
+			s.error(w, r, service.ErrUnauthorized)
+			return
+		}
+
+		var req totpCodeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.error(w, r, service.ErrMalformedRequest.Wrap(err))
+			return
+		}
+
+		codes, err := action(s.service.MFA(), r.Context(), p.UserID, req.Code)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, recoveryCodesResponse{RecoveryCodes: codes})
+	}
+}
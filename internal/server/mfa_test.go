@@ -0,0 +1,164 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/service"
+	mock_service "github.com/imarrche/jwt-auth-example/internal/service/mocks"
+)
+
+func TestServer_enrollTOTP(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	enrollment := model.TOTPEnrollment{Secret: "SECRET", URI: "otpauth://totp/issuer:user1"}
+	s := mock_service.NewMockService(c)
+	ms := mock_service.NewMockMFA(c)
+	ms.EXPECT().EnrollTOTP(gomock.Any(), 1).Return(enrollment, nil)
+	s.EXPECT().MFA().Return(ms)
+	server := &Server{router: chi.NewRouter(), service: s}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/me/mfa/totp", nil)
+	r = r.WithContext(WithPrincipal(r.Context(), model.Principal{UserID: 1}))
+
+	server.enrollTOTP().ServeHTTP(w, r)
+	var response model.TOTPEnrollment
+	err := json.NewDecoder(w.Body).Decode(&response)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, enrollment, response)
+}
+
+func TestServer_totpAction(t *testing.T) {
+	server := &Server{router: chi.NewRouter()}
+	server.configureRouter()
+
+	testcases := []struct {
+		name      string
+		mock      func(*gomock.Controller, *mock_service.MockService)
+		principal *model.Principal
+		expCode   int
+	}{
+		{
+			name: "MFA is disabled",
+			mock: func(c *gomock.Controller, s *mock_service.MockService) {
+				ms := mock_service.NewMockMFA(c)
+				ms.EXPECT().DisableTOTP(gomock.Any(), 1, "123456").Return(nil)
+				s.EXPECT().MFA().Return(ms)
+			},
+			principal: &model.Principal{UserID: 1},
+			expCode:   http.StatusOK,
+		},
+		{
+			name: "MFA is not enabled",
+			mock: func(c *gomock.Controller, s *mock_service.MockService) {
+				ms := mock_service.NewMockMFA(c)
+				ms.EXPECT().DisableTOTP(gomock.Any(), 1, "123456").Return(service.ErrMFANotEnabled)
+				s.EXPECT().MFA().Return(ms)
+			},
+			principal: &model.Principal{UserID: 1},
+			expCode:   http.StatusConflict,
+		},
+		{
+			name:    "user isn't authenticated",
+			mock:    func(*gomock.Controller, *mock_service.MockService) {},
+			expCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testcases {
+		c := gomock.NewController(t)
+		defer c.Finish()
+		s := mock_service.NewMockService(c)
+		tc.mock(c, s)
+		server.service = s
+
+		b := &bytes.Buffer{}
+		json.NewEncoder(b).Encode(totpCodeRequest{Code: "123456"})
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodDelete, "/api/v1/me/mfa/totp", b)
+		if tc.principal != nil {
+			r = r.WithContext(WithPrincipal(r.Context(), *tc.principal))
+		}
+
+		server.disableTOTP().ServeHTTP(w, r)
+
+		assert.Equal(t, tc.expCode, w.Code, tc.name)
+	}
+}
+
+func TestServer_recoveryCodesAction(t *testing.T) {
+	server := &Server{router: chi.NewRouter()}
+	server.configureRouter()
+
+	testcases := []struct {
+		name      string
+		mock      func(*gomock.Controller, *mock_service.MockService)
+		principal *model.Principal
+		expCodes  []string
+		expCode   int
+	}{
+		{
+			name: "MFA is enabled",
+			mock: func(c *gomock.Controller, s *mock_service.MockService) {
+				ms := mock_service.NewMockMFA(c)
+				ms.EXPECT().ConfirmTOTP(gomock.Any(), 1, "123456").Return([]string{"abcd-efgh"}, nil)
+				s.EXPECT().MFA().Return(ms)
+			},
+			principal: &model.Principal{UserID: 1},
+			expCodes:  []string{"abcd-efgh"},
+			expCode:   http.StatusOK,
+		},
+		{
+			name: "code is invalid",
+			mock: func(c *gomock.Controller, s *mock_service.MockService) {
+				ms := mock_service.NewMockMFA(c)
+				ms.EXPECT().ConfirmTOTP(gomock.Any(), 1, "123456").Return(nil, service.ErrInvalidMFACode)
+				s.EXPECT().MFA().Return(ms)
+			},
+			principal: &model.Principal{UserID: 1},
+			expCode:   http.StatusUnprocessableEntity,
+		},
+		{
+			name:    "user isn't authenticated",
+			mock:    func(*gomock.Controller, *mock_service.MockService) {},
+			expCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testcases {
+		c := gomock.NewController(t)
+		defer c.Finish()
+		s := mock_service.NewMockService(c)
+		tc.mock(c, s)
+		server.service = s
+
+		b := &bytes.Buffer{}
+		json.NewEncoder(b).Encode(totpCodeRequest{Code: "123456"})
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/me/mfa/totp/confirm", b)
+		if tc.principal != nil {
+			r = r.WithContext(WithPrincipal(r.Context(), *tc.principal))
+		}
+
+		server.confirmTOTP().ServeHTTP(w, r)
+
+		assert.Equal(t, tc.expCode, w.Code, tc.name)
+		if tc.expCodes != nil {
+			var response recoveryCodesResponse
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(&response), tc.name)
+			assert.Equal(t, tc.expCodes, response.RecoveryCodes, tc.name)
+		}
+	}
+}
@@ -1,14 +1,22 @@
 package server
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-chi/chi/middleware"
 	"go.uber.org/zap"
 
+	"github.com/imarrche/jwt-auth-example/internal/config"
 	"github.com/imarrche/jwt-auth-example/internal/logger"
+	"github.com/imarrche/jwt-auth-example/internal/service"
 )
 
 // loggerMiddleware is middleware that logs every request with zap logger.
@@ -35,17 +43,105 @@ func (s *Server) loggerMiddleware() func(next http.Handler) http.Handler {
 	}
 }
 
-// authMiddleware is middleware for JWT authorization.
+// authMiddleware is middleware for JWT authorization, it puts the authenticated
+// principal into request context.
 func (s *Server) authMiddleware() func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			h := r.Header.Get("Authorization")
-			if !strings.HasPrefix(h, "Bearer ") {
-				s.error(w, r, http.StatusUnauthorized, nil)
+			token, ok := bearerToken(r)
+			if !ok {
+				s.error(w, r, service.ErrUnauthorized)
 				return
 			}
-			if _, err := s.service.Auth().ValidateJWT(h[7:], "access"); err != nil {
-				s.error(w, r, http.StatusUnauthorized, nil)
+			p, err := s.service.Auth().ValidateJWT(r.Context(), token, "access")
+			if err != nil {
+				s.error(w, r, err)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), p)))
+		})
+	}
+}
+
+// RequirePermission is middleware which requires the authenticated principal to be
+// granted all specified permissions, it must be used after authMiddleware.
+func (s *Server) RequirePermission(permissions ...string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, ok := PrincipalFromContext(r.Context())
+			if !ok {
+				s.error(w, r, service.ErrUnauthorized)
+				return
+			}
+			for _, permission := range permissions {
+				if !p.HasPermission(permission) {
+					s.error(w, r, service.ErrPermissionDenied)
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rateLimitKeyFunc returns the key requests are rate limited by.
+type rateLimitKeyFunc func(*http.Request) string
+
+// rateLimitByIP rate limits requests by client IP.
+func rateLimitByIP(r *http.Request) string { return "ip:" + clientIP(r) }
+
+// rateLimitByUser rate limits requests by the authenticated user, falling back to
+// client IP. It must be used after authMiddleware.
+func rateLimitByUser(r *http.Request) string {
+	if p, ok := PrincipalFromContext(r.Context()); ok {
+		return fmt.Sprintf("user:%d", p.UserID)
+	}
+
+	return rateLimitByIP(r)
+}
+
+// rateLimitByAPIKey rate limits requests by API key from X-API-Key header, falling
+// back to client IP.
+func rateLimitByAPIKey(r *http.Request) string {
+	apiKey := r.Header.Get("X-API-Key")
+	if apiKey == "" {
+		return rateLimitByIP(r)
+	}
+	sum := sha256.Sum256([]byte(apiKey))
+
+	return "key:" + hex.EncodeToString(sum[:])
+}
+
+// rateLimitMiddleware is token bucket rate limiting middleware, requests of the route
+// group with the same key share a bucket. RateLimit-* headers are set on every
+// response and Retry-After header is set once the limit is exceeded. Requests aren't
+// limited if the limiter fails.
+func (s *Server) rateLimitMiddleware(
+	group string, rate config.Rate, key rateLimitKeyFunc,
+) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if s.limiter == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			res, err := s.limiter.Allow(r.Context(), group+":"+key(r), rate)
+			if err != nil {
+				logger.Get().Error("couldn't rate limit request", zap.Error(err))
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+			h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			h.Set("RateLimit-Reset", seconds(res.Reset))
+			if !res.Allowed {
+				h.Set("Retry-After", seconds(res.RetryAfter))
+				s.error(w, r, service.ErrRateLimitExceeded)
 				return
 			}
 
@@ -53,3 +149,28 @@ func (s *Server) authMiddleware() func(next http.Handler) http.Handler {
 		})
 	}
 }
+
+// seconds formats duration as whole seconds rounded up.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// bearerToken returns the token from request's Authorization header in Bearer format.
+func bearerToken(r *http.Request) (string, bool) {
+	h := r.Header.Get("Authorization")
+	if !strings.HasPrefix(h, "Bearer ") {
+		return "", false
+	}
+
+	return h[7:], true
+}
+
+// clientIP returns IP address of the client the request came from.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
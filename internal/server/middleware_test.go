@@ -0,0 +1,181 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imarrche/jwt-auth-example/internal/config"
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/ratelimit"
+	"github.com/imarrche/jwt-auth-example/internal/service"
+	mock_service "github.com/imarrche/jwt-auth-example/internal/service/mocks"
+)
+
+func TestServer_authMiddleware(t *testing.T) {
+	server := &Server{router: chi.NewRouter()}
+	server.configureRouter()
+
+	testcases := []struct {
+		name         string
+		mock         func(*gomock.Controller, *mock_service.MockService)
+		header       string
+		expCode      int
+		expPrincipal model.Principal
+	}{
+		{
+			name: "principal is put into context",
+			mock: func(c *gomock.Controller, s *mock_service.MockService) {
+				as := mock_service.NewMockAuth(c)
+				as.EXPECT().ValidateJWT(gomock.Any(), "access_token", "access").Return(
+					model.Principal{UserID: 1, TokenID: "token1"}, nil,
+				)
+				s.EXPECT().Auth().Return(as)
+			},
+			header:       "Bearer access_token",
+			expCode:      http.StatusOK,
+			expPrincipal: model.Principal{UserID: 1, TokenID: "token1"},
+		},
+		{
+			name: "token is invalid",
+			mock: func(c *gomock.Controller, s *mock_service.MockService) {
+				as := mock_service.NewMockAuth(c)
+				as.EXPECT().ValidateJWT(gomock.Any(), "access_token", "access").Return(
+					model.Principal{}, service.ErrInvalidToken.Wrap(errors.New("JWT expired")),
+				)
+				s.EXPECT().Auth().Return(as)
+			},
+			header:  "Bearer access_token",
+			expCode: http.StatusUnauthorized,
+		},
+		{
+			name:    "token is missing",
+			mock:    func(*gomock.Controller, *mock_service.MockService) {},
+			header:  "",
+			expCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testcases {
+		c := gomock.NewController(t)
+		defer c.Finish()
+		s := mock_service.NewMockService(c)
+		tc.mock(c, s)
+		server.service = s
+
+		var principal model.Principal
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, _ = PrincipalFromContext(r.Context())
+		})
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/private", nil)
+		r.Header.Set("Authorization", tc.header)
+
+		server.authMiddleware()(next).ServeHTTP(w, r)
+
+		assert.Equal(t, tc.expCode, w.Code)
+		assert.Equal(t, tc.expPrincipal, principal)
+	}
+}
+
+func TestServer_RequirePermission(t *testing.T) {
+	server := &Server{router: chi.NewRouter()}
+	server.configureRouter()
+
+	testcases := []struct {
+		name      string
+		principal *model.Principal
+		expCode   int
+	}{
+		{
+			name:      "permission is granted",
+			principal: &model.Principal{UserID: 1, Permissions: []string{"users:read"}},
+			expCode:   http.StatusOK,
+		},
+		{
+			name:      "permission is denied",
+			principal: &model.Principal{UserID: 1, Permissions: []string{"users:write"}},
+			expCode:   http.StatusForbidden,
+		},
+		{
+			name:      "principal is missing",
+			principal: nil,
+			expCode:   http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testcases {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/admin/users/1/roles", nil)
+		if tc.principal != nil {
+			r = r.WithContext(WithPrincipal(r.Context(), *tc.principal))
+		}
+
+		server.RequirePermission("users:read")(server.private()).ServeHTTP(w, r)
+
+		assert.Equal(t, tc.expCode, w.Code)
+	}
+}
+
+func TestServer_rateLimitMiddleware(t *testing.T) {
+	server := &Server{router: chi.NewRouter(), limiter: ratelimit.NewMemoryLimiter()}
+	rate := config.Rate{Limit: 2, Period: time.Minute}
+	handler := server.rateLimitMiddleware("test", rate, rateLimitByIP)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+
+	testcases := []struct {
+		name          string
+		remoteAddr    string
+		expCode       int
+		expRemaining  string
+		expRetryAfter string
+	}{
+		{
+			name:         "first request is allowed",
+			remoteAddr:   "192.0.2.1:1234",
+			expCode:      http.StatusOK,
+			expRemaining: "1",
+		},
+		{
+			name:         "second request is allowed",
+			remoteAddr:   "192.0.2.1:1234",
+			expCode:      http.StatusOK,
+			expRemaining: "0",
+		},
+		{
+			name:          "third request is limited",
+			remoteAddr:    "192.0.2.1:1234",
+			expCode:       http.StatusTooManyRequests,
+			expRemaining:  "0",
+			expRetryAfter: "30",
+		},
+		{
+			name:         "request from another IP is allowed",
+			remoteAddr:   "192.0.2.2:1234",
+			expCode:      http.StatusOK,
+			expRemaining: "1",
+		},
+	}
+
+	for _, tc := range testcases {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/auth/sign-in", nil)
+		r.RemoteAddr = tc.remoteAddr
+
+		handler.ServeHTTP(w, r)
+
+		assert.Equal(t, tc.expCode, w.Code, tc.name)
+		assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"), tc.name)
+		assert.Equal(t, tc.expRemaining, w.Header().Get("RateLimit-Remaining"), tc.name)
+		assert.Equal(t, tc.expRetryAfter, w.Header().Get("Retry-After"), tc.name)
+	}
+}
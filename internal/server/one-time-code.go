@@ -0,0 +1,63 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/imarrche/jwt-auth-example/internal/service"
+)
+
+type sendSignInCodeRequest struct {
+	Email string `json:"email"`
+}
+
+// sendSignInCode sends a one-time code to sign in with to user. The response doesn't
+// depend on whether the email is registered.
+func (s *Server) sendSignInCode() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req sendSignInCodeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.error(w, r, service.ErrMalformedRequest.Wrap(err))
+			return
+		}
+
+		if err := s.service.Auth().SendSignInCode(r.Context(), req.Email); err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, nil)
+	}
+}
+
+type signInWithCodeRequest struct {
+	Email string `json:"email"`
+	Code  string `json:"code"`
+}
+
+// signInWithCode returns access and refresh JWTs for user if valid one-time code is
+// provided, users with MFA enabled get MFA JWT instead.
+func (s *Server) signInWithCode() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req signInWithCodeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.error(w, r, service.ErrMalformedRequest.Wrap(err))
+			return
+		}
+
+		result, err := s.service.Auth().SignInWithCode(
+			r.Context(), req.Email, req.Code, clientIP(r),
+		)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		res := signInResponse{
+			AccessToken:  result.AccessToken,
+			RefreshToken: result.RefreshToken,
+			MFAToken:     result.MFAToken,
+		}
+		s.respond(w, r, http.StatusOK, res)
+	}
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/service"
+	mock_service "github.com/imarrche/jwt-auth-example/internal/service/mocks"
+)
+
+func TestServer_sendSignInCode(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	s := mock_service.NewMockService(c)
+	as := mock_service.NewMockAuth(c)
+	as.EXPECT().SendSignInCode(gomock.Any(), "user1@test.com").Return(nil)
+	s.EXPECT().Auth().Return(as)
+	server := &Server{router: chi.NewRouter(), service: s}
+
+	b := &bytes.Buffer{}
+	json.NewEncoder(b).Encode(sendSignInCodeRequest{Email: "user1@test.com"})
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/auth/code", b)
+
+	server.sendSignInCode().ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestServer_signInWithCode(t *testing.T) {
+	testcases := []struct {
+		name        string
+		mock        func(*gomock.Controller, *mock_service.MockService)
+		expCode     int
+		expResponse signInResponse
+	}{
+		{
+			name: "tokens are issued",
+			mock: func(c *gomock.Controller, s *mock_service.MockService) {
+				as := mock_service.NewMockAuth(c)
+				as.EXPECT().SignInWithCode(
+					gomock.Any(), "user1@test.com", "123456", gomock.Any(),
+				).Return(model.SignInResult{AccessToken: "access", RefreshToken: "refresh"}, nil)
+				s.EXPECT().Auth().Return(as)
+			},
+			expCode:     http.StatusOK,
+			expResponse: signInResponse{AccessToken: "access", RefreshToken: "refresh"},
+		},
+		{
+			name: "MFA is required",
+			mock: func(c *gomock.Controller, s *mock_service.MockService) {
+				as := mock_service.NewMockAuth(c)
+				as.EXPECT().SignInWithCode(
+					gomock.Any(), "user1@test.com", "123456", gomock.Any(),
+				).Return(model.SignInResult{MFAToken: "mfa_token"}, nil)
+				s.EXPECT().Auth().Return(as)
+			},
+			expCode:     http.StatusOK,
+			expResponse: signInResponse{MFAToken: "mfa_token"},
+		},
+		{
+			name: "code is invalid",
+			mock: func(c *gomock.Controller, s *mock_service.MockService) {
+				as := mock_service.NewMockAuth(c)
+				as.EXPECT().SignInWithCode(
+					gomock.Any(), "user1@test.com", "123456", gomock.Any(),
+				).Return(model.SignInResult{}, service.ErrInvalidOneTimeCode)
+				s.EXPECT().Auth().Return(as)
+			},
+			expCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "too many attempts",
+			mock: func(c *gomock.Controller, s *mock_service.MockService) {
+				as := mock_service.NewMockAuth(c)
+				as.EXPECT().SignInWithCode(
+					gomock.Any(), "user1@test.com", "123456", gomock.Any(),
+				).Return(model.SignInResult{}, service.ErrTooManyAttempts)
+				s.EXPECT().Auth().Return(as)
+			},
+			expCode: http.StatusTooManyRequests,
+		},
+	}
+
+	for _, tc := range testcases {
+		c := gomock.NewController(t)
+		defer c.Finish()
+		s := mock_service.NewMockService(c)
+		tc.mock(c, s)
+		server := &Server{router: chi.NewRouter(), service: s}
+
+		b := &bytes.Buffer{}
+		json.NewEncoder(b).Encode(signInWithCodeRequest{Email: "user1@test.com", Code: "123456"})
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/auth/code/sign-in", b)
+
+		server.signInWithCode().ServeHTTP(w, r)
+
+		assert.Equal(t, tc.expCode, w.Code, tc.name)
+		if tc.expCode == http.StatusOK {
+			var response signInResponse
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(&response), tc.name)
+			assert.Equal(t, tc.expResponse, response, tc.name)
+		}
+	}
+}
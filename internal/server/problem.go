@@ -0,0 +1,81 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/imarrche/jwt-auth-example/internal/logger"
+	"github.com/imarrche/jwt-auth-example/internal/service"
+)
+
+// problem is RFC 7807 problem details object, Code is a stable machine readable code
+// of the error and Errors are per field validation errors.
+type problem struct {
+	Type     string            `json:"type"`
+	Title    string            `json:"title"`
+	Status   int               `json:"status"`
+	Detail   string            `json:"detail"`
+	Instance string            `json:"instance"`
+	Code     string            `json:"code"`
+	Errors   map[string]string `json:"errors,omitempty"`
+}
+
+// statuses maps domain error kinds to HTTP status codes.
+var statuses = map[service.Kind]int{
+	service.KindInternal:        http.StatusInternalServerError,
+	service.KindMalformed:       http.StatusBadRequest,
+	service.KindInvalid:         http.StatusUnprocessableEntity,
+	service.KindUnauthorized:    http.StatusUnauthorized,
+	service.KindForbidden:       http.StatusForbidden,
+	service.KindNotFound:        http.StatusNotFound,
+	service.KindConflict:        http.StatusConflict,
+	service.KindLocked:          http.StatusLocked,
+	service.KindTooManyRequests: http.StatusTooManyRequests,
+}
+
+// newProblem returns problem details of the domain error. Only validation errors are
+// detailed with their cause, other causes may be internal, so their public message is
+// used instead.
+func newProblem(r *http.Request, e *service.Error) problem {
+	status, ok := statuses[e.Kind]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+	detail := e.Message
+	if errors.Is(e, service.ErrValidationFailed) {
+		detail = e.Error()
+	}
+
+	return problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: r.URL.Path,
+		Code:     e.Code,
+		Errors:   e.Fields,
+	}
+}
+
+// error responds with problem details of the error. Errors which aren't domain errors
+// are logged and reported as internal errors, causes of other errors are logged since
+// they aren't reported to clients.
+func (s *Server) error(w http.ResponseWriter, r *http.Request, err error) {
+	var e *service.Error
+	if !errors.As(err, &e) {
+		logger.Get().Error("unexpected error", zap.String("path", r.URL.Path), zap.Error(err))
+		e = service.ErrInternal.Wrap(err)
+	} else if e.Kind == service.KindInternal {
+		logger.Get().Error("internal error", zap.String("path", r.URL.Path), zap.Error(err))
+	} else if e.Err != nil {
+		logger.Get().Info("request error", zap.String("path", r.URL.Path), zap.Error(err))
+	}
+
+	p := newProblem(r, e)
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imarrche/jwt-auth-example/internal/service"
+)
+
+func TestServer_error(t *testing.T) {
+	server := &Server{}
+
+	testcases := []struct {
+		name       string
+		err        error
+		expProblem problem
+	}{
+		{
+			name: "email is taken",
+			err:  service.ErrEmailIsTaken,
+			expProblem: problem{
+				Type:     "about:blank",
+				Title:    "Conflict",
+				Status:   http.StatusConflict,
+				Detail:   "user with this email already exists",
+				Instance: "/api/v1/auth/sign-up",
+				Code:     "email_taken",
+			},
+		},
+		{
+			name: "validation failed",
+			err: service.NewValidationError(validation.Errors{
+				"email": errors.New("must be a valid email address"),
+			}),
+			expProblem: problem{
+				Type:     "about:blank",
+				Title:    "Unprocessable Entity",
+				Status:   http.StatusUnprocessableEntity,
+				Detail:   "validation failed: email: must be a valid email address.",
+				Instance: "/api/v1/auth/sign-up",
+				Code:     "validation_failed",
+				Errors:   map[string]string{"email": "must be a valid email address"},
+			},
+		},
+		{
+			name: "request is malformed",
+			err:  service.ErrMalformedRequest.Wrap(errors.New("unexpected EOF")),
+			expProblem: problem{
+				Type:     "about:blank",
+				Title:    "Bad Request",
+				Status:   http.StatusBadRequest,
+				Detail:   "request is malformed",
+				Instance: "/api/v1/auth/sign-up",
+				Code:     "malformed_request",
+			},
+		},
+		{
+			name: "cause of a domain error isn't exposed",
+			err:  service.ErrInvalidToken.Wrap(errors.New("signature is invalid")),
+			expProblem: problem{
+				Type:     "about:blank",
+				Title:    "Unauthorized",
+				Status:   http.StatusUnauthorized,
+				Detail:   "JWT is invalid",
+				Instance: "/api/v1/auth/sign-up",
+				Code:     "invalid_token",
+			},
+		},
+		{
+			name: "unexpected error isn't exposed",
+			err:  errors.New("connection refused"),
+			expProblem: problem{
+				Type:     "about:blank",
+				Title:    "Internal Server Error",
+				Status:   http.StatusInternalServerError,
+				Detail:   "internal error",
+				Instance: "/api/v1/auth/sign-up",
+				Code:     "internal",
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/auth/sign-up", nil)
+
+			server.error(w, r, tc.err)
+			var p problem
+			err := json.NewDecoder(w.Body).Decode(&p)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expProblem.Status, w.Code)
+			assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+			assert.Equal(t, tc.expProblem, p)
+		})
+	}
+}
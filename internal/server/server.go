@@ -9,9 +9,11 @@ import (
 	"time"
 
 	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
 
 	"github.com/imarrche/jwt-auth-example/internal/config"
 	"github.com/imarrche/jwt-auth-example/internal/logger"
+	"github.com/imarrche/jwt-auth-example/internal/ratelimit"
 	"github.com/imarrche/jwt-auth-example/internal/service"
 )
 
@@ -20,10 +22,11 @@ type Server struct {
 	server  *http.Server
 	router  *chi.Mux
 	service service.Service
+	limiter ratelimit.Limiter
 }
 
-// New returns new Server instance.
-func New(c *config.Server, service service.Service) *Server {
+// New returns new Server instance. Requests aren't rate limited if limiter is nil.
+func New(c *config.Server, service service.Service, limiter ratelimit.Limiter) *Server {
 	r := chi.NewRouter()
 	s := &http.Server{
 		Addr:         c.Addr,
@@ -33,13 +36,15 @@ func New(c *config.Server, service service.Service) *Server {
 		WriteTimeout: 3 * time.Second,
 	}
 
-	return &Server{server: s, router: r, service: service}
+	return &Server{server: s, router: r, service: service, limiter: limiter}
 }
 
 // Run runs the server.
 func (s *Server) Run() {
 	// Middleware setup.
 	s.router.Use(s.loggerMiddleware())
+	// Requests are cancelled once the response can't be written anymore.
+	s.router.Use(middleware.Timeout(s.server.WriteTimeout))
 
 	// Router setup.
 	s.configureRouter()
@@ -71,16 +76,86 @@ func (s *Server) Run() {
 
 // configureRouter maps all handlers.
 func (s *Server) configureRouter() {
+	rl := config.Get().RateLimit
+	byAPIKey := s.rateLimitMiddleware("api", rl.API, rateLimitByAPIKey)
+	byUser := s.rateLimitMiddleware("api", rl.API, rateLimitByUser)
+
+	s.router.With(byAPIKey).Get("/.well-known/jwks.json", s.jwks())
+
 	s.router.Route("/api/v1", func(r chi.Router) {
 		r.Route("/auth", func(r chi.Router) {
+			r.Use(s.rateLimitMiddleware("auth", rl.Auth, rateLimitByIP))
 			r.Post("/sign-up", s.signUp())
 			r.Post("/sign-in", s.signIn())
 			r.Post("/refresh", s.refresh())
+			r.With(s.authMiddleware()).Post("/sign-out", s.signOut())
+			r.Post("/password/forgot", s.forgotPassword())
+			r.Post("/password/reset", s.resetPassword())
+			r.Get("/verify-email", s.verifyEmail())
+			r.Post("/mfa/verify", s.verifyMFA())
+			r.Post("/magic-link", s.sendMagicLink())
+			r.Post("/magic-link/consume", s.consumeMagicLink())
+			r.Post("/code", s.sendSignInCode())
+			r.Post("/code/sign-in", s.signInWithCode())
+			r.Route("/webauthn", func(r chi.Router) {
+				r.With(s.authMiddleware()).Post("/register/begin", s.beginWebAuthnRegistration())
+				r.With(s.authMiddleware()).Post("/register/finish", s.finishWebAuthnRegistration())
+				r.Post("/login/begin", s.beginWebAuthnLogin())
+				r.Post("/login/finish", s.finishWebAuthnLogin())
+			})
 		})
 
-		r.Get("/public", s.public())
+		r.Route("/me", func(r chi.Router) {
+			r.Use(s.authMiddleware(), byUser)
+			r.Get("/", s.me())
+			r.Patch("/", s.updateMe())
+			r.Delete("/", s.deleteMe())
+			r.Post("/step-up", s.sendStepUpCode())
+			r.Post("/password", s.changePassword())
+			r.Route("/mfa/totp", func(r chi.Router) {
+				r.Post("/", s.enrollTOTP())
+				r.Post("/confirm", s.confirmTOTP())
+				r.Delete("/", s.disableTOTP())
+			})
+			r.Post("/mfa/recovery-codes", s.regenerateRecoveryCodes())
+			r.Get("/webauthn/credentials", s.webAuthnCredentials())
+			r.Delete("/webauthn/credentials/{id}", s.deleteWebAuthnCredential())
+		})
+
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(s.authMiddleware(), byUser)
+			r.With(s.RequirePermission("users:read")).Get("/users", s.listUsers())
+			r.With(s.RequirePermission("users:write")).Post("/users", s.createUser())
+			r.Route("/users/{id}", func(r chi.Router) {
+				r.With(s.RequirePermission("users:read")).Get("/", s.getUser())
+				r.With(s.RequirePermission("users:read")).Get("/audit-events", s.userAuditEvents())
+				r.Group(func(r chi.Router) {
+					r.Use(s.RequirePermission("users:write"))
+					r.Patch("/", s.updateUser())
+					r.Delete("/", s.adminAction(service.Admin.DeleteUser))
+					r.Put("/disabled", s.adminAction(service.Admin.DisableUser))
+					r.Delete("/disabled", s.adminAction(service.Admin.EnableUser))
+					r.Post("/password-reset", s.adminAction(service.Admin.ResetPassword))
+					r.Delete("/sessions", s.adminAction(service.Admin.RevokeSessions))
+				})
+			})
+			r.Route("/users/{id}/roles", func(r chi.Router) {
+				r.With(s.RequirePermission("users:read")).Get("/", s.userRoles())
+				r.With(s.RequirePermission("roles:write")).Put(
+					"/{role}", s.roleAction(service.Admin.GrantRole),
+				)
+				r.With(s.RequirePermission("roles:write")).Delete(
+					"/{role}", s.roleAction(service.Admin.RevokeRole),
+				)
+			})
+			r.With(s.RequirePermission("users:write")).Delete(
+				"/users/{id}/lockout", s.adminAction(service.Admin.UnlockUser),
+			)
+		})
+
+		r.With(byAPIKey).Get("/public", s.public())
 		r.Route("/private", func(r chi.Router) {
-			r.Use(s.authMiddleware())
+			r.Use(s.authMiddleware(), byUser)
 			r.Get("/", s.private())
 		})
 	})
@@ -94,11 +169,3 @@ func (s *Server) respond(w http.ResponseWriter, r *http.Request, code int, data
 		json.NewEncoder(w).Encode(data)
 	}
 }
-
-func (s *Server) error(w http.ResponseWriter, r *http.Request, code int, err error) {
-	if err != nil {
-		s.respond(w, r, code, map[string]string{"error": err.Error()})
-		return
-	}
-	s.respond(w, r, code, nil)
-}
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/service"
+)
+
+// meResponse is the profile of the authenticated user along with MFA status.
+type meResponse struct {
+	model.User
+	MFA model.MFAStatus `json:"mfa"`
+}
+
+// me returns the authenticated user and their MFA status.
+func (s *Server) me() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p, ok := PrincipalFromContext(r.Context())
+		if !ok {
+			s.error(w, r, service.ErrUnauthorized)
+			return
+		}
+
+		u, err := s.service.Users().GetByID(r.Context(), p.UserID)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+		mfa, err := s.service.MFA().Status(r.Context(), p.UserID)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, meResponse{User: u, MFA: mfa})
+	}
+}
+
+type updateMeRequest struct {
+	Username   string `json:"username"`
+	FirstName  string `json:"first_name"`
+	SecondName string `json:"second_name"`
+}
+
+// updateMe updates profile of the authenticated user, omitted fields are left unchanged.
+func (s *Server) updateMe() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p, ok := PrincipalFromContext(r.Context())
+		if !ok {
+			s.error(w, r, service.ErrUnauthorized)
+			return
+		}
+
+		var req updateMeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.error(w, r, service.ErrMalformedRequest.Wrap(err))
+			return
+		}
+
+		u, err := s.service.Users().UpdateProfile(r.Context(), model.User{
+			ID:         p.UserID,
+			Username:   req.Username,
+			FirstName:  req.FirstName,
+			SecondName: req.SecondName,
+		})
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, u)
+	}
+}
+
+type changePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	Code            string `json:"code"`
+	NewPassword     string `json:"new_password"`
+}
+
+// changePassword changes password of the authenticated user, either current password
+// or step-up code is required.
+func (s *Server) changePassword() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p, ok := PrincipalFromContext(r.Context())
+		if !ok {
+			s.error(w, r, service.ErrUnauthorized)
+			return
+		}
+
+		var req changePasswordRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.error(w, r, service.ErrMalformedRequest.Wrap(err))
+			return
+		}
+
+		reauth := model.Reauthentication{Password: req.CurrentPassword, Code: req.Code}
+		err := s.service.Users().ChangePassword(r.Context(), p.UserID, reauth, req.NewPassword)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, nil)
+	}
+}
+
+type deleteMeRequest struct {
+	CurrentPassword string `json:"current_password"`
+	Code            string `json:"code"`
+}
+
+// deleteMe deletes account of the authenticated user, either current password or
+// step-up code is required.
+func (s *Server) deleteMe() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p, ok := PrincipalFromContext(r.Context())
+		if !ok {
+			s.error(w, r, service.ErrUnauthorized)
+			return
+		}
+
+		var req deleteMeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.error(w, r, service.ErrMalformedRequest.Wrap(err))
+			return
+		}
+
+		reauth := model.Reauthentication{Password: req.CurrentPassword, Code: req.Code}
+		if err := s.service.Users().DeleteAccount(r.Context(), p.UserID, reauth); err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, nil)
+	}
+}
+
+// sendStepUpCode sends a one-time code to the authenticated user to confirm a sensitive
+// action with.
+func (s *Server) sendStepUpCode() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p, ok := PrincipalFromContext(r.Context())
+		if !ok {
+			s.error(w, r, service.ErrUnauthorized)
+			return
+		}
+
+		if err := s.service.Auth().SendStepUpCode(r.Context(), p.UserID); err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, nil)
+	}
+}
@@ -0,0 +1,283 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/service"
+	mock_service "github.com/imarrche/jwt-auth-example/internal/service/mocks"
+)
+
+func TestServer_me(t *testing.T) {
+	server := &Server{router: chi.NewRouter()}
+	server.configureRouter()
+
+	testcases := []struct {
+		name      string
+		mock      func(*gomock.Controller, *mock_service.MockService, model.User)
+		principal model.Principal
+		expUser   model.User
+		expMFA    model.MFAStatus
+		expCode   int
+	}{
+		{
+			name: "user is returned",
+			mock: func(c *gomock.Controller, s *mock_service.MockService, u model.User) {
+				us := mock_service.NewMockUsers(c)
+				us.EXPECT().GetByID(gomock.Any(), u.ID).Return(u, nil)
+				s.EXPECT().Users().Return(us)
+				ms := mock_service.NewMockMFA(c)
+				ms.EXPECT().Status(gomock.Any(), u.ID).Return(
+					model.MFAStatus{TOTPEnabled: true, RecoveryCodes: 8}, nil,
+				)
+				s.EXPECT().MFA().Return(ms)
+			},
+			principal: model.Principal{UserID: 1},
+			expUser:   model.User{ID: 1, Username: "user1"},
+			expMFA:    model.MFAStatus{TOTPEnabled: true, RecoveryCodes: 8},
+			expCode:   http.StatusOK,
+		},
+	}
+
+	for _, tc := range testcases {
+		c := gomock.NewController(t)
+		defer c.Finish()
+		s := mock_service.NewMockService(c)
+		tc.mock(c, s, tc.expUser)
+		server.service = s
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/me", nil)
+		r = r.WithContext(WithPrincipal(r.Context(), tc.principal))
+
+		server.me().ServeHTTP(w, r)
+		var response meResponse
+		err := json.NewDecoder(w.Body).Decode(&response)
+
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expCode, w.Code)
+		assert.Equal(t, tc.expUser, response.User)
+		assert.Equal(t, tc.expMFA, response.MFA)
+	}
+}
+
+func TestServer_updateMe(t *testing.T) {
+	server := &Server{router: chi.NewRouter()}
+	server.configureRouter()
+
+	testcases := []struct {
+		name      string
+		mock      func(*gomock.Controller, *mock_service.MockService)
+		principal model.Principal
+		request   updateMeRequest
+		expUser   model.User
+		expCode   int
+	}{
+		{
+			name: "profile is updated",
+			mock: func(c *gomock.Controller, s *mock_service.MockService) {
+				us := mock_service.NewMockUsers(c)
+				us.EXPECT().UpdateProfile(gomock.Any(), model.User{ID: 1, FirstName: "Name"}).Return(
+					model.User{ID: 1, Username: "user1", FirstName: "Name"}, nil,
+				)
+				s.EXPECT().Users().Return(us)
+			},
+			principal: model.Principal{UserID: 1},
+			request:   updateMeRequest{FirstName: "Name"},
+			expUser:   model.User{ID: 1, Username: "user1", FirstName: "Name"},
+			expCode:   http.StatusOK,
+		},
+	}
+
+	for _, tc := range testcases {
+		c := gomock.NewController(t)
+		defer c.Finish()
+		s := mock_service.NewMockService(c)
+		tc.mock(c, s)
+		server.service = s
+
+		b := &bytes.Buffer{}
+		json.NewEncoder(b).Encode(tc.request)
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPatch, "/api/v1/me", b)
+		r = r.WithContext(WithPrincipal(r.Context(), tc.principal))
+
+		server.updateMe().ServeHTTP(w, r)
+		var u model.User
+		err := json.NewDecoder(w.Body).Decode(&u)
+
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expCode, w.Code)
+		assert.Equal(t, tc.expUser, u)
+	}
+}
+
+func TestServer_changePassword(t *testing.T) {
+	server := &Server{router: chi.NewRouter()}
+	server.configureRouter()
+
+	testcases := []struct {
+		name      string
+		mock      func(*gomock.Controller, *mock_service.MockService, changePasswordRequest)
+		principal model.Principal
+		request   changePasswordRequest
+		expCode   int
+	}{
+		{
+			name: "password is changed",
+			mock: func(c *gomock.Controller, s *mock_service.MockService, r changePasswordRequest) {
+				us := mock_service.NewMockUsers(c)
+				reauth := model.Reauthentication{Password: r.CurrentPassword, Code: r.Code}
+				us.EXPECT().ChangePassword(gomock.Any(), 1, reauth, r.NewPassword).Return(nil)
+				s.EXPECT().Users().Return(us)
+			},
+			principal: model.Principal{UserID: 1},
+			request: changePasswordRequest{
+				CurrentPassword: "password1", NewPassword: "password2",
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name: "password is changed with step-up code",
+			mock: func(c *gomock.Controller, s *mock_service.MockService, r changePasswordRequest) {
+				us := mock_service.NewMockUsers(c)
+				reauth := model.Reauthentication{Code: r.Code}
+				us.EXPECT().ChangePassword(gomock.Any(), 1, reauth, r.NewPassword).Return(nil)
+				s.EXPECT().Users().Return(us)
+			},
+			principal: model.Principal{UserID: 1},
+			request:   changePasswordRequest{Code: "123456", NewPassword: "password2"},
+			expCode:   http.StatusOK,
+		},
+	}
+
+	for _, tc := range testcases {
+		c := gomock.NewController(t)
+		defer c.Finish()
+		s := mock_service.NewMockService(c)
+		tc.mock(c, s, tc.request)
+		server.service = s
+
+		b := &bytes.Buffer{}
+		json.NewEncoder(b).Encode(tc.request)
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/me/password", b)
+		r = r.WithContext(WithPrincipal(r.Context(), tc.principal))
+
+		server.changePassword().ServeHTTP(w, r)
+
+		assert.Equal(t, tc.expCode, w.Code)
+	}
+}
+
+func TestServer_deleteMe(t *testing.T) {
+	server := &Server{router: chi.NewRouter()}
+	server.configureRouter()
+
+	testcases := []struct {
+		name      string
+		mock      func(*gomock.Controller, *mock_service.MockService)
+		principal model.Principal
+		request   deleteMeRequest
+		expCode   int
+	}{
+		{
+			name: "account is deleted",
+			mock: func(c *gomock.Controller, s *mock_service.MockService) {
+				us := mock_service.NewMockUsers(c)
+				reauth := model.Reauthentication{Code: "123456"}
+				us.EXPECT().DeleteAccount(gomock.Any(), 1, reauth).Return(nil)
+				s.EXPECT().Users().Return(us)
+			},
+			principal: model.Principal{UserID: 1},
+			request:   deleteMeRequest{Code: "123456"},
+			expCode:   http.StatusOK,
+		},
+		{
+			name: "code is invalid",
+			mock: func(c *gomock.Controller, s *mock_service.MockService) {
+				us := mock_service.NewMockUsers(c)
+				reauth := model.Reauthentication{Code: "123456"}
+				us.EXPECT().DeleteAccount(gomock.Any(), 1, reauth).Return(
+					service.ErrInvalidOneTimeCode,
+				)
+				s.EXPECT().Users().Return(us)
+			},
+			principal: model.Principal{UserID: 1},
+			request:   deleteMeRequest{Code: "123456"},
+			expCode:   http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tc := range testcases {
+		c := gomock.NewController(t)
+		defer c.Finish()
+		s := mock_service.NewMockService(c)
+		tc.mock(c, s)
+		server.service = s
+
+		b := &bytes.Buffer{}
+		json.NewEncoder(b).Encode(tc.request)
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodDelete, "/api/v1/me", b)
+		r = r.WithContext(WithPrincipal(r.Context(), tc.principal))
+
+		server.deleteMe().ServeHTTP(w, r)
+
+		assert.Equal(t, tc.expCode, w.Code)
+	}
+}
+
+func TestServer_sendStepUpCode(t *testing.T) {
+	server := &Server{router: chi.NewRouter()}
+	server.configureRouter()
+
+	testcases := []struct {
+		name    string
+		mock    func(*gomock.Controller, *mock_service.MockService)
+		expCode int
+	}{
+		{
+			name: "code is sent",
+			mock: func(c *gomock.Controller, s *mock_service.MockService) {
+				as := mock_service.NewMockAuth(c)
+				as.EXPECT().SendStepUpCode(gomock.Any(), 1).Return(nil)
+				s.EXPECT().Auth().Return(as)
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name: "code has recently been sent",
+			mock: func(c *gomock.Controller, s *mock_service.MockService) {
+				as := mock_service.NewMockAuth(c)
+				as.EXPECT().SendStepUpCode(gomock.Any(), 1).Return(service.ErrCodeRecentlySent)
+				s.EXPECT().Auth().Return(as)
+			},
+			expCode: http.StatusTooManyRequests,
+		},
+	}
+
+	for _, tc := range testcases {
+		c := gomock.NewController(t)
+		defer c.Finish()
+		s := mock_service.NewMockService(c)
+		tc.mock(c, s)
+		server.service = s
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/me/step-up", nil)
+		r = r.WithContext(WithPrincipal(r.Context(), model.Principal{UserID: 1}))
+
+		server.sendStepUpCode().ServeHTTP(w, r)
+
+		assert.Equal(t, tc.expCode, w.Code)
+	}
+}
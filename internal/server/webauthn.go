@@ -0,0 +1,155 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/service"
+)
+
+type webAuthnOptionsResponse struct {
+	Options interface{} `json:"options"`
+	Session string      `json:"session"`
+}
+
+// beginWebAuthnRegistration returns options of passkey creation for the authenticated
+// user along with the session to finish registration with.
+func (s *Server) beginWebAuthnRegistration() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p, ok := PrincipalFromContext(r.Context())
+		if !ok {
+			s.error(w, r, service.ErrUnauthorized)
+			return
+		}
+
+		options, session, err := s.service.WebAuthn().BeginRegistration(r.Context(), p.UserID)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, webAuthnOptionsResponse{Options: options, Session: session})
+	}
+}
+
+type finishWebAuthnRegistrationRequest struct {
+	Session    string                    `json:"session"`
+	Name       string                    `json:"name"`
+	Credential model.WebAuthnAttestation `json:"credential"`
+}
+
+// finishWebAuthnRegistration registers the passkey created by the authenticated user's
+// authenticator and returns it.
+func (s *Server) finishWebAuthnRegistration() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p, ok := PrincipalFromContext(r.Context())
+		if !ok {
+			s.error(w, r, service.ErrUnauthorized)
+			return
+		}
+
+		var req finishWebAuthnRegistrationRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.error(w, r, service.ErrMalformedRequest.Wrap(err))
+			return
+		}
+
+		cred, err := s.service.WebAuthn().FinishRegistration(
+			r.Context(), p.UserID, req.Session, req.Name, req.Credential,
+		)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, cred)
+	}
+}
+
+// beginWebAuthnLogin returns options of passkey assertion along with the session to
+// finish signing in with.
+func (s *Server) beginWebAuthnLogin() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		options, session, err := s.service.WebAuthn().BeginLogin(r.Context())
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, webAuthnOptionsResponse{Options: options, Session: session})
+	}
+}
+
+type finishWebAuthnLoginRequest struct {
+	Session    string                  `json:"session"`
+	Credential model.WebAuthnAssertion `json:"credential"`
+}
+
+type finishWebAuthnLoginResponse struct {
+	AccessToken  string `json:"access"`
+	RefreshToken string `json:"refresh"`
+}
+
+// finishWebAuthnLogin returns access and refresh JWTs for the user if valid passkey
+// assertion is provided.
+func (s *Server) finishWebAuthnLogin() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req finishWebAuthnLoginRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.error(w, r, service.ErrMalformedRequest.Wrap(err))
+			return
+		}
+
+		accessJWT, refreshJWT, err := s.service.WebAuthn().FinishLogin(
+			r.Context(), req.Session, req.Credential,
+		)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		res := finishWebAuthnLoginResponse{AccessToken: accessJWT, RefreshToken: refreshJWT}
+		s.respond(w, r, http.StatusOK, res)
+	}
+}
+
+// webAuthnCredentials returns passkeys of the authenticated user.
+func (s *Server) webAuthnCredentials() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p, ok := PrincipalFromContext(r.Context())
+		if !ok {
+			s.error(w, r, service.ErrUnauthorized)
+			return
+		}
+
+		credentials, err := s.service.WebAuthn().Credentials(r.Context(), p.UserID)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, credentials)
+	}
+}
+
+// deleteWebAuthnCredential deletes the passkey of the authenticated user.
+func (s *Server) deleteWebAuthnCredential() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p, ok := PrincipalFromContext(r.Context())
+		if !ok {
+			s.error(w, r, service.ErrUnauthorized)
+			return
+		}
+
+		err := s.service.WebAuthn().DeleteCredential(r.Context(), p.UserID, chi.URLParam(r, "id"))
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, nil)
+	}
+}
@@ -0,0 +1,223 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/service"
+	mock_service "github.com/imarrche/jwt-auth-example/internal/service/mocks"
+)
+
+func TestServer_beginWebAuthnRegistration(t *testing.T) {
+	testcases := []struct {
+		name      string
+		mock      func(*gomock.Controller, *mock_service.MockService)
+		principal *model.Principal
+		expCode   int
+	}{
+		{
+			name: "options are returned",
+			mock: func(c *gomock.Controller, s *mock_service.MockService) {
+				ws := mock_service.NewMockWebAuthn(c)
+				ws.EXPECT().BeginRegistration(gomock.Any(), 1).Return(
+					model.WebAuthnCreationOptions{Challenge: []byte("challenge")}, "session", nil,
+				)
+				s.EXPECT().WebAuthn().Return(ws)
+			},
+			principal: &model.Principal{UserID: 1},
+			expCode:   http.StatusOK,
+		},
+		{
+			name:    "user isn't authenticated",
+			mock:    func(*gomock.Controller, *mock_service.MockService) {},
+			expCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testcases {
+		c := gomock.NewController(t)
+		defer c.Finish()
+		s := mock_service.NewMockService(c)
+		tc.mock(c, s)
+		server := &Server{router: chi.NewRouter(), service: s}
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/auth/webauthn/register/begin", nil)
+		if tc.principal != nil {
+			r = r.WithContext(WithPrincipal(r.Context(), *tc.principal))
+		}
+
+		server.beginWebAuthnRegistration().ServeHTTP(w, r)
+
+		assert.Equal(t, tc.expCode, w.Code, tc.name)
+		if tc.expCode == http.StatusOK {
+			var response struct {
+				Options model.WebAuthnCreationOptions `json:"options"`
+				Session string                        `json:"session"`
+			}
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(&response), tc.name)
+			assert.Equal(t, model.Base64URL("challenge"), response.Options.Challenge, tc.name)
+			assert.Equal(t, "session", response.Session, tc.name)
+		}
+	}
+}
+
+func TestServer_finishWebAuthnRegistration(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	credential := model.WebAuthnAttestation{
+		ID: "aWQ", RawID: []byte("id"), Type: "public-key",
+		Response: model.WebAuthnAttestationResponse{
+			ClientDataJSON: []byte("{}"), AttestationObject: []byte{0xa0},
+		},
+	}
+	s := mock_service.NewMockService(c)
+	ws := mock_service.NewMockWebAuthn(c)
+	ws.EXPECT().FinishRegistration(gomock.Any(), 1, "session", "Laptop", credential).Return(
+		model.WebAuthnCredential{ID: "aWQ", UserID: 1, Name: "Laptop"}, nil,
+	)
+	s.EXPECT().WebAuthn().Return(ws)
+	server := &Server{router: chi.NewRouter(), service: s}
+
+	b := &bytes.Buffer{}
+	json.NewEncoder(b).Encode(finishWebAuthnRegistrationRequest{
+		Session: "session", Name: "Laptop", Credential: credential,
+	})
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/auth/webauthn/register/finish", b)
+	r = r.WithContext(WithPrincipal(r.Context(), model.Principal{UserID: 1}))
+
+	server.finishWebAuthnRegistration().ServeHTTP(w, r)
+	var response map[string]interface{}
+	err := json.NewDecoder(w.Body).Decode(&response)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "aWQ", response["id"])
+	assert.Equal(t, "Laptop", response["name"])
+	assert.NotContains(t, response, "public_key")
+}
+
+func TestServer_finishWebAuthnLogin(t *testing.T) {
+	credential := model.WebAuthnAssertion{
+		ID: "aWQ", RawID: []byte("id"), Type: "public-key",
+		Response: model.WebAuthnAssertionResponse{
+			ClientDataJSON: []byte("{}"), AuthenticatorData: []byte{1},
+			Signature: []byte{2}, UserHandle: []byte("1"),
+		},
+	}
+
+	testcases := []struct {
+		name    string
+		mock    func(*gomock.Controller, *mock_service.MockService)
+		body    interface{}
+		expCode int
+	}{
+		{
+			name: "tokens are issued",
+			mock: func(c *gomock.Controller, s *mock_service.MockService) {
+				ws := mock_service.NewMockWebAuthn(c)
+				ws.EXPECT().FinishLogin(gomock.Any(), "session", credential).Return(
+					"access", "refresh", nil,
+				)
+				s.EXPECT().WebAuthn().Return(ws)
+			},
+			body:    finishWebAuthnLoginRequest{Session: "session", Credential: credential},
+			expCode: http.StatusOK,
+		},
+		{
+			name: "assertion is invalid",
+			mock: func(c *gomock.Controller, s *mock_service.MockService) {
+				ws := mock_service.NewMockWebAuthn(c)
+				ws.EXPECT().FinishLogin(gomock.Any(), "session", credential).Return(
+					"", "", service.ErrInvalidAssertion,
+				)
+				s.EXPECT().WebAuthn().Return(ws)
+			},
+			body:    finishWebAuthnLoginRequest{Session: "session", Credential: credential},
+			expCode: http.StatusUnauthorized,
+		},
+		{
+			name:    "credential isn't base64url encoded",
+			mock:    func(*gomock.Controller, *mock_service.MockService) {},
+			body:    map[string]interface{}{"credential": map[string]string{"rawId": "!"}},
+			expCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testcases {
+		c := gomock.NewController(t)
+		defer c.Finish()
+		s := mock_service.NewMockService(c)
+		tc.mock(c, s)
+		server := &Server{router: chi.NewRouter(), service: s}
+
+		b := &bytes.Buffer{}
+		json.NewEncoder(b).Encode(tc.body)
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/auth/webauthn/login/finish", b)
+
+		server.finishWebAuthnLogin().ServeHTTP(w, r)
+
+		assert.Equal(t, tc.expCode, w.Code, tc.name)
+		if tc.expCode == http.StatusOK {
+			var response finishWebAuthnLoginResponse
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(&response), tc.name)
+			assert.Equal(t, "access", response.AccessToken, tc.name)
+			assert.Equal(t, "refresh", response.RefreshToken, tc.name)
+		}
+	}
+}
+
+func TestServer_deleteWebAuthnCredential(t *testing.T) {
+	testcases := []struct {
+		name    string
+		mock    func(*gomock.Controller, *mock_service.MockService)
+		expCode int
+	}{
+		{
+			name: "credential is deleted",
+			mock: func(c *gomock.Controller, s *mock_service.MockService) {
+				ws := mock_service.NewMockWebAuthn(c)
+				ws.EXPECT().DeleteCredential(gomock.Any(), 1, "aWQ").Return(nil)
+				s.EXPECT().WebAuthn().Return(ws)
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name: "credential is not found",
+			mock: func(c *gomock.Controller, s *mock_service.MockService) {
+				ws := mock_service.NewMockWebAuthn(c)
+				ws.EXPECT().DeleteCredential(gomock.Any(), 1, "aWQ").Return(service.ErrNotFound)
+				s.EXPECT().WebAuthn().Return(ws)
+			},
+			expCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testcases {
+		c := gomock.NewController(t)
+		defer c.Finish()
+		s := mock_service.NewMockService(c)
+		tc.mock(c, s)
+		server := &Server{router: chi.NewRouter(), service: s}
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodDelete, "/api/v1/me/webauthn/credentials/aWQ", nil)
+		r = withURLParams(r, map[string]string{"id": "aWQ"})
+		r = r.WithContext(WithPrincipal(r.Context(), model.Principal{UserID: 1}))
+
+		server.deleteWebAuthnCredential().ServeHTTP(w, r)
+
+		assert.Equal(t, tc.expCode, w.Code, tc.name)
+	}
+}
@@ -0,0 +1,210 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/imarrche/jwt-auth-example/internal/logger"
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/service"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// adminService implements user management business logic.
+type adminService struct {
+	store store.Store
+	auth  *authService
+}
+
+// newAdminService creates and returns a new adminService instance.
+func newAdminService(s store.Store, auth *authService) *adminService {
+	return &adminService{store: s, auth: auth}
+}
+
+// CreateUser creates a user the same way as sign up does.
+func (s *adminService) CreateUser(
+	ctx context.Context, actorID int, u model.User,
+) (model.User, error) {
+	u, err := s.auth.SignUp(ctx, u)
+	if err != nil {
+		return model.User{}, err
+	}
+	if err := s.audit(ctx, actorID, model.AuditUserCreate, u.ID); err != nil {
+		return model.User{}, err
+	}
+
+	return u, nil
+}
+
+// UpdateUser updates username, email, first and second name of the user, empty fields
+// are left unchanged. Changed email has to be verified again.
+func (s *adminService) UpdateUser(
+	ctx context.Context, actorID int, update model.User,
+) (model.User, error) {
+	u, err := s.store.Users().GetByID(ctx, update.ID)
+	if err != nil {
+		return model.User{}, serviceError(err)
+	}
+
+	emailChanged := update.Email != "" && update.Email != u.Email
+	if update.Username != "" {
+		u.Username = update.Username
+	}
+	if emailChanged {
+		u.Email = update.Email
+	}
+	if update.FirstName != "" {
+		u.FirstName = update.FirstName
+	}
+	if update.SecondName != "" {
+		u.SecondName = update.SecondName
+	}
+	if err := u.ValidateAccount(); err != nil {
+		return model.User{}, service.NewValidationError(err)
+	}
+
+	u, err = s.store.Users().Update(ctx, u)
+	if err != nil {
+		return model.User{}, serviceError(err)
+	}
+	if err := s.audit(ctx, actorID, model.AuditUserUpdate, u.ID); err != nil {
+		return model.User{}, err
+	}
+	// Update doesn't fail if the email isn't sent, the user is already updated.
+	if emailChanged {
+		if err := s.auth.sendVerificationEmail(ctx, u); err != nil {
+			logger.Get().Error("couldn't send verification email", zap.Error(err))
+		}
+	}
+
+	return u, nil
+}
+
+// DisableUser disables the user, so the user can't sign in. All refresh tokens of the
+// user are revoked, issued access tokens stay valid until they expire.
+func (s *adminService) DisableUser(ctx context.Context, actorID, userID int) error {
+	u, err := s.store.Users().GetByID(ctx, userID)
+	if err != nil {
+		return serviceError(err)
+	}
+	if u.DisabledAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	if err := s.store.Users().SetDisabledAt(ctx, userID, &now); err != nil {
+		return serviceError(err)
+	}
+	if err := s.store.RefreshTokens().RevokeByUserID(ctx, userID); err != nil {
+		return err
+	}
+
+	return s.audit(ctx, actorID, model.AuditUserDisable, userID)
+}
+
+// EnableUser enables the disabled user.
+func (s *adminService) EnableUser(ctx context.Context, actorID, userID int) error {
+	u, err := s.store.Users().GetByID(ctx, userID)
+	if err != nil {
+		return serviceError(err)
+	}
+	if u.DisabledAt == nil {
+		return nil
+	}
+
+	if err := s.store.Users().SetDisabledAt(ctx, userID, nil); err != nil {
+		return serviceError(err)
+	}
+
+	return s.audit(ctx, actorID, model.AuditUserEnable, userID)
+}
+
+// DeleteUser deletes the user along with the user's roles and tokens, audit events of
+// the user are kept.
+func (s *adminService) DeleteUser(ctx context.Context, actorID, userID int) error {
+	if err := s.store.Users().DeleteByID(ctx, userID); err != nil {
+		return serviceError(err)
+	}
+
+	return s.audit(ctx, actorID, model.AuditUserDelete, userID)
+}
+
+// ResetPassword forces the user to reset password. The current password stops working,
+// all refresh tokens of the user are revoked and a password reset link is sent to the
+// user.
+func (s *adminService) ResetPassword(ctx context.Context, actorID, userID int) error {
+	u, err := s.store.Users().GetByID(ctx, userID)
+	if err != nil {
+		return serviceError(err)
+	}
+
+	// No password matches an empty hash.
+	if err := s.store.Users().SetPasswordHash(ctx, userID, ""); err != nil {
+		return serviceError(err)
+	}
+	if err := s.store.RefreshTokens().RevokeByUserID(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.audit(ctx, actorID, model.AuditUserPasswordReset, userID); err != nil {
+		return err
+	}
+
+	return s.auth.sendPasswordResetEmail(ctx, u)
+}
+
+// RevokeSessions revokes all refresh tokens of the user, so the user has to sign in
+// again once access tokens expire.
+func (s *adminService) RevokeSessions(ctx context.Context, actorID, userID int) error {
+	if _, err := s.store.Users().GetByID(ctx, userID); err != nil {
+		return serviceError(err)
+	}
+	if err := s.store.RefreshTokens().RevokeByUserID(ctx, userID); err != nil {
+		return err
+	}
+
+	return s.audit(ctx, actorID, model.AuditUserSessionsRevoke, userID)
+}
+
+// GrantRole grants the role to the user.
+func (s *adminService) GrantRole(ctx context.Context, actorID, userID int, role string) error {
+	if err := newRoleService(s.store).Grant(ctx, userID, role); err != nil {
+		return err
+	}
+
+	return s.audit(ctx, actorID, model.AuditUserRoleGrant, userID)
+}
+
+// RevokeRole revokes the role from the user.
+func (s *adminService) RevokeRole(ctx context.Context, actorID, userID int, role string) error {
+	if err := newRoleService(s.store).Revoke(ctx, userID, role); err != nil {
+		return err
+	}
+
+	return s.audit(ctx, actorID, model.AuditUserRoleRevoke, userID)
+}
+
+// UnlockUser unlocks the user's account locked after failed sign in attempts.
+func (s *adminService) UnlockUser(ctx context.Context, actorID, userID int) error {
+	if err := newUserService(s.store, s.auth).Unlock(ctx, userID); err != nil {
+		return err
+	}
+
+	return s.audit(ctx, actorID, model.AuditUserUnlock, userID)
+}
+
+// AuditEvents returns the audit trail of the user with specific ID in order of
+// creation, the trail of deleted users is kept.
+func (s *adminService) AuditEvents(ctx context.Context, userID int) ([]model.AuditEvent, error) {
+	return s.store.AuditEvents().GetByUserID(ctx, userID)
+}
+
+// audit records the action the admin performed on the user.
+func (s *adminService) audit(ctx context.Context, actorID int, action string, userID int) error {
+	_, err := s.store.AuditEvents().Create(ctx, model.AuditEvent{
+		ActorID: actorID, Action: action, UserID: userID,
+	})
+
+	return err
+}
@@ -0,0 +1,380 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imarrche/jwt-auth-example/internal/mail"
+	mock_mail "github.com/imarrche/jwt-auth-example/internal/mail/mocks"
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/service"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+	mock_store "github.com/imarrche/jwt-auth-example/internal/store/mocks"
+)
+
+// adminID is the ID of the admin managing users in tests.
+const adminID = 10
+
+// expectAudit expects the action on the user to be recorded on behalf of the admin.
+func expectAudit(c *gomock.Controller, s *mock_store.MockStore, action string, userID int) {
+	aer := mock_store.NewMockAuditEventRepo(c)
+	aer.EXPECT().Create(gomock.Any(), model.AuditEvent{
+		ActorID: adminID, Action: action, UserID: userID,
+	}).Return(model.AuditEvent{}, nil)
+	s.EXPECT().AuditEvents().Return(aer)
+}
+
+// newTestAdminService returns adminService with mocked store and mailer.
+func newTestAdminService(s store.Store, m mail.Mailer) *adminService {
+	return newAdminService(s, newAuthService(s, testKeyring(), m, nil))
+}
+
+func TestAdminService_UpdateUser(t *testing.T) {
+	verifiedAt := time.Now()
+	user := model.User{
+		ID: 1, Username: "user1", Email: "user1@test.com", FirstName: "Name",
+		SecondName: "Secondname", EmailVerifiedAt: &verifiedAt,
+	}
+
+	testcases := []struct {
+		name     string
+		mock     func(*gomock.Controller, *mock_store.MockStore, *mock_mail.MockMailer)
+		update   model.User
+		expUser  model.User
+		expError error
+	}{
+		{
+			name: "username is updated",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore, m *mock_mail.MockMailer) {
+				updated := user
+				updated.Username = "user2"
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil)
+				ur.EXPECT().Update(gomock.Any(), updated).Return(updated, nil)
+				s.EXPECT().Users().Return(ur).Times(2)
+				expectAudit(c, s, model.AuditUserUpdate, user.ID)
+			},
+			update: model.User{ID: 1, Username: "user2"},
+			expUser: model.User{
+				ID: 1, Username: "user2", Email: "user1@test.com", FirstName: "Name",
+				SecondName: "Secondname", EmailVerifiedAt: &verifiedAt,
+			},
+		},
+		{
+			name: "changed email has to be verified",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore, m *mock_mail.MockMailer) {
+				update := user
+				update.Email = "user2@test.com"
+				updated := update
+				updated.EmailVerifiedAt = nil
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil)
+				ur.EXPECT().Update(gomock.Any(), update).Return(updated, nil)
+				s.EXPECT().Users().Return(ur).Times(2)
+				expectAudit(c, s, model.AuditUserUpdate, user.ID)
+				evtr := mock_store.NewMockEmailVerificationTokenRepo(c)
+				evtr.EXPECT().Create(gomock.Any(), gomock.Any()).Return(model.EmailVerificationToken{}, nil)
+				s.EXPECT().EmailVerificationTokens().Return(evtr)
+				m.EXPECT().Send(gomock.Any()).DoAndReturn(func(msg mail.Message) error {
+					assert.Equal(t, "user2@test.com", msg.To)
+					return nil
+				})
+			},
+			update: model.User{ID: 1, Email: "user2@test.com"},
+			expUser: model.User{
+				ID: 1, Username: "user1", Email: "user2@test.com", FirstName: "Name",
+				SecondName: "Secondname",
+			},
+		},
+		{
+			name: "email is invalid",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore, m *mock_mail.MockMailer) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil)
+				s.EXPECT().Users().Return(ur)
+			},
+			update:   model.User{ID: 1, Email: "email"},
+			expError: &service.Error{Code: "validation_failed"},
+		},
+		{
+			name: "user is not found",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore, m *mock_mail.MockMailer) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), 2).Return(model.User{}, store.ErrNotFound)
+				s.EXPECT().Users().Return(ur)
+			},
+			update:   model.User{ID: 2, Username: "user2"},
+			expError: service.ErrNotFound,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			store := mock_store.NewMockStore(c)
+			mailer := mock_mail.NewMockMailer(c)
+			tc.mock(c, store, mailer)
+			u, err := newTestAdminService(store, mailer).UpdateUser(
+				context.Background(), adminID, tc.update,
+			)
+
+			if tc.expError == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expUser, u)
+			} else {
+				assert.True(t, errors.Is(err, tc.expError), err)
+			}
+		})
+	}
+}
+
+func TestAdminService_DisableUser(t *testing.T) {
+	testcases := []struct {
+		name     string
+		mock     func(*gomock.Controller, *mock_store.MockStore)
+		userID   int
+		expError error
+	}{
+		{
+			name: "user is disabled",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), 1).Return(model.User{ID: 1}, nil)
+				ur.EXPECT().SetDisabledAt(gomock.Any(), 1, gomock.Not(gomock.Nil())).Return(nil)
+				s.EXPECT().Users().Return(ur).Times(2)
+				rtr := mock_store.NewMockRefreshTokenRepo(c)
+				rtr.EXPECT().RevokeByUserID(gomock.Any(), 1).Return(nil)
+				s.EXPECT().RefreshTokens().Return(rtr)
+				expectAudit(c, s, model.AuditUserDisable, 1)
+			},
+			userID: 1,
+		},
+		{
+			name: "disabled user is left as is",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				disabledAt := time.Now()
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), 1).Return(
+					model.User{ID: 1, DisabledAt: &disabledAt}, nil,
+				)
+				s.EXPECT().Users().Return(ur)
+			},
+			userID: 1,
+		},
+		{
+			name: "user is not found",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), 2).Return(model.User{}, store.ErrNotFound)
+				s.EXPECT().Users().Return(ur)
+			},
+			userID:   2,
+			expError: service.ErrNotFound,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			store := mock_store.NewMockStore(c)
+			tc.mock(c, store)
+			err := newTestAdminService(store, nil).DisableUser(context.Background(), adminID, tc.userID)
+
+			assert.Equal(t, tc.expError, err)
+		})
+	}
+}
+
+func TestAdminService_EnableUser(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	disabledAt := time.Now()
+	s := mock_store.NewMockStore(c)
+	ur := mock_store.NewMockUserRepo(c)
+	ur.EXPECT().GetByID(gomock.Any(), 1).Return(model.User{ID: 1, DisabledAt: &disabledAt}, nil)
+	ur.EXPECT().SetDisabledAt(gomock.Any(), 1, gomock.Nil()).Return(nil)
+	s.EXPECT().Users().Return(ur).Times(2)
+	expectAudit(c, s, model.AuditUserEnable, 1)
+
+	assert.NoError(t, newTestAdminService(s, nil).EnableUser(context.Background(), adminID, 1))
+}
+
+func TestAdminService_DeleteUser(t *testing.T) {
+	testcases := []struct {
+		name     string
+		mock     func(*gomock.Controller, *mock_store.MockStore)
+		userID   int
+		expError error
+	}{
+		{
+			name: "user is deleted",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().DeleteByID(gomock.Any(), 1).Return(nil)
+				s.EXPECT().Users().Return(ur)
+				expectAudit(c, s, model.AuditUserDelete, 1)
+			},
+			userID: 1,
+		},
+		{
+			name: "user is not found",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().DeleteByID(gomock.Any(), 2).Return(store.ErrNotFound)
+				s.EXPECT().Users().Return(ur)
+			},
+			userID:   2,
+			expError: service.ErrNotFound,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			store := mock_store.NewMockStore(c)
+			tc.mock(c, store)
+			err := newTestAdminService(store, nil).DeleteUser(context.Background(), adminID, tc.userID)
+
+			assert.Equal(t, tc.expError, err)
+		})
+	}
+}
+
+func TestAdminService_ResetPassword(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	user := model.User{ID: 1, Email: "user1@test.com", PasswordHash: "hash"}
+	s := mock_store.NewMockStore(c)
+	m := mock_mail.NewMockMailer(c)
+	ur := mock_store.NewMockUserRepo(c)
+	ur.EXPECT().GetByID(gomock.Any(), 1).Return(user, nil)
+	ur.EXPECT().SetPasswordHash(gomock.Any(), 1, "").Return(nil)
+	s.EXPECT().Users().Return(ur).Times(2)
+	rtr := mock_store.NewMockRefreshTokenRepo(c)
+	rtr.EXPECT().RevokeByUserID(gomock.Any(), 1).Return(nil)
+	s.EXPECT().RefreshTokens().Return(rtr)
+	expectAudit(c, s, model.AuditUserPasswordReset, 1)
+	prtr := mock_store.NewMockPasswordResetTokenRepo(c)
+	prtr.EXPECT().Create(gomock.Any(), gomock.Any()).Return(model.PasswordResetToken{}, nil)
+	s.EXPECT().PasswordResetTokens().Return(prtr)
+	m.EXPECT().Send(gomock.Any()).DoAndReturn(func(msg mail.Message) error {
+		assert.Equal(t, user.Email, msg.To)
+		return nil
+	})
+
+	assert.NoError(t, newTestAdminService(s, m).ResetPassword(context.Background(), adminID, 1))
+}
+
+func TestAdminService_RevokeSessions(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	s := mock_store.NewMockStore(c)
+	ur := mock_store.NewMockUserRepo(c)
+	ur.EXPECT().GetByID(gomock.Any(), 1).Return(model.User{ID: 1}, nil)
+	s.EXPECT().Users().Return(ur)
+	rtr := mock_store.NewMockRefreshTokenRepo(c)
+	rtr.EXPECT().RevokeByUserID(gomock.Any(), 1).Return(nil)
+	s.EXPECT().RefreshTokens().Return(rtr)
+	expectAudit(c, s, model.AuditUserSessionsRevoke, 1)
+
+	assert.NoError(t, newTestAdminService(s, nil).RevokeSessions(context.Background(), adminID, 1))
+}
+
+func TestAdminService_GrantRole(t *testing.T) {
+	testcases := []struct {
+		name     string
+		mock     func(*gomock.Controller, *mock_store.MockStore)
+		role     string
+		expError error
+	}{
+		{
+			name: "role is granted",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), 1).Return(model.User{ID: 1}, nil)
+				s.EXPECT().Users().Return(ur)
+				rr := mock_store.NewMockRoleRepo(c)
+				rr.EXPECT().GetAll(gomock.Any()).Return([]model.Role{{Name: "admin"}}, nil)
+				rr.EXPECT().AssignToUser(gomock.Any(), 1, "admin").Return(nil)
+				s.EXPECT().Roles().Return(rr).Times(2)
+				expectAudit(c, s, model.AuditUserRoleGrant, 1)
+			},
+			role: "admin",
+		},
+		{
+			name: "role is not found",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), 1).Return(model.User{ID: 1}, nil)
+				s.EXPECT().Users().Return(ur)
+				rr := mock_store.NewMockRoleRepo(c)
+				rr.EXPECT().GetAll(gomock.Any()).Return([]model.Role{{Name: "admin"}}, nil)
+				s.EXPECT().Roles().Return(rr)
+			},
+			role:     "root",
+			expError: service.ErrNotFound,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			s := mock_store.NewMockStore(c)
+			tc.mock(c, s)
+			err := newTestAdminService(s, nil).GrantRole(context.Background(), adminID, 1, tc.role)
+
+			if tc.expError == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, tc.expError), err)
+			}
+		})
+	}
+}
+
+func TestAdminService_RevokeRole(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	s := mock_store.NewMockStore(c)
+	rr := mock_store.NewMockRoleRepo(c)
+	rr.EXPECT().GetByUserID(gomock.Any(), 1).Return([]model.Role{{Name: "admin"}}, nil)
+	rr.EXPECT().RemoveFromUser(gomock.Any(), 1, "admin").Return(nil)
+	s.EXPECT().Roles().Return(rr).Times(2)
+	expectAudit(c, s, model.AuditUserRoleRevoke, 1)
+
+	err := newTestAdminService(s, nil).RevokeRole(context.Background(), adminID, 1, "admin")
+	assert.NoError(t, err)
+}
+
+func TestAdminService_UnlockUser(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	s := mock_store.NewMockStore(c)
+	ur := mock_store.NewMockUserRepo(c)
+	ur.EXPECT().GetByID(gomock.Any(), 1).Return(model.User{ID: 1}, nil)
+	s.EXPECT().Users().Return(ur)
+	ltr := mock_store.NewMockLoginThrottleRepo(c)
+	ltr.EXPECT().Delete(gomock.Any(), accountThrottleKey(1)).Return(nil)
+	s.EXPECT().LoginThrottles().Return(ltr)
+	expectAudit(c, s, model.AuditUserUnlock, 1)
+
+	assert.NoError(t, newTestAdminService(s, nil).UnlockUser(context.Background(), adminID, 1))
+}
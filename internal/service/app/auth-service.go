@@ -1,56 +1,149 @@
 package app
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
-	"golang.org/x/crypto/bcrypt"
+	"go.uber.org/zap"
 
 	"github.com/imarrche/jwt-auth-example/internal/config"
+	"github.com/imarrche/jwt-auth-example/internal/logger"
+	"github.com/imarrche/jwt-auth-example/internal/mail"
 	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/notify"
+	"github.com/imarrche/jwt-auth-example/internal/service"
 	"github.com/imarrche/jwt-auth-example/internal/store"
 )
 
+// defaultRole is the role every signed up user gets.
+const defaultRole = "user"
+
+// backgroundTimeout limits the duration of a task run off the request path.
+const backgroundTimeout = 30 * time.Second
+
 // authService implements authorization business logic.
 type authService struct {
-	store store.Store
+	store    store.Store
+	keys     *keyring
+	mailer   mail.Mailer
+	notifier notify.Notifier
+
+	// background tracks tasks run off the request path.
+	background sync.WaitGroup
 }
 
 // newAuthServer creates and returns a new authService instance.
-func newAuthService(s store.Store) *authService {
-	return &authService{store: s}
+func newAuthService(
+	s store.Store, keys *keyring, m mail.Mailer, n notify.Notifier,
+) *authService {
+	return &authService{store: s, keys: keys, mailer: m, notifier: n}
+}
+
+// goBackground runs the task off the request path, so neither its duration nor its
+// error can be observed by the client. The task is cancelled after backgroundTimeout,
+// the error is logged with the message.
+func (s *authService) goBackground(msg string, task func(context.Context) error) {
+	s.background.Add(1)
+	go func() {
+		defer s.background.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), backgroundTimeout)
+		defer cancel()
+		if err := task(ctx); err != nil {
+			logger.Get().Error(msg, zap.Error(err))
+		}
+	}()
 }
 
 // Sign up signes up a user.
-func (s *authService) SignUp(u model.User) (model.User, error) {
+func (s *authService) SignUp(ctx context.Context, u model.User) (model.User, error) {
 	if err := u.Validate(); err != nil {
-		return model.User{}, err
+		return model.User{}, service.NewValidationError(err)
 	}
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
+	hashedPassword, err := hashPassword(ctx, u.Password)
 	if err != nil {
 		return model.User{}, err
 	}
 	u.Password = ""
-	u.PasswordHash = string(hashedPassword)
+	u.PasswordHash = hashedPassword
+	u.EmailVerifiedAt, u.DisabledAt = nil, nil
 
-	u, err = s.store.Users().Create(u)
+	u, err = s.store.Users().Create(ctx, u)
 	if err != nil {
+		return model.User{}, serviceError(err)
+	}
+	if err := s.store.Roles().AssignToUser(ctx, u.ID, defaultRole); err != nil {
 		return model.User{}, err
 	}
+	// Sign up doesn't fail if the email isn't sent, the account is already created.
+	if err := s.sendVerificationEmail(ctx, u); err != nil {
+		logger.Get().Error("couldn't send verification email", zap.Error(err))
+	}
 
 	return u, err
 }
 
-// generateJWT generates access/refresh JSON Web Token for user.
-func (s *authService) generateJWT(userID int, tokenType string) (string, error) {
-	secret := []byte(config.Get().JWT.Secret)
+// newTokenID generates a random unique identifier for a token.
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
 
-	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"type": tokenType, "user_id": userID, "exp": time.Now().Add(time.Hour * 24).Unix(),
-	}).SignedString(secret)
+// generateJWT generates access/refresh JSON Web Token for user. Token ID is set
+// as the jti claim, a new one is generated if it's empty.
+func (s *authService) generateJWT(
+	ctx context.Context, userID int, tokenType, tokenID string,
+) (string, error) {
+	if tokenID == "" {
+		id, err := newTokenID()
+		if err != nil {
+			return "", err
+		}
+		tokenID = id
+	}
+
+	return s.signJWT(ctx, newClaims(config.Get().JWT, userID, tokenType, tokenID))
+}
+
+// generateAccessJWT generates access JSON Web Token for user with user's roles and
+// their permissions embedded.
+func (s *authService) generateAccessJWT(ctx context.Context, userID int) (string, error) {
+	tokenID, err := newTokenID()
+	if err != nil {
+		return "", err
+	}
+	roles, err := s.store.Roles().GetByUserID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	cl := newClaims(config.Get().JWT, userID, "access", tokenID)
+	cl.setRoles(roles)
+
+	return s.signJWT(ctx, cl)
+}
+
+// signJWT signs claims with the signing key.
+func (s *authService) signJWT(ctx context.Context, cl *claims) (string, error) {
+	k, err := s.keys.signingKey(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	t := jwt.NewWithClaims(k.method, cl)
+	t.Header["kid"] = k.id
+	token, err := t.SignedString(k.private)
 	if err != nil {
 		return "", err
 	}
@@ -58,77 +151,268 @@ func (s *authService) generateJWT(userID int, tokenType string) (string, error)
 	return token, nil
 }
 
-// SignIn returns access and refresh JSON Web Tokens for a user if credentials are valid.
-func (s *authService) SignIn(email string, password string) (string, string, error) {
-	u, err := s.store.Users().GetByEmail(email)
+// generateRefreshJWT generates refresh JSON Web Token for user and persists it in
+// the given token family. A new family is started if family ID is empty.
+func (s *authService) generateRefreshJWT(
+	ctx context.Context, userID int, familyID string,
+) (string, error) {
+	tokenID, err := newTokenID()
 	if err != nil {
-		return "", "", errors.New("invalid credentials")
+		return "", err
+	}
+	if familyID == "" {
+		familyID = tokenID
+	}
+
+	t := model.RefreshToken{
+		ID:        tokenID,
+		FamilyID:  familyID,
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(tokenTTL(config.Get().JWT, "refresh")),
+	}
+	if _, err := s.store.RefreshTokens().Create(ctx, t); err != nil {
+		return "", err
+	}
+
+	return s.generateJWT(ctx, userID, "refresh", tokenID)
+}
+
+// SignIn returns access and refresh JSON Web Tokens for a user if credentials are valid.
+// Users with MFA enabled get MFA JSON Web Token instead, it's exchanged for access and
+// refresh tokens with VerifyMFA. Failed attempts are tracked per account and per client
+// IP, both get progressive delays between attempts and temporary lockouts.
+func (s *authService) SignIn(
+	ctx context.Context, email, password, clientIP string,
+) (model.SignInResult, error) {
+	c := config.Get().Lockout
+	if err := s.checkThrottle(ctx, ipThrottleKey(clientIP), service.ErrTooManyAttempts); err != nil {
+		return model.SignInResult{}, err
+	}
+
+	u, err := s.store.Users().GetByEmail(ctx, email)
+	if errors.Is(err, store.ErrNotFound) {
+		if err := s.registerFailure(ctx, ipThrottleKey(clientIP), c.IPMaxFailures); err != nil {
+			return model.SignInResult{}, err
+		}
+		return model.SignInResult{}, service.ErrInvalidCredentials
+	} else if err != nil {
+		return model.SignInResult{}, err
+	}
+	if err := s.checkThrottle(ctx, accountThrottleKey(u.ID), service.ErrAccountLocked); err != nil {
+		return model.SignInResult{}, err
+	}
+	if ok, err := checkPassword(ctx, u.PasswordHash, password); err != nil {
+		return model.SignInResult{}, err
+	} else if !ok {
+		if err := s.registerFailure(ctx, accountThrottleKey(u.ID), c.MaxFailures); err != nil {
+			return model.SignInResult{}, err
+		}
+		if err := s.registerFailure(ctx, ipThrottleKey(clientIP), c.IPMaxFailures); err != nil {
+			return model.SignInResult{}, err
+		}
+		return model.SignInResult{}, service.ErrInvalidCredentials
+	}
+	if err := s.store.LoginThrottles().Delete(ctx, accountThrottleKey(u.ID)); err != nil {
+		return model.SignInResult{}, err
+	}
+
+	return s.signInResult(ctx, u)
+}
+
+// signInResult returns the result of signing in for the user whose first factor is
+// verified: the token pair or MFA token if multi-factor authentication is enabled.
+func (s *authService) signInResult(ctx context.Context, u model.User) (model.SignInResult, error) {
+	if u.DisabledAt != nil {
+		return model.SignInResult{}, service.ErrAccountDisabled
+	}
+	if config.Get().Account.RequireVerifiedEmail && u.EmailVerifiedAt == nil {
+		return model.SignInResult{}, service.ErrEmailNotVerified
+	}
+
+	if enabled, err := s.mfaEnabled(ctx, u.ID); err != nil {
+		return model.SignInResult{}, err
+	} else if enabled {
+		mfaJWT, err := s.generateJWT(ctx, u.ID, "mfa_pending", "")
+		if err != nil {
+			return model.SignInResult{}, err
+		}
+		return model.SignInResult{MFAToken: mfaJWT}, nil
 	}
-	if err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)); err != nil {
-		return "", "", errors.New("invalid credentials")
+
+	accessJWT, refreshJWT, err := s.generateJWTPair(ctx, u.ID)
+	if err != nil {
+		return model.SignInResult{}, err
 	}
 
-	accessJWT, err := s.generateJWT(u.ID, "access")
+	return model.SignInResult{AccessToken: accessJWT, RefreshToken: refreshJWT}, nil
+}
+
+// generateJWTPair generates access and refresh JSON Web Tokens for the user who has
+// just signed in, the refresh token starts a new family.
+func (s *authService) generateJWTPair(ctx context.Context, userID int) (string, string, error) {
+	accessJWT, err := s.generateAccessJWT(ctx, userID)
 	if err != nil {
 		return "", "", err
 	}
-	refreshJWT, err := s.generateJWT(u.ID, "refresh")
+	refreshJWT, err := s.generateRefreshJWT(ctx, userID, "")
 	if err != nil {
 		return "", "", err
 	}
 
-	return accessJWT, refreshJWT, err
+	return accessJWT, refreshJWT, nil
 }
 
-// ValidateJWT validates access JSON Web Token and returns error if it's invalid.
-func (s *authService) ValidateJWT(token, tokenType string) (int, error) {
-	secret := []byte(config.Get().JWT.Secret)
-
-	t, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+// parseJWT parses and validates JSON Web Token of specific type and returns its claims.
+func (s *authService) parseJWT(ctx context.Context, token, tokenType string) (*claims, error) {
+	parser := &jwt.Parser{SkipClaimsValidation: true}
+	cl := &claims{}
+	_, err := parser.ParseWithClaims(token, cl, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		k, err := s.keys.verificationKey(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+		if token.Method.Alg() != k.method.Alg() {
 			return nil, fmt.Errorf("unexpected token signing method: %v", token.Header["alg"])
 		}
 
-		return secret, nil
+		return k.public, nil
 	})
 	if err != nil {
-		return 0, err
+		return nil, service.ErrInvalidToken.Wrap(err)
+	}
+	if err := cl.validate(config.Get().JWT, tokenType); err != nil {
+		return nil, service.ErrInvalidToken.Wrap(err)
 	}
 
-	claims, ok := t.Claims.(jwt.MapClaims)
-	if ok && t.Valid {
-		if tType, ok := claims["type"].(string); ok {
-			if tType != tokenType {
-				return 0, errors.New("invalid JWT type")
-			}
-		} else {
-			return 0, errors.New("couldn't parse JWT's type")
-		}
+	revoked, err := s.store.RevokedTokens().Exists(ctx, cl.Id)
+	if err != nil {
+		return nil, err
+	} else if revoked {
+		return nil, service.ErrInvalidToken.Wrap(errors.New("JWT is revoked"))
+	}
 
-		expTime, err := strconv.ParseInt(fmt.Sprintf("%.f", claims["exp"]), 10, 64)
-		if err != nil {
-			return 0, errors.New("couldn't parse JWT's expiration time")
-		}
-		if time.Unix(expTime, 0).Before(time.Now()) {
-			return 0, errors.New("JWT expired")
-		}
+	return cl, nil
+}
 
-		userID, err := strconv.ParseInt(fmt.Sprintf("%v", claims["user_id"]), 10, 64)
-		if err != nil {
-			return 0, errors.New("couldn't parse JWT's user ID")
+// ValidateJWT validates JSON Web Token and returns the principal it's issued for.
+func (s *authService) ValidateJWT(
+	ctx context.Context, token, tokenType string,
+) (model.Principal, error) {
+	cl, err := s.parseJWT(ctx, token, tokenType)
+	if err != nil {
+		return model.Principal{}, err
+	}
+	userID, err := cl.userID()
+	if err != nil {
+		return model.Principal{}, service.ErrInvalidToken.Wrap(err)
+	}
+
+	// Signature is already verified, so the payload can be decoded as is.
+	rawClaims := map[string]interface{}{}
+	payload, err := jwt.DecodeSegment(strings.Split(token, ".")[1])
+	if err != nil {
+		return model.Principal{}, err
+	}
+	if err := json.Unmarshal(payload, &rawClaims); err != nil {
+		return model.Principal{}, err
+	}
+
+	return model.Principal{
+		UserID:      userID,
+		TokenID:     cl.Id,
+		Roles:       cl.Roles,
+		Permissions: strings.Fields(cl.Scope),
+		Claims:      rawClaims,
+	}, nil
+}
+
+// Refresh rotates refresh JSON Web Token and returns new access and refresh JSON Web
+// Tokens if valid refresh token was provided. Every refresh token can be used only
+// once, presenting an already rotated token revokes its whole family.
+func (s *authService) Refresh(ctx context.Context, refreshToken string) (string, string, error) {
+	cl, err := s.parseJWT(ctx, refreshToken, "refresh")
+	if err != nil {
+		return "", "", err
+	}
+	t, err := s.store.RefreshTokens().GetByID(ctx, cl.Id)
+	if errors.Is(err, store.ErrNotFound) {
+		return "", "", service.ErrInvalidToken
+	} else if err != nil {
+		return "", "", err
+	}
+	if t.RevokedAt != nil {
+		return "", "", service.ErrInvalidToken.Wrap(errors.New("JWT is revoked"))
+	}
+	if err := s.store.RefreshTokens().MarkUsed(ctx, t.ID); err == store.ErrTokenIsUsed {
+		if err := s.store.RefreshTokens().RevokeFamily(ctx, t.FamilyID); err != nil {
+			return "", "", err
 		}
-		return int(userID), nil
+		return "", "", service.ErrTokenReused
+	} else if err != nil {
+		return "", "", err
 	}
 
-	return 0, errors.New("JWT is invalid")
+	accessJWT, err := s.generateAccessJWT(ctx, t.UserID)
+	if err != nil {
+		return "", "", err
+	}
+	newRefreshJWT, err := s.generateRefreshJWT(ctx, t.UserID, t.FamilyID)
+	if err != nil {
+		return "", "", err
+	}
+
+	return accessJWT, newRefreshJWT, nil
 }
 
-// RefreshAccessJWT returns new access JSON Web Token if valid refresh token was provided.
-func (s *authService) RefreshAccessJWT(refreshToken string) (string, error) {
-	userID, err := s.ValidateJWT(refreshToken, "refresh")
+// SignOut revokes access and refresh JSON Web Tokens of the same user, the whole
+// family of the refresh token is revoked.
+func (s *authService) SignOut(ctx context.Context, accessToken, refreshToken string) error {
+	accessClaims, err := s.parseJWT(ctx, accessToken, "access")
 	if err != nil {
-		return "", err
+		return err
+	}
+	refreshClaims, err := s.parseJWT(ctx, refreshToken, "refresh")
+	if err != nil {
+		return err
+	}
+	if accessClaims.Subject != refreshClaims.Subject {
+		return service.ErrInvalidToken.Wrap(errors.New("JWTs belong to different users"))
 	}
 
-	return s.generateJWT(userID, "access")
+	_, err = s.store.RevokedTokens().Create(ctx, model.RevokedToken{
+		ID: accessClaims.Id, ExpiresAt: accessClaims.expiresAt(),
+	})
+	if err != nil {
+		return err
+	}
+
+	t, err := s.store.RefreshTokens().GetByID(ctx, refreshClaims.Id)
+	if errors.Is(err, store.ErrNotFound) {
+		return service.ErrInvalidToken
+	} else if err != nil {
+		return err
+	}
+
+	return s.store.RefreshTokens().RevokeFamily(ctx, t.FamilyID)
+}
+
+// JWKS returns the set of public JSON Web Keys to verify JSON Web Tokens with.
+func (s *authService) JWKS(ctx context.Context) (model.JWKSet, error) {
+	jwks, err := s.keys.publicKeys(ctx)
+	if err != nil {
+		return model.JWKSet{}, err
+	}
+
+	return model.JWKSet{Keys: jwks}, nil
+}
+
+// purgeRevokedJWTs deletes revoked JSON Web Tokens which have already expired.
+func (s *authService) purgeRevokedJWTs(ctx context.Context) error {
+	return s.store.RevokedTokens().DeleteExpired(ctx)
+}
+
+// purgeRefreshTokens deletes refresh tokens which have already expired.
+func (s *authService) purgeRefreshTokens(ctx context.Context) error {
+	return s.store.RefreshTokens().DeleteExpired(ctx)
 }
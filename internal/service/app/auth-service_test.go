@@ -1,32 +1,52 @@
 package app
 
 import (
+	"context"
 	"testing"
+	"time"
 
+	"github.com/dgrijalva/jwt-go"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/crypto/bcrypt"
 
+	"github.com/imarrche/jwt-auth-example/internal/config"
+	"github.com/imarrche/jwt-auth-example/internal/mail"
+	mock_mail "github.com/imarrche/jwt-auth-example/internal/mail/mocks"
 	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
 	mock_store "github.com/imarrche/jwt-auth-example/internal/store/mocks"
 )
 
 func TestAuthService_SignUp(t *testing.T) {
+	now := time.Now()
 	testcases := []struct {
 		name     string
-		mock     func(*gomock.Controller, *mock_store.MockStore, model.User)
+		mock     func(*gomock.Controller, *mock_store.MockStore, *mock_mail.MockMailer, model.User)
 		user     model.User
 		expError bool
 	}{
 		{
 			name: "user is signed up",
-			mock: func(c *gomock.Controller, s *mock_store.MockStore, u model.User) {
+			mock: func(
+				c *gomock.Controller, s *mock_store.MockStore, m *mock_mail.MockMailer, u model.User,
+			) {
 				u.PasswordHash = "hashed_" + u.Password
 				u.Password = ""
 
 				ur := mock_store.NewMockUserRepo(c)
-				ur.EXPECT().Create(gomock.Any()).Return(u, nil)
+				ur.EXPECT().Create(gomock.Any(), gomock.Any()).Return(u, nil)
 				s.EXPECT().Users().Return(ur)
+				rr := mock_store.NewMockRoleRepo(c)
+				rr.EXPECT().AssignToUser(gomock.Any(), u.ID, defaultRole).Return(nil)
+				s.EXPECT().Roles().Return(rr)
+				evtr := mock_store.NewMockEmailVerificationTokenRepo(c)
+				evtr.EXPECT().Create(gomock.Any(), gomock.Any()).Return(model.EmailVerificationToken{}, nil)
+				s.EXPECT().EmailVerificationTokens().Return(evtr)
+				m.EXPECT().Send(gomock.Any()).DoAndReturn(func(msg mail.Message) error {
+					assert.Equal(t, u.Email, msg.To)
+					return nil
+				})
 			},
 			user: model.User{
 				ID:         1,
@@ -38,6 +58,40 @@ func TestAuthService_SignUp(t *testing.T) {
 			},
 			expError: false,
 		},
+		{
+			name: "email verification and disabled time are ignored",
+			mock: func(
+				c *gomock.Controller, s *mock_store.MockStore, m *mock_mail.MockMailer, u model.User,
+			) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, u model.User) (model.User, error) {
+						assert.Nil(t, u.EmailVerifiedAt)
+						assert.Nil(t, u.DisabledAt)
+						return u, nil
+					},
+				)
+				s.EXPECT().Users().Return(ur)
+				rr := mock_store.NewMockRoleRepo(c)
+				rr.EXPECT().AssignToUser(gomock.Any(), u.ID, defaultRole).Return(nil)
+				s.EXPECT().Roles().Return(rr)
+				evtr := mock_store.NewMockEmailVerificationTokenRepo(c)
+				evtr.EXPECT().Create(gomock.Any(), gomock.Any()).Return(model.EmailVerificationToken{}, nil)
+				s.EXPECT().EmailVerificationTokens().Return(evtr)
+				m.EXPECT().Send(gomock.Any()).Return(nil)
+			},
+			user: model.User{
+				ID:              1,
+				Username:        "user1",
+				Email:           "user1@test.com",
+				FirstName:       "Name",
+				SecondName:      "Secondname",
+				Password:        "password1",
+				EmailVerifiedAt: &now,
+				DisabledAt:      &now,
+			},
+			expError: false,
+		},
 	}
 
 	for _, tc := range testcases {
@@ -46,9 +100,10 @@ func TestAuthService_SignUp(t *testing.T) {
 			defer c.Finish()
 
 			store := mock_store.NewMockStore(c)
-			tc.mock(c, store, tc.user)
-			s := newAuthService(store)
-			u, err := s.SignUp(tc.user)
+			mailer := mock_mail.NewMockMailer(c)
+			tc.mock(c, store, mailer, tc.user)
+			s := newAuthService(store, testKeyring(), mailer, nil)
+			u, err := s.SignUp(context.Background(), tc.user)
 
 			if !tc.expError {
 				assert.NoError(t, err)
@@ -80,8 +135,8 @@ func TestAuthService_generateJWT(t *testing.T) {
 			c := gomock.NewController(t)
 			defer c.Finish()
 
-			s := newAuthService(nil)
-			token, err := s.generateJWT(tc.userID, "access")
+			s := newAuthService(nil, testKeyring(), nil, nil)
+			token, err := s.generateJWT(context.Background(), tc.userID, "access", "")
 
 			if !tc.expError {
 				assert.NoError(t, err)
@@ -95,10 +150,12 @@ func TestAuthService_generateJWT(t *testing.T) {
 
 func TestAuthService_SignIn(t *testing.T) {
 	testcases := []struct {
-		name     string
-		mock     func(*gomock.Controller, *mock_store.MockStore, model.User)
-		user     model.User
-		expError bool
+		name                 string
+		mock                 func(*gomock.Controller, *mock_store.MockStore, model.User)
+		user                 model.User
+		requireVerifiedEmail bool
+		expMFA               bool
+		expError             bool
 	}{
 		{
 			name: "user is signed in",
@@ -110,16 +167,170 @@ func TestAuthService_SignIn(t *testing.T) {
 				u.PasswordHash = string(hash)
 
 				ur := mock_store.NewMockUserRepo(c)
-				ur.EXPECT().GetByEmail(u.Email).Return(u, nil)
+				ur.EXPECT().GetByEmail(gomock.Any(), u.Email).Return(u, nil)
+				s.EXPECT().Users().Return(ur)
+				s.EXPECT().LoginThrottles().Return(noThrottle(c)).AnyTimes()
+				s.EXPECT().TOTPSecrets().Return(noTOTP(c))
+				rtr := mock_store.NewMockRefreshTokenRepo(c)
+				rtr.EXPECT().Create(gomock.Any(), gomock.Any()).Return(model.RefreshToken{}, nil)
+				s.EXPECT().RefreshTokens().Return(rtr)
+				s.EXPECT().Roles().Return(noRoles(c))
+			},
+			user: model.User{
+				ID:       1,
+				Email:    "user1@test.com",
+				Password: "password1",
+			},
+			expError: false,
+		},
+		{
+			name: "MFA is required",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore, u model.User) {
+				hash, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
+				if err != nil {
+					t.Fatal(err)
+				}
+				u.PasswordHash = string(hash)
+				confirmedAt := time.Now()
+
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByEmail(gomock.Any(), u.Email).Return(u, nil)
 				s.EXPECT().Users().Return(ur)
+				s.EXPECT().LoginThrottles().Return(noThrottle(c)).AnyTimes()
+				tsr := mock_store.NewMockTOTPSecretRepo(c)
+				tsr.EXPECT().GetByUserID(gomock.Any(), u.ID).Return(
+					model.TOTPSecret{UserID: u.ID, ConfirmedAt: &confirmedAt}, nil,
+				)
+				s.EXPECT().TOTPSecrets().Return(tsr)
 			},
 			user: model.User{
 				ID:       1,
 				Email:    "user1@test.com",
 				Password: "password1",
 			},
+			expMFA:   true,
 			expError: false,
 		},
+		{
+			name: "email is not verified",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore, u model.User) {
+				hash, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
+				if err != nil {
+					t.Fatal(err)
+				}
+				u.PasswordHash = string(hash)
+
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByEmail(gomock.Any(), u.Email).Return(u, nil)
+				s.EXPECT().Users().Return(ur)
+				s.EXPECT().LoginThrottles().Return(noThrottle(c)).AnyTimes()
+			},
+			user: model.User{
+				ID:       1,
+				Email:    "user1@test.com",
+				Password: "password1",
+			},
+			requireVerifiedEmail: true,
+			expError:             true,
+		},
+		{
+			name: "account is disabled",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore, u model.User) {
+				hash, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
+				if err != nil {
+					t.Fatal(err)
+				}
+				u.PasswordHash = string(hash)
+				disabledAt := time.Now()
+				u.DisabledAt = &disabledAt
+
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByEmail(gomock.Any(), u.Email).Return(u, nil)
+				s.EXPECT().Users().Return(ur)
+				s.EXPECT().LoginThrottles().Return(noThrottle(c)).AnyTimes()
+			},
+			user: model.User{
+				ID:       1,
+				Email:    "user1@test.com",
+				Password: "password1",
+			},
+			expError: true,
+		},
+		{
+			name: "password is invalid",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore, u model.User) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByEmail(gomock.Any(), u.Email).Return(u, nil)
+				s.EXPECT().Users().Return(ur)
+				ltr := mock_store.NewMockLoginThrottleRepo(c)
+				ltr.EXPECT().Get(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, key string) (model.LoginThrottle, error) {
+						return model.LoginThrottle{Key: key}, nil
+					},
+				).Times(2)
+				ltr.EXPECT().RegisterFailure(gomock.Any(), accountThrottleKey(u.ID), gomock.Any()).Return(
+					model.LoginThrottle{Failures: 1}, nil,
+				)
+				ltr.EXPECT().RegisterFailure(gomock.Any(), ipThrottleKey("127.0.0.1"), gomock.Any()).Return(
+					model.LoginThrottle{Failures: 1}, nil,
+				)
+				s.EXPECT().LoginThrottles().Return(ltr).AnyTimes()
+			},
+			user: model.User{
+				ID:       1,
+				Email:    "user1@test.com",
+				Password: "password1",
+			},
+			expError: true,
+		},
+		{
+			name: "email is unknown",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore, u model.User) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByEmail(gomock.Any(), u.Email).Return(model.User{}, store.ErrNotFound)
+				s.EXPECT().Users().Return(ur)
+				ltr := mock_store.NewMockLoginThrottleRepo(c)
+				ltr.EXPECT().Get(gomock.Any(), ipThrottleKey("127.0.0.1")).Return(model.LoginThrottle{}, nil)
+				ltr.EXPECT().RegisterFailure(gomock.Any(), ipThrottleKey("127.0.0.1"), gomock.Any()).Return(
+					model.LoginThrottle{Failures: 1}, nil,
+				)
+				s.EXPECT().LoginThrottles().Return(ltr).Times(2)
+			},
+			user:     model.User{Email: "user1@test.com", Password: "password1"},
+			expError: true,
+		},
+		{
+			name: "store error isn't counted as failure",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore, u model.User) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByEmail(gomock.Any(), u.Email).Return(model.User{}, errConnectionLost)
+				s.EXPECT().Users().Return(ur)
+				s.EXPECT().LoginThrottles().Return(noThrottle(c))
+			},
+			user:     model.User{Email: "user1@test.com", Password: "password1"},
+			expError: true,
+		},
+		{
+			name: "account is locked",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore, u model.User) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByEmail(gomock.Any(), u.Email).Return(u, nil)
+				s.EXPECT().Users().Return(ur)
+				lockedUntil := time.Now().Add(time.Minute)
+				ltr := mock_store.NewMockLoginThrottleRepo(c)
+				ltr.EXPECT().Get(gomock.Any(), ipThrottleKey("127.0.0.1")).Return(model.LoginThrottle{}, nil)
+				ltr.EXPECT().Get(gomock.Any(), accountThrottleKey(u.ID)).Return(
+					model.LoginThrottle{LockedUntil: &lockedUntil}, nil,
+				)
+				s.EXPECT().LoginThrottles().Return(ltr).Times(2)
+			},
+			user: model.User{
+				ID:       1,
+				Email:    "user1@test.com",
+				Password: "password1",
+			},
+			expError: true,
+		},
 	}
 
 	for _, tc := range testcases {
@@ -127,15 +338,29 @@ func TestAuthService_SignIn(t *testing.T) {
 			c := gomock.NewController(t)
 			defer c.Finish()
 
+			config.Get().Account.RequireVerifiedEmail = tc.requireVerifiedEmail
+			defer func() { config.Get().Account.RequireVerifiedEmail = false }()
+
 			store := mock_store.NewMockStore(c)
 			tc.mock(c, store, tc.user)
-			s := newAuthService(store)
-			accessJWT, refreshJWT, err := s.SignIn(tc.user.Email, tc.user.Password)
+			s := newAuthService(store, testKeyring(), nil, nil)
+			result, err := s.SignIn(
+				context.Background(), tc.user.Email, tc.user.Password, "127.0.0.1",
+			)
 
-			if !tc.expError {
+			if tc.expMFA {
 				assert.NoError(t, err)
-				assert.NotEqual(t, "", accessJWT)
-				assert.NotEqual(t, "", refreshJWT)
+				assert.Equal(t, "", result.AccessToken)
+				assert.Equal(t, "", result.RefreshToken)
+				cl := &claims{}
+				_, _, err := new(jwt.Parser).ParseUnverified(result.MFAToken, cl)
+				assert.NoError(t, err)
+				assert.Equal(t, "mfa_pending", cl.Type)
+			} else if !tc.expError {
+				assert.NoError(t, err)
+				assert.NotEqual(t, "", result.AccessToken)
+				assert.NotEqual(t, "", result.RefreshToken)
+				assert.Equal(t, "", result.MFAToken)
 			} else {
 				assert.Error(t, err)
 			}
@@ -146,14 +371,32 @@ func TestAuthService_SignIn(t *testing.T) {
 func TestAuthService_ValidateJWT(t *testing.T) {
 	testcases := []struct {
 		name      string
+		mock      func(*gomock.Controller, *mock_store.MockStore)
 		userID    int
 		tokenType string
 		expError  bool
 	}{
 		{
-			name:     "token is valid",
-			userID:   1,
-			expError: false,
+			name: "token is valid",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				rtr := mock_store.NewMockRevokedTokenRepo(c)
+				rtr.EXPECT().Exists(gomock.Any(), gomock.Any()).Return(false, nil)
+				s.EXPECT().RevokedTokens().Return(rtr)
+			},
+			userID:    1,
+			tokenType: "access",
+			expError:  false,
+		},
+		{
+			name: "token is revoked",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				rtr := mock_store.NewMockRevokedTokenRepo(c)
+				rtr.EXPECT().Exists(gomock.Any(), gomock.Any()).Return(true, nil)
+				s.EXPECT().RevokedTokens().Return(rtr)
+			},
+			userID:    1,
+			tokenType: "access",
+			expError:  true,
 		},
 	}
 
@@ -162,19 +405,18 @@ func TestAuthService_ValidateJWT(t *testing.T) {
 			c := gomock.NewController(t)
 			defer c.Finish()
 
-			s := newAuthService(nil)
-			token, err := s.generateJWT(tc.userID, tc.tokenType)
-			if err != nil {
-				t.Fatal(err)
-			}
-			userID, err := s.ValidateJWT(token, tc.tokenType)
+			store := mock_store.NewMockStore(c)
+			tc.mock(c, store)
+			s := newAuthService(store, testKeyring(), nil, nil)
+			token, err := s.generateJWT(context.Background(), tc.userID, tc.tokenType, "")
 			if err != nil {
 				t.Fatal(err)
 			}
+			p, err := s.ValidateJWT(context.Background(), token, tc.tokenType)
 
 			if !tc.expError {
 				assert.NoError(t, err)
-				assert.Equal(t, tc.userID, userID)
+				assert.Equal(t, tc.userID, p.UserID)
 			} else {
 				assert.Error(t, err)
 			}
@@ -182,17 +424,136 @@ func TestAuthService_ValidateJWT(t *testing.T) {
 	}
 }
 
-func TestAuthService_RefreshAccessJWT(t *testing.T) {
+func TestAuthService_ValidateJWT_principal(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	store := mock_store.NewMockStore(c)
+	rr := mock_store.NewMockRoleRepo(c)
+	rr.EXPECT().GetByUserID(gomock.Any(), 1).Return([]model.Role{
+		{Name: "admin", Permissions: []string{"users:read", "users:write"}},
+		{Name: "user", Permissions: []string{}},
+	}, nil)
+	store.EXPECT().Roles().Return(rr)
+	store.EXPECT().RevokedTokens().Return(notRevoked(c))
+	s := newAuthService(store, testKeyring(), nil, nil)
+	token, err := s.generateAccessJWT(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := s.ValidateJWT(context.Background(), token, "access")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, p.UserID)
+	assert.NotEqual(t, "", p.TokenID)
+	assert.Equal(t, []string{"admin", "user"}, p.Roles)
+	assert.Equal(t, []string{"users:read", "users:write"}, p.Permissions)
+	assert.Equal(t, "1", p.Claims["sub"])
+}
+
+func TestAuthService_Refresh(t *testing.T) {
 	testcases := []struct {
 		name     string
-		userID   int
+		mock     func(*gomock.Controller, *mock_store.MockStore, model.RefreshToken)
+		token    model.RefreshToken
 		expError bool
 	}{
 		{
-			name:     "access token is refreshed",
-			userID:   1,
+			name: "token pair is refreshed",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore, rt model.RefreshToken) {
+				rtr := mock_store.NewMockRefreshTokenRepo(c)
+				rtr.EXPECT().GetByID(gomock.Any(), rt.ID).Return(rt, nil)
+				rtr.EXPECT().MarkUsed(gomock.Any(), rt.ID).Return(nil)
+				rtr.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, nt model.RefreshToken) (model.RefreshToken, error) {
+						assert.Equal(t, rt.FamilyID, nt.FamilyID)
+						assert.NotEqual(t, rt.ID, nt.ID)
+						return nt, nil
+					},
+				)
+				s.EXPECT().RefreshTokens().Return(rtr).AnyTimes()
+				s.EXPECT().RevokedTokens().Return(notRevoked(c))
+				s.EXPECT().Roles().Return(noRoles(c))
+			},
+			token:    model.RefreshToken{ID: "token1", FamilyID: "family1", UserID: 1},
 			expError: false,
 		},
+		{
+			name: "reused token revokes family",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore, rt model.RefreshToken) {
+				rtr := mock_store.NewMockRefreshTokenRepo(c)
+				rtr.EXPECT().GetByID(gomock.Any(), rt.ID).Return(rt, nil)
+				rtr.EXPECT().MarkUsed(gomock.Any(), rt.ID).Return(store.ErrTokenIsUsed)
+				rtr.EXPECT().RevokeFamily(gomock.Any(), rt.FamilyID).Return(nil)
+				s.EXPECT().RefreshTokens().Return(rtr).AnyTimes()
+				s.EXPECT().RevokedTokens().Return(notRevoked(c))
+			},
+			token:    model.RefreshToken{ID: "token1", FamilyID: "family1", UserID: 1},
+			expError: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			store := mock_store.NewMockStore(c)
+			tc.mock(c, store, tc.token)
+			s := newAuthService(store, testKeyring(), nil, nil)
+			token, err := s.generateJWT(context.Background(), tc.token.UserID, "refresh", tc.token.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			accessJWT, refreshJWT, err := s.Refresh(context.Background(), token)
+
+			if !tc.expError {
+				assert.NoError(t, err)
+				assert.NotEqual(t, "", accessJWT)
+				assert.NotEqual(t, "", refreshJWT)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestAuthService_SignOut(t *testing.T) {
+	testcases := []struct {
+		name      string
+		mock      func(*gomock.Controller, *mock_store.MockStore, model.RefreshToken)
+		token     model.RefreshToken
+		accessFor int
+		expError  bool
+	}{
+		{
+			name: "user is signed out",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore, rt model.RefreshToken) {
+				rvr := mock_store.NewMockRevokedTokenRepo(c)
+				rvr.EXPECT().Exists(gomock.Any(), gomock.Any()).Return(false, nil).Times(2)
+				rvr.EXPECT().Create(gomock.Any(), gomock.Any()).Return(model.RevokedToken{}, nil)
+				s.EXPECT().RevokedTokens().Return(rvr).Times(3)
+				rtr := mock_store.NewMockRefreshTokenRepo(c)
+				rtr.EXPECT().GetByID(gomock.Any(), rt.ID).Return(rt, nil)
+				rtr.EXPECT().RevokeFamily(gomock.Any(), rt.FamilyID).Return(nil)
+				s.EXPECT().RefreshTokens().Return(rtr).Times(2)
+			},
+			token:     model.RefreshToken{ID: "token1", FamilyID: "family1", UserID: 1},
+			accessFor: 1,
+			expError:  false,
+		},
+		{
+			name: "tokens belong to different users",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore, rt model.RefreshToken) {
+				rvr := mock_store.NewMockRevokedTokenRepo(c)
+				rvr.EXPECT().Exists(gomock.Any(), gomock.Any()).Return(false, nil).Times(2)
+				s.EXPECT().RevokedTokens().Return(rvr).Times(2)
+			},
+			token:     model.RefreshToken{ID: "token1", FamilyID: "family1", UserID: 1},
+			accessFor: 2,
+			expError:  true,
+		},
 	}
 
 	for _, tc := range testcases {
@@ -200,22 +561,102 @@ func TestAuthService_RefreshAccessJWT(t *testing.T) {
 			c := gomock.NewController(t)
 			defer c.Finish()
 
-			s := newAuthService(nil)
-			token, err := s.generateJWT(tc.userID, "refresh")
+			store := mock_store.NewMockStore(c)
+			tc.mock(c, store, tc.token)
+			s := newAuthService(store, testKeyring(), nil, nil)
+			accessJWT, err := s.generateJWT(context.Background(), tc.accessFor, "access", "")
 			if err != nil {
 				t.Fatal(err)
 			}
-			accessJWT, err := s.RefreshAccessJWT(token)
+			refreshJWT, err := s.generateJWT(context.Background(), tc.token.UserID, "refresh", tc.token.ID)
 			if err != nil {
 				t.Fatal(err)
 			}
+			err = s.SignOut(context.Background(), accessJWT, refreshJWT)
 
 			if !tc.expError {
 				assert.NoError(t, err)
-				assert.NotEqual(t, "", accessJWT)
 			} else {
 				assert.Error(t, err)
 			}
 		})
 	}
 }
+
+// notRevoked returns revoked token repository mock which reports any token as not revoked.
+func notRevoked(c *gomock.Controller) *mock_store.MockRevokedTokenRepo {
+	r := mock_store.NewMockRevokedTokenRepo(c)
+	r.EXPECT().Exists(gomock.Any(), gomock.Any()).Return(false, nil).AnyTimes()
+
+	return r
+}
+
+func TestAuthService_JWKS(t *testing.T) {
+	testcases := []struct {
+		name      string
+		algorithm string
+		material  func(*testing.T) []byte
+		expKeys   int
+	}{
+		{
+			name:      "symmetric key isn't published",
+			algorithm: "HS256",
+			material:  func(*testing.T) []byte { return []byte("secret") },
+			expKeys:   0,
+		},
+		{
+			name:      "asymmetric key is published",
+			algorithm: "ES256",
+			material:  func(t *testing.T) []byte { return generatePEM(t, "ES256") },
+			expKeys:   1,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			k, err := newKey("", tc.algorithm, tc.material(t))
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := newAuthService(nil, newKeyring(nil, nil), nil, nil)
+			s.keys.set(k)
+
+			set, err := s.JWKS(context.Background())
+
+			assert.NoError(t, err)
+			assert.Len(t, set.Keys, tc.expKeys)
+		})
+	}
+}
+
+// noRoles returns role repository mock which reports any user has no roles.
+func noRoles(c *gomock.Controller) *mock_store.MockRoleRepo {
+	r := mock_store.NewMockRoleRepo(c)
+	r.EXPECT().GetByUserID(gomock.Any(), gomock.Any()).Return([]model.Role{}, nil).AnyTimes()
+
+	return r
+}
+
+func noThrottle(c *gomock.Controller) *mock_store.MockLoginThrottleRepo {
+	r := mock_store.NewMockLoginThrottleRepo(c)
+	r.EXPECT().Get(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, key string) (model.LoginThrottle, error) {
+			return model.LoginThrottle{Key: key}, nil
+		},
+	).AnyTimes()
+	r.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	return r
+}
+
+func noTOTP(c *gomock.Controller) *mock_store.MockTOTPSecretRepo {
+	r := mock_store.NewMockTOTPSecretRepo(c)
+	r.EXPECT().GetByUserID(gomock.Any(), gomock.Any()).Return(model.TOTPSecret{}, store.ErrNotFound)
+
+	return r
+}
+
+// testKeyring returns keyring with the key from config only.
+func testKeyring() *keyring {
+	return newKeyring(nil, config.Get().JWT)
+}
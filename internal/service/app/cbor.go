@@ -0,0 +1,152 @@
+package app
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// cborMaxDepth is the maximum nesting depth of decoded CBOR items.
+const cborMaxDepth = 16
+
+// CBOR major types.
+const (
+	cborUint   = 0
+	cborNegInt = 1
+	cborBytes  = 2
+	cborText   = 3
+	cborArray  = 4
+	cborMap    = 5
+	cborTag    = 6
+	cborSimple = 7
+)
+
+var errCBORMalformed = errors.New("malformed CBOR")
+
+// cborDecode decodes the CBOR (RFC 7049) data item at the beginning of data and returns
+// it along with its length. Only definite length items WebAuthn authenticators produce
+// are supported: integers are decoded as int64, byte strings as []byte, text strings as
+// string, arrays as []interface{}, maps as map[interface{}]interface{} and simple values
+// as bool or nil. Tags are skipped.
+func cborDecode(data []byte) (interface{}, int, error) {
+	return cborDecodeItem(data, 0)
+}
+
+// cborDecodeItem decodes the data item nested at specific depth.
+func cborDecodeItem(data []byte, depth int) (interface{}, int, error) {
+	if depth > cborMaxDepth {
+		return nil, 0, errors.New("CBOR is nested too deep")
+	}
+	major, arg, n, err := cborHead(data)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	switch major {
+	case cborUint:
+		if arg > math.MaxInt64 {
+			return nil, 0, errors.New("CBOR integer overflows int64")
+		}
+		return int64(arg), n, nil
+	case cborNegInt:
+		if arg > math.MaxInt64 {
+			return nil, 0, errors.New("CBOR integer overflows int64")
+		}
+		return -1 - int64(arg), n, nil
+	case cborBytes, cborText:
+		if arg > uint64(len(data)-n) {
+			return nil, 0, errCBORMalformed
+		}
+		end := n + int(arg)
+		if major == cborText {
+			return string(data[n:end]), end, nil
+		}
+		return append([]byte{}, data[n:end]...), end, nil
+	case cborArray:
+		// Every item takes at least a byte, longer arrays can't fit.
+		if arg > uint64(len(data)-n) {
+			return nil, 0, errCBORMalformed
+		}
+		items := make([]interface{}, 0, arg)
+		for i := uint64(0); i < arg; i++ {
+			item, m, err := cborDecodeItem(data[n:], depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			items = append(items, item)
+			n += m
+		}
+		return items, n, nil
+	case cborMap:
+		if arg > uint64(len(data)-n)/2 {
+			return nil, 0, errCBORMalformed
+		}
+		items := make(map[interface{}]interface{}, arg)
+		for i := uint64(0); i < arg; i++ {
+			key, m, err := cborDecodeItem(data[n:], depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			n += m
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, 0, errors.New("CBOR map key must be integer or text")
+			}
+			value, m, err := cborDecodeItem(data[n:], depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			n += m
+			items[key] = value
+		}
+		return items, n, nil
+	case cborTag:
+		item, m, err := cborDecodeItem(data[n:], depth+1)
+		if err != nil {
+			return nil, 0, err
+		}
+		return item, n + m, nil
+	}
+
+	// Simple values, floats aren't used by WebAuthn.
+	if n != 1 {
+		return nil, 0, errors.New("unsupported CBOR simple value")
+	}
+	switch arg {
+	case 20:
+		return false, n, nil
+	case 21:
+		return true, n, nil
+	case 22:
+		return nil, n, nil
+	}
+
+	return nil, 0, errors.New("unsupported CBOR simple value")
+}
+
+// cborHead decodes the head of the data item: its major type, argument and the length
+// of the head.
+func cborHead(data []byte) (byte, uint64, int, error) {
+	if len(data) == 0 {
+		return 0, 0, 0, errCBORMalformed
+	}
+	major, info := data[0]>>5, data[0]&0x1f
+
+	switch {
+	case info < 24:
+		return major, uint64(info), 1, nil
+	case info == 24 && len(data) >= 2:
+		return major, uint64(data[1]), 2, nil
+	case info == 25 && len(data) >= 3:
+		return major, uint64(binary.BigEndian.Uint16(data[1:])), 3, nil
+	case info == 26 && len(data) >= 5:
+		return major, uint64(binary.BigEndian.Uint32(data[1:])), 5, nil
+	case info == 27 && len(data) >= 9:
+		return major, binary.BigEndian.Uint64(data[1:]), 9, nil
+	case info == 31:
+		return 0, 0, 0, errors.New("indefinite length CBOR isn't supported")
+	}
+
+	return 0, 0, 0, errCBORMalformed
+}
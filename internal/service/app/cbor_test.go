@@ -0,0 +1,161 @@
+package app
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cborEncode encodes integers, byte and text strings, arrays and maps into CBOR the way
+// authenticators do, it's used to build WebAuthn test data.
+func cborEncode(v interface{}) []byte {
+	switch v := v.(type) {
+	case int:
+		return cborEncode(int64(v))
+	case int64:
+		if v < 0 {
+			return cborEncodeHead(cborNegInt, uint64(-1-v))
+		}
+		return cborEncodeHead(cborUint, uint64(v))
+	case []byte:
+		return append(cborEncodeHead(cborBytes, uint64(len(v))), v...)
+	case string:
+		return append(cborEncodeHead(cborText, uint64(len(v))), v...)
+	case []interface{}:
+		data := cborEncodeHead(cborArray, uint64(len(v)))
+		for _, item := range v {
+			data = append(data, cborEncode(item)...)
+		}
+		return data
+	case map[interface{}]interface{}:
+		// Keys are sorted the canonical way, so encoding is deterministic.
+		keys := make([][]byte, 0, len(v))
+		values := make(map[string][]byte, len(v))
+		for key, value := range v {
+			k := cborEncode(key)
+			keys = append(keys, k)
+			values[string(k)] = cborEncode(value)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if len(keys[i]) != len(keys[j]) {
+				return len(keys[i]) < len(keys[j])
+			}
+			return bytes.Compare(keys[i], keys[j]) < 0
+		})
+		data := cborEncodeHead(cborMap, uint64(len(v)))
+		for _, k := range keys {
+			data = append(append(data, k...), values[string(k)]...)
+		}
+		return data
+	case bool:
+		if v {
+			return []byte{cborSimple<<5 | 21}
+		}
+		return []byte{cborSimple<<5 | 20}
+	case nil:
+		return []byte{cborSimple<<5 | 22}
+	}
+
+	panic("unsupported CBOR value")
+}
+
+// cborEncodeHead encodes the head of the data item with the shortest argument.
+func cborEncodeHead(major byte, arg uint64) []byte {
+	switch {
+	case arg < 24:
+		return []byte{major<<5 | byte(arg)}
+	case arg <= 0xff:
+		return []byte{major<<5 | 24, byte(arg)}
+	case arg <= 0xffff:
+		return append([]byte{major<<5 | 25}, byte(arg>>8), byte(arg))
+	case arg <= 0xffffffff:
+		head := make([]byte, 5)
+		head[0] = major<<5 | 26
+		binary.BigEndian.PutUint32(head[1:], uint32(arg))
+		return head
+	}
+	head := make([]byte, 9)
+	head[0] = major<<5 | 27
+	binary.BigEndian.PutUint64(head[1:], arg)
+
+	return head
+}
+
+func TestCBORDecode(t *testing.T) {
+	testcases := []struct {
+		name  string
+		value interface{}
+	}{
+		{name: "small integer", value: int64(10)},
+		{name: "large integer", value: int64(1) << 40},
+		{name: "negative integer", value: int64(-257)},
+		{name: "byte string", value: make([]byte, 300)},
+		{name: "text string", value: "authData"},
+		{name: "array", value: []interface{}{int64(1), "a", []byte{2}}},
+		{
+			name: "map",
+			value: map[interface{}]interface{}{
+				int64(1): int64(2), int64(-1): []byte{1, 2}, "fmt": "none",
+				"attStmt": map[interface{}]interface{}{},
+			},
+		},
+		{name: "simple values", value: []interface{}{true, false, nil}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			data := cborEncode(tc.value)
+
+			v, n, err := cborDecode(append(data, 0xff))
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.value, v)
+			assert.Equal(t, len(data), n)
+		})
+	}
+}
+
+func TestCBORDecode_Tag(t *testing.T) {
+	// Tag 24 (encoded CBOR data item) wrapping an integer.
+	v, n, err := cborDecode([]byte{0xd8, 0x18, 0x01})
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), v)
+	assert.Equal(t, 3, n)
+}
+
+func TestCBORDecode_Error(t *testing.T) {
+	nested := []byte{}
+	for i := 0; i <= cborMaxDepth+1; i++ {
+		nested = append(nested, cborArray<<5|1)
+	}
+	nested = append(nested, 0)
+
+	testcases := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty data", data: []byte{}},
+		{name: "truncated head", data: []byte{cborUint<<5 | 25, 1}},
+		{name: "truncated byte string", data: []byte{cborBytes<<5 | 4, 1, 2}},
+		{name: "truncated array", data: []byte{cborArray<<5 | 2, 1}},
+		{name: "huge map", data: cborEncodeHead(cborMap, 1<<62)},
+		{name: "indefinite length", data: []byte{cborBytes<<5 | 31, 0xff}},
+		{name: "integer overflow", data: cborEncodeHead(cborUint, 1<<63)},
+		{name: "float", data: []byte{cborSimple<<5 | 25, 0x3c, 0x00}},
+		{name: "array map key", data: []byte{cborMap<<5 | 1, cborArray << 5, 0}},
+		{name: "too deep", data: nested},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := cborDecode(tc.data)
+
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package app
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"github.com/imarrche/jwt-auth-example/internal/config"
+	"github.com/imarrche/jwt-auth-example/internal/model"
+)
+
+// claims are JSON Web Token claims.
+type claims struct {
+	jwt.StandardClaims
+	Type      string   `json:"type"`
+	Roles     []string `json:"roles,omitempty"`
+	Scope     string   `json:"scope,omitempty"`
+	Challenge string   `json:"challenge,omitempty"`
+}
+
+// newClaims creates and returns claims of specific type for user. Expiration
+// time depends on token type.
+func newClaims(c *config.JWT, userID int, tokenType, tokenID string) *claims {
+	now := time.Now()
+
+	return &claims{
+		StandardClaims: jwt.StandardClaims{
+			Id:        tokenID,
+			Issuer:    c.Issuer,
+			Subject:   strconv.Itoa(userID),
+			Audience:  c.Audience,
+			IssuedAt:  now.Unix(),
+			NotBefore: now.Unix(),
+			ExpiresAt: now.Add(tokenTTL(c, tokenType)).Unix(),
+		},
+		Type: tokenType,
+	}
+}
+
+// tokenTTL returns lifetime of JSON Web Token of specific type.
+func tokenTTL(c *config.JWT, tokenType string) time.Duration {
+	switch tokenType {
+	case "access":
+		return c.AccessTTL
+	case "mfa_pending":
+		return c.MFATTL
+	case "webauthn_registration", "webauthn_login":
+		return c.WebAuthnTTL
+	}
+
+	return c.RefreshTTL
+}
+
+// validate validates claims of JSON Web Token of specific type. Time based claims
+// are validated with leeway to tolerate clock skew.
+func (cl *claims) validate(c *config.JWT, tokenType string) error {
+	now := time.Now()
+	leeway := int64(c.Leeway / time.Second)
+
+	if cl.Type != tokenType {
+		return errors.New("invalid JWT type")
+	}
+	if !cl.VerifyIssuer(c.Issuer, true) {
+		return errors.New("invalid JWT issuer")
+	}
+	if !cl.VerifyAudience(c.Audience, true) {
+		return errors.New("invalid JWT audience")
+	}
+	if !cl.VerifyExpiresAt(now.Unix()-leeway, true) {
+		return errors.New("JWT expired")
+	}
+	if !cl.VerifyNotBefore(now.Unix()+leeway, true) {
+		return errors.New("JWT isn't valid yet")
+	}
+	if !cl.VerifyIssuedAt(now.Unix()+leeway, true) {
+		return errors.New("JWT is issued in the future")
+	}
+	if cl.Id == "" {
+		return errors.New("couldn't parse JWT's ID")
+	}
+
+	return nil
+}
+
+// userID returns ID of the user JSON Web Token is issued for.
+func (cl *claims) userID() (int, error) {
+	userID, err := strconv.Atoi(cl.Subject)
+	if err != nil {
+		return 0, errors.New("couldn't parse JWT's user ID")
+	}
+
+	return userID, nil
+}
+
+// expiresAt returns JSON Web Token's expiration time.
+func (cl *claims) expiresAt() time.Time {
+	return time.Unix(cl.ExpiresAt, 0)
+}
+
+// setRoles sets names of roles and space separated union of their permissions.
+func (cl *claims) setRoles(roles []model.Role) {
+	cl.Roles = make([]string, 0, len(roles))
+	permissions := []string{}
+	seen := map[string]bool{}
+	for _, r := range roles {
+		cl.Roles = append(cl.Roles, r.Name)
+		for _, p := range r.Permissions {
+			if !seen[p] {
+				seen[p] = true
+				permissions = append(permissions, p)
+			}
+		}
+	}
+	sort.Strings(permissions)
+	cl.Scope = strings.Join(permissions, " ")
+}
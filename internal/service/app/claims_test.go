@@ -0,0 +1,82 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imarrche/jwt-auth-example/internal/config"
+)
+
+func TestClaims_validate(t *testing.T) {
+	c := &config.JWT{
+		Issuer: "issuer", Audience: "audience", AccessTTL: time.Minute,
+		RefreshTTL: time.Hour, Leeway: 30 * time.Second,
+	}
+
+	testcases := []struct {
+		name      string
+		modify    func(*claims)
+		tokenType string
+		expError  bool
+	}{
+		{
+			name:      "claims are valid",
+			modify:    func(*claims) {},
+			tokenType: "access",
+			expError:  false,
+		},
+		{
+			name:      "type is invalid",
+			modify:    func(*claims) {},
+			tokenType: "refresh",
+			expError:  true,
+		},
+		{
+			name:      "issuer is invalid",
+			modify:    func(cl *claims) { cl.Issuer = "other" },
+			tokenType: "access",
+			expError:  true,
+		},
+		{
+			name:      "audience is invalid",
+			modify:    func(cl *claims) { cl.Audience = "other" },
+			tokenType: "access",
+			expError:  true,
+		},
+		{
+			name:      "expired within leeway",
+			modify:    func(cl *claims) { cl.ExpiresAt = time.Now().Add(-10 * time.Second).Unix() },
+			tokenType: "access",
+			expError:  false,
+		},
+		{
+			name:      "expired",
+			modify:    func(cl *claims) { cl.ExpiresAt = time.Now().Add(-time.Minute).Unix() },
+			tokenType: "access",
+			expError:  true,
+		},
+		{
+			name:      "not valid yet",
+			modify:    func(cl *claims) { cl.NotBefore = time.Now().Add(time.Minute).Unix() },
+			tokenType: "access",
+			expError:  true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cl := newClaims(c, 1, "access", "token1")
+			tc.modify(cl)
+
+			err := cl.validate(c, tc.tokenType)
+
+			if !tc.expError {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/imarrche/jwt-auth-example/internal/config"
+	"github.com/imarrche/jwt-auth-example/internal/mail"
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/service"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// sendVerificationEmail sends a single use email verification link to the user.
+func (s *authService) sendVerificationEmail(ctx context.Context, u model.User) error {
+	token, hash, err := newSecretToken()
+	if err != nil {
+		return err
+	}
+	c := config.Get().Account
+	_, err = s.store.EmailVerificationTokens().Create(ctx, model.EmailVerificationToken{
+		Hash:      hash,
+		UserID:    u.ID,
+		Email:     u.Email,
+		ExpiresAt: time.Now().Add(c.EmailVerificationTTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(mail.Message{
+		To:      u.Email,
+		Subject: "Email verification",
+		Body: fmt.Sprintf(
+			"To verify your email follow the link: %s/api/v1/auth/verify-email?token=%s\n"+
+				"The link expires in %s.",
+			c.URL, token, c.EmailVerificationTTL,
+		),
+	})
+}
+
+// VerifyEmail marks email of the user the email verification token was issued for
+// as verified. The token can be used only once and only while the user's email is the
+// address it was sent to.
+func (s *authService) VerifyEmail(ctx context.Context, token string) error {
+	t, err := s.store.EmailVerificationTokens().GetByHash(ctx, hashSecretToken(token))
+	if errors.Is(err, store.ErrNotFound) {
+		return service.ErrInvalidOneTimeToken
+	} else if err != nil {
+		return err
+	}
+	if t.UsedAt != nil || time.Now().After(t.ExpiresAt) {
+		return service.ErrInvalidOneTimeToken
+	}
+
+	u, err := s.store.Users().GetByID(ctx, t.UserID)
+	if err != nil {
+		return serviceError(err)
+	}
+	if u.Email != t.Email {
+		return service.ErrInvalidOneTimeToken
+	}
+	if err := s.store.EmailVerificationTokens().MarkUsed(ctx, t.Hash); isTokenGone(err) {
+		return service.ErrInvalidOneTimeToken
+	} else if err != nil {
+		return err
+	}
+	if u.EmailVerifiedAt != nil {
+		return nil
+	}
+	now := time.Now()
+
+	return serviceError(s.store.Users().SetEmailVerifiedAt(ctx, u.ID, &now))
+}
+
+// purgeEmailVerificationTokens deletes email verification tokens which have already expired.
+func (s *authService) purgeEmailVerificationTokens(ctx context.Context) error {
+	return s.store.EmailVerificationTokens().DeleteExpired(ctx)
+}
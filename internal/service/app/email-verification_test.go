@@ -0,0 +1,90 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	mock_store "github.com/imarrche/jwt-auth-example/internal/store/mocks"
+)
+
+func TestAuthService_VerifyEmail(t *testing.T) {
+	token := "token1"
+
+	testcases := []struct {
+		name     string
+		mock     func(*gomock.Controller, *mock_store.MockStore)
+		expError bool
+	}{
+		{
+			name: "email is verified",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				evtr := mock_store.NewMockEmailVerificationTokenRepo(c)
+				evtr.EXPECT().GetByHash(gomock.Any(), hashSecretToken(token)).Return(model.EmailVerificationToken{
+					Hash:      hashSecretToken(token),
+					UserID:    1,
+					Email:     "user1@test.com",
+					ExpiresAt: time.Now().Add(time.Hour),
+				}, nil)
+				evtr.EXPECT().MarkUsed(gomock.Any(), hashSecretToken(token)).Return(nil)
+				s.EXPECT().EmailVerificationTokens().Return(evtr).Times(2)
+
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), 1).Return(model.User{ID: 1, Email: "user1@test.com"}, nil)
+				ur.EXPECT().SetEmailVerifiedAt(gomock.Any(), 1, gomock.Not(gomock.Nil())).Return(nil)
+				s.EXPECT().Users().Return(ur).Times(2)
+			},
+			expError: false,
+		},
+		{
+			name: "token is expired",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				evtr := mock_store.NewMockEmailVerificationTokenRepo(c)
+				evtr.EXPECT().GetByHash(gomock.Any(), hashSecretToken(token)).Return(model.EmailVerificationToken{
+					Hash: hashSecretToken(token), UserID: 1, ExpiresAt: time.Now().Add(-time.Hour),
+				}, nil)
+				s.EXPECT().EmailVerificationTokens().Return(evtr)
+			},
+			expError: true,
+		},
+		{
+			name: "email has changed since the token was sent",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				evtr := mock_store.NewMockEmailVerificationTokenRepo(c)
+				evtr.EXPECT().GetByHash(gomock.Any(), hashSecretToken(token)).Return(model.EmailVerificationToken{
+					Hash:      hashSecretToken(token),
+					UserID:    1,
+					Email:     "user1@test.com",
+					ExpiresAt: time.Now().Add(time.Hour),
+				}, nil)
+				s.EXPECT().EmailVerificationTokens().Return(evtr)
+
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), 1).Return(model.User{ID: 1, Email: "user2@test.com"}, nil)
+				s.EXPECT().Users().Return(ur)
+			},
+			expError: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			store := mock_store.NewMockStore(c)
+			tc.mock(c, store)
+			err := newAuthService(store, testKeyring(), nil, nil).VerifyEmail(context.Background(), token)
+
+			if !tc.expError {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
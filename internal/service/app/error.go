@@ -0,0 +1,29 @@
+package app
+
+import (
+	"errors"
+
+	"github.com/imarrche/jwt-auth-example/internal/service"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// serviceError maps store errors onto domain errors, other errors are returned as is.
+func serviceError(err error) error {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		return service.ErrNotFound
+	case errors.Is(err, store.ErrUsernameIsTaken):
+		return service.ErrUsernameIsTaken
+	case errors.Is(err, store.ErrEmailIsTaken):
+		return service.ErrEmailIsTaken
+	case errors.Is(err, store.ErrCredentialIsTaken):
+		return service.ErrCredentialIsTaken
+	}
+
+	return err
+}
+
+// isTokenGone reports whether the one time token was used or deleted meanwhile.
+func isTokenGone(err error) bool {
+	return errors.Is(err, store.ErrTokenIsUsed) || errors.Is(err, store.ErrNotFound)
+}
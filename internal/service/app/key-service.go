@@ -0,0 +1,91 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/imarrche/jwt-auth-example/internal/config"
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// keyService implements signing key rotation business logic.
+type keyService struct {
+	store store.Store
+	keys  *keyring
+}
+
+// newKeyService creates and returns a new keyService instance.
+func newKeyService(s store.Store, keys *keyring) *keyService {
+	return &keyService{store: s, keys: keys}
+}
+
+// GetAll returns all signing keys.
+func (s *keyService) GetAll(ctx context.Context) ([]model.SigningKey, error) {
+	return s.store.SigningKeys().GetAll(ctx)
+}
+
+// Add introduces a new pending signing key, its material is stored encrypted. Pending
+// key is published and verifies JSON Web Tokens, but doesn't sign them until it's
+// promoted.
+func (s *keyService) Add(
+	ctx context.Context, id, algorithm string, material []byte,
+) (model.SigningKey, error) {
+	k, err := newKey(id, algorithm, material)
+	if err != nil {
+		return model.SigningKey{}, err
+	}
+	encrypted, err := encryptKeyMaterial(s.keys.config, k.id, algorithm, material)
+	if err != nil {
+		return model.SigningKey{}, err
+	}
+
+	sk, err := s.store.SigningKeys().Create(ctx, model.SigningKey{
+		ID: k.id, Algorithm: algorithm, Material: encrypted, Status: model.SigningKeyPending,
+	})
+	if err != nil {
+		return model.SigningKey{}, err
+	}
+	s.keys.invalidate()
+
+	return sk, nil
+}
+
+// Promote makes the signing key with specific ID active, the previously active key
+// is retired and keeps verifying JSON Web Tokens during the grace period.
+func (s *keyService) Promote(ctx context.Context, id string) error {
+	if err := s.store.SigningKeys().Activate(ctx, id); err != nil {
+		return serviceError(err)
+	}
+	s.keys.invalidate()
+
+	return nil
+}
+
+// Retire retires the pending signing key with specific ID.
+func (s *keyService) Retire(ctx context.Context, id string) error {
+	sks, err := s.store.SigningKeys().GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, sk := range sks {
+		if sk.ID == id && sk.Status == model.SigningKeyActive {
+			return errors.New("active key can't be retired, promote another key first")
+		}
+	}
+
+	if err := s.store.SigningKeys().Retire(ctx, id); err != nil {
+		return serviceError(err)
+	}
+	s.keys.invalidate()
+
+	return nil
+}
+
+// Purge deletes signing keys retired longer than the grace period ago.
+func (s *keyService) Purge(ctx context.Context) error {
+	before := time.Now().Add(-config.Get().JWT.KeyGracePeriod)
+
+	return s.store.SigningKeys().DeleteRetiredBefore(ctx, before)
+}
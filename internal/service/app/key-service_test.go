@@ -0,0 +1,177 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imarrche/jwt-auth-example/internal/config"
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	mock_store "github.com/imarrche/jwt-auth-example/internal/store/mocks"
+)
+
+func TestKeyService_Add(t *testing.T) {
+	withKeyEncryptionKey(t)
+	testcases := []struct {
+		name      string
+		mock      func(*gomock.Controller, *mock_store.MockStore)
+		id        string
+		algorithm string
+		material  []byte
+		expError  bool
+	}{
+		{
+			name: "pending key is added",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				skr := mock_store.NewMockSigningKeyRepo(c)
+				skr.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, sk model.SigningKey) (model.SigningKey, error) {
+						assert.Equal(t, model.SigningKeyPending, sk.Status)
+						material, err := decryptKeyMaterial(config.Get().JWT, sk)
+						assert.NoError(t, err)
+						assert.Equal(t, "secret2", string(material))
+						return sk, nil
+					},
+				)
+				s.EXPECT().SigningKeys().Return(skr)
+			},
+			id:        "key2",
+			algorithm: "HS256",
+			material:  []byte("secret2"),
+			expError:  false,
+		},
+		{
+			name:      "invalid key isn't added",
+			mock:      func(*gomock.Controller, *mock_store.MockStore) {},
+			id:        "key2",
+			algorithm: "ES256",
+			material:  []byte("not a PEM"),
+			expError:  true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			store := mock_store.NewMockStore(c)
+			tc.mock(c, store)
+			s := newKeyService(store, testKeyring())
+			sk, err := s.Add(context.Background(), tc.id, tc.algorithm, tc.material)
+
+			if !tc.expError {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.id, sk.ID)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestKeyService_Promote(t *testing.T) {
+	testcases := []struct {
+		name     string
+		mock     func(*gomock.Controller, *mock_store.MockStore, string)
+		id       string
+		expError bool
+	}{
+		{
+			name: "key is promoted",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore, id string) {
+				skr := mock_store.NewMockSigningKeyRepo(c)
+				skr.EXPECT().Activate(gomock.Any(), id).Return(nil)
+				s.EXPECT().SigningKeys().Return(skr)
+			},
+			id:       "key2",
+			expError: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			store := mock_store.NewMockStore(c)
+			tc.mock(c, store, tc.id)
+			s := newKeyService(store, testKeyring())
+			err := s.Promote(context.Background(), tc.id)
+
+			if !tc.expError {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestKeyService_Retire(t *testing.T) {
+	testcases := []struct {
+		name     string
+		mock     func(*gomock.Controller, *mock_store.MockStore, string)
+		id       string
+		expError bool
+	}{
+		{
+			name: "pending key is retired",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore, id string) {
+				skr := mock_store.NewMockSigningKeyRepo(c)
+				skr.EXPECT().GetAll(gomock.Any()).Return([]model.SigningKey{
+					{ID: "key1", Status: model.SigningKeyActive},
+					{ID: id, Status: model.SigningKeyPending},
+				}, nil)
+				skr.EXPECT().Retire(gomock.Any(), id).Return(nil)
+				s.EXPECT().SigningKeys().Return(skr).Times(2)
+			},
+			id:       "key2",
+			expError: false,
+		},
+		{
+			name: "active key isn't retired",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore, id string) {
+				skr := mock_store.NewMockSigningKeyRepo(c)
+				skr.EXPECT().GetAll(gomock.Any()).Return([]model.SigningKey{
+					{ID: id, Status: model.SigningKeyActive},
+				}, nil)
+				s.EXPECT().SigningKeys().Return(skr)
+			},
+			id:       "key1",
+			expError: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			store := mock_store.NewMockStore(c)
+			tc.mock(c, store, tc.id)
+			s := newKeyService(store, testKeyring())
+			err := s.Retire(context.Background(), tc.id)
+
+			if !tc.expError {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestKeyService_Purge(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	store := mock_store.NewMockStore(c)
+	skr := mock_store.NewMockSigningKeyRepo(c)
+	skr.EXPECT().DeleteRetiredBefore(gomock.Any(), gomock.Any()).Return(nil)
+	store.EXPECT().SigningKeys().Return(skr)
+
+	assert.NoError(t, newKeyService(store, testKeyring()).Purge(context.Background()))
+}
@@ -0,0 +1,233 @@
+package app
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"strings"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"github.com/imarrche/jwt-auth-example/internal/config"
+	"github.com/imarrche/jwt-auth-example/internal/model"
+)
+
+// key is a JSON Web Token signing and verification key.
+type key struct {
+	id      string
+	method  jwt.SigningMethod
+	private interface{}
+	public  interface{}
+}
+
+// newKey creates and returns a new key for specific algorithm. Material is the
+// secret for HS256 and PEM encoded private key for asymmetric algorithms. Key ID
+// defaults to JWK thumbprint for asymmetric algorithms.
+func newKey(id, algorithm string, material []byte) (*key, error) {
+	if algorithm == "HS256" {
+		if len(material) == 0 {
+			return nil, errors.New("JWT secret is empty")
+		}
+		if id == "" {
+			id = "default"
+		}
+		return &key{id: id, method: jwt.SigningMethodHS256, private: material, public: material}, nil
+	}
+
+	privateKey, err := parsePrivateKey(material)
+	if err != nil {
+		return nil, err
+	}
+
+	k := &key{id: id, private: privateKey}
+	switch pk := privateKey.(type) {
+	case *rsa.PrivateKey:
+		k.method, k.public = jwt.SigningMethodRS256, &pk.PublicKey
+	case *ecdsa.PrivateKey:
+		if pk.Curve != elliptic.P256() {
+			return nil, errors.New("ES256 requires P-256 curve key")
+		}
+		k.method, k.public = jwt.SigningMethodES256, &pk.PublicKey
+	case ed25519.PrivateKey:
+		k.method, k.public = methodEdDSA, pk.Public()
+	default:
+		return nil, errors.New("unsupported private key type")
+	}
+	if k.method.Alg() != algorithm {
+		return nil, fmt.Errorf("private key can't be used with %s algorithm", algorithm)
+	}
+
+	if k.id == "" {
+		jwk, _ := k.jwk()
+		k.id = thumbprint(jwk)
+	}
+
+	return k, nil
+}
+
+// loadKeyMaterial loads the key material described by JSON Web Token config.
+func loadKeyMaterial(c *config.JWT) ([]byte, error) {
+	if c.Algorithm == "HS256" {
+		return []byte(c.Secret), nil
+	}
+
+	return ioutil.ReadFile(c.PrivateKeyPath)
+}
+
+// parsePrivateKey parses PEM encoded PKCS #8, PKCS #1 or SEC 1 private key.
+func parsePrivateKey(material []byte) (interface{}, error) {
+	block, _ := pem.Decode(material)
+	if block == nil {
+		return nil, errors.New("couldn't decode PEM private key")
+	}
+
+	if k, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		return k, nil
+	}
+	if k, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return k, nil
+	}
+	if k, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return k, nil
+	}
+
+	return nil, errors.New("couldn't parse private key")
+}
+
+// jwk returns the public JSON Web Key of the key. Symmetric keys are never
+// published, so false is returned for them.
+func (k *key) jwk() (model.JWK, bool) {
+	jwk := model.JWK{KeyID: k.id, Use: "sig", Algorithm: k.method.Alg()}
+
+	switch pk := k.public.(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = encodeBase64(pk.N.Bytes())
+		jwk.E = encodeBase64(big.NewInt(int64(pk.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (pk.Curve.Params().BitSize + 7) / 8
+		jwk.KeyType, jwk.Curve = "EC", pk.Curve.Params().Name
+		jwk.X = encodeBase64(pk.X.FillBytes(make([]byte, size)))
+		jwk.Y = encodeBase64(pk.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		jwk.KeyType, jwk.Curve = "OKP", "Ed25519"
+		jwk.X = encodeBase64(pk)
+	default:
+		return model.JWK{}, false
+	}
+
+	return jwk, true
+}
+
+// thumbprint returns RFC 7638 thumbprint of the public JSON Web Key.
+func thumbprint(jwk model.JWK) string {
+	var members interface{}
+	switch jwk.KeyType {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.KeyType, jwk.N}
+	case "EC":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		}{jwk.Curve, jwk.KeyType, jwk.X, jwk.Y}
+	default:
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Curve, jwk.KeyType, jwk.X}
+	}
+
+	b, _ := json.Marshal(members)
+	sum := sha256.Sum256(b)
+
+	return encodeBase64(sum[:])
+}
+
+// encodeBase64 encodes bytes with unpadded base64url encoding.
+func encodeBase64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeBase64 decodes unpadded base64url string.
+func decodeBase64(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
+
+// newKeyMaterialCipher returns AES-GCM cipher signing key material is encrypted with in
+// the store.
+func newKeyMaterialCipher(c *config.JWT) (cipher.AEAD, error) {
+	k, err := base64.StdEncoding.DecodeString(c.KeyEncryptionKey)
+	if err != nil || len(k) != 32 {
+		return nil, errors.New("JWT key encryption key must be base64 encoded 32 bytes")
+	}
+	block, err := aes.NewCipher(k)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// encryptedKeyMaterialPrefix prefixes signing key material encrypted by
+// encryptKeyMaterial, material stored before it was introduced is plaintext.
+const encryptedKeyMaterialPrefix = "aes-gcm:"
+
+// encryptKeyMaterial encrypts material of the signing key, the random nonce is
+// prepended to the base64 encoded ciphertext. The ciphertext is bound to the key ID and
+// algorithm, so it can't be moved to another key.
+func encryptKeyMaterial(c *config.JWT, id, algorithm string, material []byte) (string, error) {
+	aead, err := newKeyMaterialCipher(c)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, material, []byte(id+"\x00"+algorithm))
+
+	return encryptedKeyMaterialPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decryptKeyMaterial decrypts material of the signing key encrypted by
+// encryptKeyMaterial. Plaintext material stored before encryption was introduced is
+// returned as is.
+func decryptKeyMaterial(c *config.JWT, sk model.SigningKey) ([]byte, error) {
+	if !strings.HasPrefix(sk.Material, encryptedKeyMaterialPrefix) {
+		return []byte(sk.Material), nil
+	}
+
+	aead, err := newKeyMaterialCipher(c)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(
+		strings.TrimPrefix(sk.Material, encryptedKeyMaterialPrefix),
+	)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return nil, errors.New("signing key material is malformed")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+
+	return aead.Open(nil, nonce, ciphertext, []byte(sk.ID+"\x00"+sk.Algorithm))
+}
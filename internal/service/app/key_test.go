@@ -0,0 +1,108 @@
+package app
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+)
+
+// generatePEM generates a private key for specific algorithm and returns it PEM encoded.
+func generatePEM(t *testing.T, algorithm string) []byte {
+	var privateKey interface{}
+	var err error
+	switch algorithm {
+	case "RS256":
+		privateKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	case "ES256":
+		privateKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case "EdDSA":
+		_, privateKey, err = ed25519.GenerateKey(rand.Reader)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: b})
+}
+
+func TestNewKey(t *testing.T) {
+	testcases := []struct {
+		name      string
+		algorithm string
+		material  func(*testing.T) []byte
+		expKty    string
+		expError  bool
+	}{
+		{
+			name:      "HS256 key is created",
+			algorithm: "HS256",
+			material:  func(*testing.T) []byte { return []byte("secret") },
+			expError:  false,
+		},
+		{
+			name:      "RS256 key is created",
+			algorithm: "RS256",
+			material:  func(t *testing.T) []byte { return generatePEM(t, "RS256") },
+			expKty:    "RSA",
+			expError:  false,
+		},
+		{
+			name:      "ES256 key is created",
+			algorithm: "ES256",
+			material:  func(t *testing.T) []byte { return generatePEM(t, "ES256") },
+			expKty:    "EC",
+			expError:  false,
+		},
+		{
+			name:      "EdDSA key is created",
+			algorithm: "EdDSA",
+			material:  func(t *testing.T) []byte { return generatePEM(t, "EdDSA") },
+			expKty:    "OKP",
+			expError:  false,
+		},
+		{
+			name:      "key doesn't match algorithm",
+			algorithm: "RS256",
+			material:  func(t *testing.T) []byte { return generatePEM(t, "EdDSA") },
+			expError:  true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			k, err := newKey("", tc.algorithm, tc.material(t))
+
+			if !tc.expError {
+				assert.NoError(t, err)
+				assert.NotEqual(t, "", k.id)
+
+				token := jwt.NewWithClaims(k.method, jwt.MapClaims{"user_id": 1})
+				signed, err := token.SignedString(k.private)
+				assert.NoError(t, err)
+				_, err = jwt.Parse(signed, func(*jwt.Token) (interface{}, error) {
+					return k.public, nil
+				})
+				assert.NoError(t, err)
+
+				jwk, ok := k.jwk()
+				assert.Equal(t, tc.expKty != "", ok)
+				assert.Equal(t, tc.expKty, jwk.KeyType)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
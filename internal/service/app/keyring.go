@@ -0,0 +1,236 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/imarrche/jwt-auth-example/internal/config"
+	"github.com/imarrche/jwt-auth-example/internal/logger"
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// keyring keeps JSON Web Token keys. The active key signs tokens, while pending
+// and recently retired keys still verify them, selected by key ID.
+//
+// Keys are loaded from the store and reloaded periodically, so all instances pick
+// up rotated keys. The key from config becomes the active key of an empty store.
+type keyring struct {
+	store  store.Store
+	config *config.JWT
+
+	mu       sync.RWMutex
+	loadedAt time.Time
+	signing  *key
+	keys     map[string]*key
+	ids      []string
+}
+
+// newKeyring creates and returns a new keyring instance. Keyring without store
+// only contains the key from config.
+func newKeyring(s store.Store, c *config.JWT) *keyring {
+	return &keyring{store: s, config: c}
+}
+
+// set replaces keys of the keyring with the signing key and verification keys.
+func (r *keyring) set(signing *key, keys ...*key) {
+	r.signing = signing
+	r.keys = map[string]*key{signing.id: signing}
+	r.ids = []string{signing.id}
+	for _, k := range keys {
+		if _, ok := r.keys[k.id]; !ok {
+			r.keys[k.id] = k
+			r.ids = append(r.ids, k.id)
+		}
+	}
+	r.loadedAt = time.Now()
+}
+
+// load loads keys from the store, retired keys past the grace period are skipped.
+func (r *keyring) load(ctx context.Context) error {
+	if r.store == nil {
+		material, err := loadKeyMaterial(r.config)
+		if err != nil {
+			return err
+		}
+		k, err := newKey(r.config.KeyID, r.config.Algorithm, material)
+		if err != nil {
+			return err
+		}
+		r.set(k)
+		return nil
+	}
+
+	sks, err := r.store.SigningKeys().GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	if len(sks) == 0 {
+		if sks, err = r.bootstrap(ctx); err != nil {
+			return err
+		}
+	}
+
+	var signing *key
+	keys := []*key{}
+	expired := time.Now().Add(-r.config.KeyGracePeriod)
+	for _, sk := range sks {
+		if sk.Status == model.SigningKeyRetired && sk.RetiredAt != nil && sk.RetiredAt.Before(expired) {
+			continue
+		}
+		material, err := decryptKeyMaterial(r.config, sk)
+		if err != nil {
+			logger.Get().Error("couldn't decrypt JWT signing key", zap.String("kid", sk.ID), zap.Error(err))
+			continue
+		}
+		k, err := newKey(sk.ID, sk.Algorithm, material)
+		if err != nil {
+			logger.Get().Error("couldn't load JWT signing key", zap.String("kid", sk.ID), zap.Error(err))
+			continue
+		}
+		if sk.Status == model.SigningKeyActive {
+			signing = k
+		} else {
+			keys = append(keys, k)
+		}
+	}
+	if signing == nil {
+		return errors.New("there is no active JWT signing key")
+	}
+	r.set(signing, keys...)
+
+	return nil
+}
+
+// bootstrap persists the key from config encrypted as the active key and returns all
+// keys.
+func (r *keyring) bootstrap(ctx context.Context) ([]model.SigningKey, error) {
+	material, err := loadKeyMaterial(r.config)
+	if err != nil {
+		return nil, err
+	}
+	k, err := newKey(r.config.KeyID, r.config.Algorithm, material)
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := encryptKeyMaterial(r.config, k.id, r.config.Algorithm, material)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	_, createErr := r.store.SigningKeys().Create(ctx, model.SigningKey{
+		ID:          k.id,
+		Algorithm:   r.config.Algorithm,
+		Material:    encrypted,
+		Status:      model.SigningKeyActive,
+		ActivatedAt: &now,
+	})
+
+	// Other instance could have bootstrapped the keyring concurrently.
+	sks, err := r.store.SigningKeys().GetAll(ctx)
+	if err != nil {
+		return nil, err
+	} else if len(sks) == 0 {
+		return nil, createErr
+	}
+
+	return sks, nil
+}
+
+// ensure loads keys if they weren't loaded yet or are outdated. Outdated keys are
+// kept if they couldn't be reloaded.
+func (r *keyring) ensure(ctx context.Context) error {
+	r.mu.RLock()
+	fresh := r.isFresh()
+	r.mu.RUnlock()
+	if fresh {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.isFresh() {
+		return nil
+	}
+
+	if err := r.load(ctx); err != nil {
+		if r.signing == nil {
+			return err
+		}
+		logger.Get().Error("couldn't reload JWT keyring", zap.Error(err))
+		r.loadedAt = time.Now()
+	}
+
+	return nil
+}
+
+// isFresh reports whether loaded keys can be used without reloading.
+func (r *keyring) isFresh() bool {
+	if r.signing == nil {
+		return false
+	}
+
+	return r.store == nil || time.Since(r.loadedAt) < r.config.KeyringRefresh
+}
+
+// invalidate makes keyring reload keys on the next use.
+func (r *keyring) invalidate() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.loadedAt = time.Time{}
+}
+
+// signingKey returns the key to sign JSON Web Tokens with.
+func (r *keyring) signingKey(ctx context.Context) (*key, error) {
+	if err := r.ensure(ctx); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.signing, nil
+}
+
+// verificationKey returns the key with specific ID to verify JSON Web Tokens with.
+func (r *keyring) verificationKey(ctx context.Context, id string) (*key, error) {
+	if err := r.ensure(ctx); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	k, ok := r.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown JWT key ID: %v", id)
+	}
+
+	return k, nil
+}
+
+// publicKeys returns public JSON Web Keys of all asymmetric keys.
+func (r *keyring) publicKeys(ctx context.Context) ([]model.JWK, error) {
+	if err := r.ensure(ctx); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	jwks := []model.JWK{}
+	for _, id := range r.ids {
+		if jwk, ok := r.keys[id].jwk(); ok {
+			jwks = append(jwks, jwk)
+		}
+	}
+
+	return jwks, nil
+}
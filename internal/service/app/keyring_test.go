@@ -0,0 +1,156 @@
+package app
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imarrche/jwt-auth-example/internal/config"
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	mock_store "github.com/imarrche/jwt-auth-example/internal/store/mocks"
+)
+
+// testKeyEncryptionKey is the key signing keys are encrypted with in tests.
+var testKeyEncryptionKey = base64.StdEncoding.EncodeToString(make([]byte, 32))
+
+// withKeyEncryptionKey sets signing key encryption key for the test.
+func withKeyEncryptionKey(t *testing.T) {
+	c := config.Get().JWT
+	key := c.KeyEncryptionKey
+	c.KeyEncryptionKey = testKeyEncryptionKey
+	t.Cleanup(func() { c.KeyEncryptionKey = key })
+}
+
+// testSigningKey returns the signing key with material encrypted with the config's key.
+func testSigningKey(t *testing.T, c *config.JWT, sk model.SigningKey) model.SigningKey {
+	encrypted, err := encryptKeyMaterial(c, sk.ID, sk.Algorithm, []byte(sk.Material))
+	require.NoError(t, err)
+	sk.Material = encrypted
+
+	return sk
+}
+
+func TestKeyring_load(t *testing.T) {
+	recently := time.Now().Add(-time.Hour)
+	longAgo := time.Now().Add(-48 * time.Hour)
+	jc := &config.JWT{
+		Algorithm: "HS256", Secret: "secret", KeyEncryptionKey: testKeyEncryptionKey,
+		KeyGracePeriod: 24 * time.Hour, KeyringRefresh: time.Minute,
+	}
+	key := func(sk model.SigningKey) model.SigningKey { return testSigningKey(t, jc, sk) }
+	tampered := key(model.SigningKey{ID: "other", Algorithm: "HS256", Material: "5"})
+	tampered.ID, tampered.Status = "tampered", model.SigningKeyPending
+
+	testcases := []struct {
+		name         string
+		mock         func(*gomock.Controller, *mock_store.MockStore)
+		expSigning   string
+		expVerifying []string
+		expRejected  []string
+		expLoadError bool
+	}{
+		{
+			name: "keys are loaded from store",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				skr := mock_store.NewMockSigningKeyRepo(c)
+				skr.EXPECT().GetAll(gomock.Any()).Return([]model.SigningKey{
+					key(model.SigningKey{
+						ID: "expired", Algorithm: "HS256", Material: "1",
+						Status: model.SigningKeyRetired, RetiredAt: &longAgo,
+					}),
+					key(model.SigningKey{
+						ID: "retired", Algorithm: "HS256", Material: "2",
+						Status: model.SigningKeyRetired, RetiredAt: &recently,
+					}),
+					key(model.SigningKey{
+						ID: "active", Algorithm: "HS256", Material: "3", Status: model.SigningKeyActive,
+					}),
+					key(model.SigningKey{
+						ID: "pending", Algorithm: "HS256", Material: "4", Status: model.SigningKeyPending,
+					}),
+					tampered,
+					{ID: "plaintext", Algorithm: "HS256", Material: "6", Status: model.SigningKeyPending},
+				}, nil)
+				s.EXPECT().SigningKeys().Return(skr)
+			},
+			expSigning:   "active",
+			expVerifying: []string{"active", "pending", "plaintext", "retired"},
+			expRejected:  []string{"expired", "tampered", "unknown"},
+			expLoadError: false,
+		},
+		{
+			name: "key from config is bootstrapped",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				skr := mock_store.NewMockSigningKeyRepo(c)
+				gomock.InOrder(
+					skr.EXPECT().GetAll(gomock.Any()).Return([]model.SigningKey{}, nil),
+					skr.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+						func(_ context.Context, sk model.SigningKey) (model.SigningKey, error) {
+							assert.Equal(t, model.SigningKeyActive, sk.Status)
+							assert.NotEqual(t, "secret", sk.Material)
+							material, err := decryptKeyMaterial(jc, sk)
+							assert.NoError(t, err)
+							assert.Equal(t, "secret", string(material))
+							return sk, nil
+						},
+					),
+					skr.EXPECT().GetAll(gomock.Any()).Return([]model.SigningKey{
+						key(model.SigningKey{
+							ID: "default", Algorithm: "HS256", Material: "secret", Status: model.SigningKeyActive,
+						}),
+					}, nil),
+				)
+				s.EXPECT().SigningKeys().Return(skr).Times(3)
+			},
+			expSigning:   "default",
+			expVerifying: []string{"default"},
+			expLoadError: false,
+		},
+		{
+			name: "there is no active key",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				skr := mock_store.NewMockSigningKeyRepo(c)
+				skr.EXPECT().GetAll(gomock.Any()).Return([]model.SigningKey{
+					key(model.SigningKey{
+						ID: "pending", Algorithm: "HS256", Material: "1", Status: model.SigningKeyPending,
+					}),
+				}, nil)
+				s.EXPECT().SigningKeys().Return(skr)
+			},
+			expLoadError: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			store := mock_store.NewMockStore(c)
+			tc.mock(c, store)
+			r := newKeyring(store, jc)
+
+			k, err := r.signingKey(context.Background())
+			if tc.expLoadError {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expSigning, k.id)
+			for _, id := range tc.expVerifying {
+				_, err := r.verificationKey(context.Background(), id)
+				assert.NoError(t, err)
+			}
+			for _, id := range tc.expRejected {
+				_, err := r.verificationKey(context.Background(), id)
+				assert.Error(t, err)
+			}
+		})
+	}
+}
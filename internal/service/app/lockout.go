@@ -0,0 +1,74 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/imarrche/jwt-auth-example/internal/config"
+	"github.com/imarrche/jwt-auth-example/internal/service"
+)
+
+// accountThrottleKey returns login throttle key of the user's account.
+func accountThrottleKey(userID int) string { return fmt.Sprintf("user:%d", userID) }
+
+// ipThrottleKey returns login throttle key of the client IP.
+func ipThrottleKey(ip string) string { return "ip:" + ip }
+
+// failureDelay returns how long to wait after the last of failures before the next
+// attempt. The delay doubles with every failure and never exceeds the lockout duration.
+func failureDelay(c *config.Lockout, failures int) time.Duration {
+	if failures == 0 {
+		return 0
+	}
+
+	delay := c.Delay
+	for i := 1; i < failures && delay < c.Duration; i++ {
+		delay *= 2
+	}
+	if delay > c.Duration {
+		return c.Duration
+	}
+
+	return delay
+}
+
+// checkThrottle returns lockedErr if the login throttle with specific key is locked
+// and service.ErrTooManyAttempts if the delay after the last failure hasn't passed.
+func (s *authService) checkThrottle(ctx context.Context, key string, lockedErr error) error {
+	t, err := s.store.LoginThrottles().Get(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if t.LockedUntil != nil && now.Before(*t.LockedUntil) {
+		return lockedErr
+	}
+	if now.Before(t.LastFailureAt.Add(failureDelay(config.Get().Lockout, t.Failures))) {
+		return service.ErrTooManyAttempts
+	}
+
+	return nil
+}
+
+// registerFailure registers a failed sign in attempt for the login throttle with
+// specific key and locks it once max failures is reached.
+func (s *authService) registerFailure(ctx context.Context, key string, maxFailures int) error {
+	c := config.Get().Lockout
+	t, err := s.store.LoginThrottles().RegisterFailure(ctx, key, time.Now().Add(-c.Window))
+	if err != nil {
+		return err
+	}
+	if t.Failures < maxFailures {
+		return nil
+	}
+
+	return s.store.LoginThrottles().Lock(ctx, key, time.Now().Add(c.Duration))
+}
+
+// purgeLoginThrottles deletes login throttles which are neither locked nor have
+// recent failures.
+func (s *authService) purgeLoginThrottles(ctx context.Context) error {
+	return s.store.LoginThrottles().DeleteStale(ctx, time.Now().Add(-config.Get().Lockout.Window))
+}
@@ -0,0 +1,31 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imarrche/jwt-auth-example/internal/config"
+)
+
+func TestFailureDelay(t *testing.T) {
+	c := &config.Lockout{Delay: time.Second, Duration: 10 * time.Second}
+
+	testcases := []struct {
+		name     string
+		failures int
+		expDelay time.Duration
+	}{
+		{name: "no failures", failures: 0, expDelay: 0},
+		{name: "first failure", failures: 1, expDelay: time.Second},
+		{name: "delay doubles", failures: 3, expDelay: 4 * time.Second},
+		{name: "delay is capped", failures: 10, expDelay: 10 * time.Second},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expDelay, failureDelay(c, tc.failures))
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package app
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/imarrche/jwt-auth-example/internal/config"
+	"github.com/imarrche/jwt-auth-example/internal/mail"
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/service"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// SendMagicLink sends a single use sign in link to the user with specific email and
+// returns the device secret the link is bound to, it must be presented along with the
+// link's token. Unknown emails are silently ignored, the device secret is returned
+// anyway and the link is sent in the background, so registered emails can't be found
+// out.
+func (s *authService) SendMagicLink(ctx context.Context, email string) (string, error) {
+	device, deviceHash, err := newSecretToken()
+	if err != nil {
+		return "", err
+	}
+
+	u, err := s.store.Users().GetByEmail(ctx, email)
+	if errors.Is(err, store.ErrNotFound) {
+		return device, nil
+	} else if err != nil {
+		return "", err
+	}
+	if u.DisabledAt != nil {
+		return device, nil
+	}
+
+	s.goBackground("couldn't send magic link email", func(ctx context.Context) error {
+		return s.sendMagicLinkEmail(ctx, u, deviceHash)
+	})
+
+	return device, nil
+}
+
+// sendMagicLinkEmail sends a single use sign in link bound to the device to the user.
+func (s *authService) sendMagicLinkEmail(
+	ctx context.Context, u model.User, deviceHash string,
+) error {
+	token, hash, err := newSecretToken()
+	if err != nil {
+		return err
+	}
+	c := config.Get().Account
+	_, err = s.store.MagicLinkTokens().Create(ctx, model.MagicLinkToken{
+		Hash:       hash,
+		UserID:     u.ID,
+		DeviceHash: deviceHash,
+		ExpiresAt:  time.Now().Add(c.MagicLinkTTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(mail.Message{
+		To:      u.Email,
+		Subject: "Sign in link",
+		Body: fmt.Sprintf(
+			"To sign in follow the link: %s/magic-link?token=%s\n"+
+				"The link expires in %s and works only in the browser it's requested from. "+
+				"If you didn't request it, ignore this email.",
+			c.URL, token, c.MagicLinkTTL,
+		),
+	})
+}
+
+// ConsumeMagicLink signs in the user the magic link token was issued for if the device
+// secret it's bound to is presented. The token can be used only once, following it
+// proves the email, so the email is marked as verified.
+func (s *authService) ConsumeMagicLink(
+	ctx context.Context, token, device string,
+) (model.SignInResult, error) {
+	t, err := s.store.MagicLinkTokens().GetByHash(ctx, hashSecretToken(token))
+	if errors.Is(err, store.ErrNotFound) {
+		return model.SignInResult{}, service.ErrInvalidOneTimeToken
+	} else if err != nil {
+		return model.SignInResult{}, err
+	}
+	if t.UsedAt != nil || time.Now().After(t.ExpiresAt) {
+		return model.SignInResult{}, service.ErrInvalidOneTimeToken
+	}
+	// The token isn't used up by other devices, so whoever intercepts the link can't
+	// invalidate it.
+	deviceHash := hashSecretToken(device)
+	if subtle.ConstantTimeCompare([]byte(deviceHash), []byte(t.DeviceHash)) != 1 {
+		return model.SignInResult{}, service.ErrInvalidOneTimeToken
+	}
+	if err := s.store.MagicLinkTokens().MarkUsed(ctx, t.Hash); isTokenGone(err) {
+		return model.SignInResult{}, service.ErrInvalidOneTimeToken
+	} else if err != nil {
+		return model.SignInResult{}, err
+	}
+
+	u, err := s.store.Users().GetByID(ctx, t.UserID)
+	if err != nil {
+		return model.SignInResult{}, serviceError(err)
+	}
+	if u, err = s.markEmailVerified(ctx, u); err != nil {
+		return model.SignInResult{}, err
+	}
+
+	return s.signInResult(ctx, u)
+}
+
+// markEmailVerified marks the email of the user as verified unless it's already
+// verified and returns the user.
+func (s *authService) markEmailVerified(ctx context.Context, u model.User) (model.User, error) {
+	if u.EmailVerifiedAt != nil {
+		return u, nil
+	}
+
+	now := time.Now()
+	if err := s.store.Users().SetEmailVerifiedAt(ctx, u.ID, &now); err != nil {
+		return model.User{}, serviceError(err)
+	}
+	u.EmailVerifiedAt = &now
+
+	return u, nil
+}
+
+// purgeMagicLinkTokens deletes magic link tokens which have already expired.
+func (s *authService) purgeMagicLinkTokens(ctx context.Context) error {
+	return s.store.MagicLinkTokens().DeleteExpired(ctx)
+}
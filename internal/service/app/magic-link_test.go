@@ -0,0 +1,268 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imarrche/jwt-auth-example/internal/mail"
+	mock_mail "github.com/imarrche/jwt-auth-example/internal/mail/mocks"
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/service"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+	mock_store "github.com/imarrche/jwt-auth-example/internal/store/mocks"
+)
+
+func TestAuthService_SendMagicLink(t *testing.T) {
+	user := model.User{ID: 1, Email: "user1@test.com"}
+	disabledAt := time.Now()
+
+	testcases := []struct {
+		name  string
+		mock  func(*gomock.Controller, *mock_store.MockStore, *mock_mail.MockMailer, *string)
+		email string
+	}{
+		{
+			name: "magic link is sent",
+			mock: func(
+				c *gomock.Controller, s *mock_store.MockStore, m *mock_mail.MockMailer, device *string,
+			) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByEmail(gomock.Any(), user.Email).Return(user, nil)
+				s.EXPECT().Users().Return(ur)
+
+				var token model.MagicLinkToken
+				mltr := mock_store.NewMockMagicLinkTokenRepo(c)
+				mltr.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, t model.MagicLinkToken) (model.MagicLinkToken, error) {
+						token = t
+						return t, nil
+					},
+				)
+				s.EXPECT().MagicLinkTokens().Return(mltr)
+
+				m.EXPECT().Send(gomock.Any()).DoAndReturn(func(msg mail.Message) error {
+					assert.Equal(t, user.Email, msg.To)
+					assert.Equal(t, user.ID, token.UserID)
+					raw := msg.Body[strings.Index(msg.Body, "token=")+len("token="):]
+					assert.Equal(t, token.Hash, hashSecretToken(strings.Fields(raw)[0]))
+					*device = token.DeviceHash
+					return nil
+				})
+			},
+			email: user.Email,
+		},
+		{
+			name: "mail error isn't returned",
+			mock: func(
+				c *gomock.Controller, s *mock_store.MockStore, m *mock_mail.MockMailer, device *string,
+			) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByEmail(gomock.Any(), user.Email).Return(user, nil)
+				s.EXPECT().Users().Return(ur)
+				mltr := mock_store.NewMockMagicLinkTokenRepo(c)
+				mltr.EXPECT().Create(gomock.Any(), gomock.Any()).Return(model.MagicLinkToken{}, nil)
+				s.EXPECT().MagicLinkTokens().Return(mltr)
+				m.EXPECT().Send(gomock.Any()).Return(errors.New("smtp is down"))
+			},
+			email: user.Email,
+		},
+		{
+			name: "unknown email is ignored",
+			mock: func(
+				c *gomock.Controller, s *mock_store.MockStore, m *mock_mail.MockMailer, device *string,
+			) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByEmail(gomock.Any(), "unknown@test.com").Return(
+					model.User{}, store.ErrNotFound,
+				)
+				s.EXPECT().Users().Return(ur)
+			},
+			email: "unknown@test.com",
+		},
+		{
+			name: "disabled user is ignored",
+			mock: func(
+				c *gomock.Controller, s *mock_store.MockStore, m *mock_mail.MockMailer, device *string,
+			) {
+				u := user
+				u.DisabledAt = &disabledAt
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByEmail(gomock.Any(), u.Email).Return(u, nil)
+				s.EXPECT().Users().Return(ur)
+			},
+			email: user.Email,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var deviceHash string
+			store := mock_store.NewMockStore(c)
+			mailer := mock_mail.NewMockMailer(c)
+			tc.mock(c, store, mailer, &deviceHash)
+			s := newAuthService(store, testKeyring(), mailer, nil)
+			device, err := s.SendMagicLink(context.Background(), tc.email)
+			s.background.Wait()
+
+			assert.NoError(t, err)
+			assert.NotEmpty(t, device)
+			if deviceHash != "" {
+				assert.Equal(t, deviceHash, hashSecretToken(device))
+			}
+		})
+	}
+}
+
+func TestAuthService_ConsumeMagicLink(t *testing.T) {
+	token, device := "token1", "device1"
+	usedAt, verifiedAt := time.Now(), time.Now()
+	valid := model.MagicLinkToken{
+		Hash:       hashSecretToken(token),
+		UserID:     1,
+		DeviceHash: hashSecretToken(device),
+		ExpiresAt:  time.Now().Add(time.Hour),
+	}
+
+	testcases := []struct {
+		name     string
+		mock     func(*gomock.Controller, *mock_store.MockStore)
+		device   string
+		expMFA   bool
+		expError error
+	}{
+		{
+			name: "user is signed in and email is verified",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				mltr := mock_store.NewMockMagicLinkTokenRepo(c)
+				mltr.EXPECT().GetByHash(gomock.Any(), valid.Hash).Return(valid, nil)
+				mltr.EXPECT().MarkUsed(gomock.Any(), valid.Hash).Return(nil)
+				s.EXPECT().MagicLinkTokens().Return(mltr).Times(2)
+
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), 1).Return(model.User{ID: 1}, nil)
+				ur.EXPECT().SetEmailVerifiedAt(gomock.Any(), 1, gomock.Not(gomock.Nil())).Return(nil)
+				s.EXPECT().Users().Return(ur).Times(2)
+				s.EXPECT().TOTPSecrets().Return(noTOTP(c))
+				rtr := mock_store.NewMockRefreshTokenRepo(c)
+				rtr.EXPECT().Create(gomock.Any(), gomock.Any()).Return(model.RefreshToken{}, nil)
+				s.EXPECT().RefreshTokens().Return(rtr)
+				s.EXPECT().Roles().Return(noRoles(c))
+			},
+			device: device,
+		},
+		{
+			name: "MFA is required",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				mltr := mock_store.NewMockMagicLinkTokenRepo(c)
+				mltr.EXPECT().GetByHash(gomock.Any(), valid.Hash).Return(valid, nil)
+				mltr.EXPECT().MarkUsed(gomock.Any(), valid.Hash).Return(nil)
+				s.EXPECT().MagicLinkTokens().Return(mltr).Times(2)
+
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), 1).Return(
+					model.User{ID: 1, EmailVerifiedAt: &verifiedAt}, nil,
+				)
+				s.EXPECT().Users().Return(ur)
+				tsr := mock_store.NewMockTOTPSecretRepo(c)
+				tsr.EXPECT().GetByUserID(gomock.Any(), 1).Return(
+					model.TOTPSecret{UserID: 1, ConfirmedAt: &verifiedAt}, nil,
+				)
+				s.EXPECT().TOTPSecrets().Return(tsr)
+			},
+			device: device,
+			expMFA: true,
+		},
+		{
+			name: "device doesn't match",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				mltr := mock_store.NewMockMagicLinkTokenRepo(c)
+				mltr.EXPECT().GetByHash(gomock.Any(), valid.Hash).Return(valid, nil)
+				s.EXPECT().MagicLinkTokens().Return(mltr)
+			},
+			device:   "device2",
+			expError: service.ErrInvalidOneTimeToken,
+		},
+		{
+			name: "token is used",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				used := valid
+				used.UsedAt = &usedAt
+				mltr := mock_store.NewMockMagicLinkTokenRepo(c)
+				mltr.EXPECT().GetByHash(gomock.Any(), valid.Hash).Return(used, nil)
+				s.EXPECT().MagicLinkTokens().Return(mltr)
+			},
+			device:   device,
+			expError: service.ErrInvalidOneTimeToken,
+		},
+		{
+			name: "token is expired",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				expired := valid
+				expired.ExpiresAt = time.Now().Add(-time.Hour)
+				mltr := mock_store.NewMockMagicLinkTokenRepo(c)
+				mltr.EXPECT().GetByHash(gomock.Any(), valid.Hash).Return(expired, nil)
+				s.EXPECT().MagicLinkTokens().Return(mltr)
+			},
+			device:   device,
+			expError: service.ErrInvalidOneTimeToken,
+		},
+		{
+			name: "token is used concurrently",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				mltr := mock_store.NewMockMagicLinkTokenRepo(c)
+				mltr.EXPECT().GetByHash(gomock.Any(), valid.Hash).Return(valid, nil)
+				mltr.EXPECT().MarkUsed(gomock.Any(), valid.Hash).Return(store.ErrTokenIsUsed)
+				s.EXPECT().MagicLinkTokens().Return(mltr).Times(2)
+			},
+			device:   device,
+			expError: service.ErrInvalidOneTimeToken,
+		},
+		{
+			name: "token is not found",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				mltr := mock_store.NewMockMagicLinkTokenRepo(c)
+				mltr.EXPECT().GetByHash(gomock.Any(), valid.Hash).Return(
+					model.MagicLinkToken{}, store.ErrNotFound,
+				)
+				s.EXPECT().MagicLinkTokens().Return(mltr)
+			},
+			device:   device,
+			expError: service.ErrInvalidOneTimeToken,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			store := mock_store.NewMockStore(c)
+			tc.mock(c, store)
+			result, err := newAuthService(store, testKeyring(), nil, nil).ConsumeMagicLink(
+				context.Background(), token, tc.device,
+			)
+
+			if tc.expError != nil {
+				assert.True(t, errors.Is(err, tc.expError), err)
+				return
+			}
+			assert.NoError(t, err)
+			if tc.expMFA {
+				assert.NotEmpty(t, result.MFAToken)
+				assert.Empty(t, result.AccessToken)
+			} else {
+				assert.NotEmpty(t, result.AccessToken)
+				assert.NotEmpty(t, result.RefreshToken)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package app
+
+import (
+	"context"
+	"errors"
+
+	"github.com/imarrche/jwt-auth-example/internal/config"
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/service"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// mfaService implements multi-factor authentication business logic.
+type mfaService struct {
+	store store.Store
+	auth  *authService
+}
+
+// newMFAService creates and returns a new mfaService instance.
+func newMFAService(s store.Store, auth *authService) *mfaService {
+	return &mfaService{store: s, auth: auth}
+}
+
+// EnrollTOTP generates a new TOTP secret for the user. MFA is enabled once the secret
+// is confirmed with ConfirmTOTP, enrolling again replaces the unconfirmed secret.
+func (s *mfaService) EnrollTOTP(ctx context.Context, userID int) (model.TOTPEnrollment, error) {
+	u, err := s.store.Users().GetByID(ctx, userID)
+	if err != nil {
+		return model.TOTPEnrollment{}, serviceError(err)
+	}
+	existing, err := s.store.TOTPSecrets().GetByUserID(ctx, userID)
+	if err == nil && existing.ConfirmedAt != nil {
+		return model.TOTPEnrollment{}, service.ErrMFAEnabled
+	} else if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return model.TOTPEnrollment{}, err
+	}
+
+	secret, err := newTOTPSecret()
+	if err != nil {
+		return model.TOTPEnrollment{}, err
+	}
+	encrypted, err := encryptTOTPSecret(userID, secret)
+	if err != nil {
+		return model.TOTPEnrollment{}, err
+	}
+	err = s.store.TOTPSecrets().Save(ctx, model.TOTPSecret{UserID: userID, Secret: encrypted})
+	if err != nil {
+		return model.TOTPEnrollment{}, serviceError(err)
+	}
+
+	return model.TOTPEnrollment{
+		Secret: totpEncoding.EncodeToString(secret),
+		URI:    totpURI(config.Get().MFA.Issuer, u.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTP enables MFA for the user if the code is valid for the enrolled secret.
+// A new set of recovery codes is returned, they are shown to the user only once.
+func (s *mfaService) ConfirmTOTP(ctx context.Context, userID int, code string) ([]string, error) {
+	secret, err := s.store.TOTPSecrets().GetByUserID(ctx, userID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, service.ErrMFANotEnabled
+	} else if err != nil {
+		return nil, err
+	}
+	if secret.ConfirmedAt != nil {
+		return nil, service.ErrMFAEnabled
+	}
+	if err := s.auth.checkTOTPCode(ctx, secret, code); err != nil {
+		return nil, err
+	}
+
+	// Codes are useless until the secret is confirmed, so they're saved first.
+	codes, err := s.replaceRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.TOTPSecrets().Confirm(ctx, userID); err != nil {
+		return nil, serviceError(err)
+	}
+
+	return codes, nil
+}
+
+// DisableTOTP disables MFA for the user if the TOTP or recovery code is valid, the
+// secret and recovery codes are deleted.
+func (s *mfaService) DisableTOTP(ctx context.Context, userID int, code string) error {
+	secret, err := s.confirmedSecret(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.auth.checkMFACode(ctx, secret, code); err != nil {
+		return err
+	}
+
+	if err := s.store.TOTPSecrets().DeleteByUserID(ctx, userID); err != nil {
+		return serviceError(err)
+	}
+
+	return s.store.RecoveryCodes().DeleteByUserID(ctx, userID)
+}
+
+// RegenerateRecoveryCodes returns a new set of recovery codes of the user if the TOTP
+// or recovery code is valid, the old set is invalidated.
+func (s *mfaService) RegenerateRecoveryCodes(
+	ctx context.Context, userID int, code string,
+) ([]string, error) {
+	secret, err := s.confirmedSecret(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.auth.checkMFACode(ctx, secret, code); err != nil {
+		return nil, err
+	}
+
+	return s.replaceRecoveryCodes(ctx, userID)
+}
+
+// Status returns MFA status of the user along with the number of unused recovery codes.
+func (s *mfaService) Status(ctx context.Context, userID int) (model.MFAStatus, error) {
+	enabled, err := s.auth.mfaEnabled(ctx, userID)
+	if err != nil {
+		return model.MFAStatus{}, err
+	}
+	if !enabled {
+		return model.MFAStatus{}, nil
+	}
+	count, err := s.store.RecoveryCodes().CountUnused(ctx, userID)
+	if err != nil {
+		return model.MFAStatus{}, err
+	}
+
+	return model.MFAStatus{TOTPEnabled: true, RecoveryCodes: count}, nil
+}
+
+// confirmedSecret returns the TOTP secret of the user, service.ErrMFANotEnabled is
+// returned if the user has no confirmed secret.
+func (s *mfaService) confirmedSecret(ctx context.Context, userID int) (model.TOTPSecret, error) {
+	secret, err := s.store.TOTPSecrets().GetByUserID(ctx, userID)
+	if errors.Is(err, store.ErrNotFound) {
+		return model.TOTPSecret{}, service.ErrMFANotEnabled
+	} else if err != nil {
+		return model.TOTPSecret{}, err
+	}
+	if secret.ConfirmedAt == nil {
+		return model.TOTPSecret{}, service.ErrMFANotEnabled
+	}
+
+	return secret, nil
+}
+
+// replaceRecoveryCodes generates a new set of recovery codes of the user, the old set is
+// invalidated.
+func (s *mfaService) replaceRecoveryCodes(ctx context.Context, userID int) ([]string, error) {
+	codes, hashes, err := newRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.RecoveryCodes().Replace(ctx, userID, hashes); err != nil {
+		return nil, serviceError(err)
+	}
+
+	return codes, nil
+}
@@ -0,0 +1,362 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/service"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+	mock_store "github.com/imarrche/jwt-auth-example/internal/store/mocks"
+)
+
+// newTestMFAService returns mfaService with mocked store.
+func newTestMFAService(s store.Store) *mfaService {
+	return newMFAService(s, newAuthService(s, testKeyring(), nil, nil))
+}
+
+func TestMFAService_EnrollTOTP(t *testing.T) {
+	withTOTPKey(t)
+
+	testcases := []struct {
+		name     string
+		mock     func(*gomock.Controller, *mock_store.MockStore)
+		expError error
+	}{
+		{
+			name: "secret is generated",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), 1).Return(model.User{ID: 1, Email: "user1@test.com"}, nil)
+				s.EXPECT().Users().Return(ur)
+				tsr := mock_store.NewMockTOTPSecretRepo(c)
+				tsr.EXPECT().GetByUserID(gomock.Any(), 1).Return(model.TOTPSecret{}, store.ErrNotFound)
+				tsr.EXPECT().Save(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, secret model.TOTPSecret) error {
+						assert.Equal(t, 1, secret.UserID)
+						assert.Nil(t, secret.ConfirmedAt)
+						return nil
+					},
+				)
+				s.EXPECT().TOTPSecrets().Return(tsr).Times(2)
+			},
+		},
+		{
+			name: "MFA is already enabled",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), 1).Return(model.User{ID: 1}, nil)
+				s.EXPECT().Users().Return(ur)
+				tsr := mock_store.NewMockTOTPSecretRepo(c)
+				tsr.EXPECT().GetByUserID(gomock.Any(), 1).Return(testTOTPSecret(t, 1, true), nil)
+				s.EXPECT().TOTPSecrets().Return(tsr)
+			},
+			expError: service.ErrMFAEnabled,
+		},
+		{
+			name: "user is not found",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), 1).Return(model.User{}, store.ErrNotFound)
+				s.EXPECT().Users().Return(ur)
+			},
+			expError: service.ErrNotFound,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			store := mock_store.NewMockStore(c)
+			tc.mock(c, store)
+			e, err := newTestMFAService(store).EnrollTOTP(context.Background(), 1)
+
+			if tc.expError == nil {
+				require.NoError(t, err)
+				u, err := url.Parse(e.URI)
+				require.NoError(t, err)
+				assert.Equal(t, e.Secret, u.Query().Get("secret"))
+				assert.Equal(t, "/jwt-auth-example:user1@test.com", u.Path)
+			} else {
+				assert.Equal(t, tc.expError, err)
+			}
+		})
+	}
+}
+
+func TestMFAService_ConfirmTOTP(t *testing.T) {
+	withTOTPKey(t)
+
+	testcases := []struct {
+		name     string
+		mock     func(*gomock.Controller, *mock_store.MockStore)
+		code     string
+		expError error
+	}{
+		{
+			name: "MFA is enabled",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				tsr := mock_store.NewMockTOTPSecretRepo(c)
+				tsr.EXPECT().GetByUserID(gomock.Any(), 1).Return(testTOTPSecret(t, 1, false), nil)
+				tsr.EXPECT().MarkUsed(gomock.Any(), 1, totpStep(time.Now())).Return(nil)
+				tsr.EXPECT().Confirm(gomock.Any(), 1).Return(nil)
+				s.EXPECT().TOTPSecrets().Return(tsr).Times(3)
+				s.EXPECT().LoginThrottles().Return(noThrottle(c)).Times(2)
+				rcr := mock_store.NewMockRecoveryCodeRepo(c)
+				rcr.EXPECT().Replace(gomock.Any(), 1, gomock.Len(recoveryCodeCount)).Return(nil)
+				s.EXPECT().RecoveryCodes().Return(rcr)
+			},
+			code: totpCode(rfcTOTPSecret, totpStep(time.Now())),
+		},
+		{
+			name: "code is invalid",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				tsr := mock_store.NewMockTOTPSecretRepo(c)
+				tsr.EXPECT().GetByUserID(gomock.Any(), 1).Return(testTOTPSecret(t, 1, false), nil)
+				s.EXPECT().TOTPSecrets().Return(tsr)
+				ltr := mock_store.NewMockLoginThrottleRepo(c)
+				ltr.EXPECT().Get(gomock.Any(), accountThrottleKey(1)).Return(model.LoginThrottle{}, nil)
+				ltr.EXPECT().RegisterFailure(gomock.Any(), accountThrottleKey(1), gomock.Any()).Return(
+					model.LoginThrottle{Failures: 1}, nil,
+				)
+				s.EXPECT().LoginThrottles().Return(ltr).Times(2)
+			},
+			code:     totpCode(rfcTOTPSecret, totpStep(time.Now())-5),
+			expError: service.ErrInvalidMFACode,
+		},
+		{
+			name: "code has already been used",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				tsr := mock_store.NewMockTOTPSecretRepo(c)
+				tsr.EXPECT().GetByUserID(gomock.Any(), 1).Return(testTOTPSecret(t, 1, false), nil)
+				tsr.EXPECT().MarkUsed(gomock.Any(), 1, gomock.Any()).Return(store.ErrTokenIsUsed)
+				s.EXPECT().TOTPSecrets().Return(tsr).Times(2)
+				s.EXPECT().LoginThrottles().Return(noThrottle(c))
+			},
+			code:     totpCode(rfcTOTPSecret, totpStep(time.Now())),
+			expError: service.ErrInvalidMFACode,
+		},
+		{
+			name: "MFA is already enabled",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				tsr := mock_store.NewMockTOTPSecretRepo(c)
+				tsr.EXPECT().GetByUserID(gomock.Any(), 1).Return(testTOTPSecret(t, 1, true), nil)
+				s.EXPECT().TOTPSecrets().Return(tsr)
+			},
+			code:     totpCode(rfcTOTPSecret, totpStep(time.Now())),
+			expError: service.ErrMFAEnabled,
+		},
+		{
+			name: "TOTP isn't enrolled",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				tsr := mock_store.NewMockTOTPSecretRepo(c)
+				tsr.EXPECT().GetByUserID(gomock.Any(), 1).Return(model.TOTPSecret{}, store.ErrNotFound)
+				s.EXPECT().TOTPSecrets().Return(tsr)
+			},
+			code:     "123456",
+			expError: service.ErrMFANotEnabled,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			store := mock_store.NewMockStore(c)
+			tc.mock(c, store)
+			codes, err := newTestMFAService(store).ConfirmTOTP(context.Background(), 1, tc.code)
+
+			assert.Equal(t, tc.expError, err)
+			if tc.expError == nil {
+				assert.Len(t, codes, recoveryCodeCount)
+			}
+		})
+	}
+}
+
+func TestMFAService_DisableTOTP(t *testing.T) {
+	withTOTPKey(t)
+
+	testcases := []struct {
+		name     string
+		mock     func(*gomock.Controller, *mock_store.MockStore)
+		expError error
+	}{
+		{
+			name: "MFA is disabled",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				tsr := mock_store.NewMockTOTPSecretRepo(c)
+				tsr.EXPECT().GetByUserID(gomock.Any(), 1).Return(testTOTPSecret(t, 1, true), nil)
+				tsr.EXPECT().MarkUsed(gomock.Any(), 1, gomock.Any()).Return(nil)
+				tsr.EXPECT().DeleteByUserID(gomock.Any(), 1).Return(nil)
+				s.EXPECT().TOTPSecrets().Return(tsr).Times(3)
+				s.EXPECT().LoginThrottles().Return(noThrottle(c)).Times(2)
+				rcr := mock_store.NewMockRecoveryCodeRepo(c)
+				rcr.EXPECT().DeleteByUserID(gomock.Any(), 1).Return(nil)
+				s.EXPECT().RecoveryCodes().Return(rcr)
+			},
+		},
+		{
+			name: "MFA is not enabled",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				tsr := mock_store.NewMockTOTPSecretRepo(c)
+				tsr.EXPECT().GetByUserID(gomock.Any(), 1).Return(testTOTPSecret(t, 1, false), nil)
+				s.EXPECT().TOTPSecrets().Return(tsr)
+			},
+			expError: service.ErrMFANotEnabled,
+		},
+		{
+			name: "store error is returned",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				tsr := mock_store.NewMockTOTPSecretRepo(c)
+				tsr.EXPECT().GetByUserID(gomock.Any(), 1).Return(model.TOTPSecret{}, errConnectionLost)
+				s.EXPECT().TOTPSecrets().Return(tsr)
+			},
+			expError: errConnectionLost,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			store := mock_store.NewMockStore(c)
+			tc.mock(c, store)
+			code := totpCode(rfcTOTPSecret, totpStep(time.Now()))
+			err := newTestMFAService(store).DisableTOTP(context.Background(), 1, code)
+
+			assert.True(t, errors.Is(err, tc.expError), err)
+		})
+	}
+}
+
+func TestMFAService_RegenerateRecoveryCodes(t *testing.T) {
+	withTOTPKey(t)
+
+	testcases := []struct {
+		name     string
+		mock     func(*gomock.Controller, *mock_store.MockStore)
+		code     string
+		expError error
+	}{
+		{
+			name: "codes are regenerated with TOTP code",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				tsr := mock_store.NewMockTOTPSecretRepo(c)
+				tsr.EXPECT().GetByUserID(gomock.Any(), 1).Return(testTOTPSecret(t, 1, true), nil)
+				tsr.EXPECT().MarkUsed(gomock.Any(), 1, gomock.Any()).Return(nil)
+				s.EXPECT().TOTPSecrets().Return(tsr).Times(2)
+				s.EXPECT().LoginThrottles().Return(noThrottle(c)).Times(2)
+				rcr := mock_store.NewMockRecoveryCodeRepo(c)
+				rcr.EXPECT().Replace(gomock.Any(), 1, gomock.Len(recoveryCodeCount)).Return(nil)
+				s.EXPECT().RecoveryCodes().Return(rcr)
+			},
+			code: totpCode(rfcTOTPSecret, totpStep(time.Now())),
+		},
+		{
+			name: "codes are regenerated with recovery code",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				tsr := mock_store.NewMockTOTPSecretRepo(c)
+				tsr.EXPECT().GetByUserID(gomock.Any(), 1).Return(testTOTPSecret(t, 1, true), nil)
+				s.EXPECT().TOTPSecrets().Return(tsr)
+				s.EXPECT().LoginThrottles().Return(noThrottle(c)).Times(2)
+				rcr := mock_store.NewMockRecoveryCodeRepo(c)
+				rcr.EXPECT().Use(gomock.Any(), 1, testRecoveryCodeHash(t, 1, "abcd-efgh")).Return(nil)
+				rcr.EXPECT().Replace(gomock.Any(), 1, gomock.Len(recoveryCodeCount)).Return(nil)
+				s.EXPECT().RecoveryCodes().Return(rcr).Times(2)
+			},
+			code: "abcd-efgh",
+		},
+		{
+			name: "MFA is not enabled",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				tsr := mock_store.NewMockTOTPSecretRepo(c)
+				tsr.EXPECT().GetByUserID(gomock.Any(), 1).Return(model.TOTPSecret{}, store.ErrNotFound)
+				s.EXPECT().TOTPSecrets().Return(tsr)
+			},
+			code:     "123456",
+			expError: service.ErrMFANotEnabled,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			store := mock_store.NewMockStore(c)
+			tc.mock(c, store)
+			codes, err := newTestMFAService(store).RegenerateRecoveryCodes(context.Background(), 1, tc.code)
+
+			assert.Equal(t, tc.expError, err)
+			if tc.expError == nil {
+				assert.Len(t, codes, recoveryCodeCount)
+			}
+		})
+	}
+}
+
+func TestMFAService_Status(t *testing.T) {
+	testcases := []struct {
+		name      string
+		mock      func(*gomock.Controller, *mock_store.MockStore)
+		expStatus model.MFAStatus
+		expError  error
+	}{
+		{
+			name: "MFA is enabled",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				confirmedAt := time.Now()
+				tsr := mock_store.NewMockTOTPSecretRepo(c)
+				tsr.EXPECT().GetByUserID(gomock.Any(), 1).Return(
+					model.TOTPSecret{UserID: 1, ConfirmedAt: &confirmedAt}, nil,
+				)
+				s.EXPECT().TOTPSecrets().Return(tsr)
+				rcr := mock_store.NewMockRecoveryCodeRepo(c)
+				rcr.EXPECT().CountUnused(gomock.Any(), 1).Return(7, nil)
+				s.EXPECT().RecoveryCodes().Return(rcr)
+			},
+			expStatus: model.MFAStatus{TOTPEnabled: true, RecoveryCodes: 7},
+		},
+		{
+			name: "MFA is not enabled",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				s.EXPECT().TOTPSecrets().Return(noTOTP(c))
+			},
+		},
+		{
+			name: "store error is returned",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				tsr := mock_store.NewMockTOTPSecretRepo(c)
+				tsr.EXPECT().GetByUserID(gomock.Any(), 1).Return(model.TOTPSecret{}, errConnectionLost)
+				s.EXPECT().TOTPSecrets().Return(tsr)
+			},
+			expError: errConnectionLost,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			store := mock_store.NewMockStore(c)
+			tc.mock(c, store)
+			status, err := newTestMFAService(store).Status(context.Background(), 1)
+
+			assert.Equal(t, tc.expError, err)
+			assert.Equal(t, tc.expStatus, status)
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/imarrche/jwt-auth-example/internal/config"
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/service"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// mfaEnabled reports whether the user has confirmed TOTP secret, so signing in requires
+// a TOTP code.
+func (s *authService) mfaEnabled(ctx context.Context, userID int) (bool, error) {
+	secret, err := s.store.TOTPSecrets().GetByUserID(ctx, userID)
+	if errors.Is(err, store.ErrNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return secret.ConfirmedAt != nil, nil
+}
+
+// VerifyMFA returns access and refresh JSON Web Tokens if valid MFA JSON Web Token
+// issued by SignIn and valid TOTP or recovery code are provided. MFA token can be used
+// only once.
+func (s *authService) VerifyMFA(ctx context.Context, mfaToken, code string) (string, string, error) {
+	cl, err := s.parseJWT(ctx, mfaToken, "mfa_pending")
+	if err != nil {
+		return "", "", err
+	}
+	userID, err := cl.userID()
+	if err != nil {
+		return "", "", service.ErrInvalidToken.Wrap(err)
+	}
+
+	// The user might have been deleted or disabled since signing in.
+	u, err := s.store.Users().GetByID(ctx, userID)
+	if errors.Is(err, store.ErrNotFound) {
+		return "", "", service.ErrInvalidToken
+	} else if err != nil {
+		return "", "", err
+	}
+	if u.DisabledAt != nil {
+		return "", "", service.ErrAccountDisabled
+	}
+
+	secret, err := s.store.TOTPSecrets().GetByUserID(ctx, userID)
+	if errors.Is(err, store.ErrNotFound) {
+		return "", "", service.ErrMFANotEnabled
+	} else if err != nil {
+		return "", "", err
+	}
+	if secret.ConfirmedAt == nil {
+		return "", "", service.ErrMFANotEnabled
+	}
+	if err := s.checkMFACode(ctx, secret, code); err != nil {
+		return "", "", err
+	}
+
+	// MFA token is consumed atomically, so it can't be used by concurrent requests.
+	err = s.store.RevokedTokens().Use(ctx, model.RevokedToken{
+		ID: cl.Id, ExpiresAt: cl.expiresAt(),
+	})
+	if errors.Is(err, store.ErrTokenIsUsed) {
+		return "", "", service.ErrInvalidToken
+	} else if err != nil {
+		return "", "", err
+	}
+
+	return s.generateJWTPair(ctx, userID)
+}
+
+// checkMFACode checks the code of the user with enabled MFA. Codes of TOTP code length
+// are checked against the TOTP secret, others are checked as recovery codes.
+func (s *authService) checkMFACode(
+	ctx context.Context, secret model.TOTPSecret, code string,
+) error {
+	if len(code) == totpDigits {
+		return s.checkTOTPCode(ctx, secret, code)
+	}
+
+	return s.checkRecoveryCode(ctx, secret.UserID, code)
+}
+
+// checkTOTPCode returns service.ErrInvalidMFACode if the code isn't valid for the TOTP
+// secret or has already been used. Failures are tracked with the account's login
+// throttle, so guessing codes locks the account the same way guessing passwords does.
+func (s *authService) checkTOTPCode(ctx context.Context, secret model.TOTPSecret, code string) error {
+	key := accountThrottleKey(secret.UserID)
+	if err := s.checkThrottle(ctx, key, service.ErrAccountLocked); err != nil {
+		return err
+	}
+
+	plain, err := decryptTOTPSecret(secret.UserID, secret.Secret)
+	if err != nil {
+		return err
+	}
+	step, ok := validateTOTP(plain, code, time.Now())
+	if !ok {
+		if err := s.registerFailure(ctx, key, config.Get().Lockout.MaxFailures); err != nil {
+			return err
+		}
+		return service.ErrInvalidMFACode
+	}
+	if err := s.store.TOTPSecrets().MarkUsed(ctx, secret.UserID, step); isTokenGone(err) {
+		return service.ErrInvalidMFACode
+	} else if err != nil {
+		return err
+	}
+
+	return s.store.LoginThrottles().Delete(ctx, key)
+}
+
+// checkRecoveryCode uses the recovery code of the user, it returns
+// service.ErrInvalidMFACode if the code doesn't exist or has already been used.
+// Failures are tracked the same way TOTP code failures are.
+func (s *authService) checkRecoveryCode(ctx context.Context, userID int, code string) error {
+	key := accountThrottleKey(userID)
+	if err := s.checkThrottle(ctx, key, service.ErrAccountLocked); err != nil {
+		return err
+	}
+
+	hash, err := hashRecoveryCode(userID, code)
+	if err != nil {
+		return err
+	}
+	err = s.store.RecoveryCodes().Use(ctx, userID, hash)
+	if isTokenGone(err) {
+		if err := s.registerFailure(ctx, key, config.Get().Lockout.MaxFailures); err != nil {
+			return err
+		}
+		return service.ErrInvalidMFACode
+	} else if err != nil {
+		return err
+	}
+
+	return s.store.LoginThrottles().Delete(ctx, key)
+}
@@ -0,0 +1,219 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/service"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+	mock_store "github.com/imarrche/jwt-auth-example/internal/store/mocks"
+)
+
+func TestAuthService_VerifyMFA(t *testing.T) {
+	withTOTPKey(t)
+	s := newAuthService(nil, testKeyring(), nil, nil)
+	mfaJWT, err := s.generateJWT(context.Background(), 1, "mfa_pending", "")
+	require.NoError(t, err)
+	accessJWT, err := s.generateJWT(context.Background(), 1, "access", "")
+	require.NoError(t, err)
+	code := totpCode(rfcTOTPSecret, totpStep(time.Now()))
+
+	testcases := []struct {
+		name     string
+		mock     func(*gomock.Controller, *mock_store.MockStore)
+		token    string
+		code     string
+		expError error
+	}{
+		{
+			name: "tokens are issued",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				rvr := notRevoked(c)
+				rvr.EXPECT().Use(gomock.Any(), gomock.Any()).Return(nil)
+				s.EXPECT().RevokedTokens().Return(rvr).Times(2)
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), 1).Return(model.User{ID: 1}, nil)
+				s.EXPECT().Users().Return(ur)
+				tsr := mock_store.NewMockTOTPSecretRepo(c)
+				tsr.EXPECT().GetByUserID(gomock.Any(), 1).Return(testTOTPSecret(t, 1, true), nil)
+				tsr.EXPECT().MarkUsed(gomock.Any(), 1, totpStep(time.Now())).Return(nil)
+				s.EXPECT().TOTPSecrets().Return(tsr).Times(2)
+				s.EXPECT().LoginThrottles().Return(noThrottle(c)).Times(2)
+				rtr := mock_store.NewMockRefreshTokenRepo(c)
+				rtr.EXPECT().Create(gomock.Any(), gomock.Any()).Return(model.RefreshToken{}, nil)
+				s.EXPECT().RefreshTokens().Return(rtr)
+				s.EXPECT().Roles().Return(noRoles(c))
+			},
+			token: mfaJWT,
+			code:  code,
+		},
+		{
+			name: "tokens are issued for recovery code",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				rvr := notRevoked(c)
+				rvr.EXPECT().Use(gomock.Any(), gomock.Any()).Return(nil)
+				s.EXPECT().RevokedTokens().Return(rvr).Times(2)
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), 1).Return(model.User{ID: 1}, nil)
+				s.EXPECT().Users().Return(ur)
+				tsr := mock_store.NewMockTOTPSecretRepo(c)
+				tsr.EXPECT().GetByUserID(gomock.Any(), 1).Return(testTOTPSecret(t, 1, true), nil)
+				s.EXPECT().TOTPSecrets().Return(tsr)
+				rcr := mock_store.NewMockRecoveryCodeRepo(c)
+				rcr.EXPECT().Use(gomock.Any(), 1, testRecoveryCodeHash(t, 1, "abcd-efgh")).Return(nil)
+				s.EXPECT().RecoveryCodes().Return(rcr)
+				s.EXPECT().LoginThrottles().Return(noThrottle(c)).Times(2)
+				rtr := mock_store.NewMockRefreshTokenRepo(c)
+				rtr.EXPECT().Create(gomock.Any(), gomock.Any()).Return(model.RefreshToken{}, nil)
+				s.EXPECT().RefreshTokens().Return(rtr)
+				s.EXPECT().Roles().Return(noRoles(c))
+			},
+			token: mfaJWT,
+			code:  "ABCD-EFGH",
+		},
+		{
+			name: "token is used concurrently",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				rvr := notRevoked(c)
+				rvr.EXPECT().Use(gomock.Any(), gomock.Any()).Return(store.ErrTokenIsUsed)
+				s.EXPECT().RevokedTokens().Return(rvr).Times(2)
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), 1).Return(model.User{ID: 1}, nil)
+				s.EXPECT().Users().Return(ur)
+				tsr := mock_store.NewMockTOTPSecretRepo(c)
+				tsr.EXPECT().GetByUserID(gomock.Any(), 1).Return(testTOTPSecret(t, 1, true), nil)
+				tsr.EXPECT().MarkUsed(gomock.Any(), 1, totpStep(time.Now())).Return(nil)
+				s.EXPECT().TOTPSecrets().Return(tsr).Times(2)
+				s.EXPECT().LoginThrottles().Return(noThrottle(c)).Times(2)
+			},
+			token:    mfaJWT,
+			code:     code,
+			expError: service.ErrInvalidToken,
+		},
+		{
+			name: "recovery code has already been used",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				s.EXPECT().RevokedTokens().Return(notRevoked(c))
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), 1).Return(model.User{ID: 1}, nil)
+				s.EXPECT().Users().Return(ur)
+				tsr := mock_store.NewMockTOTPSecretRepo(c)
+				tsr.EXPECT().GetByUserID(gomock.Any(), 1).Return(testTOTPSecret(t, 1, true), nil)
+				s.EXPECT().TOTPSecrets().Return(tsr)
+				rcr := mock_store.NewMockRecoveryCodeRepo(c)
+				rcr.EXPECT().Use(gomock.Any(), 1, gomock.Any()).Return(store.ErrTokenIsUsed)
+				s.EXPECT().RecoveryCodes().Return(rcr)
+				ltr := mock_store.NewMockLoginThrottleRepo(c)
+				ltr.EXPECT().Get(gomock.Any(), accountThrottleKey(1)).Return(model.LoginThrottle{}, nil)
+				ltr.EXPECT().RegisterFailure(gomock.Any(), accountThrottleKey(1), gomock.Any()).Return(
+					model.LoginThrottle{Failures: 1}, nil,
+				)
+				s.EXPECT().LoginThrottles().Return(ltr).Times(2)
+			},
+			token:    mfaJWT,
+			code:     "abcd-efgh",
+			expError: service.ErrInvalidMFACode,
+		},
+		{
+			name: "code is invalid",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				s.EXPECT().RevokedTokens().Return(notRevoked(c))
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), 1).Return(model.User{ID: 1}, nil)
+				s.EXPECT().Users().Return(ur)
+				tsr := mock_store.NewMockTOTPSecretRepo(c)
+				tsr.EXPECT().GetByUserID(gomock.Any(), 1).Return(testTOTPSecret(t, 1, true), nil)
+				s.EXPECT().TOTPSecrets().Return(tsr)
+				ltr := mock_store.NewMockLoginThrottleRepo(c)
+				ltr.EXPECT().Get(gomock.Any(), accountThrottleKey(1)).Return(model.LoginThrottle{}, nil)
+				ltr.EXPECT().RegisterFailure(gomock.Any(), accountThrottleKey(1), gomock.Any()).Return(
+					model.LoginThrottle{Failures: 1}, nil,
+				)
+				s.EXPECT().LoginThrottles().Return(ltr).Times(2)
+			},
+			token:    mfaJWT,
+			code:     "000000",
+			expError: service.ErrInvalidMFACode,
+		},
+		{
+			name: "account is locked",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				s.EXPECT().RevokedTokens().Return(notRevoked(c))
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), 1).Return(model.User{ID: 1}, nil)
+				s.EXPECT().Users().Return(ur)
+				tsr := mock_store.NewMockTOTPSecretRepo(c)
+				tsr.EXPECT().GetByUserID(gomock.Any(), 1).Return(testTOTPSecret(t, 1, true), nil)
+				s.EXPECT().TOTPSecrets().Return(tsr)
+				lockedUntil := time.Now().Add(time.Minute)
+				ltr := mock_store.NewMockLoginThrottleRepo(c)
+				ltr.EXPECT().Get(gomock.Any(), accountThrottleKey(1)).Return(
+					model.LoginThrottle{LockedUntil: &lockedUntil}, nil,
+				)
+				s.EXPECT().LoginThrottles().Return(ltr)
+			},
+			token:    mfaJWT,
+			code:     code,
+			expError: service.ErrAccountLocked,
+		},
+		{
+			name: "account is disabled",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				s.EXPECT().RevokedTokens().Return(notRevoked(c))
+				disabledAt := time.Now()
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), 1).Return(model.User{ID: 1, DisabledAt: &disabledAt}, nil)
+				s.EXPECT().Users().Return(ur)
+			},
+			token:    mfaJWT,
+			code:     code,
+			expError: service.ErrAccountDisabled,
+		},
+		{
+			name: "user is deleted",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				s.EXPECT().RevokedTokens().Return(notRevoked(c))
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), 1).Return(model.User{}, store.ErrNotFound)
+				s.EXPECT().Users().Return(ur)
+			},
+			token:    mfaJWT,
+			code:     code,
+			expError: service.ErrInvalidToken,
+		},
+		{
+			name:     "access token isn't accepted",
+			mock:     func(c *gomock.Controller, s *mock_store.MockStore) {},
+			token:    accessJWT,
+			code:     code,
+			expError: service.ErrInvalidToken,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			store := mock_store.NewMockStore(c)
+			tc.mock(c, store)
+			s := newAuthService(store, testKeyring(), nil, nil)
+			accessJWT, refreshJWT, err := s.VerifyMFA(context.Background(), tc.token, tc.code)
+
+			if tc.expError == nil {
+				assert.NoError(t, err)
+				assert.NotEqual(t, "", accessJWT)
+				assert.NotEqual(t, "", refreshJWT)
+			} else {
+				assert.True(t, errors.Is(err, tc.expError), err)
+			}
+		})
+	}
+}
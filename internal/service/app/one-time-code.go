@@ -0,0 +1,223 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/imarrche/jwt-auth-example/internal/config"
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/notify"
+	"github.com/imarrche/jwt-auth-example/internal/service"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// oneTimeCodeDigits is the number of digits of one-time codes.
+const oneTimeCodeDigits = 6
+
+// Purposes of one-time codes, codes of one purpose can't be used for another.
+const (
+	oneTimeCodeSignIn = "sign_in"
+	oneTimeCodeStepUp = "step_up"
+)
+
+// newOneTimeCode generates a random numeric one-time code of the user with specific
+// purpose and returns it along with its hash.
+func newOneTimeCode(userID int, purpose string) (string, string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(oneTimeCodeDigits), nil)
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", "", err
+	}
+	code := fmt.Sprintf("%0*d", oneTimeCodeDigits, n)
+	hash, err := hashOneTimeCode(userID, purpose, code)
+	if err != nil {
+		return "", "", err
+	}
+
+	return code, hash, nil
+}
+
+// hashOneTimeCode returns hash of the one-time code of the user with specific purpose.
+// There are too few codes to store them as plain hashes, so they're keyed with the
+// one-time code key and bound to the user and the purpose.
+func hashOneTimeCode(userID int, purpose, code string) (string, error) {
+	key, err := base64.StdEncoding.DecodeString(config.Get().OneTimeCode.Key)
+	if err != nil || len(key) != 32 {
+		return "", errors.New("one-time code key must be base64 encoded 32 bytes")
+	}
+
+	return macSecretToken(key, purpose, strconv.Itoa(userID), code), nil
+}
+
+// SendSignInCode sends a one-time code to sign in with to the user with specific email.
+// Unknown emails, disabled users and requests made before the resend interval passes
+// are silently ignored and the code is sent in the background, so registered emails
+// can't be found out.
+func (s *authService) SendSignInCode(ctx context.Context, email string) error {
+	u, err := s.store.Users().GetByEmail(ctx, email)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if u.DisabledAt != nil {
+		return nil
+	}
+
+	s.goBackground("couldn't send sign in code", func(ctx context.Context) error {
+		err := s.sendOneTimeCode(ctx, u, oneTimeCodeSignIn)
+		if errors.Is(err, service.ErrCodeRecentlySent) {
+			return nil
+		}
+		return err
+	})
+
+	return nil
+}
+
+// SignInWithCode signs in the user with specific email if the one-time code sent by
+// SendSignInCode is valid. Receiving the code proves the email, so the email is marked
+// as verified. Failed attempts are tracked per account and per client IP the same way
+// SignIn tracks them.
+func (s *authService) SignInWithCode(
+	ctx context.Context, email, code, clientIP string,
+) (model.SignInResult, error) {
+	c := config.Get().Lockout
+	if err := s.checkThrottle(ctx, ipThrottleKey(clientIP), service.ErrTooManyAttempts); err != nil {
+		return model.SignInResult{}, err
+	}
+
+	u, err := s.store.Users().GetByEmail(ctx, email)
+	if errors.Is(err, store.ErrNotFound) {
+		err = service.ErrInvalidOneTimeCode
+	} else if err == nil {
+		err = s.checkOneTimeCode(ctx, u.ID, oneTimeCodeSignIn, code)
+	}
+	if errors.Is(err, service.ErrInvalidOneTimeCode) {
+		if err := s.registerFailure(ctx, ipThrottleKey(clientIP), c.IPMaxFailures); err != nil {
+			return model.SignInResult{}, err
+		}
+		return model.SignInResult{}, service.ErrInvalidOneTimeCode
+	} else if err != nil {
+		return model.SignInResult{}, err
+	}
+
+	if u, err = s.markEmailVerified(ctx, u); err != nil {
+		return model.SignInResult{}, err
+	}
+
+	return s.signInResult(ctx, u)
+}
+
+// SendStepUpCode sends a one-time code to the user to confirm a sensitive action with.
+func (s *authService) SendStepUpCode(ctx context.Context, userID int) error {
+	u, err := s.store.Users().GetByID(ctx, userID)
+	if err != nil {
+		return serviceError(err)
+	}
+
+	return s.sendOneTimeCode(ctx, u, oneTimeCodeStepUp)
+}
+
+// sendOneTimeCode sends a new one-time code with specific purpose to the user, the
+// previous code with the same purpose is invalidated. It returns
+// service.ErrCodeRecentlySent if the previous code was sent less than the resend
+// interval ago.
+func (s *authService) sendOneTimeCode(ctx context.Context, u model.User, purpose string) error {
+	c := config.Get().OneTimeCode
+	previous, err := s.store.OneTimeCodes().Get(ctx, u.ID, purpose)
+	if err == nil && time.Since(previous.CreatedAt) < c.ResendInterval {
+		return service.ErrCodeRecentlySent
+	} else if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return err
+	}
+
+	code, hash, err := newOneTimeCode(u.ID, purpose)
+	if err != nil {
+		return err
+	}
+	_, err = s.store.OneTimeCodes().Save(ctx, model.OneTimeCode{
+		UserID:    u.ID,
+		Purpose:   purpose,
+		Hash:      hash,
+		ExpiresAt: time.Now().Add(c.TTL),
+	})
+	if err != nil {
+		return serviceError(err)
+	}
+
+	msg := notify.Message{User: u}
+	if purpose == oneTimeCodeSignIn {
+		msg.Subject = "Sign in code"
+		msg.Text = fmt.Sprintf(
+			"Your sign in code is %s, it expires in %s. "+
+				"If you didn't request it, ignore this message.",
+			code, c.TTL,
+		)
+	} else {
+		msg.Subject = "Confirmation code"
+		msg.Text = fmt.Sprintf(
+			"Your code to confirm the action is %s, it expires in %s. "+
+				"If you didn't request it, change your password.",
+			code, c.TTL,
+		)
+	}
+
+	return s.notifier.Notify(ctx, msg)
+}
+
+// checkOneTimeCode uses the one-time code of the user with specific purpose, it returns
+// service.ErrInvalidOneTimeCode if the code doesn't match, has expired or has run out
+// of attempts. Failures are tracked with the account's login throttle the same way
+// MFA code failures are.
+func (s *authService) checkOneTimeCode(
+	ctx context.Context, userID int, purpose, code string,
+) error {
+	key := accountThrottleKey(userID)
+	if err := s.checkThrottle(ctx, key, service.ErrAccountLocked); err != nil {
+		return err
+	}
+
+	c, err := s.store.OneTimeCodes().Attempt(
+		ctx, userID, purpose, config.Get().OneTimeCode.MaxAttempts,
+	)
+	if errors.Is(err, store.ErrNotFound) {
+		return service.ErrInvalidOneTimeCode
+	} else if err != nil {
+		return err
+	}
+	if time.Now().After(c.ExpiresAt) {
+		return service.ErrInvalidOneTimeCode
+	}
+	hash, err := hashOneTimeCode(userID, purpose, code)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(c.Hash)) != 1 {
+		if err := s.registerFailure(ctx, key, config.Get().Lockout.MaxFailures); err != nil {
+			return err
+		}
+		return service.ErrInvalidOneTimeCode
+	}
+	// The code is deleted once it's used, so it can't be used concurrently twice.
+	err = s.store.OneTimeCodes().Delete(ctx, userID, purpose)
+	if errors.Is(err, store.ErrNotFound) {
+		return service.ErrInvalidOneTimeCode
+	} else if err != nil {
+		return err
+	}
+
+	return s.store.LoginThrottles().Delete(ctx, key)
+}
+
+// purgeOneTimeCodes deletes one-time codes which have already expired.
+func (s *authService) purgeOneTimeCodes(ctx context.Context) error {
+	return s.store.OneTimeCodes().DeleteExpired(ctx)
+}
@@ -0,0 +1,390 @@
+package app
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imarrche/jwt-auth-example/internal/config"
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/notify"
+	mock_notify "github.com/imarrche/jwt-auth-example/internal/notify/mocks"
+	"github.com/imarrche/jwt-auth-example/internal/service"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+	mock_store "github.com/imarrche/jwt-auth-example/internal/store/mocks"
+)
+
+// codePattern matches one-time codes in notification texts.
+var codePattern = regexp.MustCompile(`\d{6}`)
+
+// anyThrottle returns login throttle repository which accepts any failures.
+func anyThrottle(c *gomock.Controller) *mock_store.MockLoginThrottleRepo {
+	r := noThrottle(c)
+	r.EXPECT().RegisterFailure(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, key string, _ time.Time) (model.LoginThrottle, error) {
+			return model.LoginThrottle{Key: key, Failures: 1}, nil
+		},
+	).AnyTimes()
+
+	return r
+}
+
+// withOneTimeCodeKey sets one-time code key for the test.
+func withOneTimeCodeKey(t *testing.T) {
+	c := config.Get().OneTimeCode
+	key := c.Key
+	c.Key = base64.StdEncoding.EncodeToString(make([]byte, 32))
+	t.Cleanup(func() { c.Key = key })
+}
+
+// testOneTimeCodeHash returns hash of the one-time code keyed with the key set by
+// withOneTimeCodeKey.
+func testOneTimeCodeHash(t *testing.T, userID int, purpose, code string) string {
+	hash, err := hashOneTimeCode(userID, purpose, code)
+	require.NoError(t, err)
+
+	return hash
+}
+
+// expectStepUpCode expects the step-up code of user 1 to be checked, valid is the code
+// which was sent to the user.
+func expectStepUpCode(
+	t *testing.T, c *gomock.Controller, s *mock_store.MockStore, valid string,
+) {
+	otcr := mock_store.NewMockOneTimeCodeRepo(c)
+	otcr.EXPECT().Attempt(gomock.Any(), 1, oneTimeCodeStepUp, gomock.Any()).Return(model.OneTimeCode{
+		UserID:    1,
+		Purpose:   oneTimeCodeStepUp,
+		Hash:      testOneTimeCodeHash(t, 1, oneTimeCodeStepUp, valid),
+		Attempts:  1,
+		ExpiresAt: time.Now().Add(time.Minute),
+	}, nil)
+	otcr.EXPECT().Delete(gomock.Any(), 1, oneTimeCodeStepUp).Return(nil).MaxTimes(1)
+	s.EXPECT().OneTimeCodes().Return(otcr).MinTimes(1)
+	s.EXPECT().LoginThrottles().Return(anyThrottle(c)).AnyTimes()
+}
+
+func TestNewOneTimeCode(t *testing.T) {
+	withOneTimeCodeKey(t)
+	code, hash, err := newOneTimeCode(1, oneTimeCodeSignIn)
+
+	assert.NoError(t, err)
+	assert.Regexp(t, `^\d{6}$`, code)
+	assert.Equal(t, testOneTimeCodeHash(t, 1, oneTimeCodeSignIn, code), hash)
+}
+
+func TestHashOneTimeCode(t *testing.T) {
+	withOneTimeCodeKey(t)
+	hash := testOneTimeCodeHash(t, 1, oneTimeCodeSignIn, "123456")
+
+	assert.NotEqual(t, hash, testOneTimeCodeHash(t, 2, oneTimeCodeSignIn, "123456"))
+	assert.NotEqual(t, hash, testOneTimeCodeHash(t, 1, oneTimeCodeStepUp, "123456"))
+	assert.NotEqual(t, hash, hashSecretToken("123456"))
+
+	config.Get().OneTimeCode.Key = ""
+	_, err := hashOneTimeCode(1, oneTimeCodeSignIn, "123456")
+	assert.Error(t, err)
+}
+
+func TestAuthService_SendSignInCode(t *testing.T) {
+	withOneTimeCodeKey(t)
+	user := model.User{ID: 1, Email: "user1@test.com"}
+
+	testcases := []struct {
+		name  string
+		mock  func(*gomock.Controller, *mock_store.MockStore, *mock_notify.MockNotifier)
+		email string
+	}{
+		{
+			name: "code is sent",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore, n *mock_notify.MockNotifier) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByEmail(gomock.Any(), user.Email).Return(user, nil)
+				s.EXPECT().Users().Return(ur)
+
+				var saved model.OneTimeCode
+				otcr := mock_store.NewMockOneTimeCodeRepo(c)
+				otcr.EXPECT().Get(gomock.Any(), user.ID, oneTimeCodeSignIn).Return(
+					model.OneTimeCode{}, store.ErrNotFound,
+				)
+				otcr.EXPECT().Save(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, c model.OneTimeCode) (model.OneTimeCode, error) {
+						saved = c
+						return c, nil
+					},
+				)
+				s.EXPECT().OneTimeCodes().Return(otcr).Times(2)
+
+				n.EXPECT().Notify(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, msg notify.Message) error {
+						assert.Equal(t, user, msg.User)
+						code := codePattern.FindString(msg.Text)
+						assert.Equal(t, testOneTimeCodeHash(t, user.ID, saved.Purpose, code), saved.Hash)
+						assert.Equal(t, oneTimeCodeSignIn, saved.Purpose)
+						return nil
+					},
+				)
+			},
+			email: user.Email,
+		},
+		{
+			name: "code has recently been sent",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore, n *mock_notify.MockNotifier) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByEmail(gomock.Any(), user.Email).Return(user, nil)
+				s.EXPECT().Users().Return(ur)
+				otcr := mock_store.NewMockOneTimeCodeRepo(c)
+				otcr.EXPECT().Get(gomock.Any(), user.ID, oneTimeCodeSignIn).Return(
+					model.OneTimeCode{CreatedAt: time.Now()}, nil,
+				)
+				s.EXPECT().OneTimeCodes().Return(otcr)
+			},
+			email: user.Email,
+		},
+		{
+			name: "notifier error isn't returned",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore, n *mock_notify.MockNotifier) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByEmail(gomock.Any(), user.Email).Return(user, nil)
+				s.EXPECT().Users().Return(ur)
+				otcr := mock_store.NewMockOneTimeCodeRepo(c)
+				otcr.EXPECT().Get(gomock.Any(), user.ID, oneTimeCodeSignIn).Return(
+					model.OneTimeCode{}, store.ErrNotFound,
+				)
+				otcr.EXPECT().Save(gomock.Any(), gomock.Any()).Return(model.OneTimeCode{}, nil)
+				s.EXPECT().OneTimeCodes().Return(otcr).Times(2)
+				n.EXPECT().Notify(gomock.Any(), gomock.Any()).Return(errors.New("webhook is down"))
+			},
+			email: user.Email,
+		},
+		{
+			name: "unknown email is ignored",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore, n *mock_notify.MockNotifier) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByEmail(gomock.Any(), "unknown@test.com").Return(
+					model.User{}, store.ErrNotFound,
+				)
+				s.EXPECT().Users().Return(ur)
+			},
+			email: "unknown@test.com",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			store := mock_store.NewMockStore(c)
+			notifier := mock_notify.NewMockNotifier(c)
+			tc.mock(c, store, notifier)
+			s := newAuthService(store, testKeyring(), nil, notifier)
+			err := s.SendSignInCode(context.Background(), tc.email)
+			s.background.Wait()
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestAuthService_SignInWithCode(t *testing.T) {
+	withOneTimeCodeKey(t)
+	user := model.User{ID: 1, Email: "user1@test.com"}
+	code := model.OneTimeCode{
+		UserID:    user.ID,
+		Purpose:   oneTimeCodeSignIn,
+		Hash:      testOneTimeCodeHash(t, user.ID, oneTimeCodeSignIn, "123456"),
+		Attempts:  1,
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+
+	testcases := []struct {
+		name     string
+		mock     func(*gomock.Controller, *mock_store.MockStore)
+		email    string
+		code     string
+		expError error
+	}{
+		{
+			name: "user is signed in and email is verified",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByEmail(gomock.Any(), user.Email).Return(user, nil)
+				ur.EXPECT().SetEmailVerifiedAt(
+					gomock.Any(), user.ID, gomock.Not(gomock.Nil()),
+				).Return(nil)
+				s.EXPECT().Users().Return(ur).Times(2)
+				s.EXPECT().LoginThrottles().Return(noThrottle(c)).AnyTimes()
+				otcr := mock_store.NewMockOneTimeCodeRepo(c)
+				otcr.EXPECT().Attempt(gomock.Any(), user.ID, oneTimeCodeSignIn, 5).Return(code, nil)
+				otcr.EXPECT().Delete(gomock.Any(), user.ID, oneTimeCodeSignIn).Return(nil)
+				s.EXPECT().OneTimeCodes().Return(otcr).Times(2)
+				s.EXPECT().TOTPSecrets().Return(noTOTP(c))
+				rtr := mock_store.NewMockRefreshTokenRepo(c)
+				rtr.EXPECT().Create(gomock.Any(), gomock.Any()).Return(model.RefreshToken{}, nil)
+				s.EXPECT().RefreshTokens().Return(rtr)
+				s.EXPECT().Roles().Return(noRoles(c))
+			},
+			email: user.Email,
+			code:  "123456",
+		},
+		{
+			name: "code doesn't match",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByEmail(gomock.Any(), user.Email).Return(user, nil)
+				s.EXPECT().Users().Return(ur)
+				ltr := anyThrottle(c)
+				s.EXPECT().LoginThrottles().Return(ltr).AnyTimes()
+				otcr := mock_store.NewMockOneTimeCodeRepo(c)
+				otcr.EXPECT().Attempt(gomock.Any(), user.ID, oneTimeCodeSignIn, 5).Return(code, nil)
+				s.EXPECT().OneTimeCodes().Return(otcr)
+			},
+			email:    user.Email,
+			code:     "654321",
+			expError: service.ErrInvalidOneTimeCode,
+		},
+		{
+			name: "code is out of attempts",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByEmail(gomock.Any(), user.Email).Return(user, nil)
+				s.EXPECT().Users().Return(ur)
+				s.EXPECT().LoginThrottles().Return(anyThrottle(c)).AnyTimes()
+				otcr := mock_store.NewMockOneTimeCodeRepo(c)
+				otcr.EXPECT().Attempt(gomock.Any(), user.ID, oneTimeCodeSignIn, 5).Return(
+					model.OneTimeCode{}, store.ErrNotFound,
+				)
+				s.EXPECT().OneTimeCodes().Return(otcr)
+			},
+			email:    user.Email,
+			code:     "123456",
+			expError: service.ErrInvalidOneTimeCode,
+		},
+		{
+			name: "code is expired",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByEmail(gomock.Any(), user.Email).Return(user, nil)
+				s.EXPECT().Users().Return(ur)
+				s.EXPECT().LoginThrottles().Return(anyThrottle(c)).AnyTimes()
+				expired := code
+				expired.ExpiresAt = time.Now().Add(-time.Minute)
+				otcr := mock_store.NewMockOneTimeCodeRepo(c)
+				otcr.EXPECT().Attempt(gomock.Any(), user.ID, oneTimeCodeSignIn, 5).Return(expired, nil)
+				s.EXPECT().OneTimeCodes().Return(otcr)
+			},
+			email:    user.Email,
+			code:     "123456",
+			expError: service.ErrInvalidOneTimeCode,
+		},
+		{
+			name: "email is unknown",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByEmail(gomock.Any(), "unknown@test.com").Return(
+					model.User{}, store.ErrNotFound,
+				)
+				s.EXPECT().Users().Return(ur)
+				s.EXPECT().LoginThrottles().Return(anyThrottle(c)).AnyTimes()
+			},
+			email:    "unknown@test.com",
+			code:     "123456",
+			expError: service.ErrInvalidOneTimeCode,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			store := mock_store.NewMockStore(c)
+			tc.mock(c, store)
+			result, err := newAuthService(store, testKeyring(), nil, nil).SignInWithCode(
+				context.Background(), tc.email, tc.code, "127.0.0.1",
+			)
+
+			if tc.expError != nil {
+				assert.True(t, errors.Is(err, tc.expError), err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotEmpty(t, result.AccessToken)
+			assert.NotEmpty(t, result.RefreshToken)
+		})
+	}
+}
+
+func TestAuthService_SendStepUpCode(t *testing.T) {
+	withOneTimeCodeKey(t)
+	user := model.User{ID: 1, Email: "user1@test.com"}
+
+	testcases := []struct {
+		name     string
+		mock     func(*gomock.Controller, *mock_store.MockStore, *mock_notify.MockNotifier)
+		expError error
+	}{
+		{
+			name: "code is sent",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore, n *mock_notify.MockNotifier) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil)
+				s.EXPECT().Users().Return(ur)
+				otcr := mock_store.NewMockOneTimeCodeRepo(c)
+				otcr.EXPECT().Get(gomock.Any(), user.ID, oneTimeCodeStepUp).Return(
+					model.OneTimeCode{CreatedAt: time.Now().Add(-time.Hour)}, nil,
+				)
+				otcr.EXPECT().Save(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, c model.OneTimeCode) (model.OneTimeCode, error) {
+						assert.Equal(t, oneTimeCodeStepUp, c.Purpose)
+						return c, nil
+					},
+				)
+				s.EXPECT().OneTimeCodes().Return(otcr).Times(2)
+				n.EXPECT().Notify(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "code has recently been sent",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore, n *mock_notify.MockNotifier) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil)
+				s.EXPECT().Users().Return(ur)
+				otcr := mock_store.NewMockOneTimeCodeRepo(c)
+				otcr.EXPECT().Get(gomock.Any(), user.ID, oneTimeCodeStepUp).Return(
+					model.OneTimeCode{CreatedAt: time.Now()}, nil,
+				)
+				s.EXPECT().OneTimeCodes().Return(otcr)
+			},
+			expError: service.ErrCodeRecentlySent,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			store := mock_store.NewMockStore(c)
+			notifier := mock_notify.NewMockNotifier(c)
+			tc.mock(c, store, notifier)
+			err := newAuthService(store, testKeyring(), nil, notifier).SendStepUpCode(
+				context.Background(), user.ID,
+			)
+
+			if tc.expError == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, tc.expError), err)
+			}
+		})
+	}
+}
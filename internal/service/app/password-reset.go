@@ -0,0 +1,134 @@
+package app
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+
+	"github.com/imarrche/jwt-auth-example/internal/config"
+	"github.com/imarrche/jwt-auth-example/internal/mail"
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/service"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// newSecretToken generates a random token to be sent to user and its hash to be stored.
+func newSecretToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token := encodeBase64(b)
+
+	return token, hashSecretToken(token), nil
+}
+
+// hashSecretToken returns hex encoded SHA-256 hash of the token.
+func hashSecretToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// macSecretToken returns hex encoded HMAC-SHA256 of the token parts keyed with the key,
+// it's used for low entropy secrets whose plain hashes could be brute-forced.
+func macSecretToken(key []byte, parts ...string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(strings.Join(parts, "\x00")))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ForgotPassword sends a single use password reset link to the user with specific
+// email. Unknown emails are silently ignored and the link is sent in the background,
+// so registered emails can't be found out.
+func (s *authService) ForgotPassword(ctx context.Context, email string) error {
+	u, err := s.store.Users().GetByEmail(ctx, email)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	s.goBackground("couldn't send password reset email", func(ctx context.Context) error {
+		return s.sendPasswordResetEmail(ctx, u)
+	})
+
+	return nil
+}
+
+// sendPasswordResetEmail sends a single use password reset link to the user.
+func (s *authService) sendPasswordResetEmail(ctx context.Context, u model.User) error {
+	token, hash, err := newSecretToken()
+	if err != nil {
+		return err
+	}
+	c := config.Get().Account
+	_, err = s.store.PasswordResetTokens().Create(ctx, model.PasswordResetToken{
+		Hash: hash, UserID: u.ID, ExpiresAt: time.Now().Add(c.PasswordResetTTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(mail.Message{
+		To:      u.Email,
+		Subject: "Password reset",
+		Body: fmt.Sprintf(
+			"To reset your password follow the link: %s/reset-password?token=%s\n"+
+				"The link expires in %s. If you didn't request password reset, ignore this email.",
+			c.URL, token, c.PasswordResetTTL,
+		),
+	})
+}
+
+// ResetPassword sets a new password for the user the password reset token was
+// issued for. The token can be used only once, all refresh tokens of the user are
+// revoked.
+func (s *authService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	if err := model.ValidatePassword(newPassword); err != nil {
+		return service.NewValidationError(validation.Errors{"new_password": err})
+	}
+
+	t, err := s.store.PasswordResetTokens().GetByHash(ctx, hashSecretToken(token))
+	if errors.Is(err, store.ErrNotFound) {
+		return service.ErrInvalidOneTimeToken
+	} else if err != nil {
+		return err
+	}
+	if t.UsedAt != nil || time.Now().After(t.ExpiresAt) {
+		return service.ErrInvalidOneTimeToken
+	}
+	if err := s.store.PasswordResetTokens().MarkUsed(ctx, t.Hash); isTokenGone(err) {
+		return service.ErrInvalidOneTimeToken
+	} else if err != nil {
+		return err
+	}
+
+	u, err := s.store.Users().GetByID(ctx, t.UserID)
+	if err != nil {
+		return serviceError(err)
+	}
+	hashedPassword, err := hashPassword(ctx, newPassword)
+	if err != nil {
+		return err
+	}
+	if err := s.store.Users().SetPasswordHash(ctx, u.ID, hashedPassword); err != nil {
+		return serviceError(err)
+	}
+
+	return s.store.RefreshTokens().RevokeByUserID(ctx, u.ID)
+}
+
+// purgePasswordResetTokens deletes password reset tokens which have already expired.
+func (s *authService) purgePasswordResetTokens(ctx context.Context) error {
+	return s.store.PasswordResetTokens().DeleteExpired(ctx)
+}
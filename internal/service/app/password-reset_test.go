@@ -0,0 +1,212 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/imarrche/jwt-auth-example/internal/mail"
+	mock_mail "github.com/imarrche/jwt-auth-example/internal/mail/mocks"
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+	mock_store "github.com/imarrche/jwt-auth-example/internal/store/mocks"
+)
+
+func TestAuthService_ForgotPassword(t *testing.T) {
+	user := model.User{ID: 1, Email: "user1@test.com"}
+
+	testcases := []struct {
+		name     string
+		mock     func(*gomock.Controller, *mock_store.MockStore, *mock_mail.MockMailer)
+		email    string
+		expError bool
+	}{
+		{
+			name: "password reset link is sent",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore, m *mock_mail.MockMailer) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByEmail(gomock.Any(), user.Email).Return(user, nil)
+				s.EXPECT().Users().Return(ur)
+
+				var hash string
+				prtr := mock_store.NewMockPasswordResetTokenRepo(c)
+				prtr.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, t model.PasswordResetToken) (model.PasswordResetToken, error) {
+						hash = t.Hash
+						return t, nil
+					},
+				)
+				s.EXPECT().PasswordResetTokens().Return(prtr)
+
+				m.EXPECT().Send(gomock.Any()).DoAndReturn(func(msg mail.Message) error {
+					assert.Equal(t, user.Email, msg.To)
+					token := msg.Body[strings.Index(msg.Body, "token=")+len("token="):]
+					token = strings.Fields(token)[0]
+					assert.Equal(t, hash, hashSecretToken(token))
+					return nil
+				})
+			},
+			email:    user.Email,
+			expError: false,
+		},
+		{
+			name: "mail error isn't returned",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore, m *mock_mail.MockMailer) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByEmail(gomock.Any(), user.Email).Return(user, nil)
+				s.EXPECT().Users().Return(ur)
+				prtr := mock_store.NewMockPasswordResetTokenRepo(c)
+				prtr.EXPECT().Create(gomock.Any(), gomock.Any()).Return(
+					model.PasswordResetToken{}, nil,
+				)
+				s.EXPECT().PasswordResetTokens().Return(prtr)
+				m.EXPECT().Send(gomock.Any()).Return(errors.New("smtp is down"))
+			},
+			email:    user.Email,
+			expError: false,
+		},
+		{
+			name: "unknown email is ignored",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore, m *mock_mail.MockMailer) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByEmail(gomock.Any(), "unknown@test.com").Return(
+					model.User{}, store.ErrNotFound,
+				)
+				s.EXPECT().Users().Return(ur)
+			},
+			email:    "unknown@test.com",
+			expError: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			store := mock_store.NewMockStore(c)
+			mailer := mock_mail.NewMockMailer(c)
+			tc.mock(c, store, mailer)
+			s := newAuthService(store, testKeyring(), mailer, nil)
+			err := s.ForgotPassword(context.Background(), tc.email)
+			s.background.Wait()
+
+			if !tc.expError {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestAuthService_ResetPassword(t *testing.T) {
+	token := "token1"
+	usedAt := time.Now()
+
+	testcases := []struct {
+		name     string
+		mock     func(*gomock.Controller, *mock_store.MockStore)
+		password string
+		expError bool
+	}{
+		{
+			name: "password is reset",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				prtr := mock_store.NewMockPasswordResetTokenRepo(c)
+				prtr.EXPECT().GetByHash(gomock.Any(), hashSecretToken(token)).Return(model.PasswordResetToken{
+					Hash: hashSecretToken(token), UserID: 1, ExpiresAt: time.Now().Add(time.Hour),
+				}, nil)
+				prtr.EXPECT().MarkUsed(gomock.Any(), hashSecretToken(token)).Return(nil)
+				s.EXPECT().PasswordResetTokens().Return(prtr).Times(2)
+
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), 1).Return(model.User{ID: 1}, nil)
+				ur.EXPECT().SetPasswordHash(gomock.Any(), 1, gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ int, hash string) error {
+						assert.NoError(t, bcrypt.CompareHashAndPassword(
+							[]byte(hash), []byte("password2"),
+						))
+						return nil
+					},
+				)
+				s.EXPECT().Users().Return(ur).Times(2)
+
+				rtr := mock_store.NewMockRefreshTokenRepo(c)
+				rtr.EXPECT().RevokeByUserID(gomock.Any(), 1).Return(nil)
+				s.EXPECT().RefreshTokens().Return(rtr)
+			},
+			password: "password2",
+			expError: false,
+		},
+		{
+			name: "token is expired",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				prtr := mock_store.NewMockPasswordResetTokenRepo(c)
+				prtr.EXPECT().GetByHash(gomock.Any(), hashSecretToken(token)).Return(model.PasswordResetToken{
+					Hash: hashSecretToken(token), UserID: 1, ExpiresAt: time.Now().Add(-time.Hour),
+				}, nil)
+				s.EXPECT().PasswordResetTokens().Return(prtr)
+			},
+			password: "password2",
+			expError: true,
+		},
+		{
+			name: "token has already been used",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				prtr := mock_store.NewMockPasswordResetTokenRepo(c)
+				prtr.EXPECT().GetByHash(gomock.Any(), hashSecretToken(token)).Return(model.PasswordResetToken{
+					Hash: hashSecretToken(token), UserID: 1, ExpiresAt: time.Now().Add(time.Hour),
+					UsedAt: &usedAt,
+				}, nil)
+				s.EXPECT().PasswordResetTokens().Return(prtr)
+			},
+			password: "password2",
+			expError: true,
+		},
+		{
+			name: "token is used concurrently",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				prtr := mock_store.NewMockPasswordResetTokenRepo(c)
+				prtr.EXPECT().GetByHash(gomock.Any(), hashSecretToken(token)).Return(model.PasswordResetToken{
+					Hash: hashSecretToken(token), UserID: 1, ExpiresAt: time.Now().Add(time.Hour),
+				}, nil)
+				prtr.EXPECT().MarkUsed(gomock.Any(), hashSecretToken(token)).Return(store.ErrTokenIsUsed)
+				s.EXPECT().PasswordResetTokens().Return(prtr).Times(2)
+			},
+			password: "password2",
+			expError: true,
+		},
+		{
+			name:     "password is invalid",
+			mock:     func(c *gomock.Controller, s *mock_store.MockStore) {},
+			password: "pass",
+			expError: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			store := mock_store.NewMockStore(c)
+			tc.mock(c, store)
+			err := newAuthService(store, testKeyring(), nil, nil).ResetPassword(
+				context.Background(), token, tc.password,
+			)
+
+			if !tc.expError {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package app
+
+import (
+	"context"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// hashPassword returns bcrypt hash of the password. Hashing can't be interrupted, so
+// it's skipped if the context is already done.
+func hashPassword(ctx context.Context, password string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hash), nil
+}
+
+// checkPassword reports whether the password matches bcrypt hash. Comparison is
+// skipped if the context is already done.
+func checkPassword(ctx context.Context, hash, password string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil, nil
+}
@@ -0,0 +1,28 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPassword(t *testing.T) {
+	hash, err := hashPassword(context.Background(), "password")
+	assert.NoError(t, err)
+
+	ok, err := checkPassword(context.Background(), hash, "password")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = checkPassword(context.Background(), hash, "password2")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = hashPassword(ctx, "password")
+	assert.Equal(t, context.Canceled, err)
+	_, err = checkPassword(ctx, hash, "password")
+	assert.Equal(t, context.Canceled, err)
+}
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"crypto/rand"
+	"strconv"
+	"strings"
+
+	"github.com/imarrche/jwt-auth-example/internal/config"
+)
+
+const (
+	// recoveryCodeCount is the number of recovery codes generated at once.
+	recoveryCodeCount = 10
+	// recoveryCodeSize is the size of a recovery code, 40 bits are 8 base32 characters.
+	recoveryCodeSize = 5
+)
+
+// newRecoveryCodes generates a set of recovery codes of the user to be shown to the
+// user and their hashes to be stored. Codes are formatted as two groups of 4 characters.
+func newRecoveryCodes(userID int) ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, recoveryCodeSize)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(totpEncoding.EncodeToString(b))
+		code = code[:4] + "-" + code[4:]
+		hash, err := hashRecoveryCode(userID, code)
+		if err != nil {
+			return nil, nil, err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, hash)
+	}
+
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode returns hash of the recovery code of the user. Codes are too short to
+// be stored as plain hashes, so they're keyed with the MFA encryption key and bound to
+// the user. Case, dashes and spaces are ignored, so codes typed by hand are accepted.
+func hashRecoveryCode(userID int, code string) (string, error) {
+	key, err := totpKey(config.Get().MFA)
+	if err != nil {
+		return "", err
+	}
+	code = strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(code))
+
+	return macSecretToken(key, "recovery_code", strconv.Itoa(userID), code), nil
+}
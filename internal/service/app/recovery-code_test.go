@@ -0,0 +1,52 @@
+package app
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imarrche/jwt-auth-example/internal/config"
+)
+
+// testRecoveryCodeHash returns hash of the recovery code of the user keyed with the key
+// set by withTOTPKey.
+func testRecoveryCodeHash(t *testing.T, userID int, code string) string {
+	hash, err := hashRecoveryCode(userID, code)
+	require.NoError(t, err)
+
+	return hash
+}
+
+func TestNewRecoveryCodes(t *testing.T) {
+	withTOTPKey(t)
+
+	codes, hashes, err := newRecoveryCodes(1)
+	require.NoError(t, err)
+	require.Len(t, codes, recoveryCodeCount)
+	require.Len(t, hashes, recoveryCodeCount)
+
+	seen := map[string]bool{}
+	for i, code := range codes {
+		assert.Regexp(t, regexp.MustCompile("^[a-z2-7]{4}-[a-z2-7]{4}$"), code)
+		assert.Equal(t, testRecoveryCodeHash(t, 1, code), hashes[i])
+		assert.False(t, seen[code], "code is repeated")
+		seen[code] = true
+	}
+}
+
+func TestHashRecoveryCode(t *testing.T) {
+	withTOTPKey(t)
+	hash := testRecoveryCodeHash(t, 1, "abcd-efgh")
+
+	assert.Equal(t, hash, testRecoveryCodeHash(t, 1, "ABCD EFGH"))
+	assert.Equal(t, hash, testRecoveryCodeHash(t, 1, "abcdefgh"))
+	assert.NotEqual(t, hash, testRecoveryCodeHash(t, 1, "abcd-efgi"))
+	assert.NotEqual(t, hash, testRecoveryCodeHash(t, 2, "abcd-efgh"))
+	assert.NotEqual(t, hash, hashSecretToken("abcdefgh"))
+
+	config.Get().MFA.EncryptionKey = ""
+	_, err := hashRecoveryCode(1, "abcd-efgh")
+	assert.Error(t, err)
+}
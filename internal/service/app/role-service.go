@@ -0,0 +1,69 @@
+package app
+
+import (
+	"context"
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/service"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// roleService implements role management business logic.
+type roleService struct {
+	store store.Store
+}
+
+// newRoleService creates and returns a new roleService instance.
+func newRoleService(s store.Store) *roleService {
+	return &roleService{store: s}
+}
+
+// GetAll returns all roles with their permissions.
+func (s *roleService) GetAll(ctx context.Context) ([]model.Role, error) {
+	return s.store.Roles().GetAll(ctx)
+}
+
+// GetByUserID returns all roles of the user with specific ID.
+func (s *roleService) GetByUserID(ctx context.Context, userID int) ([]model.Role, error) {
+	return s.store.Roles().GetByUserID(ctx, userID)
+}
+
+// Grant grants the role to the user with specific ID. Granted role is embedded
+// into access JSON Web Tokens issued after that.
+func (s *roleService) Grant(ctx context.Context, userID int, role string) error {
+	if _, err := s.store.Users().GetByID(ctx, userID); err != nil {
+		return serviceError(err)
+	}
+	roles, err := s.store.Roles().GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	if !hasRole(roles, role) {
+		return service.ErrNotFound
+	}
+
+	return serviceError(s.store.Roles().AssignToUser(ctx, userID, role))
+}
+
+// Revoke revokes the role from the user with specific ID.
+func (s *roleService) Revoke(ctx context.Context, userID int, role string) error {
+	roles, err := s.store.Roles().GetByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !hasRole(roles, role) {
+		return service.ErrNotFound
+	}
+
+	return serviceError(s.store.Roles().RemoveFromUser(ctx, userID, role))
+}
+
+// hasRole reports whether roles contain the role with specific name.
+func hasRole(roles []model.Role, name string) bool {
+	for _, r := range roles {
+		if r.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
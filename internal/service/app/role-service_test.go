@@ -0,0 +1,120 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	mock_store "github.com/imarrche/jwt-auth-example/internal/store/mocks"
+)
+
+func TestRoleService_Grant(t *testing.T) {
+	testcases := []struct {
+		name     string
+		mock     func(*gomock.Controller, *mock_store.MockStore, int, string)
+		userID   int
+		role     string
+		expError bool
+	}{
+		{
+			name: "role is granted",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore, userID int, role string) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), userID).Return(model.User{ID: userID}, nil)
+				s.EXPECT().Users().Return(ur)
+				rr := mock_store.NewMockRoleRepo(c)
+				rr.EXPECT().GetAll(gomock.Any()).Return([]model.Role{{Name: "admin"}, {Name: "user"}}, nil)
+				rr.EXPECT().AssignToUser(gomock.Any(), userID, role).Return(nil)
+				s.EXPECT().Roles().Return(rr).Times(2)
+			},
+			userID:   1,
+			role:     "admin",
+			expError: false,
+		},
+		{
+			name: "role doesn't exist",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore, userID int, role string) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), userID).Return(model.User{ID: userID}, nil)
+				s.EXPECT().Users().Return(ur)
+				rr := mock_store.NewMockRoleRepo(c)
+				rr.EXPECT().GetAll(gomock.Any()).Return([]model.Role{{Name: "admin"}, {Name: "user"}}, nil)
+				s.EXPECT().Roles().Return(rr)
+			},
+			userID:   1,
+			role:     "owner",
+			expError: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			store := mock_store.NewMockStore(c)
+			tc.mock(c, store, tc.userID, tc.role)
+			err := newRoleService(store).Grant(context.Background(), tc.userID, tc.role)
+
+			if !tc.expError {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestRoleService_Revoke(t *testing.T) {
+	testcases := []struct {
+		name     string
+		mock     func(*gomock.Controller, *mock_store.MockStore, int, string)
+		userID   int
+		role     string
+		expError bool
+	}{
+		{
+			name: "role is revoked",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore, userID int, role string) {
+				rr := mock_store.NewMockRoleRepo(c)
+				rr.EXPECT().GetByUserID(gomock.Any(), userID).Return([]model.Role{{Name: "admin"}}, nil)
+				rr.EXPECT().RemoveFromUser(gomock.Any(), userID, role).Return(nil)
+				s.EXPECT().Roles().Return(rr).Times(2)
+			},
+			userID:   1,
+			role:     "admin",
+			expError: false,
+		},
+		{
+			name: "user doesn't have the role",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore, userID int, role string) {
+				rr := mock_store.NewMockRoleRepo(c)
+				rr.EXPECT().GetByUserID(gomock.Any(), userID).Return([]model.Role{{Name: "user"}}, nil)
+				s.EXPECT().Roles().Return(rr)
+			},
+			userID:   1,
+			role:     "admin",
+			expError: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			store := mock_store.NewMockStore(c)
+			tc.mock(c, store, tc.userID, tc.role)
+			err := newRoleService(store).Revoke(context.Background(), tc.userID, tc.role)
+
+			if !tc.expError {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
@@ -1,26 +1,162 @@
 package app
 
 import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/imarrche/jwt-auth-example/internal/config"
+	"github.com/imarrche/jwt-auth-example/internal/logger"
+	"github.com/imarrche/jwt-auth-example/internal/mail"
+	"github.com/imarrche/jwt-auth-example/internal/notify"
 	"github.com/imarrche/jwt-auth-example/internal/service"
 	"github.com/imarrche/jwt-auth-example/internal/store"
 )
 
 // Service is the app service implementation.
 type Service struct {
-	store store.Store
-	auth  *authService
+	store    store.Store
+	mailer   mail.Mailer
+	notifier notify.Notifier
+	keys     *keyring
+	auth     *authService
+	key      *keyService
+	roles    *roleService
+	users    *userService
+	admin    *adminService
+	mfa      *mfaService
+	webAuthn *webAuthnService
 }
 
-// NewService creates and returns a new service instance.
-func NewService(s store.Store) *Service {
-	return &Service{store: s}
+// NewService creates and returns a new service instance. Signing keys are encrypted in
+// the store, so an error is returned if the key encryption key is invalid.
+func NewService(s store.Store, m mail.Mailer, n notify.Notifier) (*Service, error) {
+	c := config.Get().JWT
+	if s != nil {
+		if _, err := newKeyMaterialCipher(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Service{store: s, mailer: m, notifier: n, keys: newKeyring(s, c)}, nil
 }
 
 // Auth returns authorization service.
 func (s *Service) Auth() service.Auth {
 	if s.auth == nil {
-		s.auth = newAuthService(s.store)
+		s.auth = newAuthService(s.store, s.keys, s.mailer, s.notifier)
 	}
 
 	return s.auth
 }
+
+// Keys returns signing key service.
+func (s *Service) Keys() service.Keys {
+	if s.key == nil {
+		s.key = newKeyService(s.store, s.keys)
+	}
+
+	return s.key
+}
+
+// Roles returns role service.
+func (s *Service) Roles() service.Roles {
+	if s.roles == nil {
+		s.roles = newRoleService(s.store)
+	}
+
+	return s.roles
+}
+
+// Users returns user service.
+func (s *Service) Users() service.Users {
+	if s.users == nil {
+		s.Auth()
+		s.users = newUserService(s.store, s.auth)
+	}
+
+	return s.users
+}
+
+// Admin returns user management service.
+func (s *Service) Admin() service.Admin {
+	if s.admin == nil {
+		s.Auth()
+		s.admin = newAdminService(s.store, s.auth)
+	}
+
+	return s.admin
+}
+
+// MFA returns multi-factor authentication service.
+func (s *Service) MFA() service.MFA {
+	if s.mfa == nil {
+		s.Auth()
+		s.mfa = newMFAService(s.store, s.auth)
+	}
+
+	return s.mfa
+}
+
+// WebAuthn returns WebAuthn(passkey) service.
+func (s *Service) WebAuthn() service.WebAuthn {
+	if s.webAuthn == nil {
+		s.Auth()
+		s.webAuthn = newWebAuthnService(s.store, s.auth)
+	}
+
+	return s.webAuthn
+}
+
+// Wait waits for tasks run off the request path, e.g. emails being sent, to finish. It
+// has to be called before the store is closed.
+func (s *Service) Wait() {
+	if s.auth != nil {
+		s.auth.background.Wait()
+	}
+}
+
+// PurgeExpired deletes expired revoked, refresh, password reset, email verification and
+// magic link tokens, expired one-time codes, stale login throttles and signing keys
+// retired longer than the grace period ago every interval until done is closed.
+func (s *Service) PurgeExpired(interval time.Duration, done <-chan struct{}) {
+	s.Auth()
+	s.Keys()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			ctx := context.Background()
+			if err := s.auth.purgeRevokedJWTs(ctx); err != nil {
+				logger.Get().Error("couldn't purge revoked tokens", zap.Error(err))
+			}
+			if err := s.auth.purgeRefreshTokens(ctx); err != nil {
+				logger.Get().Error("couldn't purge refresh tokens", zap.Error(err))
+			}
+			if err := s.auth.purgePasswordResetTokens(ctx); err != nil {
+				logger.Get().Error("couldn't purge password reset tokens", zap.Error(err))
+			}
+			if err := s.auth.purgeEmailVerificationTokens(ctx); err != nil {
+				logger.Get().Error("couldn't purge email verification tokens", zap.Error(err))
+			}
+			if err := s.auth.purgeMagicLinkTokens(ctx); err != nil {
+				logger.Get().Error("couldn't purge magic link tokens", zap.Error(err))
+			}
+			if err := s.auth.purgeOneTimeCodes(ctx); err != nil {
+				logger.Get().Error("couldn't purge one-time codes", zap.Error(err))
+			}
+			if err := s.auth.purgeLoginThrottles(ctx); err != nil {
+				logger.Get().Error("couldn't purge login throttles", zap.Error(err))
+			}
+			if err := s.key.Purge(ctx); err != nil {
+				logger.Get().Error("couldn't purge retired signing keys", zap.Error(err))
+			}
+		}
+	}
+}
@@ -1,11 +1,73 @@
 package app
 
 import (
+	"context"
 	"testing"
+	"time"
 
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imarrche/jwt-auth-example/internal/config"
+	mock_store "github.com/imarrche/jwt-auth-example/internal/store/mocks"
 )
 
+// testService creates and returns a new service instance without store.
+func testService(t *testing.T) *Service {
+	s, err := NewService(nil, nil, nil)
+	require.NoError(t, err)
+
+	return s
+}
+
+func TestNewService(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+	store := mock_store.NewMockStore(c)
+
+	withKeyEncryptionKey(t)
+	_, err := NewService(store, nil, nil)
+	assert.NoError(t, err)
+
+	config.Get().JWT.KeyEncryptionKey = ""
+	_, err = NewService(store, nil, nil)
+	assert.Error(t, err)
+}
+
 func TestService_Auth(t *testing.T) {
-	assert.Equal(t, newAuthService(nil), NewService(nil).Auth())
+	s := testService(t)
+	assert.Equal(t, newAuthService(nil, s.keys, nil, nil), s.Auth())
+}
+
+func TestService_Keys(t *testing.T) {
+	s := testService(t)
+	assert.Equal(t, newKeyService(nil, s.keys), s.Keys())
+}
+
+func TestService_Roles(t *testing.T) {
+	assert.Equal(t, newRoleService(nil), testService(t).Roles())
+}
+
+func TestService_Users(t *testing.T) {
+	s := testService(t)
+	assert.Equal(t, newUserService(nil, s.Auth().(*authService)), s.Users())
+}
+
+func TestService_Wait(t *testing.T) {
+	s := testService(t)
+	s.Wait()
+
+	done := false
+	s.Auth()
+	s.auth.goBackground("couldn't finish task", func(ctx context.Context) error {
+		_, ok := ctx.Deadline()
+		assert.True(t, ok)
+		time.Sleep(10 * time.Millisecond)
+		done = true
+		return nil
+	})
+	s.Wait()
+
+	assert.True(t, done)
 }
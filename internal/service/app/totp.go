@@ -0,0 +1,156 @@
+package app
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/imarrche/jwt-auth-example/internal/config"
+)
+
+const (
+	// totpPeriod is the lifetime of a TOTP code.
+	totpPeriod = 30
+	// totpDigits is the number of digits of a TOTP code.
+	totpDigits = 6
+	// totpSkew is the number of steps before and after the current one codes of
+	// which are accepted to tolerate clock skew.
+	totpSkew = 1
+	// totpSecretSize is the size of a TOTP secret, RFC 4226 recommends 160 bits.
+	totpSecretSize = 20
+)
+
+// totpEncoding is the encoding of TOTP secrets authenticator apps expect.
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// newTOTPSecret generates a random TOTP secret.
+func newTOTPSecret() ([]byte, error) {
+	secret := make([]byte, totpSecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
+
+// totpStep returns the TOTP time step of specific time.
+func totpStep(t time.Time) int64 { return t.Unix() / totpPeriod }
+
+// totpCode returns the TOTP code of the secret for specific time step, it's the HOTP
+// value (RFC 4226) of the step.
+func totpCode(secret []byte, step int64) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(step))
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", totpDigits, value%uint32(math.Pow10(totpDigits)))
+}
+
+// validateTOTP returns the time step the code is valid for at specific time. Codes
+// of adjacent steps are accepted too.
+func validateTOTP(secret []byte, code string, t time.Time) (int64, bool) {
+	if len(code) != totpDigits {
+		return 0, false
+	}
+
+	current := totpStep(t)
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if subtle.ConstantTimeCompare([]byte(totpCode(secret, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// totpURI returns otpauth:// provisioning URI of the secret, authenticator apps read
+// it from QR codes.
+func totpURI(issuer, account string, secret []byte) string {
+	q := url.Values{}
+	q.Set("secret", totpEncoding.EncodeToString(secret))
+	q.Set("issuer", issuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", strconv.Itoa(totpDigits))
+	q.Set("period", strconv.Itoa(totpPeriod))
+
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + account,
+		RawQuery: q.Encode(),
+	}
+
+	return u.String()
+}
+
+// totpKey returns the key TOTP secrets are encrypted with.
+func totpKey(c *config.MFA) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(c.EncryptionKey)
+	if err != nil || len(key) != 32 {
+		return nil, errors.New("MFA encryption key must be base64 encoded 32 bytes")
+	}
+
+	return key, nil
+}
+
+// newTOTPCipher returns AES-GCM cipher TOTP secrets are encrypted with.
+func newTOTPCipher() (cipher.AEAD, error) {
+	key, err := totpKey(config.Get().MFA)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// encryptTOTPSecret encrypts the TOTP secret of the user, the random nonce is prepended
+// to the ciphertext. The ciphertext is bound to the user, so it can't be moved to
+// another user.
+func encryptTOTPSecret(userID int, secret []byte) ([]byte, error) {
+	aead, err := newTOTPCipher()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, secret, []byte(strconv.Itoa(userID))), nil
+}
+
+// decryptTOTPSecret decrypts the TOTP secret of the user encrypted with
+// encryptTOTPSecret.
+func decryptTOTPSecret(userID int, ciphertext []byte) ([]byte, error) {
+	aead, err := newTOTPCipher()
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("TOTP secret is malformed")
+	}
+	nonce, ciphertext := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+
+	return aead.Open(nil, nonce, ciphertext, []byte(strconv.Itoa(userID)))
+}
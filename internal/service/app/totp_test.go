@@ -0,0 +1,121 @@
+package app
+
+import (
+	"encoding/base64"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imarrche/jwt-auth-example/internal/config"
+	"github.com/imarrche/jwt-auth-example/internal/model"
+)
+
+// rfcTOTPSecret is the SHA-1 secret of RFC 6238 test vectors.
+var rfcTOTPSecret = []byte("12345678901234567890")
+
+// withTOTPKey sets MFA encryption key for the duration of the test.
+func withTOTPKey(t *testing.T) {
+	c := config.Get().MFA
+	key := c.EncryptionKey
+	c.EncryptionKey = base64.StdEncoding.EncodeToString(make([]byte, 32))
+	t.Cleanup(func() { c.EncryptionKey = key })
+}
+
+// testTOTPSecret returns TOTP secret of the user encrypted with the key set by
+// withTOTPKey.
+func testTOTPSecret(t *testing.T, userID int, confirmed bool) model.TOTPSecret {
+	encrypted, err := encryptTOTPSecret(userID, rfcTOTPSecret)
+	require.NoError(t, err)
+	s := model.TOTPSecret{UserID: userID, Secret: encrypted}
+	if confirmed {
+		confirmedAt := time.Now()
+		s.ConfirmedAt = &confirmedAt
+	}
+
+	return s
+}
+
+func TestTOTPCode(t *testing.T) {
+	// RFC 6238 test vectors truncated to 6 digits.
+	testcases := []struct {
+		time    int64
+		expCode string
+	}{
+		{time: 59, expCode: "287082"},
+		{time: 1111111109, expCode: "081804"},
+		{time: 1111111111, expCode: "050471"},
+		{time: 1234567890, expCode: "005924"},
+		{time: 2000000000, expCode: "279037"},
+	}
+
+	for _, tc := range testcases {
+		assert.Equal(t, tc.expCode, totpCode(rfcTOTPSecret, totpStep(time.Unix(tc.time, 0))))
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := totpStep(now)
+
+	testcases := []struct {
+		name    string
+		code    string
+		expStep int64
+		expOK   bool
+	}{
+		{name: "current code is valid", code: totpCode(rfcTOTPSecret, step), expStep: step, expOK: true},
+		{
+			name: "previous code is valid", code: totpCode(rfcTOTPSecret, step-1),
+			expStep: step - 1, expOK: true,
+		},
+		{
+			name: "next code is valid", code: totpCode(rfcTOTPSecret, step+1),
+			expStep: step + 1, expOK: true,
+		},
+		{name: "old code is invalid", code: totpCode(rfcTOTPSecret, step-2)},
+		{name: "code is malformed", code: "1234"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, ok := validateTOTP(rfcTOTPSecret, tc.code, now)
+
+			assert.Equal(t, tc.expOK, ok)
+			assert.Equal(t, tc.expStep, s)
+		})
+	}
+}
+
+func TestTOTPURI(t *testing.T) {
+	u, err := url.Parse(totpURI("Example App", "user1@test.com", rfcTOTPSecret))
+	require.NoError(t, err)
+
+	assert.Equal(t, "otpauth", u.Scheme)
+	assert.Equal(t, "totp", u.Host)
+	assert.Equal(t, "/Example App:user1@test.com", u.Path)
+	assert.Equal(t, "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", u.Query().Get("secret"))
+	assert.Equal(t, "Example App", u.Query().Get("issuer"))
+	assert.Equal(t, "6", u.Query().Get("digits"))
+	assert.Equal(t, "30", u.Query().Get("period"))
+}
+
+func TestTOTPSecretEncryption(t *testing.T) {
+	withTOTPKey(t)
+
+	encrypted, err := encryptTOTPSecret(1, rfcTOTPSecret)
+	require.NoError(t, err)
+	assert.NotContains(t, string(encrypted), string(rfcTOTPSecret))
+
+	secret, err := decryptTOTPSecret(1, encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, rfcTOTPSecret, secret)
+	_, err = decryptTOTPSecret(2, encrypted)
+	assert.Error(t, err, "secret of another user is decrypted")
+
+	config.Get().MFA.EncryptionKey = ""
+	_, err = encryptTOTPSecret(1, rfcTOTPSecret)
+	assert.Error(t, err, "secret is encrypted without key")
+}
@@ -0,0 +1,112 @@
+package app
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/service"
+)
+
+const (
+	// defaultUserPageSize is the number of users listed when limit isn't set.
+	defaultUserPageSize = 50
+	// maxUserPageSize is the maximum number of users listed at once.
+	maxUserPageSize = 100
+)
+
+// userCursor is the position in users listing, it keeps the sort order and the sort
+// field value of the last listed user.
+type userCursor struct {
+	Sort      string     `json:"s"`
+	Desc      bool       `json:"d,omitempty"`
+	ID        int        `json:"id"`
+	Username  string     `json:"u,omitempty"`
+	Email     string     `json:"e,omitempty"`
+	CreatedAt *time.Time `json:"c,omitempty"`
+}
+
+// List returns the page of users matching the query listed after the cursor, the
+// cursor must be empty for the first page. Users are sorted by ID by default.
+func (s *userService) List(
+	ctx context.Context, q model.UserQuery, cursor string,
+) (model.UserPage, error) {
+	if q.Sort == "" {
+		q.Sort = model.UserSortID
+	}
+	if q.Limit == 0 {
+		q.Limit = defaultUserPageSize
+	}
+	err := validation.Errors{
+		"sort": validation.Validate(q.Sort, validation.In(
+			model.UserSortID, model.UserSortUsername, model.UserSortEmail, model.UserSortCreatedAt,
+		)),
+		"limit": validation.Validate(q.Limit, validation.Min(1), validation.Max(maxUserPageSize)),
+	}.Filter()
+	if err != nil {
+		return model.UserPage{}, service.NewValidationError(err)
+	}
+	if cursor != "" {
+		if q.After, err = decodeUserCursor(cursor, q); err != nil {
+			return model.UserPage{}, err
+		}
+	}
+
+	limit := q.Limit
+	q.Limit++
+	users, total, err := s.store.Users().List(ctx, q)
+	if err != nil {
+		return model.UserPage{}, serviceError(err)
+	}
+
+	page := model.UserPage{Users: users, Total: total}
+	if len(users) > limit {
+		page.Users = users[:limit]
+		page.NextCursor = encodeUserCursor(page.Users[limit-1], q)
+	}
+
+	return page, nil
+}
+
+// encodeUserCursor returns the cursor of users listed after the user.
+func encodeUserCursor(u model.User, q model.UserQuery) string {
+	c := userCursor{Sort: q.Sort, Desc: q.Desc, ID: u.ID}
+	switch q.Sort {
+	case model.UserSortUsername:
+		c.Username = u.Username
+	case model.UserSortEmail:
+		c.Email = u.Email
+	case model.UserSortCreatedAt:
+		c.CreatedAt = &u.CreatedAt
+	}
+	payload, _ := json.Marshal(c)
+
+	return base64.RawURLEncoding.EncodeToString(payload)
+}
+
+// decodeUserCursor returns the user the cursor points at. The cursor must be issued
+// for the same sort order as the query.
+func decodeUserCursor(cursor string, q model.UserQuery) (*model.User, error) {
+	payload, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, service.ErrMalformedRequest.Wrap(err)
+	}
+	var c userCursor
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return nil, service.ErrMalformedRequest.Wrap(err)
+	}
+	if c.Sort != q.Sort || c.Desc != q.Desc {
+		return nil, service.ErrMalformedRequest
+	}
+
+	u := &model.User{ID: c.ID, Username: c.Username, Email: c.Email}
+	if c.CreatedAt != nil {
+		u.CreatedAt = *c.CreatedAt
+	}
+
+	return u, nil
+}
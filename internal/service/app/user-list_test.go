@@ -0,0 +1,141 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/service"
+	mock_store "github.com/imarrche/jwt-auth-example/internal/store/mocks"
+)
+
+func TestUserService_List(t *testing.T) {
+	createdAt := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	users := []model.User{
+		{ID: 1, Username: "user1", CreatedAt: createdAt},
+		{ID: 2, Username: "user2", CreatedAt: createdAt},
+		{ID: 3, Username: "user3", CreatedAt: createdAt},
+	}
+	usernameQuery := model.UserQuery{Sort: model.UserSortUsername, Limit: 2}
+	cursor := encodeUserCursor(users[1], usernameQuery)
+
+	testcases := []struct {
+		name     string
+		mock     func(*gomock.Controller, *mock_store.MockStore)
+		query    model.UserQuery
+		cursor   string
+		expPage  model.UserPage
+		expError error
+	}{
+		{
+			name: "the only page is listed with defaults",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().List(gomock.Any(), model.UserQuery{
+					Sort: model.UserSortID, Limit: defaultUserPageSize + 1,
+				}).Return(users, 3, nil)
+				s.EXPECT().Users().Return(ur)
+			},
+			expPage: model.UserPage{Users: users, Total: 3},
+		},
+		{
+			name: "the first page has the next cursor",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().List(gomock.Any(), model.UserQuery{
+					Sort: model.UserSortUsername, Limit: 3,
+				}).Return(users, 5, nil)
+				s.EXPECT().Users().Return(ur)
+			},
+			query:   usernameQuery,
+			expPage: model.UserPage{Users: users[:2], Total: 5, NextCursor: cursor},
+		},
+		{
+			name: "the next page is listed after the cursor",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().List(gomock.Any(), model.UserQuery{
+					Sort: model.UserSortUsername, Limit: 3,
+					After: &model.User{ID: 2, Username: "user2"},
+				}).Return(users[2:], 5, nil)
+				s.EXPECT().Users().Return(ur)
+			},
+			query:   usernameQuery,
+			cursor:  cursor,
+			expPage: model.UserPage{Users: users[2:], Total: 5},
+		},
+		{
+			name:     "cursor of another sort order is malformed",
+			mock:     func(c *gomock.Controller, s *mock_store.MockStore) {},
+			query:    model.UserQuery{Sort: model.UserSortUsername, Desc: true},
+			cursor:   cursor,
+			expError: service.ErrMalformedRequest,
+		},
+		{
+			name:     "cursor is malformed",
+			mock:     func(c *gomock.Controller, s *mock_store.MockStore) {},
+			cursor:   "not a cursor",
+			expError: service.ErrMalformedRequest,
+		},
+		{
+			name:     "sort field is unknown",
+			mock:     func(c *gomock.Controller, s *mock_store.MockStore) {},
+			query:    model.UserQuery{Sort: "password_hash"},
+			expError: &service.Error{Code: "validation_failed"},
+		},
+		{
+			name:     "limit is too big",
+			mock:     func(c *gomock.Controller, s *mock_store.MockStore) {},
+			query:    model.UserQuery{Limit: maxUserPageSize + 1},
+			expError: &service.Error{Code: "validation_failed"},
+		},
+		{
+			name: "store error is returned",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil, 0, errConnectionLost)
+				s.EXPECT().Users().Return(ur)
+			},
+			expError: errConnectionLost,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			store := mock_store.NewMockStore(c)
+			tc.mock(c, store)
+			page, err := newUserService(store, nil).List(context.Background(), tc.query, tc.cursor)
+
+			if tc.expError == nil {
+				require.NoError(t, err)
+				assert.Equal(t, tc.expPage, page)
+			} else {
+				assert.True(t, errors.Is(err, tc.expError), err)
+			}
+		})
+	}
+}
+
+func TestUserCursor(t *testing.T) {
+	createdAt := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	u := model.User{ID: 1, Username: "user1", Email: "user1@test.com", CreatedAt: createdAt}
+
+	q := model.UserQuery{Sort: model.UserSortCreatedAt, Desc: true}
+	after, err := decodeUserCursor(encodeUserCursor(u, q), q)
+	require.NoError(t, err)
+	assert.Equal(t, &model.User{ID: 1, CreatedAt: createdAt}, after)
+
+	q = model.UserQuery{Sort: model.UserSortEmail}
+	after, err = decodeUserCursor(encodeUserCursor(u, q), q)
+	require.NoError(t, err)
+	assert.Equal(t, &model.User{ID: 1, Email: "user1@test.com"}, after)
+}
@@ -0,0 +1,145 @@
+package app
+
+import (
+	"context"
+	validation "github.com/go-ozzo/ozzo-validation"
+
+	"github.com/imarrche/jwt-auth-example/internal/config"
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/service"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// userService implements user business logic.
+type userService struct {
+	store store.Store
+	auth  *authService
+}
+
+// newUserService creates and returns a new userService instance.
+func newUserService(s store.Store, auth *authService) *userService {
+	return &userService{store: s, auth: auth}
+}
+
+// GetByID returns the user with specific ID.
+func (s *userService) GetByID(ctx context.Context, id int) (model.User, error) {
+	u, err := s.store.Users().GetByID(ctx, id)
+	if err != nil {
+		return model.User{}, serviceError(err)
+	}
+
+	return u, nil
+}
+
+// UpdateProfile updates username, first and second name of the user, empty fields
+// are left unchanged.
+func (s *userService) UpdateProfile(ctx context.Context, update model.User) (model.User, error) {
+	u, err := s.GetByID(ctx, update.ID)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	if update.Username != "" {
+		u.Username = update.Username
+	}
+	if update.FirstName != "" {
+		u.FirstName = update.FirstName
+	}
+	if update.SecondName != "" {
+		u.SecondName = update.SecondName
+	}
+	if err := u.ValidateProfile(); err != nil {
+		return model.User{}, service.NewValidationError(err)
+	}
+
+	u, err = s.store.Users().Update(ctx, u)
+	if err != nil {
+		return model.User{}, serviceError(err)
+	}
+
+	return u, nil
+}
+
+// ChangePassword changes password of the user if the user is reauthenticated with
+// current password or step-up code. All refresh tokens of the user are revoked.
+func (s *userService) ChangePassword(
+	ctx context.Context, userID int, reauth model.Reauthentication, newPassword string,
+) error {
+	u, err := s.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.reauthenticate(ctx, u, reauth); err != nil {
+		return err
+	}
+	if err := model.ValidatePassword(newPassword); err != nil {
+		return service.NewValidationError(validation.Errors{"new_password": err})
+	}
+
+	hashedPassword, err := hashPassword(ctx, newPassword)
+	if err != nil {
+		return err
+	}
+	if err := s.store.Users().SetPasswordHash(ctx, userID, hashedPassword); err != nil {
+		return serviceError(err)
+	}
+
+	return s.store.RefreshTokens().RevokeByUserID(ctx, userID)
+}
+
+// DeleteAccount deletes the user if the user is reauthenticated with current password
+// or step-up code, all user's data is deleted along with the account.
+func (s *userService) DeleteAccount(
+	ctx context.Context, userID int, reauth model.Reauthentication,
+) error {
+	u, err := s.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.reauthenticate(ctx, u, reauth); err != nil {
+		return err
+	}
+
+	if err := s.store.Users().DeleteByID(ctx, userID); err != nil {
+		return serviceError(err)
+	}
+
+	return nil
+}
+
+// reauthenticate checks the proof of the user's identity required before sensitive
+// actions. Step-up code is checked if it's provided, the current password otherwise.
+// Password failures are tracked with the account's login throttle, so guessing the
+// password locks the account the same way signing in does.
+func (s *userService) reauthenticate(
+	ctx context.Context, u model.User, reauth model.Reauthentication,
+) error {
+	if reauth.Code != "" {
+		return s.auth.checkOneTimeCode(ctx, u.ID, oneTimeCodeStepUp, reauth.Code)
+	}
+
+	key := accountThrottleKey(u.ID)
+	if err := s.auth.checkThrottle(ctx, key, service.ErrAccountLocked); err != nil {
+		return err
+	}
+	if ok, err := checkPassword(ctx, u.PasswordHash, reauth.Password); err != nil {
+		return err
+	} else if !ok {
+		if err := s.auth.registerFailure(ctx, key, config.Get().Lockout.MaxFailures); err != nil {
+			return err
+		}
+		return service.ErrInvalidCredentials
+	}
+
+	return s.store.LoginThrottles().Delete(ctx, key)
+}
+
+// Unlock unlocks the user's account locked after failed sign in attempts and
+// forgets its failures.
+func (s *userService) Unlock(ctx context.Context, userID int) error {
+	if _, err := s.GetByID(ctx, userID); err != nil {
+		return err
+	}
+
+	return s.store.LoginThrottles().Delete(ctx, accountThrottleKey(userID))
+}
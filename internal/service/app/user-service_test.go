@@ -0,0 +1,384 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/service"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+	mock_store "github.com/imarrche/jwt-auth-example/internal/store/mocks"
+)
+
+// errConnectionLost is a store error which isn't a store sentinel error.
+var errConnectionLost = errors.New("connection lost")
+
+func TestUserService_GetByID(t *testing.T) {
+	testcases := []struct {
+		name     string
+		mock     func(*gomock.Controller, *mock_store.MockStore, model.User)
+		user     model.User
+		expError error
+	}{
+		{
+			name: "user is retrieved",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore, u model.User) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), u.ID).Return(u, nil)
+				s.EXPECT().Users().Return(ur)
+			},
+			user: model.User{ID: 1, Username: "user1"},
+		},
+		{
+			name: "user is not found",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore, u model.User) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), u.ID).Return(model.User{}, store.ErrNotFound)
+				s.EXPECT().Users().Return(ur)
+			},
+			user:     model.User{ID: 1},
+			expError: service.ErrNotFound,
+		},
+		{
+			name: "store error isn't reported as not found",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore, u model.User) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), u.ID).Return(model.User{}, errConnectionLost)
+				s.EXPECT().Users().Return(ur)
+			},
+			user:     model.User{ID: 1},
+			expError: errConnectionLost,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			store := mock_store.NewMockStore(c)
+			tc.mock(c, store, tc.user)
+			u, err := newUserService(store, nil).GetByID(context.Background(), tc.user.ID)
+
+			if tc.expError == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.user, u)
+			} else {
+				assert.Equal(t, tc.expError, err)
+			}
+		})
+	}
+}
+
+func TestUserService_UpdateProfile(t *testing.T) {
+	testcases := []struct {
+		name     string
+		mock     func(*gomock.Controller, *mock_store.MockStore)
+		update   model.User
+		expUser  model.User
+		expError bool
+	}{
+		{
+			name: "profile is updated",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), 1).Return(model.User{
+					ID: 1, Username: "user1", FirstName: "Name", SecondName: "Secondname",
+				}, nil)
+				ur.EXPECT().Update(gomock.Any(), model.User{
+					ID: 1, Username: "user1", FirstName: "New", SecondName: "Secondname",
+				}).DoAndReturn(func(_ context.Context, u model.User) (model.User, error) { return u, nil })
+				s.EXPECT().Users().Return(ur).Times(2)
+			},
+			update: model.User{ID: 1, FirstName: "New"},
+			expUser: model.User{
+				ID: 1, Username: "user1", FirstName: "New", SecondName: "Secondname",
+			},
+			expError: false,
+		},
+		{
+			name: "username is invalid",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), 1).Return(model.User{
+					ID: 1, Username: "user1", FirstName: "Name", SecondName: "Secondname",
+				}, nil)
+				s.EXPECT().Users().Return(ur)
+			},
+			update:   model.User{ID: 1, Username: "u"},
+			expError: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			store := mock_store.NewMockStore(c)
+			tc.mock(c, store)
+			u, err := newUserService(store, nil).UpdateProfile(context.Background(), tc.update)
+
+			if !tc.expError {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expUser, u)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestUserService_ChangePassword(t *testing.T) {
+	withOneTimeCodeKey(t)
+	hash, err := bcrypt.GenerateFromPassword([]byte("password1"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := model.User{ID: 1, PasswordHash: string(hash)}
+
+	testcases := []struct {
+		name            string
+		mock            func(*gomock.Controller, *mock_store.MockStore)
+		currentPassword string
+		code            string
+		newPassword     string
+		expError        bool
+	}{
+		{
+			name: "password is changed",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil)
+				ur.EXPECT().SetPasswordHash(gomock.Any(), user.ID, gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ int, hash string) error {
+						assert.NoError(t, bcrypt.CompareHashAndPassword(
+							[]byte(hash), []byte("password2"),
+						))
+						return nil
+					},
+				)
+				s.EXPECT().Users().Return(ur).Times(2)
+				s.EXPECT().LoginThrottles().Return(noThrottle(c)).Times(2)
+				rtr := mock_store.NewMockRefreshTokenRepo(c)
+				rtr.EXPECT().RevokeByUserID(gomock.Any(), user.ID).Return(nil)
+				s.EXPECT().RefreshTokens().Return(rtr)
+			},
+			currentPassword: "password1",
+			newPassword:     "password2",
+			expError:        false,
+		},
+		{
+			name: "current password is invalid",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil)
+				s.EXPECT().Users().Return(ur)
+				ltr := noThrottle(c)
+				ltr.EXPECT().RegisterFailure(
+					gomock.Any(), accountThrottleKey(user.ID), gomock.Any(),
+				).Return(model.LoginThrottle{Failures: 1}, nil)
+				s.EXPECT().LoginThrottles().Return(ltr).Times(2)
+			},
+			currentPassword: "password3",
+			newPassword:     "password2",
+			expError:        true,
+		},
+		{
+			name: "account is locked",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil)
+				s.EXPECT().Users().Return(ur)
+				lockedUntil := time.Now().Add(time.Minute)
+				ltr := mock_store.NewMockLoginThrottleRepo(c)
+				ltr.EXPECT().Get(gomock.Any(), accountThrottleKey(user.ID)).Return(
+					model.LoginThrottle{Failures: 5, LockedUntil: &lockedUntil}, nil,
+				)
+				s.EXPECT().LoginThrottles().Return(ltr)
+			},
+			currentPassword: "password1",
+			newPassword:     "password2",
+			expError:        true,
+		},
+		{
+			name: "password is changed with step-up code",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil)
+				ur.EXPECT().SetPasswordHash(gomock.Any(), user.ID, gomock.Any()).Return(nil)
+				s.EXPECT().Users().Return(ur).Times(2)
+				expectStepUpCode(t, c, s, "123456")
+				rtr := mock_store.NewMockRefreshTokenRepo(c)
+				rtr.EXPECT().RevokeByUserID(gomock.Any(), user.ID).Return(nil)
+				s.EXPECT().RefreshTokens().Return(rtr)
+			},
+			code:        "123456",
+			newPassword: "password2",
+			expError:    false,
+		},
+		{
+			name: "step-up code is invalid",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil)
+				s.EXPECT().Users().Return(ur)
+				expectStepUpCode(t, c, s, "654321")
+			},
+			code:        "123456",
+			newPassword: "password2",
+			expError:    true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			store := mock_store.NewMockStore(c)
+			tc.mock(c, store)
+			reauth := model.Reauthentication{Password: tc.currentPassword, Code: tc.code}
+			err := newUserService(store, newAuthService(store, testKeyring(), nil, nil)).ChangePassword(
+				context.Background(), user.ID, reauth, tc.newPassword,
+			)
+
+			if !tc.expError {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestUserService_DeleteAccount(t *testing.T) {
+	withOneTimeCodeKey(t)
+	hash, err := bcrypt.GenerateFromPassword([]byte("password1"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := model.User{ID: 1, PasswordHash: string(hash)}
+
+	testcases := []struct {
+		name     string
+		mock     func(*gomock.Controller, *mock_store.MockStore)
+		reauth   model.Reauthentication
+		expError error
+	}{
+		{
+			name: "account is deleted",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil)
+				ur.EXPECT().DeleteByID(gomock.Any(), user.ID).Return(nil)
+				s.EXPECT().Users().Return(ur).Times(2)
+				s.EXPECT().LoginThrottles().Return(noThrottle(c)).Times(2)
+			},
+			reauth: model.Reauthentication{Password: "password1"},
+		},
+		{
+			name: "account is deleted with step-up code",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil)
+				ur.EXPECT().DeleteByID(gomock.Any(), user.ID).Return(nil)
+				s.EXPECT().Users().Return(ur).Times(2)
+				expectStepUpCode(t, c, s, "123456")
+			},
+			reauth: model.Reauthentication{Code: "123456"},
+		},
+		{
+			name: "password is invalid",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil)
+				s.EXPECT().Users().Return(ur)
+				s.EXPECT().LoginThrottles().Return(anyThrottle(c)).Times(2)
+			},
+			reauth:   model.Reauthentication{Password: "password2"},
+			expError: service.ErrInvalidCredentials,
+		},
+		{
+			name: "account is locked",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil)
+				s.EXPECT().Users().Return(ur)
+				lockedUntil := time.Now().Add(time.Minute)
+				ltr := mock_store.NewMockLoginThrottleRepo(c)
+				ltr.EXPECT().Get(gomock.Any(), accountThrottleKey(user.ID)).Return(
+					model.LoginThrottle{Failures: 5, LockedUntil: &lockedUntil}, nil,
+				)
+				s.EXPECT().LoginThrottles().Return(ltr)
+			},
+			reauth:   model.Reauthentication{Password: "password1"},
+			expError: service.ErrAccountLocked,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			store := mock_store.NewMockStore(c)
+			tc.mock(c, store)
+			err := newUserService(store, newAuthService(store, testKeyring(), nil, nil)).DeleteAccount(
+				context.Background(), user.ID, tc.reauth,
+			)
+
+			if tc.expError == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, tc.expError), err)
+			}
+		})
+	}
+}
+
+func TestUserService_Unlock(t *testing.T) {
+	testcases := []struct {
+		name     string
+		mock     func(*gomock.Controller, *mock_store.MockStore)
+		userID   int
+		expError bool
+	}{
+		{
+			name: "user is unlocked",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), 1).Return(model.User{ID: 1}, nil)
+				s.EXPECT().Users().Return(ur)
+				ltr := mock_store.NewMockLoginThrottleRepo(c)
+				ltr.EXPECT().Delete(gomock.Any(), accountThrottleKey(1)).Return(nil)
+				s.EXPECT().LoginThrottles().Return(ltr)
+			},
+			userID:   1,
+			expError: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			store := mock_store.NewMockStore(c)
+			tc.mock(c, store)
+			err := newUserService(store, nil).Unlock(context.Background(), tc.userID)
+
+			if !tc.expError {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
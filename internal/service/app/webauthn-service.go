@@ -0,0 +1,319 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+
+	"github.com/imarrche/jwt-auth-example/internal/config"
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/service"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// defaultCredentialName is the name of WebAuthn credentials registered without one.
+const defaultCredentialName = "Passkey"
+
+// webAuthnService implements WebAuthn(passkey) business logic. Challenges are carried
+// by single use session JSON Web Tokens, so nothing is stored between ceremony steps.
+type webAuthnService struct {
+	store store.Store
+	auth  *authService
+}
+
+// newWebAuthnService creates and returns a new webAuthnService instance.
+func newWebAuthnService(s store.Store, auth *authService) *webAuthnService {
+	return &webAuthnService{store: s, auth: auth}
+}
+
+// BeginRegistration returns options of credential creation for the user along with the
+// session JSON Web Token to finish registration with.
+func (s *webAuthnService) BeginRegistration(
+	ctx context.Context, userID int,
+) (model.WebAuthnCreationOptions, string, error) {
+	u, err := s.store.Users().GetByID(ctx, userID)
+	if err != nil {
+		return model.WebAuthnCreationOptions{}, "", serviceError(err)
+	}
+	credentials, err := s.store.WebAuthnCredentials().GetByUserID(ctx, userID)
+	if err != nil {
+		return model.WebAuthnCreationOptions{}, "", err
+	}
+	challenge, session, err := s.newSession(ctx, userID, "webauthn_registration")
+	if err != nil {
+		return model.WebAuthnCreationOptions{}, "", err
+	}
+
+	c := config.Get()
+	options := model.WebAuthnCreationOptions{
+		RP: model.WebAuthnRelyingParty{ID: c.WebAuthn.RPID, Name: c.WebAuthn.RPName},
+		User: model.WebAuthnUser{
+			ID: userHandle(userID), Name: u.Email, DisplayName: u.Username,
+		},
+		Challenge:          challenge,
+		PubKeyCredParams:   make([]model.WebAuthnCredentialParameter, 0, len(webAuthnAlgorithms)),
+		Timeout:            c.JWT.WebAuthnTTL.Milliseconds(),
+		ExcludeCredentials: make([]model.WebAuthnCredentialDescriptor, 0, len(credentials)),
+		AuthenticatorSelection: model.WebAuthnAuthenticatorSelection{
+			ResidentKey: "required", RequireResidentKey: true, UserVerification: "required",
+		},
+		Attestation: "none",
+	}
+	for _, alg := range webAuthnAlgorithms {
+		options.PubKeyCredParams = append(
+			options.PubKeyCredParams, model.WebAuthnCredentialParameter{Type: "public-key", Alg: alg},
+		)
+	}
+	for _, cred := range credentials {
+		id, err := decodeBase64(cred.ID)
+		if err != nil {
+			return model.WebAuthnCreationOptions{}, "", err
+		}
+		options.ExcludeCredentials = append(
+			options.ExcludeCredentials, model.WebAuthnCredentialDescriptor{Type: "public-key", ID: id},
+		)
+	}
+
+	return options, session, nil
+}
+
+// FinishRegistration verifies the attestation created with options of BeginRegistration
+// and registers the credential for the user under specific name.
+func (s *webAuthnService) FinishRegistration(
+	ctx context.Context, userID int, session, name string, att model.WebAuthnAttestation,
+) (model.WebAuthnCredential, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = defaultCredentialName
+	}
+	if err := validation.Validate(name, validation.Length(1, 64)); err != nil {
+		return model.WebAuthnCredential{}, service.NewValidationError(validation.Errors{"name": err})
+	}
+
+	cl, err := s.useSession(ctx, session, "webauthn_registration")
+	if err != nil {
+		return model.WebAuthnCredential{}, err
+	}
+	if sessionUserID, err := cl.userID(); err != nil || sessionUserID != userID {
+		return model.WebAuthnCredential{}, service.ErrInvalidToken
+	}
+
+	ad, err := verifyAttestation(cl, att)
+	if err != nil {
+		return model.WebAuthnCredential{}, service.ErrInvalidAttestation.Wrap(err)
+	}
+	cred, err := s.store.WebAuthnCredentials().Create(ctx, model.WebAuthnCredential{
+		ID:        encodeBase64(ad.CredentialID),
+		UserID:    userID,
+		Name:      name,
+		PublicKey: ad.PublicKey,
+		SignCount: int64(ad.SignCount),
+	})
+	if err != nil {
+		return model.WebAuthnCredential{}, serviceError(err)
+	}
+
+	return cred, nil
+}
+
+// verifyAttestation verifies the attestation against the session it's created for and
+// returns authenticator data with the attested credential.
+func verifyAttestation(cl *claims, att model.WebAuthnAttestation) (authenticatorData, error) {
+	if att.Type != "public-key" {
+		return authenticatorData{}, errors.New("unexpected credential type")
+	}
+	challenge, err := decodeBase64(cl.Challenge)
+	if err != nil {
+		return authenticatorData{}, err
+	}
+	err = verifyClientData(att.Response.ClientDataJSON, "webauthn.create", challenge)
+	if err != nil {
+		return authenticatorData{}, err
+	}
+	authData, err := parseAttestationObject(att.Response.AttestationObject)
+	if err != nil {
+		return authenticatorData{}, err
+	}
+	ad, err := parseAuthenticatorData(authData)
+	if err != nil {
+		return authenticatorData{}, err
+	}
+	if err := ad.verify(config.Get().WebAuthn); err != nil {
+		return authenticatorData{}, err
+	}
+	if ad.CredentialID == nil {
+		return authenticatorData{}, errors.New("no attested credential data")
+	}
+	if string(ad.CredentialID) != string(att.RawID) {
+		return authenticatorData{}, errors.New("credential ID doesn't match")
+	}
+	if _, _, err := parseCOSEKey(ad.PublicKey); err != nil {
+		return authenticatorData{}, err
+	}
+
+	return ad, nil
+}
+
+// BeginLogin returns options of credential assertion along with the session JSON Web
+// Token to finish signing in with. The user isn't known until the assertion is made.
+func (s *webAuthnService) BeginLogin(
+	ctx context.Context,
+) (model.WebAuthnRequestOptions, string, error) {
+	challenge, session, err := s.newSession(ctx, 0, "webauthn_login")
+	if err != nil {
+		return model.WebAuthnRequestOptions{}, "", err
+	}
+
+	c := config.Get()
+	return model.WebAuthnRequestOptions{
+		Challenge:        challenge,
+		Timeout:          c.JWT.WebAuthnTTL.Milliseconds(),
+		RPID:             c.WebAuthn.RPID,
+		AllowCredentials: []model.WebAuthnCredentialDescriptor{},
+		UserVerification: "required",
+	}, session, nil
+}
+
+// FinishLogin verifies the assertion made with options of BeginLogin and returns access
+// and refresh JSON Web Tokens for the user the credential is registered for. Assertions
+// with sign count which doesn't grow are refused, the authenticator might be cloned.
+func (s *webAuthnService) FinishLogin(
+	ctx context.Context, session string, assertion model.WebAuthnAssertion,
+) (string, string, error) {
+	cl, err := s.useSession(ctx, session, "webauthn_login")
+	if err != nil {
+		return "", "", err
+	}
+	cred, err := s.store.WebAuthnCredentials().GetByID(ctx, encodeBase64(assertion.RawID))
+	if errors.Is(err, store.ErrNotFound) {
+		return "", "", service.ErrInvalidAssertion.Wrap(errors.New("credential is not registered"))
+	} else if err != nil {
+		return "", "", err
+	}
+	ad, err := verifyAssertion(cl, cred, assertion)
+	if err != nil {
+		return "", "", service.ErrInvalidAssertion.Wrap(err)
+	}
+	err = s.store.WebAuthnCredentials().MarkUsed(ctx, cred.ID, int64(ad.SignCount))
+	if isTokenGone(err) {
+		return "", "", service.ErrInvalidAssertion.Wrap(errors.New("sign count didn't grow"))
+	} else if err != nil {
+		return "", "", err
+	}
+
+	u, err := s.store.Users().GetByID(ctx, cred.UserID)
+	if err != nil {
+		return "", "", err
+	}
+	if u.DisabledAt != nil {
+		return "", "", service.ErrAccountDisabled
+	}
+	if config.Get().Account.RequireVerifiedEmail && u.EmailVerifiedAt == nil {
+		return "", "", service.ErrEmailNotVerified
+	}
+
+	return s.auth.generateJWTPair(ctx, u.ID)
+}
+
+// verifyAssertion verifies the assertion of the credential against the session it's
+// made for and returns authenticator data.
+func verifyAssertion(
+	cl *claims, cred model.WebAuthnCredential, assertion model.WebAuthnAssertion,
+) (authenticatorData, error) {
+	if assertion.Type != "public-key" {
+		return authenticatorData{}, errors.New("unexpected credential type")
+	}
+	r := assertion.Response
+	if len(r.UserHandle) > 0 && string(r.UserHandle) != string(userHandle(cred.UserID)) {
+		return authenticatorData{}, errors.New("user handle doesn't match")
+	}
+	challenge, err := decodeBase64(cl.Challenge)
+	if err != nil {
+		return authenticatorData{}, err
+	}
+	if err := verifyClientData(r.ClientDataJSON, "webauthn.get", challenge); err != nil {
+		return authenticatorData{}, err
+	}
+	ad, err := parseAuthenticatorData(r.AuthenticatorData)
+	if err != nil {
+		return authenticatorData{}, err
+	}
+	if err := ad.verify(config.Get().WebAuthn); err != nil {
+		return authenticatorData{}, err
+	}
+	err = verifyAssertionSignature(cred.PublicKey, r.AuthenticatorData, r.ClientDataJSON, r.Signature)
+	if err != nil {
+		return authenticatorData{}, err
+	}
+
+	return ad, nil
+}
+
+// Credentials returns WebAuthn credentials of the user.
+func (s *webAuthnService) Credentials(
+	ctx context.Context, userID int,
+) ([]model.WebAuthnCredential, error) {
+	return s.store.WebAuthnCredentials().GetByUserID(ctx, userID)
+}
+
+// DeleteCredential deletes the WebAuthn credential with specific ID of the user.
+func (s *webAuthnService) DeleteCredential(ctx context.Context, userID int, id string) error {
+	return serviceError(s.store.WebAuthnCredentials().Delete(ctx, userID, id))
+}
+
+// newSession generates a challenge of WebAuthn ceremony of specific type and signs it
+// into session JSON Web Token. Login sessions aren't bound to a user.
+func (s *webAuthnService) newSession(
+	ctx context.Context, userID int, sessionType string,
+) ([]byte, string, error) {
+	challenge, err := newWebAuthnChallenge()
+	if err != nil {
+		return nil, "", err
+	}
+	tokenID, err := newTokenID()
+	if err != nil {
+		return nil, "", err
+	}
+
+	cl := newClaims(config.Get().JWT, userID, sessionType, tokenID)
+	cl.Challenge = encodeBase64(challenge)
+	if userID == 0 {
+		cl.Subject = ""
+	}
+	session, err := s.auth.signJWT(ctx, cl)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return challenge, session, nil
+}
+
+// useSession parses session JSON Web Token of specific type and revokes it, so every
+// challenge can be answered only once, even concurrently.
+func (s *webAuthnService) useSession(
+	ctx context.Context, session, sessionType string,
+) (*claims, error) {
+	cl, err := s.auth.parseJWT(ctx, session, sessionType)
+	if err != nil {
+		return nil, err
+	}
+	err = s.store.RevokedTokens().Use(ctx, model.RevokedToken{
+		ID: cl.Id, ExpiresAt: cl.expiresAt(),
+	})
+	if errors.Is(err, store.ErrTokenIsUsed) {
+		return nil, service.ErrInvalidToken
+	} else if err != nil {
+		return nil, err
+	}
+
+	return cl, nil
+}
+
+// userHandle returns WebAuthn user handle of the user, authenticators return it on
+// assertion of discoverable credentials.
+func userHandle(userID int) []byte {
+	return []byte(strconv.Itoa(userID))
+}
@@ -0,0 +1,370 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/service"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+	mock_store "github.com/imarrche/jwt-auth-example/internal/store/mocks"
+)
+
+// newTestWebAuthnService returns webAuthnService with mocked store.
+func newTestWebAuthnService(s store.Store) *webAuthnService {
+	return newWebAuthnService(s, newAuthService(s, testKeyring(), nil, nil))
+}
+
+// sessionChallenge returns the challenge carried by session JSON Web Token.
+func sessionChallenge(t *testing.T, session string) []byte {
+	cl := &claims{}
+	_, _, err := new(jwt.Parser).ParseUnverified(session, cl)
+	require.NoError(t, err)
+	challenge, err := decodeBase64(cl.Challenge)
+	require.NoError(t, err)
+
+	return challenge
+}
+
+// usedSession returns revoked token repository mock which reports any token as not
+// revoked and expects the session to be revoked.
+func usedSession(c *gomock.Controller) *mock_store.MockRevokedTokenRepo {
+	r := notRevoked(c)
+	r.EXPECT().Use(gomock.Any(), gomock.Any()).Return(nil)
+
+	return r
+}
+
+func TestWebAuthnService_BeginRegistration(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	s := mock_store.NewMockStore(c)
+	ur := mock_store.NewMockUserRepo(c)
+	ur.EXPECT().GetByID(gomock.Any(), 1).Return(model.User{ID: 1, Email: "user1@test.com"}, nil)
+	s.EXPECT().Users().Return(ur)
+	wcr := mock_store.NewMockWebAuthnCredentialRepo(c)
+	wcr.EXPECT().GetByUserID(gomock.Any(), 1).Return(
+		[]model.WebAuthnCredential{{ID: encodeBase64([]byte("registered")), UserID: 1}}, nil,
+	)
+	s.EXPECT().WebAuthnCredentials().Return(wcr)
+
+	options, session, err := newTestWebAuthnService(s).BeginRegistration(context.Background(), 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", options.RP.ID)
+	assert.Equal(t, model.Base64URL("1"), options.User.ID)
+	assert.Equal(t, "user1@test.com", options.User.Name)
+	assert.Len(t, options.PubKeyCredParams, len(webAuthnAlgorithms))
+	assert.Equal(t, []model.WebAuthnCredentialDescriptor{
+		{Type: "public-key", ID: []byte("registered")},
+	}, options.ExcludeCredentials)
+	assert.Equal(t, []byte(options.Challenge), sessionChallenge(t, session))
+}
+
+func TestWebAuthnService_FinishRegistration(t *testing.T) {
+	a := newTestAuthenticator(t)
+	newSession := func(userID int) (string, []byte) {
+		s := newTestWebAuthnService(nil)
+		challenge, session, err := s.newSession(context.Background(), userID, "webauthn_registration")
+		require.NoError(t, err)
+		return session, challenge
+	}
+
+	testcases := []struct {
+		name        string
+		mock        func(*gomock.Controller, *mock_store.MockStore)
+		credName    string
+		attestation func(challenge []byte) model.WebAuthnAttestation
+		userID      int
+		expError    error
+	}{
+		{
+			name: "credential is registered",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				s.EXPECT().RevokedTokens().Return(usedSession(c)).Times(2)
+				wcr := mock_store.NewMockWebAuthnCredentialRepo(c)
+				wcr.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, cred model.WebAuthnCredential) (model.WebAuthnCredential, error) {
+						assert.Equal(t, encodeBase64(a.id), cred.ID)
+						assert.Equal(t, 1, cred.UserID)
+						assert.Equal(t, "Laptop", cred.Name)
+						assert.Equal(t, a.publicKey(), cred.PublicKey)
+						return cred, nil
+					},
+				)
+				s.EXPECT().WebAuthnCredentials().Return(wcr)
+			},
+			credName:    " Laptop ",
+			attestation: a.create,
+			userID:      1,
+		},
+		{
+			name: "credential is already registered",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				s.EXPECT().RevokedTokens().Return(usedSession(c)).Times(2)
+				wcr := mock_store.NewMockWebAuthnCredentialRepo(c)
+				wcr.EXPECT().Create(gomock.Any(), gomock.Any()).Return(
+					model.WebAuthnCredential{}, store.ErrCredentialIsTaken,
+				)
+				s.EXPECT().WebAuthnCredentials().Return(wcr)
+			},
+			attestation: a.create,
+			userID:      1,
+			expError:    service.ErrCredentialIsTaken,
+		},
+		{
+			name: "attestation is made for another challenge",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				s.EXPECT().RevokedTokens().Return(usedSession(c)).Times(2)
+			},
+			attestation: func([]byte) model.WebAuthnAttestation {
+				return a.create([]byte("other challenge"))
+			},
+			userID:   1,
+			expError: service.ErrInvalidAttestation,
+		},
+		{
+			name: "attestation is made for another origin",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				s.EXPECT().RevokedTokens().Return(usedSession(c)).Times(2)
+			},
+			attestation: func(challenge []byte) model.WebAuthnAttestation {
+				att := a.create(challenge)
+				att.Response.ClientDataJSON = testClientData(
+					"webauthn.create", challenge, "https://evil.com",
+				)
+				return att
+			},
+			userID:   1,
+			expError: service.ErrInvalidAttestation,
+		},
+		{
+			name: "session is issued for another user",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				s.EXPECT().RevokedTokens().Return(usedSession(c)).Times(2)
+			},
+			attestation: a.create,
+			userID:      2,
+			expError:    service.ErrInvalidToken,
+		},
+		{
+			name: "session has already been used",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				rvr := mock_store.NewMockRevokedTokenRepo(c)
+				rvr.EXPECT().Exists(gomock.Any(), gomock.Any()).Return(true, nil)
+				s.EXPECT().RevokedTokens().Return(rvr)
+			},
+			attestation: a.create,
+			userID:      1,
+			expError:    service.ErrInvalidToken,
+		},
+		{
+			name:        "name is too long",
+			mock:        func(*gomock.Controller, *mock_store.MockStore) {},
+			credName:    strings.Repeat("a", 65),
+			attestation: a.create,
+			userID:      1,
+			expError:    service.NewValidationError(nil),
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			store := mock_store.NewMockStore(c)
+			tc.mock(c, store)
+			session, challenge := newSession(1)
+			cred, err := newTestWebAuthnService(store).FinishRegistration(
+				context.Background(), tc.userID, session, tc.credName, tc.attestation(challenge),
+			)
+
+			if tc.expError == nil {
+				require.NoError(t, err)
+				assert.Equal(t, encodeBase64(a.id), cred.ID)
+			} else {
+				assert.True(t, errors.Is(err, tc.expError), err)
+			}
+		})
+	}
+}
+
+func TestWebAuthnService_BeginLogin(t *testing.T) {
+	options, session, err := newTestWebAuthnService(nil).BeginLogin(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", options.RPID)
+	assert.Empty(t, options.AllowCredentials)
+	assert.Equal(t, "required", options.UserVerification)
+	assert.Equal(t, []byte(options.Challenge), sessionChallenge(t, session))
+}
+
+func TestWebAuthnService_FinishLogin(t *testing.T) {
+	a := newTestAuthenticator(t)
+	cred := model.WebAuthnCredential{ID: encodeBase64(a.id), UserID: 1, PublicKey: a.publicKey()}
+	disabledAt := time.Now()
+
+	testcases := []struct {
+		name      string
+		mock      func(*gomock.Controller, *mock_store.MockStore)
+		assertion func(*testing.T, []byte) model.WebAuthnAssertion
+		expError  error
+	}{
+		{
+			name: "tokens are issued",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				s.EXPECT().RevokedTokens().Return(usedSession(c)).Times(2)
+				wcr := mock_store.NewMockWebAuthnCredentialRepo(c)
+				wcr.EXPECT().GetByID(gomock.Any(), cred.ID).Return(cred, nil)
+				wcr.EXPECT().MarkUsed(gomock.Any(), cred.ID, gomock.Any()).Return(nil)
+				s.EXPECT().WebAuthnCredentials().Return(wcr).Times(2)
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), 1).Return(model.User{ID: 1}, nil)
+				s.EXPECT().Users().Return(ur)
+				rtr := mock_store.NewMockRefreshTokenRepo(c)
+				rtr.EXPECT().Create(gomock.Any(), gomock.Any()).Return(model.RefreshToken{}, nil)
+				s.EXPECT().RefreshTokens().Return(rtr)
+				s.EXPECT().Roles().Return(noRoles(c))
+			},
+			assertion: func(t *testing.T, challenge []byte) model.WebAuthnAssertion {
+				return a.get(t, challenge, 1)
+			},
+		},
+		{
+			name: "credential is not registered",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				s.EXPECT().RevokedTokens().Return(usedSession(c)).Times(2)
+				wcr := mock_store.NewMockWebAuthnCredentialRepo(c)
+				wcr.EXPECT().GetByID(gomock.Any(), cred.ID).Return(
+					model.WebAuthnCredential{}, store.ErrNotFound,
+				)
+				s.EXPECT().WebAuthnCredentials().Return(wcr)
+			},
+			assertion: func(t *testing.T, challenge []byte) model.WebAuthnAssertion {
+				return a.get(t, challenge, 1)
+			},
+			expError: service.ErrInvalidAssertion,
+		},
+		{
+			name: "signature is invalid",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				s.EXPECT().RevokedTokens().Return(usedSession(c)).Times(2)
+				wcr := mock_store.NewMockWebAuthnCredentialRepo(c)
+				wcr.EXPECT().GetByID(gomock.Any(), cred.ID).Return(cred, nil)
+				s.EXPECT().WebAuthnCredentials().Return(wcr)
+			},
+			assertion: func(t *testing.T, challenge []byte) model.WebAuthnAssertion {
+				assertion := a.get(t, challenge, 1)
+				assertion.Response.AuthenticatorData[36]++
+				return assertion
+			},
+			expError: service.ErrInvalidAssertion,
+		},
+		{
+			name: "user handle doesn't match",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				s.EXPECT().RevokedTokens().Return(usedSession(c)).Times(2)
+				wcr := mock_store.NewMockWebAuthnCredentialRepo(c)
+				wcr.EXPECT().GetByID(gomock.Any(), cred.ID).Return(cred, nil)
+				s.EXPECT().WebAuthnCredentials().Return(wcr)
+			},
+			assertion: func(t *testing.T, challenge []byte) model.WebAuthnAssertion {
+				return a.get(t, challenge, 2)
+			},
+			expError: service.ErrInvalidAssertion,
+		},
+		{
+			name: "session is used concurrently",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				rvr := notRevoked(c)
+				rvr.EXPECT().Use(gomock.Any(), gomock.Any()).Return(store.ErrTokenIsUsed)
+				s.EXPECT().RevokedTokens().Return(rvr).Times(2)
+			},
+			assertion: func(t *testing.T, challenge []byte) model.WebAuthnAssertion {
+				return a.get(t, challenge, 1)
+			},
+			expError: service.ErrInvalidToken,
+		},
+		{
+			name: "sign count didn't grow",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				s.EXPECT().RevokedTokens().Return(usedSession(c)).Times(2)
+				wcr := mock_store.NewMockWebAuthnCredentialRepo(c)
+				wcr.EXPECT().GetByID(gomock.Any(), cred.ID).Return(cred, nil)
+				wcr.EXPECT().MarkUsed(gomock.Any(), cred.ID, gomock.Any()).Return(store.ErrTokenIsUsed)
+				s.EXPECT().WebAuthnCredentials().Return(wcr).Times(2)
+			},
+			assertion: func(t *testing.T, challenge []byte) model.WebAuthnAssertion {
+				return a.get(t, challenge, 1)
+			},
+			expError: service.ErrInvalidAssertion,
+		},
+		{
+			name: "account is disabled",
+			mock: func(c *gomock.Controller, s *mock_store.MockStore) {
+				s.EXPECT().RevokedTokens().Return(usedSession(c)).Times(2)
+				wcr := mock_store.NewMockWebAuthnCredentialRepo(c)
+				wcr.EXPECT().GetByID(gomock.Any(), cred.ID).Return(cred, nil)
+				wcr.EXPECT().MarkUsed(gomock.Any(), cred.ID, gomock.Any()).Return(nil)
+				s.EXPECT().WebAuthnCredentials().Return(wcr).Times(2)
+				ur := mock_store.NewMockUserRepo(c)
+				ur.EXPECT().GetByID(gomock.Any(), 1).Return(
+					model.User{ID: 1, DisabledAt: &disabledAt}, nil,
+				)
+				s.EXPECT().Users().Return(ur)
+			},
+			assertion: func(t *testing.T, challenge []byte) model.WebAuthnAssertion {
+				return a.get(t, challenge, 1)
+			},
+			expError: service.ErrAccountDisabled,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			store := mock_store.NewMockStore(c)
+			tc.mock(c, store)
+			s := newTestWebAuthnService(store)
+			challenge, session, err := s.newSession(context.Background(), 0, "webauthn_login")
+			require.NoError(t, err)
+			access, refresh, err := s.FinishLogin(
+				context.Background(), session, tc.assertion(t, challenge),
+			)
+
+			if tc.expError == nil {
+				require.NoError(t, err)
+				assert.NotEmpty(t, access)
+				assert.NotEmpty(t, refresh)
+			} else {
+				assert.True(t, errors.Is(err, tc.expError), err)
+			}
+		})
+	}
+}
+
+func TestWebAuthnService_DeleteCredential(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	s := mock_store.NewMockStore(c)
+	wcr := mock_store.NewMockWebAuthnCredentialRepo(c)
+	wcr.EXPECT().Delete(gomock.Any(), 1, "id").Return(store.ErrNotFound)
+	s.EXPECT().WebAuthnCredentials().Return(wcr)
+
+	err := newTestWebAuthnService(s).DeleteCredential(context.Background(), 1, "id")
+
+	assert.Equal(t, service.ErrNotFound, err)
+}
@@ -0,0 +1,234 @@
+package app
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/imarrche/jwt-auth-example/internal/config"
+)
+
+// COSE algorithms of supported credential public keys.
+const (
+	coseAlgES256 = -7
+	coseAlgEdDSA = -8
+	coseAlgRS256 = -257
+)
+
+// webAuthnAlgorithms are COSE algorithms credentials can be created with in order of
+// preference.
+var webAuthnAlgorithms = []int{coseAlgES256, coseAlgEdDSA, coseAlgRS256}
+
+// Authenticator data flags.
+const (
+	authDataUserPresent      = 0x01
+	authDataUserVerified     = 0x04
+	authDataAttestedCredData = 0x40
+)
+
+// newWebAuthnChallenge generates a random challenge of WebAuthn ceremony.
+func newWebAuthnChallenge() ([]byte, error) {
+	challenge := make([]byte, 32)
+	if _, err := rand.Read(challenge); err != nil {
+		return nil, err
+	}
+
+	return challenge, nil
+}
+
+// clientData is the client data WebAuthn clients sign along with authenticator data.
+type clientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+// verifyClientData verifies that client data is collected by the ceremony of specific
+// type with specific challenge from one of configured origins.
+func verifyClientData(raw []byte, ceremonyType string, challenge []byte) error {
+	var cd clientData
+	if err := json.Unmarshal(raw, &cd); err != nil {
+		return fmt.Errorf("couldn't parse client data: %w", err)
+	}
+	if cd.Type != ceremonyType {
+		return fmt.Errorf("unexpected ceremony type: %s", cd.Type)
+	}
+	expected := []byte(encodeBase64(challenge))
+	if subtle.ConstantTimeCompare([]byte(cd.Challenge), expected) != 1 {
+		return errors.New("challenge doesn't match")
+	}
+	for _, origin := range config.Get().WebAuthn.Origins {
+		if cd.Origin == origin {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unexpected origin: %s", cd.Origin)
+}
+
+// authenticatorData is the data authenticators sign. Attested credential ID and its
+// COSE encoded public key are present only on registration.
+type authenticatorData struct {
+	RPIDHash     []byte
+	Flags        byte
+	SignCount    uint32
+	CredentialID []byte
+	PublicKey    []byte
+}
+
+// parseAuthenticatorData parses authenticator data, extensions are ignored.
+func parseAuthenticatorData(data []byte) (authenticatorData, error) {
+	if len(data) < 37 {
+		return authenticatorData{}, errors.New("authenticator data is too short")
+	}
+	ad := authenticatorData{
+		RPIDHash:  data[:32],
+		Flags:     data[32],
+		SignCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+	if ad.Flags&authDataAttestedCredData == 0 {
+		return ad, nil
+	}
+
+	// AAGUID and credential ID length precede the credential ID.
+	rest := data[37:]
+	if len(rest) < 18 {
+		return authenticatorData{}, errors.New("attested credential data is too short")
+	}
+	idLen := int(binary.BigEndian.Uint16(rest[16:18]))
+	rest = rest[18:]
+	if len(rest) < idLen {
+		return authenticatorData{}, errors.New("attested credential data is too short")
+	}
+	ad.CredentialID = rest[:idLen]
+	_, keyLen, err := cborDecode(rest[idLen:])
+	if err != nil {
+		return authenticatorData{}, fmt.Errorf("couldn't parse credential public key: %w", err)
+	}
+	ad.PublicKey = rest[idLen : idLen+keyLen]
+
+	return ad, nil
+}
+
+// verify verifies that authenticator data is scoped to the relying party and that the
+// user is both present and verified, since passkeys are the only authentication factor.
+func (ad authenticatorData) verify(c *config.WebAuthn) error {
+	rpIDHash := sha256.Sum256([]byte(c.RPID))
+	if subtle.ConstantTimeCompare(ad.RPIDHash, rpIDHash[:]) != 1 {
+		return errors.New("relying party ID doesn't match")
+	}
+	if ad.Flags&authDataUserPresent == 0 {
+		return errors.New("user isn't present")
+	}
+	if ad.Flags&authDataUserVerified == 0 {
+		return errors.New("user isn't verified")
+	}
+
+	return nil
+}
+
+// parseAttestationObject returns authenticator data of the attestation object.
+// Attestation statements aren't verified since "none" attestation is requested.
+func parseAttestationObject(data []byte) ([]byte, error) {
+	v, _, err := cborDecode(data)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse attestation object: %w", err)
+	}
+	m, ok := v.(map[interface{}]interface{})
+	if !ok {
+		return nil, errors.New("attestation object isn't a map")
+	}
+	authData, ok := m["authData"].([]byte)
+	if !ok {
+		return nil, errors.New("attestation object has no authenticator data")
+	}
+
+	return authData, nil
+}
+
+// parseCOSEKey parses COSE (RFC 8152) encoded public key of supported algorithm.
+func parseCOSEKey(data []byte) (crypto.PublicKey, int, error) {
+	v, _, err := cborDecode(data)
+	if err != nil {
+		return nil, 0, fmt.Errorf("couldn't parse COSE key: %w", err)
+	}
+	m, ok := v.(map[interface{}]interface{})
+	if !ok {
+		return nil, 0, errors.New("COSE key isn't a map")
+	}
+	kty, _ := m[int64(1)].(int64)
+	alg, _ := m[int64(3)].(int64)
+	crv, _ := m[int64(-1)].(int64)
+	x, _ := m[int64(-2)].([]byte)
+	y, _ := m[int64(-3)].([]byte)
+	// RSA keys reuse the labels for modulus and exponent.
+	rsaN, _ := m[int64(-1)].([]byte)
+	rsaE, _ := m[int64(-2)].([]byte)
+
+	switch {
+	case alg == coseAlgES256 && kty == 2 && crv == 1:
+		// EC2 key on P-256 curve.
+		if len(x) != 32 || len(y) != 32 {
+			return nil, 0, errors.New("invalid P-256 key coordinates")
+		}
+		key := &ecdsa.PublicKey{
+			Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y),
+		}
+		if !key.Curve.IsOnCurve(key.X, key.Y) {
+			return nil, 0, errors.New("P-256 key isn't on the curve")
+		}
+		return key, coseAlgES256, nil
+	case alg == coseAlgEdDSA && kty == 1 && crv == 6:
+		// OKP key on Ed25519 curve.
+		if len(x) != ed25519.PublicKeySize {
+			return nil, 0, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), coseAlgEdDSA, nil
+	case alg == coseAlgRS256 && kty == 3:
+		n, e := new(big.Int).SetBytes(rsaN), new(big.Int).SetBytes(rsaE)
+		if n.BitLen() < 2048 || !e.IsInt64() || e.Int64() > 1<<31-1 || e.Int64() < 3 {
+			return nil, 0, errors.New("invalid RSA key")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, coseAlgRS256, nil
+	}
+
+	return nil, 0, fmt.Errorf("unsupported COSE key algorithm: %d", alg)
+}
+
+// verifyAssertionSignature verifies the signature of authenticator data and client data
+// hash with COSE encoded public key.
+func verifyAssertionSignature(publicKey, authData, clientDataJSON, signature []byte) error {
+	key, alg, err := parseCOSEKey(publicKey)
+	if err != nil {
+		return err
+	}
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte{}, authData...), clientDataHash[:]...)
+
+	ok := false
+	switch alg {
+	case coseAlgES256:
+		digest := sha256.Sum256(signed)
+		ok = ecdsa.VerifyASN1(key.(*ecdsa.PublicKey), digest[:], signature)
+	case coseAlgEdDSA:
+		ok = ed25519.Verify(key.(ed25519.PublicKey), signed, signature)
+	case coseAlgRS256:
+		digest := sha256.Sum256(signed)
+		ok = rsa.VerifyPKCS1v15(key.(*rsa.PublicKey), crypto.SHA256, digest[:], signature) == nil
+	}
+	if !ok {
+		return errors.New("signature is invalid")
+	}
+
+	return nil
+}
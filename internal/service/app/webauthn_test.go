@@ -0,0 +1,342 @@
+package app
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imarrche/jwt-auth-example/internal/config"
+	"github.com/imarrche/jwt-auth-example/internal/model"
+)
+
+// testOrigin is the origin of WebAuthn ceremonies allowed by default config.
+const testOrigin = "http://localhost:8080"
+
+// testAuthenticator is a software authenticator with a single ES256 credential.
+type testAuthenticator struct {
+	id        []byte
+	key       *ecdsa.PrivateKey
+	signCount uint32
+}
+
+// newTestAuthenticator creates and returns a new testAuthenticator instance.
+func newTestAuthenticator(t *testing.T) *testAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	return &testAuthenticator{id: []byte("test-credential"), key: key}
+}
+
+// publicKey returns COSE encoded public key of the credential.
+func (a *testAuthenticator) publicKey() []byte {
+	return cborEncode(map[interface{}]interface{}{
+		1: 2, 3: coseAlgES256, -1: 1,
+		-2: padLeft(a.key.X.Bytes(), 32), -3: padLeft(a.key.Y.Bytes(), 32),
+	})
+}
+
+// authData returns authenticator data scoped to the relying party with specific flags,
+// attested credential data is included on registration.
+func (a *testAuthenticator) authData(rpID string, flags byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	data := append(rpIDHash[:], flags, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(data[33:], a.signCount)
+	if flags&authDataAttestedCredData != 0 {
+		data = append(data, make([]byte, 16)...)
+		data = append(data, byte(len(a.id)>>8), byte(len(a.id)))
+		data = append(data, a.id...)
+		data = append(data, a.publicKey()...)
+	}
+
+	return data
+}
+
+// create returns the attestation of the credential for the challenge.
+func (a *testAuthenticator) create(challenge []byte) model.WebAuthnAttestation {
+	flags := byte(authDataUserPresent | authDataUserVerified | authDataAttestedCredData)
+	authData := a.authData("localhost", flags)
+	return model.WebAuthnAttestation{
+		ID:    encodeBase64(a.id),
+		RawID: a.id,
+		Type:  "public-key",
+		Response: model.WebAuthnAttestationResponse{
+			ClientDataJSON: testClientData("webauthn.create", challenge, testOrigin),
+			AttestationObject: cborEncode(map[interface{}]interface{}{
+				"fmt": "none", "attStmt": map[interface{}]interface{}{}, "authData": authData,
+			}),
+		},
+	}
+}
+
+// get returns the assertion of the credential registered for the user for the challenge
+// and increments sign count.
+func (a *testAuthenticator) get(
+	t *testing.T, challenge []byte, userID int,
+) model.WebAuthnAssertion {
+	a.signCount++
+	authData := a.authData("localhost", authDataUserPresent|authDataUserVerified)
+	clientDataJSON := testClientData("webauthn.get", challenge, testOrigin)
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	require.NoError(t, err)
+
+	return model.WebAuthnAssertion{
+		ID:    encodeBase64(a.id),
+		RawID: a.id,
+		Type:  "public-key",
+		Response: model.WebAuthnAssertionResponse{
+			ClientDataJSON:    clientDataJSON,
+			AuthenticatorData: authData,
+			Signature:         signature,
+			UserHandle:        userHandle(userID),
+		},
+	}
+}
+
+// testClientData returns client data JSON collected by the ceremony.
+func testClientData(ceremonyType string, challenge []byte, origin string) []byte {
+	data, _ := json.Marshal(clientData{
+		Type: ceremonyType, Challenge: encodeBase64(challenge), Origin: origin,
+	})
+
+	return data
+}
+
+// padLeft pads big-endian integer bytes with leading zeros to specific size.
+func padLeft(b []byte, size int) []byte {
+	return append(make([]byte, size-len(b)), b...)
+}
+
+func TestVerifyClientData(t *testing.T) {
+	challenge := []byte("challenge")
+
+	testcases := []struct {
+		name       string
+		clientData []byte
+		expError   bool
+	}{
+		{
+			name:       "client data is valid",
+			clientData: testClientData("webauthn.get", challenge, testOrigin),
+		},
+		{
+			name:       "ceremony type doesn't match",
+			clientData: testClientData("webauthn.create", challenge, testOrigin),
+			expError:   true,
+		},
+		{
+			name:       "challenge doesn't match",
+			clientData: testClientData("webauthn.get", []byte("other"), testOrigin),
+			expError:   true,
+		},
+		{
+			name:       "origin isn't allowed",
+			clientData: testClientData("webauthn.get", challenge, "https://evil.com"),
+			expError:   true,
+		},
+		{
+			name:       "client data is malformed",
+			clientData: []byte("{"),
+			expError:   true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := verifyClientData(tc.clientData, "webauthn.get", challenge)
+
+			if tc.expError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestParseAuthenticatorData(t *testing.T) {
+	a := newTestAuthenticator(t)
+	a.signCount = 7
+	data := a.authData("localhost", authDataUserPresent|authDataAttestedCredData)
+
+	ad, err := parseAuthenticatorData(data)
+
+	require.NoError(t, err)
+	assert.Equal(t, uint32(7), ad.SignCount)
+	assert.Equal(t, a.id, ad.CredentialID)
+	assert.Equal(t, a.publicKey(), ad.PublicKey)
+
+	for _, size := range []int{0, 36, 40, 60, len(data) - 1} {
+		_, err := parseAuthenticatorData(data[:size])
+		assert.Error(t, err, size)
+	}
+}
+
+func TestAuthenticatorData_verify(t *testing.T) {
+	a := newTestAuthenticator(t)
+
+	testcases := []struct {
+		name     string
+		rpID     string
+		flags    byte
+		expError bool
+	}{
+		{
+			name:  "authenticator data is valid",
+			rpID:  "localhost",
+			flags: authDataUserPresent | authDataUserVerified,
+		},
+		{
+			name:     "relying party ID doesn't match",
+			rpID:     "evil.com",
+			flags:    authDataUserPresent | authDataUserVerified,
+			expError: true,
+		},
+		{
+			name:     "user isn't present",
+			rpID:     "localhost",
+			flags:    authDataUserVerified,
+			expError: true,
+		},
+		{
+			name:     "user isn't verified",
+			rpID:     "localhost",
+			flags:    authDataUserPresent,
+			expError: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ad, err := parseAuthenticatorData(a.authData(tc.rpID, tc.flags))
+			require.NoError(t, err)
+
+			err = ad.verify(config.Get().WebAuthn)
+
+			if tc.expError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestParseAttestationObject(t *testing.T) {
+	att := newTestAuthenticator(t).create([]byte("challenge"))
+
+	authData, err := parseAttestationObject(att.Response.AttestationObject)
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, authData)
+
+	_, err = parseAttestationObject(cborEncode(map[interface{}]interface{}{"fmt": "none"}))
+	assert.Error(t, err)
+	_, err = parseAttestationObject(cborEncode("authData"))
+	assert.Error(t, err)
+}
+
+func TestVerifyAssertionSignature(t *testing.T) {
+	authData, clientDataJSON := []byte("authenticator data"), []byte("client data")
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte{}, authData...), clientDataHash[:]...)
+	digest := sha256.Sum256(signed)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ecSignature, err := ecdsa.SignASN1(rand.Reader, ecKey, digest[:])
+	require.NoError(t, err)
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rsaSignature, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+	smallRSAKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	ecCOSEKey := cborEncode(map[interface{}]interface{}{
+		1: 2, 3: coseAlgES256, -1: 1,
+		-2: padLeft(ecKey.X.Bytes(), 32), -3: padLeft(ecKey.Y.Bytes(), 32),
+	})
+	rsaCOSEKey := func(key *rsa.PrivateKey) []byte {
+		return cborEncode(map[interface{}]interface{}{
+			1: 3, 3: coseAlgRS256, -1: key.N.Bytes(), -2: big.NewInt(int64(key.E)).Bytes(),
+		})
+	}
+
+	testcases := []struct {
+		name      string
+		publicKey []byte
+		signature []byte
+		expError  bool
+	}{
+		{
+			name:      "ES256 signature is valid",
+			publicKey: ecCOSEKey,
+			signature: ecSignature,
+		},
+		{
+			name: "EdDSA signature is valid",
+			publicKey: cborEncode(map[interface{}]interface{}{
+				1: 1, 3: coseAlgEdDSA, -1: 6, -2: []byte(edPublic),
+			}),
+			signature: ed25519.Sign(edPrivate, signed),
+		},
+		{
+			name:      "RS256 signature is valid",
+			publicKey: rsaCOSEKey(rsaKey),
+			signature: rsaSignature,
+		},
+		{
+			name:      "signature is invalid",
+			publicKey: ecCOSEKey,
+			signature: rsaSignature,
+			expError:  true,
+		},
+		{
+			name:      "RSA key is too small",
+			publicKey: rsaCOSEKey(smallRSAKey),
+			signature: rsaSignature,
+			expError:  true,
+		},
+		{
+			name: "point isn't on the curve",
+			publicKey: cborEncode(map[interface{}]interface{}{
+				1: 2, 3: coseAlgES256, -1: 1, -2: make([]byte, 32), -3: make([]byte, 32),
+			}),
+			signature: ecSignature,
+			expError:  true,
+		},
+		{
+			name:      "algorithm isn't supported",
+			publicKey: cborEncode(map[interface{}]interface{}{1: 2, 3: -35, -1: 2}),
+			signature: ecSignature,
+			expError:  true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := verifyAssertionSignature(tc.publicKey, authData, clientDataJSON, tc.signature)
+
+			if tc.expError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
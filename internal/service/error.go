@@ -0,0 +1,154 @@
+package service
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// Kind is the kind of a domain error, it defines how the error is reported to clients.
+type Kind int
+
+// Domain error kinds.
+const (
+	KindInternal Kind = iota
+	KindMalformed
+	KindInvalid
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindLocked
+	KindTooManyRequests
+)
+
+// Error is a domain error. Code is a stable machine readable code of the error, Fields
+// are per field validation errors and Err is the cause of the error.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Fields  map[string]string
+	Err     error
+}
+
+// Error returns the message of the error followed by its cause.
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+
+	return e.Message
+}
+
+// Unwrap returns the cause of the error.
+func (e *Error) Unwrap() error { return e.Err }
+
+// Is reports whether target is a domain error with the same code, so errors wrapping
+// a cause match their sentinel.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+
+	return ok && t.Code == e.Code
+}
+
+// Wrap returns a copy of the error with specific cause.
+func (e *Error) Wrap(err error) *Error {
+	c := *e
+	c.Err = err
+
+	return &c
+}
+
+var (
+	ErrInternal = &Error{
+		Kind: KindInternal, Code: "internal", Message: "internal error",
+	}
+	ErrValidationFailed = &Error{
+		Kind: KindInvalid, Code: "validation_failed", Message: "validation failed",
+	}
+	ErrMalformedRequest = &Error{
+		Kind: KindMalformed, Code: "malformed_request", Message: "request is malformed",
+	}
+	ErrUnauthorized = &Error{
+		Kind: KindUnauthorized, Code: "unauthorized", Message: "authorization is required",
+	}
+	ErrInvalidToken = &Error{
+		Kind: KindUnauthorized, Code: "invalid_token", Message: "JWT is invalid",
+	}
+	ErrTokenReused = &Error{
+		Kind: KindUnauthorized, Code: "token_reused", Message: "JWT reuse detected",
+	}
+	ErrInvalidCredentials = &Error{
+		Kind: KindUnauthorized, Code: "invalid_credentials", Message: "invalid credentials",
+	}
+	ErrEmailNotVerified = &Error{
+		Kind: KindForbidden, Code: "email_not_verified", Message: "email is not verified",
+	}
+	ErrAccountDisabled = &Error{
+		Kind: KindForbidden, Code: "account_disabled", Message: "account is disabled",
+	}
+	ErrPermissionDenied = &Error{
+		Kind: KindForbidden, Code: "permission_denied", Message: "permission denied",
+	}
+	ErrNotFound = &Error{
+		Kind: KindNotFound, Code: "not_found", Message: "not found",
+	}
+	ErrUsernameIsTaken = &Error{
+		Kind: KindConflict, Code: "username_taken", Message: "user with this username already exists",
+	}
+	ErrEmailIsTaken = &Error{
+		Kind: KindConflict, Code: "email_taken", Message: "user with this email already exists",
+	}
+	ErrCredentialIsTaken = &Error{
+		Kind: KindConflict, Code: "credential_taken", Message: "credential is already registered",
+	}
+	ErrMFAEnabled = &Error{
+		Kind: KindConflict, Code: "mfa_enabled", Message: "MFA is already enabled",
+	}
+	ErrMFANotEnabled = &Error{
+		Kind: KindConflict, Code: "mfa_not_enabled", Message: "MFA is not enabled",
+	}
+	ErrInvalidOneTimeToken = &Error{
+		Kind: KindInvalid, Code: "invalid_one_time_token", Message: "token is invalid or expired",
+	}
+	ErrInvalidOneTimeCode = &Error{
+		Kind: KindInvalid, Code: "invalid_one_time_code", Message: "code is invalid or expired",
+	}
+	ErrInvalidMFACode = &Error{
+		Kind: KindInvalid, Code: "invalid_mfa_code", Message: "MFA code is invalid",
+	}
+	ErrInvalidAttestation = &Error{
+		Kind: KindInvalid, Code: "invalid_attestation", Message: "WebAuthn attestation is invalid",
+	}
+	ErrInvalidAssertion = &Error{
+		Kind: KindUnauthorized, Code: "invalid_assertion", Message: "WebAuthn assertion is invalid",
+	}
+	ErrAccountLocked = &Error{
+		Kind: KindLocked, Code: "account_locked", Message: "account is temporarily locked",
+	}
+	ErrTooManyAttempts = &Error{
+		Kind:    KindTooManyRequests,
+		Code:    "too_many_attempts",
+		Message: "too many sign in attempts, try again later",
+	}
+	ErrCodeRecentlySent = &Error{
+		Kind:    KindTooManyRequests,
+		Code:    "code_recently_sent",
+		Message: "code has recently been sent, try again later",
+	}
+	ErrRateLimitExceeded = &Error{
+		Kind: KindTooManyRequests, Code: "rate_limit_exceeded", Message: "rate limit exceeded",
+	}
+)
+
+// NewValidationError returns a domain error with per field errors of validation errors.
+func NewValidationError(err error) *Error {
+	e := ErrValidationFailed.Wrap(err)
+	if errs, ok := err.(validation.Errors); ok {
+		e.Fields = make(map[string]string, len(errs))
+		for field, fieldErr := range errs {
+			e.Fields[field] = fieldErr.Error()
+		}
+	}
+
+	return e
+}
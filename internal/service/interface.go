@@ -1,18 +1,103 @@
 package service
 
-import "github.com/imarrche/jwt-auth-example/internal/model"
+import (
+	"context"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+)
 
 //go:generate mockgen -source=interface.go -destination=mocks/mock.go
 
 // Service is the interface all services must implement.
 type Service interface {
 	Auth() Auth
+	Keys() Keys
+	Roles() Roles
+	Users() Users
+	Admin() Admin
+	MFA() MFA
+	WebAuthn() WebAuthn
 }
 
 // Auth is the interface all authorization services must implement.
 type Auth interface {
-	SignUp(model.User) (model.User, error)
-	SignIn(string, string) (string, string, error)
-	ValidateJWT(string, string) (int, error)
-	RefreshAccessJWT(string) (string, error)
+	SignUp(context.Context, model.User) (model.User, error)
+	SignIn(context.Context, string, string, string) (model.SignInResult, error)
+	VerifyMFA(context.Context, string, string) (string, string, error)
+	ValidateJWT(context.Context, string, string) (model.Principal, error)
+	Refresh(context.Context, string) (string, string, error)
+	SignOut(context.Context, string, string) error
+	ForgotPassword(context.Context, string) error
+	ResetPassword(context.Context, string, string) error
+	VerifyEmail(context.Context, string) error
+	SendMagicLink(context.Context, string) (string, error)
+	ConsumeMagicLink(context.Context, string, string) (model.SignInResult, error)
+	SendSignInCode(context.Context, string) error
+	SignInWithCode(context.Context, string, string, string) (model.SignInResult, error)
+	SendStepUpCode(context.Context, int) error
+	JWKS(context.Context) (model.JWKSet, error)
+}
+
+// Keys is the interface all signing key services must implement.
+type Keys interface {
+	GetAll(context.Context) ([]model.SigningKey, error)
+	Add(context.Context, string, string, []byte) (model.SigningKey, error)
+	Promote(context.Context, string) error
+	Retire(context.Context, string) error
+	Purge(context.Context) error
+}
+
+// Roles is the interface all role services must implement.
+type Roles interface {
+	GetAll(context.Context) ([]model.Role, error)
+	GetByUserID(context.Context, int) ([]model.Role, error)
+	Grant(context.Context, int, string) error
+	Revoke(context.Context, int, string) error
+}
+
+// Users is the interface all user services must implement.
+type Users interface {
+	GetByID(context.Context, int) (model.User, error)
+	List(context.Context, model.UserQuery, string) (model.UserPage, error)
+	UpdateProfile(context.Context, model.User) (model.User, error)
+	ChangePassword(context.Context, int, model.Reauthentication, string) error
+	DeleteAccount(context.Context, int, model.Reauthentication) error
+	Unlock(context.Context, int) error
+}
+
+// Admin is the interface all user management services must implement. Changes are made
+// on behalf of the admin with specific ID and recorded in the audit trail.
+type Admin interface {
+	CreateUser(context.Context, int, model.User) (model.User, error)
+	UpdateUser(context.Context, int, model.User) (model.User, error)
+	DisableUser(context.Context, int, int) error
+	EnableUser(context.Context, int, int) error
+	DeleteUser(context.Context, int, int) error
+	ResetPassword(context.Context, int, int) error
+	RevokeSessions(context.Context, int, int) error
+	GrantRole(context.Context, int, int, string) error
+	RevokeRole(context.Context, int, int, string) error
+	UnlockUser(context.Context, int, int) error
+	AuditEvents(context.Context, int) ([]model.AuditEvent, error)
+}
+
+// MFA is the interface all multi-factor authentication services must implement.
+type MFA interface {
+	EnrollTOTP(context.Context, int) (model.TOTPEnrollment, error)
+	ConfirmTOTP(context.Context, int, string) ([]string, error)
+	DisableTOTP(context.Context, int, string) error
+	RegenerateRecoveryCodes(context.Context, int, string) ([]string, error)
+	Status(context.Context, int) (model.MFAStatus, error)
+}
+
+// WebAuthn is the interface all WebAuthn(passkey) services must implement.
+type WebAuthn interface {
+	BeginRegistration(context.Context, int) (model.WebAuthnCreationOptions, string, error)
+	FinishRegistration(
+		context.Context, int, string, string, model.WebAuthnAttestation,
+	) (model.WebAuthnCredential, error)
+	BeginLogin(context.Context) (model.WebAuthnRequestOptions, string, error)
+	FinishLogin(context.Context, string, model.WebAuthnAssertion) (string, string, error)
+	Credentials(context.Context, int) ([]model.WebAuthnCredential, error)
+	DeleteCredential(context.Context, int, string) error
 }
@@ -5,6 +5,7 @@
 package mock_service
 
 import (
+	context "context"
 	gomock "github.com/golang/mock/gomock"
 	model "github.com/imarrche/jwt-auth-example/internal/model"
 	service "github.com/imarrche/jwt-auth-example/internal/service"
@@ -48,6 +49,90 @@ func (mr *MockServiceMockRecorder) Auth() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Auth", reflect.TypeOf((*MockService)(nil).Auth))
 }
 
+// Keys mocks base method
+func (m *MockService) Keys() service.Keys {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Keys")
+	ret0, _ := ret[0].(service.Keys)
+	return ret0
+}
+
+// Keys indicates an expected call of Keys
+func (mr *MockServiceMockRecorder) Keys() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Keys", reflect.TypeOf((*MockService)(nil).Keys))
+}
+
+// Roles mocks base method
+func (m *MockService) Roles() service.Roles {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Roles")
+	ret0, _ := ret[0].(service.Roles)
+	return ret0
+}
+
+// Roles indicates an expected call of Roles
+func (mr *MockServiceMockRecorder) Roles() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Roles", reflect.TypeOf((*MockService)(nil).Roles))
+}
+
+// Users mocks base method
+func (m *MockService) Users() service.Users {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Users")
+	ret0, _ := ret[0].(service.Users)
+	return ret0
+}
+
+// Users indicates an expected call of Users
+func (mr *MockServiceMockRecorder) Users() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Users", reflect.TypeOf((*MockService)(nil).Users))
+}
+
+// Admin mocks base method
+func (m *MockService) Admin() service.Admin {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Admin")
+	ret0, _ := ret[0].(service.Admin)
+	return ret0
+}
+
+// Admin indicates an expected call of Admin
+func (mr *MockServiceMockRecorder) Admin() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Admin", reflect.TypeOf((*MockService)(nil).Admin))
+}
+
+// MFA mocks base method
+func (m *MockService) MFA() service.MFA {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MFA")
+	ret0, _ := ret[0].(service.MFA)
+	return ret0
+}
+
+// MFA indicates an expected call of MFA
+func (mr *MockServiceMockRecorder) MFA() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MFA", reflect.TypeOf((*MockService)(nil).MFA))
+}
+
+// WebAuthn mocks base method
+func (m *MockService) WebAuthn() service.WebAuthn {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WebAuthn")
+	ret0, _ := ret[0].(service.WebAuthn)
+	return ret0
+}
+
+// WebAuthn indicates an expected call of WebAuthn
+func (mr *MockServiceMockRecorder) WebAuthn() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WebAuthn", reflect.TypeOf((*MockService)(nil).WebAuthn))
+}
+
 // MockAuth is a mock of Auth interface
 type MockAuth struct {
 	ctrl     *gomock.Controller
@@ -72,62 +157,900 @@ func (m *MockAuth) EXPECT() *MockAuthMockRecorder {
 }
 
 // SignUp mocks base method
-func (m *MockAuth) SignUp(arg0 model.User) (model.User, error) {
+func (m *MockAuth) SignUp(arg0 context.Context, arg1 model.User) (model.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SignUp", arg0)
+	ret := m.ctrl.Call(m, "SignUp", arg0, arg1)
 	ret0, _ := ret[0].(model.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SignUp indicates an expected call of SignUp
-func (mr *MockAuthMockRecorder) SignUp(arg0 interface{}) *gomock.Call {
+func (mr *MockAuthMockRecorder) SignUp(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignUp", reflect.TypeOf((*MockAuth)(nil).SignUp), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignUp", reflect.TypeOf((*MockAuth)(nil).SignUp), arg0, arg1)
 }
 
 // SignIn mocks base method
-func (m *MockAuth) SignIn(arg0, arg1 string) (string, string, error) {
+func (m *MockAuth) SignIn(arg0 context.Context, arg1, arg2, arg3 string) (model.SignInResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignIn", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(model.SignInResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SignIn indicates an expected call of SignIn
+func (mr *MockAuthMockRecorder) SignIn(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignIn", reflect.TypeOf((*MockAuth)(nil).SignIn), arg0, arg1, arg2, arg3)
+}
+
+// VerifyMFA mocks base method
+func (m *MockAuth) VerifyMFA(arg0 context.Context, arg1, arg2 string) (string, string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SignIn", arg0, arg1)
+	ret := m.ctrl.Call(m, "VerifyMFA", arg0, arg1, arg2)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(string)
 	ret2, _ := ret[2].(error)
 	return ret0, ret1, ret2
 }
 
-// SignIn indicates an expected call of SignIn
-func (mr *MockAuthMockRecorder) SignIn(arg0, arg1 interface{}) *gomock.Call {
+// VerifyMFA indicates an expected call of VerifyMFA
+func (mr *MockAuthMockRecorder) VerifyMFA(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignIn", reflect.TypeOf((*MockAuth)(nil).SignIn), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyMFA", reflect.TypeOf((*MockAuth)(nil).VerifyMFA), arg0, arg1, arg2)
 }
 
 // ValidateJWT mocks base method
-func (m *MockAuth) ValidateJWT(arg0, arg1 string) (int, error) {
+func (m *MockAuth) ValidateJWT(arg0 context.Context, arg1, arg2 string) (model.Principal, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ValidateJWT", arg0, arg1)
-	ret0, _ := ret[0].(int)
+	ret := m.ctrl.Call(m, "ValidateJWT", arg0, arg1, arg2)
+	ret0, _ := ret[0].(model.Principal)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ValidateJWT indicates an expected call of ValidateJWT
-func (mr *MockAuthMockRecorder) ValidateJWT(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockAuthMockRecorder) ValidateJWT(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateJWT", reflect.TypeOf((*MockAuth)(nil).ValidateJWT), arg0, arg1, arg2)
+}
+
+// Refresh mocks base method
+func (m *MockAuth) Refresh(arg0 context.Context, arg1 string) (string, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refresh", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Refresh indicates an expected call of Refresh
+func (mr *MockAuthMockRecorder) Refresh(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MockAuth)(nil).Refresh), arg0, arg1)
+}
+
+// SignOut mocks base method
+func (m *MockAuth) SignOut(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignOut", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SignOut indicates an expected call of SignOut
+func (mr *MockAuthMockRecorder) SignOut(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignOut", reflect.TypeOf((*MockAuth)(nil).SignOut), arg0, arg1, arg2)
+}
+
+// ForgotPassword mocks base method
+func (m *MockAuth) ForgotPassword(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForgotPassword", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ForgotPassword indicates an expected call of ForgotPassword
+func (mr *MockAuthMockRecorder) ForgotPassword(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForgotPassword", reflect.TypeOf((*MockAuth)(nil).ForgotPassword), arg0, arg1)
+}
+
+// ResetPassword mocks base method
+func (m *MockAuth) ResetPassword(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword
+func (mr *MockAuthMockRecorder) ResetPassword(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockAuth)(nil).ResetPassword), arg0, arg1, arg2)
+}
+
+// VerifyEmail mocks base method
+func (m *MockAuth) VerifyEmail(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail
+func (mr *MockAuthMockRecorder) VerifyEmail(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockAuth)(nil).VerifyEmail), arg0, arg1)
+}
+
+// SendMagicLink mocks base method
+func (m *MockAuth) SendMagicLink(arg0 context.Context, arg1 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendMagicLink", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendMagicLink indicates an expected call of SendMagicLink
+func (mr *MockAuthMockRecorder) SendMagicLink(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMagicLink", reflect.TypeOf((*MockAuth)(nil).SendMagicLink), arg0, arg1)
+}
+
+// ConsumeMagicLink mocks base method
+func (m *MockAuth) ConsumeMagicLink(arg0 context.Context, arg1, arg2 string) (model.SignInResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeMagicLink", arg0, arg1, arg2)
+	ret0, _ := ret[0].(model.SignInResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeMagicLink indicates an expected call of ConsumeMagicLink
+func (mr *MockAuthMockRecorder) ConsumeMagicLink(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeMagicLink", reflect.TypeOf((*MockAuth)(nil).ConsumeMagicLink), arg0, arg1, arg2)
+}
+
+// SendSignInCode mocks base method
+func (m *MockAuth) SendSignInCode(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendSignInCode", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendSignInCode indicates an expected call of SendSignInCode
+func (mr *MockAuthMockRecorder) SendSignInCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendSignInCode", reflect.TypeOf((*MockAuth)(nil).SendSignInCode), arg0, arg1)
+}
+
+// SignInWithCode mocks base method
+func (m *MockAuth) SignInWithCode(arg0 context.Context, arg1, arg2, arg3 string) (model.SignInResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignInWithCode", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(model.SignInResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SignInWithCode indicates an expected call of SignInWithCode
+func (mr *MockAuthMockRecorder) SignInWithCode(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignInWithCode", reflect.TypeOf((*MockAuth)(nil).SignInWithCode), arg0, arg1, arg2, arg3)
+}
+
+// SendStepUpCode mocks base method
+func (m *MockAuth) SendStepUpCode(arg0 context.Context, arg1 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendStepUpCode", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendStepUpCode indicates an expected call of SendStepUpCode
+func (mr *MockAuthMockRecorder) SendStepUpCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendStepUpCode", reflect.TypeOf((*MockAuth)(nil).SendStepUpCode), arg0, arg1)
+}
+
+// JWKS mocks base method
+func (m *MockAuth) JWKS(arg0 context.Context) (model.JWKSet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JWKS", arg0)
+	ret0, _ := ret[0].(model.JWKSet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// JWKS indicates an expected call of JWKS
+func (mr *MockAuthMockRecorder) JWKS(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JWKS", reflect.TypeOf((*MockAuth)(nil).JWKS), arg0)
+}
+
+// MockKeys is a mock of Keys interface
+type MockKeys struct {
+	ctrl     *gomock.Controller
+	recorder *MockKeysMockRecorder
+}
+
+// MockKeysMockRecorder is the mock recorder for MockKeys
+type MockKeysMockRecorder struct {
+	mock *MockKeys
+}
+
+// NewMockKeys creates a new mock instance
+func NewMockKeys(ctrl *gomock.Controller) *MockKeys {
+	mock := &MockKeys{ctrl: ctrl}
+	mock.recorder = &MockKeysMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockKeys) EXPECT() *MockKeysMockRecorder {
+	return m.recorder
+}
+
+// GetAll mocks base method
+func (m *MockKeys) GetAll(arg0 context.Context) ([]model.SigningKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", arg0)
+	ret0, _ := ret[0].([]model.SigningKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll
+func (mr *MockKeysMockRecorder) GetAll(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockKeys)(nil).GetAll), arg0)
+}
+
+// Add mocks base method
+func (m *MockKeys) Add(arg0 context.Context, arg1, arg2 string, arg3 []byte) (model.SigningKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(model.SigningKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Add indicates an expected call of Add
+func (mr *MockKeysMockRecorder) Add(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockKeys)(nil).Add), arg0, arg1, arg2, arg3)
+}
+
+// Promote mocks base method
+func (m *MockKeys) Promote(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Promote", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Promote indicates an expected call of Promote
+func (mr *MockKeysMockRecorder) Promote(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Promote", reflect.TypeOf((*MockKeys)(nil).Promote), arg0, arg1)
+}
+
+// Retire mocks base method
+func (m *MockKeys) Retire(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Retire", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Retire indicates an expected call of Retire
+func (mr *MockKeysMockRecorder) Retire(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Retire", reflect.TypeOf((*MockKeys)(nil).Retire), arg0, arg1)
+}
+
+// Purge mocks base method
+func (m *MockKeys) Purge(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge
+func (mr *MockKeysMockRecorder) Purge(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockKeys)(nil).Purge), arg0)
+}
+
+// MockRoles is a mock of Roles interface
+type MockRoles struct {
+	ctrl     *gomock.Controller
+	recorder *MockRolesMockRecorder
+}
+
+// MockRolesMockRecorder is the mock recorder for MockRoles
+type MockRolesMockRecorder struct {
+	mock *MockRoles
+}
+
+// NewMockRoles creates a new mock instance
+func NewMockRoles(ctrl *gomock.Controller) *MockRoles {
+	mock := &MockRoles{ctrl: ctrl}
+	mock.recorder = &MockRolesMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRoles) EXPECT() *MockRolesMockRecorder {
+	return m.recorder
+}
+
+// GetAll mocks base method
+func (m *MockRoles) GetAll(arg0 context.Context) ([]model.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", arg0)
+	ret0, _ := ret[0].([]model.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll
+func (mr *MockRolesMockRecorder) GetAll(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockRoles)(nil).GetAll), arg0)
+}
+
+// GetByUserID mocks base method
+func (m *MockRoles) GetByUserID(arg0 context.Context, arg1 int) ([]model.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByUserID", arg0, arg1)
+	ret0, _ := ret[0].([]model.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByUserID indicates an expected call of GetByUserID
+func (mr *MockRolesMockRecorder) GetByUserID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUserID", reflect.TypeOf((*MockRoles)(nil).GetByUserID), arg0, arg1)
+}
+
+// Grant mocks base method
+func (m *MockRoles) Grant(arg0 context.Context, arg1 int, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Grant", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Grant indicates an expected call of Grant
+func (mr *MockRolesMockRecorder) Grant(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Grant", reflect.TypeOf((*MockRoles)(nil).Grant), arg0, arg1, arg2)
+}
+
+// Revoke mocks base method
+func (m *MockRoles) Revoke(arg0 context.Context, arg1 int, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke
+func (mr *MockRolesMockRecorder) Revoke(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockRoles)(nil).Revoke), arg0, arg1, arg2)
+}
+
+// MockUsers is a mock of Users interface
+type MockUsers struct {
+	ctrl     *gomock.Controller
+	recorder *MockUsersMockRecorder
+}
+
+// MockUsersMockRecorder is the mock recorder for MockUsers
+type MockUsersMockRecorder struct {
+	mock *MockUsers
+}
+
+// NewMockUsers creates a new mock instance
+func NewMockUsers(ctrl *gomock.Controller) *MockUsers {
+	mock := &MockUsers{ctrl: ctrl}
+	mock.recorder = &MockUsersMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockUsers) EXPECT() *MockUsersMockRecorder {
+	return m.recorder
+}
+
+// GetByID mocks base method
+func (m *MockUsers) GetByID(arg0 context.Context, arg1 int) (model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", arg0, arg1)
+	ret0, _ := ret[0].(model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID
+func (mr *MockUsersMockRecorder) GetByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockUsers)(nil).GetByID), arg0, arg1)
+}
+
+// List mocks base method
+func (m *MockUsers) List(arg0 context.Context, arg1 model.UserQuery, arg2 string) (model.UserPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1, arg2)
+	ret0, _ := ret[0].(model.UserPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List
+func (mr *MockUsersMockRecorder) List(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockUsers)(nil).List), arg0, arg1, arg2)
+}
+
+// UpdateProfile mocks base method
+func (m *MockUsers) UpdateProfile(arg0 context.Context, arg1 model.User) (model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProfile", arg0, arg1)
+	ret0, _ := ret[0].(model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProfile indicates an expected call of UpdateProfile
+func (mr *MockUsersMockRecorder) UpdateProfile(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProfile", reflect.TypeOf((*MockUsers)(nil).UpdateProfile), arg0, arg1)
+}
+
+// ChangePassword mocks base method
+func (m *MockUsers) ChangePassword(arg0 context.Context, arg1 int, arg2 model.Reauthentication, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangePassword indicates an expected call of ChangePassword
+func (mr *MockUsersMockRecorder) ChangePassword(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockUsers)(nil).ChangePassword), arg0, arg1, arg2, arg3)
+}
+
+// DeleteAccount mocks base method
+func (m *MockUsers) DeleteAccount(arg0 context.Context, arg1 int, arg2 model.Reauthentication) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccount", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAccount indicates an expected call of DeleteAccount
+func (mr *MockUsersMockRecorder) DeleteAccount(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockUsers)(nil).DeleteAccount), arg0, arg1, arg2)
+}
+
+// Unlock mocks base method
+func (m *MockUsers) Unlock(arg0 context.Context, arg1 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unlock", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unlock indicates an expected call of Unlock
+func (mr *MockUsersMockRecorder) Unlock(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unlock", reflect.TypeOf((*MockUsers)(nil).Unlock), arg0, arg1)
+}
+
+// MockAdmin is a mock of Admin interface
+type MockAdmin struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdminMockRecorder
+}
+
+// MockAdminMockRecorder is the mock recorder for MockAdmin
+type MockAdminMockRecorder struct {
+	mock *MockAdmin
+}
+
+// NewMockAdmin creates a new mock instance
+func NewMockAdmin(ctrl *gomock.Controller) *MockAdmin {
+	mock := &MockAdmin{ctrl: ctrl}
+	mock.recorder = &MockAdminMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAdmin) EXPECT() *MockAdminMockRecorder {
+	return m.recorder
+}
+
+// CreateUser mocks base method
+func (m *MockAdmin) CreateUser(arg0 context.Context, arg1 int, arg2 model.User) (model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", arg0, arg1, arg2)
+	ret0, _ := ret[0].(model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUser indicates an expected call of CreateUser
+func (mr *MockAdminMockRecorder) CreateUser(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockAdmin)(nil).CreateUser), arg0, arg1, arg2)
+}
+
+// UpdateUser mocks base method
+func (m *MockAdmin) UpdateUser(arg0 context.Context, arg1 int, arg2 model.User) (model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUser", arg0, arg1, arg2)
+	ret0, _ := ret[0].(model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUser indicates an expected call of UpdateUser
+func (mr *MockAdminMockRecorder) UpdateUser(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateJWT", reflect.TypeOf((*MockAuth)(nil).ValidateJWT), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockAdmin)(nil).UpdateUser), arg0, arg1, arg2)
 }
 
-// RefreshAccessJWT mocks base method
-func (m *MockAuth) RefreshAccessJWT(arg0 string) (string, error) {
+// DisableUser mocks base method
+func (m *MockAdmin) DisableUser(arg0 context.Context, arg1, arg2 int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RefreshAccessJWT", arg0)
+	ret := m.ctrl.Call(m, "DisableUser", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableUser indicates an expected call of DisableUser
+func (mr *MockAdminMockRecorder) DisableUser(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableUser", reflect.TypeOf((*MockAdmin)(nil).DisableUser), arg0, arg1, arg2)
+}
+
+// EnableUser mocks base method
+func (m *MockAdmin) EnableUser(arg0 context.Context, arg1, arg2 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableUser", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnableUser indicates an expected call of EnableUser
+func (mr *MockAdminMockRecorder) EnableUser(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableUser", reflect.TypeOf((*MockAdmin)(nil).EnableUser), arg0, arg1, arg2)
+}
+
+// DeleteUser mocks base method
+func (m *MockAdmin) DeleteUser(arg0 context.Context, arg1, arg2 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUser indicates an expected call of DeleteUser
+func (mr *MockAdminMockRecorder) DeleteUser(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockAdmin)(nil).DeleteUser), arg0, arg1, arg2)
+}
+
+// ResetPassword mocks base method
+func (m *MockAdmin) ResetPassword(arg0 context.Context, arg1, arg2 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword
+func (mr *MockAdminMockRecorder) ResetPassword(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockAdmin)(nil).ResetPassword), arg0, arg1, arg2)
+}
+
+// RevokeSessions mocks base method
+func (m *MockAdmin) RevokeSessions(arg0 context.Context, arg1, arg2 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSessions", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSessions indicates an expected call of RevokeSessions
+func (mr *MockAdminMockRecorder) RevokeSessions(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSessions", reflect.TypeOf((*MockAdmin)(nil).RevokeSessions), arg0, arg1, arg2)
+}
+
+// GrantRole mocks base method
+func (m *MockAdmin) GrantRole(arg0 context.Context, arg1, arg2 int, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GrantRole", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GrantRole indicates an expected call of GrantRole
+func (mr *MockAdminMockRecorder) GrantRole(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantRole", reflect.TypeOf((*MockAdmin)(nil).GrantRole), arg0, arg1, arg2, arg3)
+}
+
+// RevokeRole mocks base method
+func (m *MockAdmin) RevokeRole(arg0 context.Context, arg1, arg2 int, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRole", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeRole indicates an expected call of RevokeRole
+func (mr *MockAdminMockRecorder) RevokeRole(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRole", reflect.TypeOf((*MockAdmin)(nil).RevokeRole), arg0, arg1, arg2, arg3)
+}
+
+// UnlockUser mocks base method
+func (m *MockAdmin) UnlockUser(arg0 context.Context, arg1, arg2 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnlockUser", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnlockUser indicates an expected call of UnlockUser
+func (mr *MockAdminMockRecorder) UnlockUser(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnlockUser", reflect.TypeOf((*MockAdmin)(nil).UnlockUser), arg0, arg1, arg2)
+}
+
+// AuditEvents mocks base method
+func (m *MockAdmin) AuditEvents(arg0 context.Context, arg1 int) ([]model.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuditEvents", arg0, arg1)
+	ret0, _ := ret[0].([]model.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuditEvents indicates an expected call of AuditEvents
+func (mr *MockAdminMockRecorder) AuditEvents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuditEvents", reflect.TypeOf((*MockAdmin)(nil).AuditEvents), arg0, arg1)
+}
+
+// MockMFA is a mock of MFA interface
+type MockMFA struct {
+	ctrl     *gomock.Controller
+	recorder *MockMFAMockRecorder
+}
+
+// MockMFAMockRecorder is the mock recorder for MockMFA
+type MockMFAMockRecorder struct {
+	mock *MockMFA
+}
+
+// NewMockMFA creates a new mock instance
+func NewMockMFA(ctrl *gomock.Controller) *MockMFA {
+	mock := &MockMFA{ctrl: ctrl}
+	mock.recorder = &MockMFAMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockMFA) EXPECT() *MockMFAMockRecorder {
+	return m.recorder
+}
+
+// EnrollTOTP mocks base method
+func (m *MockMFA) EnrollTOTP(arg0 context.Context, arg1 int) (model.TOTPEnrollment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnrollTOTP", arg0, arg1)
+	ret0, _ := ret[0].(model.TOTPEnrollment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnrollTOTP indicates an expected call of EnrollTOTP
+func (mr *MockMFAMockRecorder) EnrollTOTP(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnrollTOTP", reflect.TypeOf((*MockMFA)(nil).EnrollTOTP), arg0, arg1)
+}
+
+// ConfirmTOTP mocks base method
+func (m *MockMFA) ConfirmTOTP(arg0 context.Context, arg1 int, arg2 string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmTOTP", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmTOTP indicates an expected call of ConfirmTOTP
+func (mr *MockMFAMockRecorder) ConfirmTOTP(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTOTP", reflect.TypeOf((*MockMFA)(nil).ConfirmTOTP), arg0, arg1, arg2)
+}
+
+// DisableTOTP mocks base method
+func (m *MockMFA) DisableTOTP(arg0 context.Context, arg1 int, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTOTP", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTOTP indicates an expected call of DisableTOTP
+func (mr *MockMFAMockRecorder) DisableTOTP(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTOTP", reflect.TypeOf((*MockMFA)(nil).DisableTOTP), arg0, arg1, arg2)
+}
+
+// RegenerateRecoveryCodes mocks base method
+func (m *MockMFA) RegenerateRecoveryCodes(arg0 context.Context, arg1 int, arg2 string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegenerateRecoveryCodes", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RegenerateRecoveryCodes indicates an expected call of RegenerateRecoveryCodes
+func (mr *MockMFAMockRecorder) RegenerateRecoveryCodes(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegenerateRecoveryCodes", reflect.TypeOf((*MockMFA)(nil).RegenerateRecoveryCodes), arg0, arg1, arg2)
+}
+
+// Status mocks base method
+func (m *MockMFA) Status(arg0 context.Context, arg1 int) (model.MFAStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Status", arg0, arg1)
+	ret0, _ := ret[0].(model.MFAStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Status indicates an expected call of Status
+func (mr *MockMFAMockRecorder) Status(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Status", reflect.TypeOf((*MockMFA)(nil).Status), arg0, arg1)
+}
+
+// MockWebAuthn is a mock of WebAuthn interface
+type MockWebAuthn struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebAuthnMockRecorder
+}
+
+// MockWebAuthnMockRecorder is the mock recorder for MockWebAuthn
+type MockWebAuthnMockRecorder struct {
+	mock *MockWebAuthn
+}
+
+// NewMockWebAuthn creates a new mock instance
+func NewMockWebAuthn(ctrl *gomock.Controller) *MockWebAuthn {
+	mock := &MockWebAuthn{ctrl: ctrl}
+	mock.recorder = &MockWebAuthnMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockWebAuthn) EXPECT() *MockWebAuthnMockRecorder {
+	return m.recorder
+}
+
+// BeginRegistration mocks base method
+func (m *MockWebAuthn) BeginRegistration(arg0 context.Context, arg1 int) (model.WebAuthnCreationOptions, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginRegistration", arg0, arg1)
+	ret0, _ := ret[0].(model.WebAuthnCreationOptions)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// BeginRegistration indicates an expected call of BeginRegistration
+func (mr *MockWebAuthnMockRecorder) BeginRegistration(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginRegistration", reflect.TypeOf((*MockWebAuthn)(nil).BeginRegistration), arg0, arg1)
+}
+
+// FinishRegistration mocks base method
+func (m *MockWebAuthn) FinishRegistration(arg0 context.Context, arg1 int, arg2, arg3 string, arg4 model.WebAuthnAttestation) (model.WebAuthnCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishRegistration", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(model.WebAuthnCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FinishRegistration indicates an expected call of FinishRegistration
+func (mr *MockWebAuthnMockRecorder) FinishRegistration(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishRegistration", reflect.TypeOf((*MockWebAuthn)(nil).FinishRegistration), arg0, arg1, arg2, arg3, arg4)
+}
+
+// BeginLogin mocks base method
+func (m *MockWebAuthn) BeginLogin(arg0 context.Context) (model.WebAuthnRequestOptions, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginLogin", arg0)
+	ret0, _ := ret[0].(model.WebAuthnRequestOptions)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// BeginLogin indicates an expected call of BeginLogin
+func (mr *MockWebAuthnMockRecorder) BeginLogin(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginLogin", reflect.TypeOf((*MockWebAuthn)(nil).BeginLogin), arg0)
+}
+
+// FinishLogin mocks base method
+func (m *MockWebAuthn) FinishLogin(arg0 context.Context, arg1 string, arg2 model.WebAuthnAssertion) (string, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishLogin", arg0, arg1, arg2)
 	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FinishLogin indicates an expected call of FinishLogin
+func (mr *MockWebAuthnMockRecorder) FinishLogin(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishLogin", reflect.TypeOf((*MockWebAuthn)(nil).FinishLogin), arg0, arg1, arg2)
+}
+
+// Credentials mocks base method
+func (m *MockWebAuthn) Credentials(arg0 context.Context, arg1 int) ([]model.WebAuthnCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Credentials", arg0, arg1)
+	ret0, _ := ret[0].([]model.WebAuthnCredential)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// RefreshAccessJWT indicates an expected call of RefreshAccessJWT
-func (mr *MockAuthMockRecorder) RefreshAccessJWT(arg0 interface{}) *gomock.Call {
+// Credentials indicates an expected call of Credentials
+func (mr *MockWebAuthnMockRecorder) Credentials(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Credentials", reflect.TypeOf((*MockWebAuthn)(nil).Credentials), arg0, arg1)
+}
+
+// DeleteCredential mocks base method
+func (m *MockWebAuthn) DeleteCredential(arg0 context.Context, arg1 int, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCredential", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCredential indicates an expected call of DeleteCredential
+func (mr *MockWebAuthnMockRecorder) DeleteCredential(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshAccessJWT", reflect.TypeOf((*MockAuth)(nil).RefreshAccessJWT), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCredential", reflect.TypeOf((*MockWebAuthn)(nil).DeleteCredential), arg0, arg1, arg2)
 }
@@ -3,6 +3,9 @@ package store
 import "errors"
 
 var (
-	ErrUsernameIsTaken = errors.New("user with this username already exists")
-	ErrEmailIsTaken    = errors.New("user with this email already exists")
+	ErrNotFound          = errors.New("not found")
+	ErrUsernameIsTaken   = errors.New("user with this username already exists")
+	ErrEmailIsTaken      = errors.New("user with this email already exists")
+	ErrTokenIsUsed       = errors.New("token has already been used")
+	ErrCredentialIsTaken = errors.New("credential is already registered")
 )
@@ -1,6 +1,11 @@
 package store
 
-import "github.com/imarrche/jwt-auth-example/internal/model"
+import (
+	"context"
+	"time"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+)
 
 //go:generate mockgen -source=interface.go -destination=mocks/mock.go
 
@@ -8,15 +13,152 @@ import "github.com/imarrche/jwt-auth-example/internal/model"
 type Store interface {
 	Open() error
 	Users() UserRepo
+	RefreshTokens() RefreshTokenRepo
+	RevokedTokens() RevokedTokenRepo
+	SigningKeys() SigningKeyRepo
+	Roles() RoleRepo
+	PasswordResetTokens() PasswordResetTokenRepo
+	EmailVerificationTokens() EmailVerificationTokenRepo
+	LoginThrottles() LoginThrottleRepo
+	RateLimits() RateLimitRepo
+	AuditEvents() AuditEventRepo
+	TOTPSecrets() TOTPSecretRepo
+	RecoveryCodes() RecoveryCodeRepo
+	WebAuthnCredentials() WebAuthnCredentialRepo
+	MagicLinkTokens() MagicLinkTokenRepo
+	OneTimeCodes() OneTimeCodeRepo
 	Close() error
 }
 
-// UserRepo is the interface all user repositories must implement.
+// UserRepo is the interface all user repositories must implement. Update only updates
+// username, email, first and second name, password hash, email verification and
+// disabled time are set separately, so concurrent changes don't overwrite each other.
 type UserRepo interface {
-	GetAll() ([]model.User, error)
-	Create(model.User) (model.User, error)
-	GetByID(int) (model.User, error)
-	GetByEmail(string) (model.User, error)
-	Update(model.User) (model.User, error)
-	DeleteByID(int) error
+	GetAll(context.Context) ([]model.User, error)
+	List(context.Context, model.UserQuery) ([]model.User, int, error)
+	Create(context.Context, model.User) (model.User, error)
+	GetByID(context.Context, int) (model.User, error)
+	GetByEmail(context.Context, string) (model.User, error)
+	Update(context.Context, model.User) (model.User, error)
+	SetPasswordHash(context.Context, int, string) error
+	SetEmailVerifiedAt(context.Context, int, *time.Time) error
+	SetDisabledAt(context.Context, int, *time.Time) error
+	DeleteByID(context.Context, int) error
+}
+
+// RefreshTokenRepo is the interface all refresh token repositories must implement.
+type RefreshTokenRepo interface {
+	Create(context.Context, model.RefreshToken) (model.RefreshToken, error)
+	GetByID(context.Context, string) (model.RefreshToken, error)
+	MarkUsed(context.Context, string) error
+	RevokeFamily(context.Context, string) error
+	RevokeByUserID(context.Context, int) error
+	DeleteExpired(context.Context) error
+}
+
+// RevokedTokenRepo is the interface all revoked token repositories must implement.
+type RevokedTokenRepo interface {
+	Create(context.Context, model.RevokedToken) (model.RevokedToken, error)
+	Use(context.Context, model.RevokedToken) error
+	Exists(context.Context, string) (bool, error)
+	DeleteExpired(context.Context) error
+}
+
+// SigningKeyRepo is the interface all signing key repositories must implement.
+type SigningKeyRepo interface {
+	GetAll(context.Context) ([]model.SigningKey, error)
+	Create(context.Context, model.SigningKey) (model.SigningKey, error)
+	Activate(context.Context, string) error
+	Retire(context.Context, string) error
+	DeleteRetiredBefore(context.Context, time.Time) error
+}
+
+// RoleRepo is the interface all role repositories must implement.
+type RoleRepo interface {
+	GetAll(context.Context) ([]model.Role, error)
+	GetByUserID(context.Context, int) ([]model.Role, error)
+	AssignToUser(context.Context, int, string) error
+	RemoveFromUser(context.Context, int, string) error
+}
+
+// PasswordResetTokenRepo is the interface all password reset token repositories must implement.
+type PasswordResetTokenRepo interface {
+	Create(context.Context, model.PasswordResetToken) (model.PasswordResetToken, error)
+	GetByHash(context.Context, string) (model.PasswordResetToken, error)
+	MarkUsed(context.Context, string) error
+	DeleteExpired(context.Context) error
+}
+
+// MagicLinkTokenRepo is the interface all magic link token repositories must implement.
+type MagicLinkTokenRepo interface {
+	Create(context.Context, model.MagicLinkToken) (model.MagicLinkToken, error)
+	GetByHash(context.Context, string) (model.MagicLinkToken, error)
+	MarkUsed(context.Context, string) error
+	DeleteExpired(context.Context) error
+}
+
+// OneTimeCodeRepo is the interface all one-time code repositories must implement.
+type OneTimeCodeRepo interface {
+	Save(context.Context, model.OneTimeCode) (model.OneTimeCode, error)
+	Get(context.Context, int, string) (model.OneTimeCode, error)
+	Attempt(context.Context, int, string, int) (model.OneTimeCode, error)
+	Delete(context.Context, int, string) error
+	DeleteExpired(context.Context) error
+}
+
+// EmailVerificationTokenRepo is the interface all email verification token repositories
+// must implement.
+type EmailVerificationTokenRepo interface {
+	Create(context.Context, model.EmailVerificationToken) (model.EmailVerificationToken, error)
+	GetByHash(context.Context, string) (model.EmailVerificationToken, error)
+	MarkUsed(context.Context, string) error
+	DeleteExpired(context.Context) error
+}
+
+// LoginThrottleRepo is the interface all login throttle repositories must implement.
+type LoginThrottleRepo interface {
+	Get(context.Context, string) (model.LoginThrottle, error)
+	RegisterFailure(context.Context, string, time.Time) (model.LoginThrottle, error)
+	Lock(context.Context, string, time.Time) error
+	Delete(context.Context, string) error
+	DeleteStale(context.Context, time.Time) error
+}
+
+// RateLimitRepo is the interface all rate limit token bucket repositories must implement.
+type RateLimitRepo interface {
+	Take(context.Context, string, int, time.Duration) (float64, bool, error)
+	DeleteStale(context.Context, time.Time) error
+}
+
+// AuditEventRepo is the interface all audit event repositories must implement.
+type AuditEventRepo interface {
+	Create(context.Context, model.AuditEvent) (model.AuditEvent, error)
+	GetByUserID(context.Context, int) ([]model.AuditEvent, error)
+}
+
+// TOTPSecretRepo is the interface all TOTP secret repositories must implement.
+type TOTPSecretRepo interface {
+	Save(context.Context, model.TOTPSecret) error
+	GetByUserID(context.Context, int) (model.TOTPSecret, error)
+	Confirm(context.Context, int) error
+	MarkUsed(context.Context, int, int64) error
+	DeleteByUserID(context.Context, int) error
+}
+
+// RecoveryCodeRepo is the interface all MFA recovery code repositories must implement.
+type RecoveryCodeRepo interface {
+	Replace(context.Context, int, []string) error
+	Use(context.Context, int, string) error
+	CountUnused(context.Context, int) (int, error)
+	DeleteByUserID(context.Context, int) error
+}
+
+// WebAuthnCredentialRepo is the interface all WebAuthn credential repositories must
+// implement.
+type WebAuthnCredentialRepo interface {
+	Create(context.Context, model.WebAuthnCredential) (model.WebAuthnCredential, error)
+	GetByID(context.Context, string) (model.WebAuthnCredential, error)
+	GetByUserID(context.Context, int) ([]model.WebAuthnCredential, error)
+	MarkUsed(context.Context, string, int64) error
+	Delete(context.Context, int, string) error
 }
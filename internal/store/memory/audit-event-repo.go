@@ -0,0 +1,43 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+)
+
+// auditEventRepo is the audit event repository for in-memory store.
+type auditEventRepo struct {
+	db *db
+}
+
+// newAuditEventRepo creates and returns a new auditEventRepo instance.
+func newAuditEventRepo(db *db) *auditEventRepo { return &auditEventRepo{db: db} }
+
+// Create creates and returns a new audit event.
+func (r *auditEventRepo) Create(_ context.Context, e model.AuditEvent) (model.AuditEvent, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	e.ID = len(r.db.auditEvents) + 1
+	e.CreatedAt = time.Now()
+	r.db.auditEvents = append(r.db.auditEvents, e)
+
+	return e, nil
+}
+
+// GetByUserID returns audit events of the user with specific ID in order of creation.
+func (r *auditEventRepo) GetByUserID(_ context.Context, userID int) ([]model.AuditEvent, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	events := []model.AuditEvent{}
+	for _, e := range r.db.auditEvents {
+		if e.UserID == userID {
+			events = append(events, e)
+		}
+	}
+
+	return events, nil
+}
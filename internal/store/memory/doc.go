@@ -0,0 +1,2 @@
+// Package memory provides in-memory store, it's handy for local runs and tests.
+package memory
@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// emailVerificationTokenRepo is the email verification token repository for in-memory store.
+type emailVerificationTokenRepo struct {
+	db *db
+}
+
+// newEmailVerificationTokenRepo creates and returns a new emailVerificationTokenRepo instance.
+func newEmailVerificationTokenRepo(db *db) *emailVerificationTokenRepo {
+	return &emailVerificationTokenRepo{db: db}
+}
+
+// Create creates and returns a new email verification token.
+func (r *emailVerificationTokenRepo) Create(
+	_ context.Context, t model.EmailVerificationToken,
+) (model.EmailVerificationToken, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	if _, ok := r.db.users[t.UserID]; !ok {
+		return model.EmailVerificationToken{}, store.ErrNotFound
+	}
+	if _, ok := r.db.verifyTokens[t.Hash]; ok {
+		return model.EmailVerificationToken{}, errors.New("token with this hash already exists")
+	}
+	t.UsedAt = nil
+	r.db.verifyTokens[t.Hash] = t
+
+	return t, nil
+}
+
+// GetByHash returns the email verification token with specific hash.
+func (r *emailVerificationTokenRepo) GetByHash(
+	_ context.Context, hash string,
+) (model.EmailVerificationToken, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	t, ok := r.db.verifyTokens[hash]
+	if !ok {
+		return model.EmailVerificationToken{}, store.ErrNotFound
+	}
+
+	return t, nil
+}
+
+// MarkUsed marks the email verification token with specific hash as used. It returns
+// store.ErrTokenIsUsed if the token has already been used before.
+func (r *emailVerificationTokenRepo) MarkUsed(_ context.Context, hash string) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	t, ok := r.db.verifyTokens[hash]
+	if !ok {
+		return store.ErrNotFound
+	} else if t.UsedAt != nil {
+		return store.ErrTokenIsUsed
+	}
+	t.UsedAt = now()
+	r.db.verifyTokens[hash] = t
+
+	return nil
+}
+
+// DeleteExpired deletes all email verification tokens which have already expired.
+func (r *emailVerificationTokenRepo) DeleteExpired(_ context.Context) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	for hash, t := range r.db.verifyTokens {
+		if t.ExpiresAt.Before(time.Now()) {
+			delete(r.db.verifyTokens, hash)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+)
+
+// loginThrottleRepo is the login throttle repository for in-memory store.
+type loginThrottleRepo struct {
+	db *db
+}
+
+// newLoginThrottleRepo creates and returns a new loginThrottleRepo instance.
+func newLoginThrottleRepo(db *db) *loginThrottleRepo { return &loginThrottleRepo{db: db} }
+
+// Get returns the login throttle with specific key. A throttle without failures is
+// returned if there were no failures.
+func (r *loginThrottleRepo) Get(_ context.Context, key string) (model.LoginThrottle, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	t, ok := r.db.throttles[key]
+	if !ok {
+		return model.LoginThrottle{Key: key}, nil
+	}
+
+	return t, nil
+}
+
+// RegisterFailure registers a failed sign in attempt and returns the updated login
+// throttle. Failures registered before since are forgotten.
+func (r *loginThrottleRepo) RegisterFailure(
+	_ context.Context, key string, since time.Time,
+) (model.LoginThrottle, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	t, ok := r.db.throttles[key]
+	if !ok || t.LastFailureAt.Before(since) {
+		t.Key, t.Failures = key, 0
+	}
+	t.Failures++
+	t.LastFailureAt = time.Now()
+	r.db.throttles[key] = t
+
+	return t, nil
+}
+
+// Lock locks the login throttle with specific key until specific time, its failures
+// are reset.
+func (r *loginThrottleRepo) Lock(_ context.Context, key string, until time.Time) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	if t, ok := r.db.throttles[key]; ok {
+		t.Failures = 0
+		t.LockedUntil = &until
+		r.db.throttles[key] = t
+	}
+
+	return nil
+}
+
+// Delete deletes the login throttle with specific key, so its failures and lock are
+// forgotten.
+func (r *loginThrottleRepo) Delete(_ context.Context, key string) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	delete(r.db.throttles, key)
+
+	return nil
+}
+
+// DeleteStale deletes unlocked login throttles without failures since specific time.
+func (r *loginThrottleRepo) DeleteStale(_ context.Context, before time.Time) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	for key, t := range r.db.throttles {
+		if t.LastFailureAt.Before(before) && (t.LockedUntil == nil || t.LockedUntil.Before(time.Now())) {
+			delete(r.db.throttles, key)
+		}
+	}
+
+	return nil
+}
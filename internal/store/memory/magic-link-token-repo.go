@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// magicLinkTokenRepo is the magic link token repository for in-memory store.
+type magicLinkTokenRepo struct {
+	db *db
+}
+
+// newMagicLinkTokenRepo creates and returns a new magicLinkTokenRepo instance.
+func newMagicLinkTokenRepo(db *db) *magicLinkTokenRepo {
+	return &magicLinkTokenRepo{db: db}
+}
+
+// Create creates and returns a new magic link token.
+func (r *magicLinkTokenRepo) Create(
+	_ context.Context, t model.MagicLinkToken,
+) (model.MagicLinkToken, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	if _, ok := r.db.users[t.UserID]; !ok {
+		return model.MagicLinkToken{}, store.ErrNotFound
+	}
+	if _, ok := r.db.magicLinks[t.Hash]; ok {
+		return model.MagicLinkToken{}, errors.New("token with this hash already exists")
+	}
+	t.UsedAt = nil
+	r.db.magicLinks[t.Hash] = t
+
+	return t, nil
+}
+
+// GetByHash returns the magic link token with specific hash.
+func (r *magicLinkTokenRepo) GetByHash(
+	_ context.Context, hash string,
+) (model.MagicLinkToken, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	t, ok := r.db.magicLinks[hash]
+	if !ok {
+		return model.MagicLinkToken{}, store.ErrNotFound
+	}
+
+	return t, nil
+}
+
+// MarkUsed marks the magic link token with specific hash as used. It returns
+// store.ErrTokenIsUsed if the token has already been used before.
+func (r *magicLinkTokenRepo) MarkUsed(_ context.Context, hash string) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	t, ok := r.db.magicLinks[hash]
+	if !ok {
+		return store.ErrNotFound
+	} else if t.UsedAt != nil {
+		return store.ErrTokenIsUsed
+	}
+	t.UsedAt = now()
+	r.db.magicLinks[hash] = t
+
+	return nil
+}
+
+// DeleteExpired deletes all magic link tokens which have already expired.
+func (r *magicLinkTokenRepo) DeleteExpired(_ context.Context) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	for hash, t := range r.db.magicLinks {
+		if t.ExpiresAt.Before(time.Now()) {
+			delete(r.db.magicLinks, hash)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// oneTimeCodeKey identifies the one-time code of the user with specific purpose.
+type oneTimeCodeKey struct {
+	userID  int
+	purpose string
+}
+
+// oneTimeCodeRepo is the one-time code repository for in-memory store.
+type oneTimeCodeRepo struct {
+	db *db
+}
+
+// newOneTimeCodeRepo creates and returns a new oneTimeCodeRepo instance.
+func newOneTimeCodeRepo(db *db) *oneTimeCodeRepo { return &oneTimeCodeRepo{db: db} }
+
+// Save saves and returns the one-time code, the code of the user with the same purpose
+// is replaced and its attempts are reset.
+func (r *oneTimeCodeRepo) Save(_ context.Context, c model.OneTimeCode) (model.OneTimeCode, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	if _, ok := r.db.users[c.UserID]; !ok {
+		return model.OneTimeCode{}, store.ErrNotFound
+	}
+	c.Attempts = 0
+	c.CreatedAt = time.Now()
+	r.db.oneTimeCodes[oneTimeCodeKey{c.UserID, c.Purpose}] = c
+
+	return c, nil
+}
+
+// Get returns the one-time code of the user with specific purpose.
+func (r *oneTimeCodeRepo) Get(
+	_ context.Context, userID int, purpose string,
+) (model.OneTimeCode, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	c, ok := r.db.oneTimeCodes[oneTimeCodeKey{userID, purpose}]
+	if !ok {
+		return model.OneTimeCode{}, store.ErrNotFound
+	}
+
+	return c, nil
+}
+
+// Attempt registers an attempt to use the one-time code of the user with specific
+// purpose and returns the code. It returns store.ErrNotFound if there is no code or
+// max attempts have already been made.
+func (r *oneTimeCodeRepo) Attempt(
+	_ context.Context, userID int, purpose string, maxAttempts int,
+) (model.OneTimeCode, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	key := oneTimeCodeKey{userID, purpose}
+	c, ok := r.db.oneTimeCodes[key]
+	if !ok || c.Attempts >= maxAttempts {
+		return model.OneTimeCode{}, store.ErrNotFound
+	}
+	c.Attempts++
+	r.db.oneTimeCodes[key] = c
+
+	return c, nil
+}
+
+// Delete deletes the one-time code of the user with specific purpose.
+func (r *oneTimeCodeRepo) Delete(_ context.Context, userID int, purpose string) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	key := oneTimeCodeKey{userID, purpose}
+	if _, ok := r.db.oneTimeCodes[key]; !ok {
+		return store.ErrNotFound
+	}
+	delete(r.db.oneTimeCodes, key)
+
+	return nil
+}
+
+// DeleteExpired deletes all one-time codes which have already expired.
+func (r *oneTimeCodeRepo) DeleteExpired(_ context.Context) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	for key, c := range r.db.oneTimeCodes {
+		if c.ExpiresAt.Before(time.Now()) {
+			delete(r.db.oneTimeCodes, key)
+		}
+	}
+
+	return nil
+}
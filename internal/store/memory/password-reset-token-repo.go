@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// passwordResetTokenRepo is the password reset token repository for in-memory store.
+type passwordResetTokenRepo struct {
+	db *db
+}
+
+// newPasswordResetTokenRepo creates and returns a new passwordResetTokenRepo instance.
+func newPasswordResetTokenRepo(db *db) *passwordResetTokenRepo {
+	return &passwordResetTokenRepo{db: db}
+}
+
+// Create creates and returns a new password reset token.
+func (r *passwordResetTokenRepo) Create(
+	_ context.Context, t model.PasswordResetToken,
+) (model.PasswordResetToken, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	if _, ok := r.db.users[t.UserID]; !ok {
+		return model.PasswordResetToken{}, store.ErrNotFound
+	}
+	if _, ok := r.db.resetTokens[t.Hash]; ok {
+		return model.PasswordResetToken{}, errors.New("token with this hash already exists")
+	}
+	t.UsedAt = nil
+	r.db.resetTokens[t.Hash] = t
+
+	return t, nil
+}
+
+// GetByHash returns the password reset token with specific hash.
+func (r *passwordResetTokenRepo) GetByHash(
+	_ context.Context, hash string,
+) (model.PasswordResetToken, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	t, ok := r.db.resetTokens[hash]
+	if !ok {
+		return model.PasswordResetToken{}, store.ErrNotFound
+	}
+
+	return t, nil
+}
+
+// MarkUsed marks the password reset token with specific hash as used. It returns
+// store.ErrTokenIsUsed if the token has already been used before.
+func (r *passwordResetTokenRepo) MarkUsed(_ context.Context, hash string) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	t, ok := r.db.resetTokens[hash]
+	if !ok {
+		return store.ErrNotFound
+	} else if t.UsedAt != nil {
+		return store.ErrTokenIsUsed
+	}
+	t.UsedAt = now()
+	r.db.resetTokens[hash] = t
+
+	return nil
+}
+
+// DeleteExpired deletes all password reset tokens which have already expired.
+func (r *passwordResetTokenRepo) DeleteExpired(_ context.Context) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	for hash, t := range r.db.resetTokens {
+		if t.ExpiresAt.Before(time.Now()) {
+			delete(r.db.resetTokens, hash)
+		}
+	}
+
+	return nil
+}
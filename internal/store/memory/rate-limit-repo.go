@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// bucket is a rate limit token bucket.
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// rateLimitRepo is the rate limit token bucket repository for in-memory store.
+type rateLimitRepo struct {
+	db *db
+}
+
+// newRateLimitRepo creates and returns a new rateLimitRepo instance.
+func newRateLimitRepo(db *db) *rateLimitRepo { return &rateLimitRepo{db: db} }
+
+// Take takes a token from the bucket with specific key which holds up to limit tokens
+// and is refilled with limit tokens per period. It returns tokens left in the bucket
+// and whether the token was taken. The bucket is left untouched if it's empty.
+func (r *rateLimitRepo) Take(
+	_ context.Context, key string, limit int, period time.Duration,
+) (float64, bool, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	t := time.Now()
+	b, ok := r.db.buckets[key]
+	if !ok {
+		b = bucket{tokens: float64(limit), updatedAt: t}
+	}
+	perSecond := float64(limit) / period.Seconds()
+	tokens := math.Min(float64(limit), b.tokens+t.Sub(b.updatedAt).Seconds()*perSecond)
+	if tokens < 1 {
+		return tokens, false, nil
+	}
+	r.db.buckets[key] = bucket{tokens: tokens - 1, updatedAt: t}
+
+	return tokens - 1, true, nil
+}
+
+// DeleteStale deletes buckets which haven't been updated since specific time.
+func (r *rateLimitRepo) DeleteStale(_ context.Context, before time.Time) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	for key, b := range r.db.buckets {
+		if b.updatedAt.Before(before) {
+			delete(r.db.buckets, key)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// recoveryCodeRepo is the MFA recovery code repository for in-memory store.
+type recoveryCodeRepo struct {
+	db *db
+}
+
+// newRecoveryCodeRepo creates and returns a new recoveryCodeRepo instance.
+func newRecoveryCodeRepo(db *db) *recoveryCodeRepo { return &recoveryCodeRepo{db: db} }
+
+// Replace replaces all recovery codes of the user with specific ID with the codes
+// having specific hashes.
+func (r *recoveryCodeRepo) Replace(_ context.Context, userID int, hashes []string) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	if _, ok := r.db.users[userID]; !ok {
+		return store.ErrNotFound
+	}
+	codes := make([]model.RecoveryCode, 0, len(hashes))
+	for _, hash := range hashes {
+		codes = append(codes, model.RecoveryCode{UserID: userID, Hash: hash})
+	}
+	r.db.recoveryCodes[userID] = codes
+
+	return nil
+}
+
+// Use marks the recovery code with specific hash of the user with specific ID as used.
+// It returns store.ErrTokenIsUsed if the code has already been used.
+func (r *recoveryCodeRepo) Use(_ context.Context, userID int, hash string) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	codes := r.db.recoveryCodes[userID]
+	for i := range codes {
+		if codes[i].Hash != hash {
+			continue
+		}
+		if codes[i].UsedAt != nil {
+			return store.ErrTokenIsUsed
+		}
+		codes[i].UsedAt = now()
+		return nil
+	}
+
+	return store.ErrNotFound
+}
+
+// CountUnused returns the number of unused recovery codes of the user with specific ID.
+func (r *recoveryCodeRepo) CountUnused(_ context.Context, userID int) (int, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	count := 0
+	for _, c := range r.db.recoveryCodes[userID] {
+		if c.UsedAt == nil {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// DeleteByUserID deletes all recovery codes of the user with specific ID.
+func (r *recoveryCodeRepo) DeleteByUserID(_ context.Context, userID int) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	delete(r.db.recoveryCodes, userID)
+
+	return nil
+}
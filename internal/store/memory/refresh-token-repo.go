@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// refreshTokenRepo is the refresh token repository for in-memory store.
+type refreshTokenRepo struct {
+	db *db
+}
+
+// newRefreshTokenRepo creates and returns a new refreshTokenRepo instance.
+func newRefreshTokenRepo(db *db) *refreshTokenRepo { return &refreshTokenRepo{db: db} }
+
+// Create creates and returns a new refresh token.
+func (r *refreshTokenRepo) Create(
+	_ context.Context, t model.RefreshToken,
+) (model.RefreshToken, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	if _, ok := r.db.users[t.UserID]; !ok {
+		return model.RefreshToken{}, store.ErrNotFound
+	}
+	if _, ok := r.db.refreshTokens[t.ID]; ok {
+		return model.RefreshToken{}, errors.New("refresh token with this ID already exists")
+	}
+	t.UsedAt, t.RevokedAt = nil, nil
+	r.db.refreshTokens[t.ID] = t
+
+	return t, nil
+}
+
+// GetByID returns the refresh token with specific ID.
+func (r *refreshTokenRepo) GetByID(_ context.Context, id string) (model.RefreshToken, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	t, ok := r.db.refreshTokens[id]
+	if !ok {
+		return model.RefreshToken{}, store.ErrNotFound
+	}
+
+	return t, nil
+}
+
+// MarkUsed marks the refresh token with specific ID as used. It returns
+// store.ErrTokenIsUsed if the token has already been used before.
+func (r *refreshTokenRepo) MarkUsed(_ context.Context, id string) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	t, ok := r.db.refreshTokens[id]
+	if !ok {
+		return store.ErrNotFound
+	} else if t.UsedAt != nil {
+		return store.ErrTokenIsUsed
+	}
+	t.UsedAt = now()
+	r.db.refreshTokens[id] = t
+
+	return nil
+}
+
+// RevokeFamily revokes all refresh tokens of the family with specific ID.
+func (r *refreshTokenRepo) RevokeFamily(_ context.Context, familyID string) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	for id, t := range r.db.refreshTokens {
+		if t.FamilyID == familyID && t.RevokedAt == nil {
+			t.RevokedAt = now()
+			r.db.refreshTokens[id] = t
+		}
+	}
+
+	return nil
+}
+
+// RevokeByUserID revokes all refresh tokens of the user with specific ID.
+func (r *refreshTokenRepo) RevokeByUserID(_ context.Context, userID int) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	for id, t := range r.db.refreshTokens {
+		if t.UserID == userID && t.RevokedAt == nil {
+			t.RevokedAt = now()
+			r.db.refreshTokens[id] = t
+		}
+	}
+
+	return nil
+}
+
+// DeleteExpired deletes all refresh tokens which have already expired.
+func (r *refreshTokenRepo) DeleteExpired(_ context.Context) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	for id, t := range r.db.refreshTokens {
+		if t.ExpiresAt.Before(time.Now()) {
+			delete(r.db.refreshTokens, id)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// revokedTokenRepo is the revoked token repository for in-memory store.
+type revokedTokenRepo struct {
+	db *db
+}
+
+// newRevokedTokenRepo creates and returns a new revokedTokenRepo instance.
+func newRevokedTokenRepo(db *db) *revokedTokenRepo { return &revokedTokenRepo{db: db} }
+
+// Create revokes a token. Revoking already revoked token is not an error.
+func (r *revokedTokenRepo) Create(
+	_ context.Context, t model.RevokedToken,
+) (model.RevokedToken, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	if _, ok := r.db.revokedTokens[t.ID]; !ok {
+		r.db.revokedTokens[t.ID] = t
+	}
+
+	return t, nil
+}
+
+// Use revokes a single use token. It returns store.ErrTokenIsUsed if the token has
+// already been revoked, so the token can't be used concurrently twice.
+func (r *revokedTokenRepo) Use(_ context.Context, t model.RevokedToken) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	if _, ok := r.db.revokedTokens[t.ID]; ok {
+		return store.ErrTokenIsUsed
+	}
+	r.db.revokedTokens[t.ID] = t
+
+	return nil
+}
+
+// Exists reports whether the token with specific ID is revoked.
+func (r *revokedTokenRepo) Exists(_ context.Context, id string) (bool, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	_, ok := r.db.revokedTokens[id]
+
+	return ok, nil
+}
+
+// DeleteExpired deletes all revoked tokens which have already expired.
+func (r *revokedTokenRepo) DeleteExpired(_ context.Context) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	for id, t := range r.db.revokedTokens {
+		if t.ExpiresAt.Before(time.Now()) {
+			delete(r.db.revokedTokens, id)
+		}
+	}
+
+	return nil
+}
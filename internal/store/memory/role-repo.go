@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// roleRepo is the role repository for in-memory store.
+type roleRepo struct {
+	db *db
+}
+
+// newRoleRepo creates and returns a new roleRepo instance.
+func newRoleRepo(db *db) *roleRepo { return &roleRepo{db: db} }
+
+// copyRole returns a copy of the role which doesn't share permissions with it.
+func copyRole(role model.Role) model.Role {
+	role.Permissions = append([]string{}, role.Permissions...)
+
+	return role
+}
+
+// GetAll returns all roles ordered by name.
+func (r *roleRepo) GetAll(_ context.Context) ([]model.Role, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	roles := make([]model.Role, 0, len(r.db.roles))
+	for _, role := range r.db.roles {
+		roles = append(roles, copyRole(role))
+	}
+
+	return roles, nil
+}
+
+// GetByUserID returns all roles of the user with specific ID ordered by name.
+func (r *roleRepo) GetByUserID(_ context.Context, userID int) ([]model.Role, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	roles := []model.Role{}
+	for _, role := range r.db.roles {
+		if r.db.userRoles[userID][role.Name] {
+			roles = append(roles, copyRole(role))
+		}
+	}
+
+	return roles, nil
+}
+
+// AssignToUser assigns the role with specific name to the user with specific ID.
+func (r *roleRepo) AssignToUser(_ context.Context, userID int, role string) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	if _, ok := r.db.users[userID]; !ok || !r.exists(role) {
+		return store.ErrNotFound
+	}
+	if r.db.userRoles[userID] == nil {
+		r.db.userRoles[userID] = map[string]bool{}
+	}
+	r.db.userRoles[userID][role] = true
+
+	return nil
+}
+
+// RemoveFromUser removes the role with specific name from the user with specific ID.
+func (r *roleRepo) RemoveFromUser(_ context.Context, userID int, role string) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	if !r.db.userRoles[userID][role] {
+		return store.ErrNotFound
+	}
+	delete(r.db.userRoles[userID], role)
+
+	return nil
+}
+
+// exists reports whether the role with specific name exists.
+func (r *roleRepo) exists(name string) bool {
+	for _, role := range r.db.roles {
+		if role.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,109 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// signingKeyRepo is the signing key repository for in-memory store.
+type signingKeyRepo struct {
+	db *db
+}
+
+// newSigningKeyRepo creates and returns a new signingKeyRepo instance.
+func newSigningKeyRepo(db *db) *signingKeyRepo { return &signingKeyRepo{db: db} }
+
+// GetAll returns all signing keys in order of creation.
+func (r *signingKeyRepo) GetAll(_ context.Context) ([]model.SigningKey, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	keys := make([]model.SigningKey, len(r.db.signingKeys))
+	copy(keys, r.db.signingKeys)
+
+	return keys, nil
+}
+
+// Create creates and returns a new signing key.
+func (r *signingKeyRepo) Create(_ context.Context, k model.SigningKey) (model.SigningKey, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	if r.find(k.ID) >= 0 {
+		return model.SigningKey{}, errors.New("signing key with this ID already exists")
+	}
+	k.CreatedAt = time.Now()
+	r.db.signingKeys = append(r.db.signingKeys, k)
+
+	return k, nil
+}
+
+// Activate makes the signing key with specific ID active, the previously active
+// key is retired.
+func (r *signingKeyRepo) Activate(_ context.Context, id string) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	i := r.find(id)
+	if i < 0 {
+		return store.ErrNotFound
+	}
+	for j, k := range r.db.signingKeys {
+		if k.Status == model.SigningKeyActive && k.ID != id {
+			r.db.signingKeys[j].Status = model.SigningKeyRetired
+			r.db.signingKeys[j].RetiredAt = now()
+		}
+	}
+	r.db.signingKeys[i].Status = model.SigningKeyActive
+	r.db.signingKeys[i].ActivatedAt = now()
+	r.db.signingKeys[i].RetiredAt = nil
+
+	return nil
+}
+
+// Retire retires the pending signing key with specific ID.
+func (r *signingKeyRepo) Retire(_ context.Context, id string) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	i := r.find(id)
+	if i < 0 || r.db.signingKeys[i].Status != model.SigningKeyPending {
+		return store.ErrNotFound
+	}
+	r.db.signingKeys[i].Status = model.SigningKeyRetired
+	r.db.signingKeys[i].RetiredAt = now()
+
+	return nil
+}
+
+// DeleteRetiredBefore deletes all signing keys retired before specific time.
+func (r *signingKeyRepo) DeleteRetiredBefore(_ context.Context, t time.Time) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	keys := r.db.signingKeys[:0]
+	for _, k := range r.db.signingKeys {
+		if k.Status == model.SigningKeyRetired && k.RetiredAt != nil && k.RetiredAt.Before(t) {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	r.db.signingKeys = keys
+
+	return nil
+}
+
+// find returns index of the signing key with specific ID or -1 if there is no such key.
+func (r *signingKeyRepo) find(id string) int {
+	for i, k := range r.db.signingKeys {
+		if k.ID == id {
+			return i
+		}
+	}
+
+	return -1
+}
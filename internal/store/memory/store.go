@@ -0,0 +1,231 @@
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// db keeps all data of the store, repositories share it and its lock, so
+// cascading changes are atomic.
+type db struct {
+	sync.RWMutex
+	users         map[int]model.User
+	lastUserID    int
+	refreshTokens map[string]model.RefreshToken
+	revokedTokens map[string]model.RevokedToken
+	signingKeys   []model.SigningKey
+	roles         []model.Role
+	userRoles     map[int]map[string]bool
+	resetTokens   map[string]model.PasswordResetToken
+	verifyTokens  map[string]model.EmailVerificationToken
+	throttles     map[string]model.LoginThrottle
+	buckets       map[string]bucket
+	auditEvents   []model.AuditEvent
+	totpSecrets   map[int]model.TOTPSecret
+	recoveryCodes map[int][]model.RecoveryCode
+	credentials   map[string]model.WebAuthnCredential
+	magicLinks    map[string]model.MagicLinkToken
+	oneTimeCodes  map[oneTimeCodeKey]model.OneTimeCode
+}
+
+// newDB creates and returns a new empty db with the same roles PostgreSQL store is
+// seeded with.
+func newDB() *db {
+	return &db{
+		users:         map[int]model.User{},
+		refreshTokens: map[string]model.RefreshToken{},
+		revokedTokens: map[string]model.RevokedToken{},
+		signingKeys:   []model.SigningKey{},
+		roles: []model.Role{
+			{ID: 1, Name: "admin", Permissions: []string{"roles:write", "users:read", "users:write"}},
+			{ID: 2, Name: "user", Permissions: []string{}},
+		},
+		userRoles:     map[int]map[string]bool{},
+		resetTokens:   map[string]model.PasswordResetToken{},
+		verifyTokens:  map[string]model.EmailVerificationToken{},
+		throttles:     map[string]model.LoginThrottle{},
+		buckets:       map[string]bucket{},
+		auditEvents:   []model.AuditEvent{},
+		totpSecrets:   map[int]model.TOTPSecret{},
+		recoveryCodes: map[int][]model.RecoveryCode{},
+		credentials:   map[string]model.WebAuthnCredential{},
+		magicLinks:    map[string]model.MagicLinkToken{},
+		oneTimeCodes:  map[oneTimeCodeKey]model.OneTimeCode{},
+	}
+}
+
+// Store is in-memory store, its data is lost once the process exits.
+type Store struct {
+	db               *db
+	userRepo         *userRepo
+	refreshTokenRepo *refreshTokenRepo
+	revokedTokenRepo *revokedTokenRepo
+	signingKeyRepo   *signingKeyRepo
+	roleRepo         *roleRepo
+	resetTokenRepo   *passwordResetTokenRepo
+	verifyTokenRepo  *emailVerificationTokenRepo
+	throttleRepo     *loginThrottleRepo
+	rateLimitRepo    *rateLimitRepo
+	auditEventRepo   *auditEventRepo
+	totpSecretRepo   *totpSecretRepo
+	recoveryCodeRepo *recoveryCodeRepo
+	credentialRepo   *webAuthnCredentialRepo
+	magicLinkRepo    *magicLinkTokenRepo
+	oneTimeCodeRepo  *oneTimeCodeRepo
+}
+
+// New creates and returns a new empty store.
+func New() *Store {
+	return &Store{db: newDB()}
+}
+
+// Open does nothing, in-memory store is always open.
+func (s *Store) Open() error { return nil }
+
+// Users returns the users repository.
+func (s *Store) Users() store.UserRepo {
+	if s.userRepo == nil {
+		s.userRepo = newUserRepo(s.db)
+	}
+
+	return s.userRepo
+}
+
+// RefreshTokens returns the refresh tokens repository.
+func (s *Store) RefreshTokens() store.RefreshTokenRepo {
+	if s.refreshTokenRepo == nil {
+		s.refreshTokenRepo = newRefreshTokenRepo(s.db)
+	}
+
+	return s.refreshTokenRepo
+}
+
+// RevokedTokens returns the revoked tokens repository.
+func (s *Store) RevokedTokens() store.RevokedTokenRepo {
+	if s.revokedTokenRepo == nil {
+		s.revokedTokenRepo = newRevokedTokenRepo(s.db)
+	}
+
+	return s.revokedTokenRepo
+}
+
+// SigningKeys returns the signing keys repository.
+func (s *Store) SigningKeys() store.SigningKeyRepo {
+	if s.signingKeyRepo == nil {
+		s.signingKeyRepo = newSigningKeyRepo(s.db)
+	}
+
+	return s.signingKeyRepo
+}
+
+// Roles returns the roles repository.
+func (s *Store) Roles() store.RoleRepo {
+	if s.roleRepo == nil {
+		s.roleRepo = newRoleRepo(s.db)
+	}
+
+	return s.roleRepo
+}
+
+// PasswordResetTokens returns the password reset tokens repository.
+func (s *Store) PasswordResetTokens() store.PasswordResetTokenRepo {
+	if s.resetTokenRepo == nil {
+		s.resetTokenRepo = newPasswordResetTokenRepo(s.db)
+	}
+
+	return s.resetTokenRepo
+}
+
+// EmailVerificationTokens returns the email verification tokens repository.
+func (s *Store) EmailVerificationTokens() store.EmailVerificationTokenRepo {
+	if s.verifyTokenRepo == nil {
+		s.verifyTokenRepo = newEmailVerificationTokenRepo(s.db)
+	}
+
+	return s.verifyTokenRepo
+}
+
+// LoginThrottles returns the login throttles repository.
+func (s *Store) LoginThrottles() store.LoginThrottleRepo {
+	if s.throttleRepo == nil {
+		s.throttleRepo = newLoginThrottleRepo(s.db)
+	}
+
+	return s.throttleRepo
+}
+
+// RateLimits returns the rate limit token buckets repository.
+func (s *Store) RateLimits() store.RateLimitRepo {
+	if s.rateLimitRepo == nil {
+		s.rateLimitRepo = newRateLimitRepo(s.db)
+	}
+
+	return s.rateLimitRepo
+}
+
+// AuditEvents returns the audit events repository.
+func (s *Store) AuditEvents() store.AuditEventRepo {
+	if s.auditEventRepo == nil {
+		s.auditEventRepo = newAuditEventRepo(s.db)
+	}
+
+	return s.auditEventRepo
+}
+
+// TOTPSecrets returns the TOTP secrets repository.
+func (s *Store) TOTPSecrets() store.TOTPSecretRepo {
+	if s.totpSecretRepo == nil {
+		s.totpSecretRepo = newTOTPSecretRepo(s.db)
+	}
+
+	return s.totpSecretRepo
+}
+
+// RecoveryCodes returns the MFA recovery codes repository.
+func (s *Store) RecoveryCodes() store.RecoveryCodeRepo {
+	if s.recoveryCodeRepo == nil {
+		s.recoveryCodeRepo = newRecoveryCodeRepo(s.db)
+	}
+
+	return s.recoveryCodeRepo
+}
+
+// WebAuthnCredentials returns the WebAuthn credentials repository.
+func (s *Store) WebAuthnCredentials() store.WebAuthnCredentialRepo {
+	if s.credentialRepo == nil {
+		s.credentialRepo = newWebAuthnCredentialRepo(s.db)
+	}
+
+	return s.credentialRepo
+}
+
+// MagicLinkTokens returns the magic link tokens repository.
+func (s *Store) MagicLinkTokens() store.MagicLinkTokenRepo {
+	if s.magicLinkRepo == nil {
+		s.magicLinkRepo = newMagicLinkTokenRepo(s.db)
+	}
+
+	return s.magicLinkRepo
+}
+
+// OneTimeCodes returns the one-time codes repository.
+func (s *Store) OneTimeCodes() store.OneTimeCodeRepo {
+	if s.oneTimeCodeRepo == nil {
+		s.oneTimeCodeRepo = newOneTimeCodeRepo(s.db)
+	}
+
+	return s.oneTimeCodeRepo
+}
+
+// Close does nothing, data is kept until the store is garbage collected.
+func (s *Store) Close() error { return nil }
+
+// now returns the current time, it's the counterpart of NOW() in SQL queries.
+func now() *time.Time {
+	t := time.Now()
+
+	return &t
+}
@@ -0,0 +1,23 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imarrche/jwt-auth-example/internal/store"
+	"github.com/imarrche/jwt-auth-example/internal/store/storetest"
+)
+
+func TestStore(t *testing.T) {
+	var s store.Store = New()
+
+	assert.NoError(t, s.Open())
+	assert.Equal(t, s.Users(), s.Users())
+	assert.Equal(t, s.Roles(), s.Roles())
+	assert.NoError(t, s.Close())
+}
+
+func TestStore_Conformance(t *testing.T) {
+	storetest.Run(t, func(*testing.T) store.Store { return New() })
+}
@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// totpSecretRepo is the TOTP secret repository for in-memory store.
+type totpSecretRepo struct {
+	db *db
+}
+
+// newTOTPSecretRepo creates and returns a new totpSecretRepo instance.
+func newTOTPSecretRepo(db *db) *totpSecretRepo { return &totpSecretRepo{db: db} }
+
+// Save creates the TOTP secret of the user or replaces the existing one.
+func (r *totpSecretRepo) Save(_ context.Context, s model.TOTPSecret) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	if _, ok := r.db.users[s.UserID]; !ok {
+		return store.ErrNotFound
+	}
+	s.Secret = append([]byte{}, s.Secret...)
+	r.db.totpSecrets[s.UserID] = s
+
+	return nil
+}
+
+// GetByUserID returns the TOTP secret of the user with specific ID.
+func (r *totpSecretRepo) GetByUserID(_ context.Context, userID int) (model.TOTPSecret, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	s, ok := r.db.totpSecrets[userID]
+	if !ok {
+		return model.TOTPSecret{}, store.ErrNotFound
+	}
+
+	return s, nil
+}
+
+// Confirm confirms the TOTP secret of the user with specific ID.
+func (r *totpSecretRepo) Confirm(_ context.Context, userID int) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	s, ok := r.db.totpSecrets[userID]
+	if !ok {
+		return store.ErrNotFound
+	}
+	s.ConfirmedAt = now()
+	r.db.totpSecrets[userID] = s
+
+	return nil
+}
+
+// MarkUsed marks the time step of the code accepted for the user with specific ID as
+// used. It returns store.ErrTokenIsUsed if the same or a later step has already been
+// used before.
+func (r *totpSecretRepo) MarkUsed(_ context.Context, userID int, step int64) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	s, ok := r.db.totpSecrets[userID]
+	if !ok {
+		return store.ErrNotFound
+	}
+	if s.LastUsedStep >= step {
+		return store.ErrTokenIsUsed
+	}
+	s.LastUsedStep = step
+	r.db.totpSecrets[userID] = s
+
+	return nil
+}
+
+// DeleteByUserID deletes the TOTP secret of the user with specific ID.
+func (r *totpSecretRepo) DeleteByUserID(_ context.Context, userID int) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	if _, ok := r.db.totpSecrets[userID]; !ok {
+		return store.ErrNotFound
+	}
+	delete(r.db.totpSecrets, userID)
+
+	return nil
+}
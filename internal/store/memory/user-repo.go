@@ -0,0 +1,268 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// userRepo is the user repository for in-memory store.
+type userRepo struct {
+	db *db
+}
+
+// newUserRepo creates and returns a new userRepo instance.
+func newUserRepo(db *db) *userRepo { return &userRepo{db: db} }
+
+// GetAll returns all users ordered by ID.
+func (r *userRepo) GetAll(_ context.Context) ([]model.User, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	users := make([]model.User, 0, len(r.db.users))
+	for _, u := range r.db.users {
+		users = append(users, u)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+
+	return users, nil
+}
+
+// Create creates and returns a new user, the user's email isn't verified and the
+// user isn't disabled.
+func (r *userRepo) Create(_ context.Context, u model.User) (model.User, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	if err := r.checkUnique(u); err != nil {
+		return model.User{}, err
+	}
+	r.db.lastUserID++
+	u.ID = r.db.lastUserID
+	u.CreatedAt = time.Now()
+	u.EmailVerifiedAt, u.DisabledAt = nil, nil
+	stored := u
+	stored.Password = ""
+	r.db.users[u.ID] = stored
+
+	return u, nil
+}
+
+// List returns users matching the query and the total number of users matching its
+// filters.
+func (r *userRepo) List(_ context.Context, q model.UserQuery) ([]model.User, int, error) {
+	less, ok := userLess[q.Sort]
+	if !ok {
+		return []model.User{}, 0, fmt.Errorf("unknown sort field: %s", q.Sort)
+	}
+	if q.Desc {
+		asc := less
+		less = func(a, b model.User) bool { return asc(b, a) }
+	}
+
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	users := []model.User{}
+	for _, u := range r.db.users {
+		if matchUser(u, q) {
+			users = append(users, u)
+		}
+	}
+	total := len(users)
+	sort.Slice(users, func(i, j int) bool { return less(users[i], users[j]) })
+
+	if q.After != nil {
+		i := sort.Search(len(users), func(i int) bool { return less(*q.After, users[i]) })
+		users = users[i:]
+	}
+	if q.Limit > 0 && len(users) > q.Limit {
+		users = users[:q.Limit]
+	}
+
+	return users, total, nil
+}
+
+// GetByID returns the user with specific ID.
+func (r *userRepo) GetByID(_ context.Context, id int) (model.User, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	u, ok := r.db.users[id]
+	if !ok {
+		return model.User{}, store.ErrNotFound
+	}
+
+	return u, nil
+}
+
+// GetByEmail returns the user with specific email.
+func (r *userRepo) GetByEmail(_ context.Context, email string) (model.User, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	for _, u := range r.db.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+
+	return model.User{}, store.ErrNotFound
+}
+
+// Update updates username, email, first and second name of the user and returns the
+// updated user, email verification is reset if the email is changed.
+func (r *userRepo) Update(_ context.Context, u model.User) (model.User, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	stored, ok := r.db.users[u.ID]
+	if !ok {
+		return model.User{}, store.ErrNotFound
+	}
+	if err := r.checkUnique(u); err != nil {
+		return model.User{}, err
+	}
+	if u.Email != stored.Email {
+		stored.EmailVerifiedAt = nil
+	}
+	stored.Username, stored.Email = u.Username, u.Email
+	stored.FirstName, stored.SecondName = u.FirstName, u.SecondName
+	r.db.users[u.ID] = stored
+
+	return stored, nil
+}
+
+// SetPasswordHash sets password hash of the user with specific ID.
+func (r *userRepo) SetPasswordHash(_ context.Context, id int, hash string) error {
+	return r.set(id, func(u *model.User) { u.PasswordHash = hash })
+}
+
+// SetEmailVerifiedAt sets email verification time of the user with specific ID, nil
+// marks the email as not verified.
+func (r *userRepo) SetEmailVerifiedAt(_ context.Context, id int, at *time.Time) error {
+	return r.set(id, func(u *model.User) { u.EmailVerifiedAt = at })
+}
+
+// SetDisabledAt sets disabled time of the user with specific ID, nil enables the user.
+func (r *userRepo) SetDisabledAt(_ context.Context, id int, at *time.Time) error {
+	return r.set(id, func(u *model.User) { u.DisabledAt = at })
+}
+
+// set applies the change to the user with specific ID.
+func (r *userRepo) set(id int, change func(*model.User)) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	u, ok := r.db.users[id]
+	if !ok {
+		return store.ErrNotFound
+	}
+	change(&u)
+	r.db.users[id] = u
+
+	return nil
+}
+
+// DeleteByID deletes the user with specific ID along with the user's roles and tokens.
+func (r *userRepo) DeleteByID(_ context.Context, id int) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	if _, ok := r.db.users[id]; !ok {
+		return store.ErrNotFound
+	}
+	delete(r.db.users, id)
+	delete(r.db.userRoles, id)
+	for k, t := range r.db.refreshTokens {
+		if t.UserID == id {
+			delete(r.db.refreshTokens, k)
+		}
+	}
+	for k, t := range r.db.resetTokens {
+		if t.UserID == id {
+			delete(r.db.resetTokens, k)
+		}
+	}
+	for k, t := range r.db.verifyTokens {
+		if t.UserID == id {
+			delete(r.db.verifyTokens, k)
+		}
+	}
+	for k, t := range r.db.magicLinks {
+		if t.UserID == id {
+			delete(r.db.magicLinks, k)
+		}
+	}
+	for k := range r.db.oneTimeCodes {
+		if k.userID == id {
+			delete(r.db.oneTimeCodes, k)
+		}
+	}
+	delete(r.db.totpSecrets, id)
+	delete(r.db.recoveryCodes, id)
+	for k, c := range r.db.credentials {
+		if c.UserID == id {
+			delete(r.db.credentials, k)
+		}
+	}
+
+	return nil
+}
+
+// checkUnique checks that username and email of the user aren't taken by other users.
+func (r *userRepo) checkUnique(u model.User) error {
+	for _, other := range r.db.users {
+		if other.ID == u.ID {
+			continue
+		}
+		if other.Username == u.Username {
+			return store.ErrUsernameIsTaken
+		} else if other.Email == u.Email {
+			return store.ErrEmailIsTaken
+		}
+	}
+
+	return nil
+}
+
+// userLess maps sort fields onto functions ordering users by them in ascending order,
+// users with equal values are ordered by ID.
+var userLess = map[string]func(a, b model.User) bool{
+	model.UserSortID: func(a, b model.User) bool { return a.ID < b.ID },
+	model.UserSortUsername: func(a, b model.User) bool {
+		return a.Username < b.Username || a.Username == b.Username && a.ID < b.ID
+	},
+	model.UserSortEmail: func(a, b model.User) bool {
+		return a.Email < b.Email || a.Email == b.Email && a.ID < b.ID
+	},
+	model.UserSortCreatedAt: func(a, b model.User) bool {
+		return a.CreatedAt.Before(b.CreatedAt) || a.CreatedAt.Equal(b.CreatedAt) && a.ID < b.ID
+	},
+}
+
+// matchUser reports whether the user matches filters of the query.
+func matchUser(u model.User, q model.UserQuery) bool {
+	if !strings.HasPrefix(strings.ToLower(u.Email), strings.ToLower(q.EmailPrefix)) {
+		return false
+	}
+	if !strings.HasPrefix(strings.ToLower(u.Username), strings.ToLower(q.UsernamePrefix)) {
+		return false
+	}
+	if q.CreatedAfter != nil && !u.CreatedAt.After(*q.CreatedAfter) {
+		return false
+	}
+	if q.CreatedBefore != nil && !u.CreatedAt.Before(*q.CreatedBefore) {
+		return false
+	}
+	if q.Verified != nil && *q.Verified != (u.EmailVerifiedAt != nil) {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,114 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// webAuthnCredentialRepo is the WebAuthn credential repository for in-memory store.
+type webAuthnCredentialRepo struct {
+	db *db
+}
+
+// newWebAuthnCredentialRepo creates and returns a new webAuthnCredentialRepo instance.
+func newWebAuthnCredentialRepo(db *db) *webAuthnCredentialRepo {
+	return &webAuthnCredentialRepo{db: db}
+}
+
+// Create creates and returns a new WebAuthn credential.
+func (r *webAuthnCredentialRepo) Create(
+	_ context.Context, c model.WebAuthnCredential,
+) (model.WebAuthnCredential, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	if _, ok := r.db.users[c.UserID]; !ok {
+		return model.WebAuthnCredential{}, store.ErrNotFound
+	}
+	if _, ok := r.db.credentials[c.ID]; ok {
+		return model.WebAuthnCredential{}, store.ErrCredentialIsTaken
+	}
+	c.PublicKey = append([]byte{}, c.PublicKey...)
+	c.CreatedAt = time.Now()
+	r.db.credentials[c.ID] = c
+
+	return c, nil
+}
+
+// GetByID returns the WebAuthn credential with specific ID.
+func (r *webAuthnCredentialRepo) GetByID(
+	_ context.Context, id string,
+) (model.WebAuthnCredential, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	c, ok := r.db.credentials[id]
+	if !ok {
+		return model.WebAuthnCredential{}, store.ErrNotFound
+	}
+
+	return c, nil
+}
+
+// GetByUserID returns WebAuthn credentials of the user with specific ID in order of
+// creation.
+func (r *webAuthnCredentialRepo) GetByUserID(
+	_ context.Context, userID int,
+) ([]model.WebAuthnCredential, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	credentials := []model.WebAuthnCredential{}
+	for _, c := range r.db.credentials {
+		if c.UserID == userID {
+			credentials = append(credentials, c)
+		}
+	}
+	sort.Slice(credentials, func(i, j int) bool {
+		if !credentials[i].CreatedAt.Equal(credentials[j].CreatedAt) {
+			return credentials[i].CreatedAt.Before(credentials[j].CreatedAt)
+		}
+		return credentials[i].ID < credentials[j].ID
+	})
+
+	return credentials, nil
+}
+
+// MarkUsed sets the sign count of the WebAuthn credential with specific ID. It returns
+// store.ErrTokenIsUsed if the sign count doesn't grow, counters which stay at zero are
+// accepted since some authenticators don't count signatures.
+func (r *webAuthnCredentialRepo) MarkUsed(_ context.Context, id string, signCount int64) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	c, ok := r.db.credentials[id]
+	if !ok {
+		return store.ErrNotFound
+	}
+	if c.SignCount >= signCount && !(c.SignCount == 0 && signCount == 0) {
+		return store.ErrTokenIsUsed
+	}
+	c.SignCount = signCount
+	c.LastUsedAt = now()
+	r.db.credentials[id] = c
+
+	return nil
+}
+
+// Delete deletes the WebAuthn credential with specific ID of the user with specific ID.
+func (r *webAuthnCredentialRepo) Delete(_ context.Context, userID int, id string) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	c, ok := r.db.credentials[id]
+	if !ok || c.UserID != userID {
+		return store.ErrNotFound
+	}
+	delete(r.db.credentials, id)
+
+	return nil
+}
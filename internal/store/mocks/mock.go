@@ -5,10 +5,12 @@
 package mock_store
 
 import (
+	context "context"
 	gomock "github.com/golang/mock/gomock"
 	model "github.com/imarrche/jwt-auth-example/internal/model"
 	store "github.com/imarrche/jwt-auth-example/internal/store"
 	reflect "reflect"
+	time "time"
 )
 
 // MockStore is a mock of Store interface
@@ -62,6 +64,202 @@ func (mr *MockStoreMockRecorder) Users() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Users", reflect.TypeOf((*MockStore)(nil).Users))
 }
 
+// RefreshTokens mocks base method
+func (m *MockStore) RefreshTokens() store.RefreshTokenRepo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshTokens")
+	ret0, _ := ret[0].(store.RefreshTokenRepo)
+	return ret0
+}
+
+// RefreshTokens indicates an expected call of RefreshTokens
+func (mr *MockStoreMockRecorder) RefreshTokens() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshTokens", reflect.TypeOf((*MockStore)(nil).RefreshTokens))
+}
+
+// RevokedTokens mocks base method
+func (m *MockStore) RevokedTokens() store.RevokedTokenRepo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokedTokens")
+	ret0, _ := ret[0].(store.RevokedTokenRepo)
+	return ret0
+}
+
+// RevokedTokens indicates an expected call of RevokedTokens
+func (mr *MockStoreMockRecorder) RevokedTokens() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokedTokens", reflect.TypeOf((*MockStore)(nil).RevokedTokens))
+}
+
+// SigningKeys mocks base method
+func (m *MockStore) SigningKeys() store.SigningKeyRepo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SigningKeys")
+	ret0, _ := ret[0].(store.SigningKeyRepo)
+	return ret0
+}
+
+// SigningKeys indicates an expected call of SigningKeys
+func (mr *MockStoreMockRecorder) SigningKeys() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SigningKeys", reflect.TypeOf((*MockStore)(nil).SigningKeys))
+}
+
+// Roles mocks base method
+func (m *MockStore) Roles() store.RoleRepo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Roles")
+	ret0, _ := ret[0].(store.RoleRepo)
+	return ret0
+}
+
+// Roles indicates an expected call of Roles
+func (mr *MockStoreMockRecorder) Roles() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Roles", reflect.TypeOf((*MockStore)(nil).Roles))
+}
+
+// PasswordResetTokens mocks base method
+func (m *MockStore) PasswordResetTokens() store.PasswordResetTokenRepo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PasswordResetTokens")
+	ret0, _ := ret[0].(store.PasswordResetTokenRepo)
+	return ret0
+}
+
+// PasswordResetTokens indicates an expected call of PasswordResetTokens
+func (mr *MockStoreMockRecorder) PasswordResetTokens() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PasswordResetTokens", reflect.TypeOf((*MockStore)(nil).PasswordResetTokens))
+}
+
+// EmailVerificationTokens mocks base method
+func (m *MockStore) EmailVerificationTokens() store.EmailVerificationTokenRepo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EmailVerificationTokens")
+	ret0, _ := ret[0].(store.EmailVerificationTokenRepo)
+	return ret0
+}
+
+// EmailVerificationTokens indicates an expected call of EmailVerificationTokens
+func (mr *MockStoreMockRecorder) EmailVerificationTokens() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EmailVerificationTokens", reflect.TypeOf((*MockStore)(nil).EmailVerificationTokens))
+}
+
+// LoginThrottles mocks base method
+func (m *MockStore) LoginThrottles() store.LoginThrottleRepo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoginThrottles")
+	ret0, _ := ret[0].(store.LoginThrottleRepo)
+	return ret0
+}
+
+// LoginThrottles indicates an expected call of LoginThrottles
+func (mr *MockStoreMockRecorder) LoginThrottles() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginThrottles", reflect.TypeOf((*MockStore)(nil).LoginThrottles))
+}
+
+// RateLimits mocks base method
+func (m *MockStore) RateLimits() store.RateLimitRepo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RateLimits")
+	ret0, _ := ret[0].(store.RateLimitRepo)
+	return ret0
+}
+
+// RateLimits indicates an expected call of RateLimits
+func (mr *MockStoreMockRecorder) RateLimits() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RateLimits", reflect.TypeOf((*MockStore)(nil).RateLimits))
+}
+
+// AuditEvents mocks base method
+func (m *MockStore) AuditEvents() store.AuditEventRepo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuditEvents")
+	ret0, _ := ret[0].(store.AuditEventRepo)
+	return ret0
+}
+
+// AuditEvents indicates an expected call of AuditEvents
+func (mr *MockStoreMockRecorder) AuditEvents() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuditEvents", reflect.TypeOf((*MockStore)(nil).AuditEvents))
+}
+
+// TOTPSecrets mocks base method
+func (m *MockStore) TOTPSecrets() store.TOTPSecretRepo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TOTPSecrets")
+	ret0, _ := ret[0].(store.TOTPSecretRepo)
+	return ret0
+}
+
+// TOTPSecrets indicates an expected call of TOTPSecrets
+func (mr *MockStoreMockRecorder) TOTPSecrets() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TOTPSecrets", reflect.TypeOf((*MockStore)(nil).TOTPSecrets))
+}
+
+// RecoveryCodes mocks base method
+func (m *MockStore) RecoveryCodes() store.RecoveryCodeRepo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecoveryCodes")
+	ret0, _ := ret[0].(store.RecoveryCodeRepo)
+	return ret0
+}
+
+// RecoveryCodes indicates an expected call of RecoveryCodes
+func (mr *MockStoreMockRecorder) RecoveryCodes() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecoveryCodes", reflect.TypeOf((*MockStore)(nil).RecoveryCodes))
+}
+
+// WebAuthnCredentials mocks base method
+func (m *MockStore) WebAuthnCredentials() store.WebAuthnCredentialRepo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WebAuthnCredentials")
+	ret0, _ := ret[0].(store.WebAuthnCredentialRepo)
+	return ret0
+}
+
+// WebAuthnCredentials indicates an expected call of WebAuthnCredentials
+func (mr *MockStoreMockRecorder) WebAuthnCredentials() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WebAuthnCredentials", reflect.TypeOf((*MockStore)(nil).WebAuthnCredentials))
+}
+
+// MagicLinkTokens mocks base method
+func (m *MockStore) MagicLinkTokens() store.MagicLinkTokenRepo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MagicLinkTokens")
+	ret0, _ := ret[0].(store.MagicLinkTokenRepo)
+	return ret0
+}
+
+// MagicLinkTokens indicates an expected call of MagicLinkTokens
+func (mr *MockStoreMockRecorder) MagicLinkTokens() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MagicLinkTokens", reflect.TypeOf((*MockStore)(nil).MagicLinkTokens))
+}
+
+// OneTimeCodes mocks base method
+func (m *MockStore) OneTimeCodes() store.OneTimeCodeRepo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OneTimeCodes")
+	ret0, _ := ret[0].(store.OneTimeCodeRepo)
+	return ret0
+}
+
+// OneTimeCodes indicates an expected call of OneTimeCodes
+func (mr *MockStoreMockRecorder) OneTimeCodes() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OneTimeCodes", reflect.TypeOf((*MockStore)(nil).OneTimeCodes))
+}
+
 // Close mocks base method
 func (m *MockStore) Close() error {
 	m.ctrl.T.Helper()
@@ -100,90 +298,1324 @@ func (m *MockUserRepo) EXPECT() *MockUserRepoMockRecorder {
 }
 
 // GetAll mocks base method
-func (m *MockUserRepo) GetAll() ([]model.User, error) {
+func (m *MockUserRepo) GetAll(arg0 context.Context) ([]model.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAll")
+	ret := m.ctrl.Call(m, "GetAll", arg0)
 	ret0, _ := ret[0].([]model.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAll indicates an expected call of GetAll
-func (mr *MockUserRepoMockRecorder) GetAll() *gomock.Call {
+func (mr *MockUserRepoMockRecorder) GetAll(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockUserRepo)(nil).GetAll), arg0)
+}
+
+// List mocks base method
+func (m *MockUserRepo) List(arg0 context.Context, arg1 model.UserQuery) ([]model.User, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret0, _ := ret[0].([]model.User)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// List indicates an expected call of List
+func (mr *MockUserRepoMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockUserRepo)(nil).GetAll))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockUserRepo)(nil).List), arg0, arg1)
 }
 
 // Create mocks base method
-func (m *MockUserRepo) Create(arg0 model.User) (model.User, error) {
+func (m *MockUserRepo) Create(arg0 context.Context, arg1 model.User) (model.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", arg0)
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
 	ret0, _ := ret[0].(model.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Create indicates an expected call of Create
-func (mr *MockUserRepoMockRecorder) Create(arg0 interface{}) *gomock.Call {
+func (mr *MockUserRepoMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockUserRepo)(nil).Create), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockUserRepo)(nil).Create), arg0, arg1)
 }
 
 // GetByID mocks base method
-func (m *MockUserRepo) GetByID(arg0 int) (model.User, error) {
+func (m *MockUserRepo) GetByID(arg0 context.Context, arg1 int) (model.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetByID", arg0)
+	ret := m.ctrl.Call(m, "GetByID", arg0, arg1)
 	ret0, _ := ret[0].(model.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetByID indicates an expected call of GetByID
-func (mr *MockUserRepoMockRecorder) GetByID(arg0 interface{}) *gomock.Call {
+func (mr *MockUserRepoMockRecorder) GetByID(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockUserRepo)(nil).GetByID), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockUserRepo)(nil).GetByID), arg0, arg1)
 }
 
 // GetByEmail mocks base method
-func (m *MockUserRepo) GetByEmail(arg0 string) (model.User, error) {
+func (m *MockUserRepo) GetByEmail(arg0 context.Context, arg1 string) (model.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetByEmail", arg0)
+	ret := m.ctrl.Call(m, "GetByEmail", arg0, arg1)
 	ret0, _ := ret[0].(model.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetByEmail indicates an expected call of GetByEmail
-func (mr *MockUserRepoMockRecorder) GetByEmail(arg0 interface{}) *gomock.Call {
+func (mr *MockUserRepoMockRecorder) GetByEmail(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByEmail", reflect.TypeOf((*MockUserRepo)(nil).GetByEmail), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByEmail", reflect.TypeOf((*MockUserRepo)(nil).GetByEmail), arg0, arg1)
 }
 
 // Update mocks base method
-func (m *MockUserRepo) Update(arg0 model.User) (model.User, error) {
+func (m *MockUserRepo) Update(arg0 context.Context, arg1 model.User) (model.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", arg0)
+	ret := m.ctrl.Call(m, "Update", arg0, arg1)
 	ret0, _ := ret[0].(model.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Update indicates an expected call of Update
-func (mr *MockUserRepoMockRecorder) Update(arg0 interface{}) *gomock.Call {
+func (mr *MockUserRepoMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockUserRepo)(nil).Update), arg0, arg1)
+}
+
+// SetPasswordHash mocks base method
+func (m *MockUserRepo) SetPasswordHash(arg0 context.Context, arg1 int, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPasswordHash", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPasswordHash indicates an expected call of SetPasswordHash
+func (mr *MockUserRepoMockRecorder) SetPasswordHash(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPasswordHash", reflect.TypeOf((*MockUserRepo)(nil).SetPasswordHash), arg0, arg1, arg2)
+}
+
+// SetEmailVerifiedAt mocks base method
+func (m *MockUserRepo) SetEmailVerifiedAt(arg0 context.Context, arg1 int, arg2 *time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetEmailVerifiedAt", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetEmailVerifiedAt indicates an expected call of SetEmailVerifiedAt
+func (mr *MockUserRepoMockRecorder) SetEmailVerifiedAt(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEmailVerifiedAt", reflect.TypeOf((*MockUserRepo)(nil).SetEmailVerifiedAt), arg0, arg1, arg2)
+}
+
+// SetDisabledAt mocks base method
+func (m *MockUserRepo) SetDisabledAt(arg0 context.Context, arg1 int, arg2 *time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDisabledAt", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetDisabledAt indicates an expected call of SetDisabledAt
+func (mr *MockUserRepoMockRecorder) SetDisabledAt(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockUserRepo)(nil).Update), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDisabledAt", reflect.TypeOf((*MockUserRepo)(nil).SetDisabledAt), arg0, arg1, arg2)
 }
 
 // DeleteByID mocks base method
-func (m *MockUserRepo) DeleteByID(arg0 int) error {
+func (m *MockUserRepo) DeleteByID(arg0 context.Context, arg1 int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteByID", arg0)
+	ret := m.ctrl.Call(m, "DeleteByID", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteByID indicates an expected call of DeleteByID
-func (mr *MockUserRepoMockRecorder) DeleteByID(arg0 interface{}) *gomock.Call {
+func (mr *MockUserRepoMockRecorder) DeleteByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByID", reflect.TypeOf((*MockUserRepo)(nil).DeleteByID), arg0, arg1)
+}
+
+// MockRefreshTokenRepo is a mock of RefreshTokenRepo interface
+type MockRefreshTokenRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockRefreshTokenRepoMockRecorder
+}
+
+// MockRefreshTokenRepoMockRecorder is the mock recorder for MockRefreshTokenRepo
+type MockRefreshTokenRepoMockRecorder struct {
+	mock *MockRefreshTokenRepo
+}
+
+// NewMockRefreshTokenRepo creates a new mock instance
+func NewMockRefreshTokenRepo(ctrl *gomock.Controller) *MockRefreshTokenRepo {
+	mock := &MockRefreshTokenRepo{ctrl: ctrl}
+	mock.recorder = &MockRefreshTokenRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRefreshTokenRepo) EXPECT() *MockRefreshTokenRepoMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockRefreshTokenRepo) Create(arg0 context.Context, arg1 model.RefreshToken) (model.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(model.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockRefreshTokenRepoMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRefreshTokenRepo)(nil).Create), arg0, arg1)
+}
+
+// GetByID mocks base method
+func (m *MockRefreshTokenRepo) GetByID(arg0 context.Context, arg1 string) (model.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", arg0, arg1)
+	ret0, _ := ret[0].(model.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID
+func (mr *MockRefreshTokenRepoMockRecorder) GetByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockRefreshTokenRepo)(nil).GetByID), arg0, arg1)
+}
+
+// MarkUsed mocks base method
+func (m *MockRefreshTokenRepo) MarkUsed(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkUsed", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkUsed indicates an expected call of MarkUsed
+func (mr *MockRefreshTokenRepoMockRecorder) MarkUsed(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkUsed", reflect.TypeOf((*MockRefreshTokenRepo)(nil).MarkUsed), arg0, arg1)
+}
+
+// RevokeFamily mocks base method
+func (m *MockRefreshTokenRepo) RevokeFamily(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeFamily", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeFamily indicates an expected call of RevokeFamily
+func (mr *MockRefreshTokenRepoMockRecorder) RevokeFamily(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeFamily", reflect.TypeOf((*MockRefreshTokenRepo)(nil).RevokeFamily), arg0, arg1)
+}
+
+// RevokeByUserID mocks base method
+func (m *MockRefreshTokenRepo) RevokeByUserID(arg0 context.Context, arg1 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeByUserID", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeByUserID indicates an expected call of RevokeByUserID
+func (mr *MockRefreshTokenRepoMockRecorder) RevokeByUserID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeByUserID", reflect.TypeOf((*MockRefreshTokenRepo)(nil).RevokeByUserID), arg0, arg1)
+}
+
+// DeleteExpired mocks base method
+func (m *MockRefreshTokenRepo) DeleteExpired(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpired", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired
+func (mr *MockRefreshTokenRepoMockRecorder) DeleteExpired(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockRefreshTokenRepo)(nil).DeleteExpired), arg0)
+}
+
+// MockRevokedTokenRepo is a mock of RevokedTokenRepo interface
+type MockRevokedTokenRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockRevokedTokenRepoMockRecorder
+}
+
+// MockRevokedTokenRepoMockRecorder is the mock recorder for MockRevokedTokenRepo
+type MockRevokedTokenRepoMockRecorder struct {
+	mock *MockRevokedTokenRepo
+}
+
+// NewMockRevokedTokenRepo creates a new mock instance
+func NewMockRevokedTokenRepo(ctrl *gomock.Controller) *MockRevokedTokenRepo {
+	mock := &MockRevokedTokenRepo{ctrl: ctrl}
+	mock.recorder = &MockRevokedTokenRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRevokedTokenRepo) EXPECT() *MockRevokedTokenRepoMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockRevokedTokenRepo) Create(arg0 context.Context, arg1 model.RevokedToken) (model.RevokedToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(model.RevokedToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockRevokedTokenRepoMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRevokedTokenRepo)(nil).Create), arg0, arg1)
+}
+
+// Use mocks base method
+func (m *MockRevokedTokenRepo) Use(arg0 context.Context, arg1 model.RevokedToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Use", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Use indicates an expected call of Use
+func (mr *MockRevokedTokenRepoMockRecorder) Use(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Use", reflect.TypeOf((*MockRevokedTokenRepo)(nil).Use), arg0, arg1)
+}
+
+// Exists mocks base method
+func (m *MockRevokedTokenRepo) Exists(arg0 context.Context, arg1 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Exists", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Exists indicates an expected call of Exists
+func (mr *MockRevokedTokenRepoMockRecorder) Exists(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exists", reflect.TypeOf((*MockRevokedTokenRepo)(nil).Exists), arg0, arg1)
+}
+
+// DeleteExpired mocks base method
+func (m *MockRevokedTokenRepo) DeleteExpired(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpired", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired
+func (mr *MockRevokedTokenRepoMockRecorder) DeleteExpired(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockRevokedTokenRepo)(nil).DeleteExpired), arg0)
+}
+
+// MockSigningKeyRepo is a mock of SigningKeyRepo interface
+type MockSigningKeyRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockSigningKeyRepoMockRecorder
+}
+
+// MockSigningKeyRepoMockRecorder is the mock recorder for MockSigningKeyRepo
+type MockSigningKeyRepoMockRecorder struct {
+	mock *MockSigningKeyRepo
+}
+
+// NewMockSigningKeyRepo creates a new mock instance
+func NewMockSigningKeyRepo(ctrl *gomock.Controller) *MockSigningKeyRepo {
+	mock := &MockSigningKeyRepo{ctrl: ctrl}
+	mock.recorder = &MockSigningKeyRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockSigningKeyRepo) EXPECT() *MockSigningKeyRepoMockRecorder {
+	return m.recorder
+}
+
+// GetAll mocks base method
+func (m *MockSigningKeyRepo) GetAll(arg0 context.Context) ([]model.SigningKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", arg0)
+	ret0, _ := ret[0].([]model.SigningKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll
+func (mr *MockSigningKeyRepoMockRecorder) GetAll(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockSigningKeyRepo)(nil).GetAll), arg0)
+}
+
+// Create mocks base method
+func (m *MockSigningKeyRepo) Create(arg0 context.Context, arg1 model.SigningKey) (model.SigningKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(model.SigningKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockSigningKeyRepoMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockSigningKeyRepo)(nil).Create), arg0, arg1)
+}
+
+// Activate mocks base method
+func (m *MockSigningKeyRepo) Activate(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Activate", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Activate indicates an expected call of Activate
+func (mr *MockSigningKeyRepoMockRecorder) Activate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Activate", reflect.TypeOf((*MockSigningKeyRepo)(nil).Activate), arg0, arg1)
+}
+
+// Retire mocks base method
+func (m *MockSigningKeyRepo) Retire(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Retire", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Retire indicates an expected call of Retire
+func (mr *MockSigningKeyRepoMockRecorder) Retire(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Retire", reflect.TypeOf((*MockSigningKeyRepo)(nil).Retire), arg0, arg1)
+}
+
+// DeleteRetiredBefore mocks base method
+func (m *MockSigningKeyRepo) DeleteRetiredBefore(arg0 context.Context, arg1 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRetiredBefore", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRetiredBefore indicates an expected call of DeleteRetiredBefore
+func (mr *MockSigningKeyRepoMockRecorder) DeleteRetiredBefore(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRetiredBefore", reflect.TypeOf((*MockSigningKeyRepo)(nil).DeleteRetiredBefore), arg0, arg1)
+}
+
+// MockRoleRepo is a mock of RoleRepo interface
+type MockRoleRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockRoleRepoMockRecorder
+}
+
+// MockRoleRepoMockRecorder is the mock recorder for MockRoleRepo
+type MockRoleRepoMockRecorder struct {
+	mock *MockRoleRepo
+}
+
+// NewMockRoleRepo creates a new mock instance
+func NewMockRoleRepo(ctrl *gomock.Controller) *MockRoleRepo {
+	mock := &MockRoleRepo{ctrl: ctrl}
+	mock.recorder = &MockRoleRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRoleRepo) EXPECT() *MockRoleRepoMockRecorder {
+	return m.recorder
+}
+
+// GetAll mocks base method
+func (m *MockRoleRepo) GetAll(arg0 context.Context) ([]model.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", arg0)
+	ret0, _ := ret[0].([]model.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll
+func (mr *MockRoleRepoMockRecorder) GetAll(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockRoleRepo)(nil).GetAll), arg0)
+}
+
+// GetByUserID mocks base method
+func (m *MockRoleRepo) GetByUserID(arg0 context.Context, arg1 int) ([]model.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByUserID", arg0, arg1)
+	ret0, _ := ret[0].([]model.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByUserID indicates an expected call of GetByUserID
+func (mr *MockRoleRepoMockRecorder) GetByUserID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUserID", reflect.TypeOf((*MockRoleRepo)(nil).GetByUserID), arg0, arg1)
+}
+
+// AssignToUser mocks base method
+func (m *MockRoleRepo) AssignToUser(arg0 context.Context, arg1 int, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssignToUser", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AssignToUser indicates an expected call of AssignToUser
+func (mr *MockRoleRepoMockRecorder) AssignToUser(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignToUser", reflect.TypeOf((*MockRoleRepo)(nil).AssignToUser), arg0, arg1, arg2)
+}
+
+// RemoveFromUser mocks base method
+func (m *MockRoleRepo) RemoveFromUser(arg0 context.Context, arg1 int, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveFromUser", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveFromUser indicates an expected call of RemoveFromUser
+func (mr *MockRoleRepoMockRecorder) RemoveFromUser(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveFromUser", reflect.TypeOf((*MockRoleRepo)(nil).RemoveFromUser), arg0, arg1, arg2)
+}
+
+// MockPasswordResetTokenRepo is a mock of PasswordResetTokenRepo interface
+type MockPasswordResetTokenRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasswordResetTokenRepoMockRecorder
+}
+
+// MockPasswordResetTokenRepoMockRecorder is the mock recorder for MockPasswordResetTokenRepo
+type MockPasswordResetTokenRepoMockRecorder struct {
+	mock *MockPasswordResetTokenRepo
+}
+
+// NewMockPasswordResetTokenRepo creates a new mock instance
+func NewMockPasswordResetTokenRepo(ctrl *gomock.Controller) *MockPasswordResetTokenRepo {
+	mock := &MockPasswordResetTokenRepo{ctrl: ctrl}
+	mock.recorder = &MockPasswordResetTokenRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockPasswordResetTokenRepo) EXPECT() *MockPasswordResetTokenRepoMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockPasswordResetTokenRepo) Create(arg0 context.Context, arg1 model.PasswordResetToken) (model.PasswordResetToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(model.PasswordResetToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockPasswordResetTokenRepoMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockPasswordResetTokenRepo)(nil).Create), arg0, arg1)
+}
+
+// GetByHash mocks base method
+func (m *MockPasswordResetTokenRepo) GetByHash(arg0 context.Context, arg1 string) (model.PasswordResetToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByHash", arg0, arg1)
+	ret0, _ := ret[0].(model.PasswordResetToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByHash indicates an expected call of GetByHash
+func (mr *MockPasswordResetTokenRepoMockRecorder) GetByHash(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByHash", reflect.TypeOf((*MockPasswordResetTokenRepo)(nil).GetByHash), arg0, arg1)
+}
+
+// MarkUsed mocks base method
+func (m *MockPasswordResetTokenRepo) MarkUsed(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkUsed", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkUsed indicates an expected call of MarkUsed
+func (mr *MockPasswordResetTokenRepoMockRecorder) MarkUsed(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkUsed", reflect.TypeOf((*MockPasswordResetTokenRepo)(nil).MarkUsed), arg0, arg1)
+}
+
+// DeleteExpired mocks base method
+func (m *MockPasswordResetTokenRepo) DeleteExpired(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpired", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired
+func (mr *MockPasswordResetTokenRepoMockRecorder) DeleteExpired(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockPasswordResetTokenRepo)(nil).DeleteExpired), arg0)
+}
+
+// MockMagicLinkTokenRepo is a mock of MagicLinkTokenRepo interface
+type MockMagicLinkTokenRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockMagicLinkTokenRepoMockRecorder
+}
+
+// MockMagicLinkTokenRepoMockRecorder is the mock recorder for MockMagicLinkTokenRepo
+type MockMagicLinkTokenRepoMockRecorder struct {
+	mock *MockMagicLinkTokenRepo
+}
+
+// NewMockMagicLinkTokenRepo creates a new mock instance
+func NewMockMagicLinkTokenRepo(ctrl *gomock.Controller) *MockMagicLinkTokenRepo {
+	mock := &MockMagicLinkTokenRepo{ctrl: ctrl}
+	mock.recorder = &MockMagicLinkTokenRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockMagicLinkTokenRepo) EXPECT() *MockMagicLinkTokenRepoMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockMagicLinkTokenRepo) Create(arg0 context.Context, arg1 model.MagicLinkToken) (model.MagicLinkToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(model.MagicLinkToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockMagicLinkTokenRepoMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockMagicLinkTokenRepo)(nil).Create), arg0, arg1)
+}
+
+// GetByHash mocks base method
+func (m *MockMagicLinkTokenRepo) GetByHash(arg0 context.Context, arg1 string) (model.MagicLinkToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByHash", arg0, arg1)
+	ret0, _ := ret[0].(model.MagicLinkToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByHash indicates an expected call of GetByHash
+func (mr *MockMagicLinkTokenRepoMockRecorder) GetByHash(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByHash", reflect.TypeOf((*MockMagicLinkTokenRepo)(nil).GetByHash), arg0, arg1)
+}
+
+// MarkUsed mocks base method
+func (m *MockMagicLinkTokenRepo) MarkUsed(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkUsed", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkUsed indicates an expected call of MarkUsed
+func (mr *MockMagicLinkTokenRepoMockRecorder) MarkUsed(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkUsed", reflect.TypeOf((*MockMagicLinkTokenRepo)(nil).MarkUsed), arg0, arg1)
+}
+
+// DeleteExpired mocks base method
+func (m *MockMagicLinkTokenRepo) DeleteExpired(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpired", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired
+func (mr *MockMagicLinkTokenRepoMockRecorder) DeleteExpired(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockMagicLinkTokenRepo)(nil).DeleteExpired), arg0)
+}
+
+// MockOneTimeCodeRepo is a mock of OneTimeCodeRepo interface
+type MockOneTimeCodeRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockOneTimeCodeRepoMockRecorder
+}
+
+// MockOneTimeCodeRepoMockRecorder is the mock recorder for MockOneTimeCodeRepo
+type MockOneTimeCodeRepoMockRecorder struct {
+	mock *MockOneTimeCodeRepo
+}
+
+// NewMockOneTimeCodeRepo creates a new mock instance
+func NewMockOneTimeCodeRepo(ctrl *gomock.Controller) *MockOneTimeCodeRepo {
+	mock := &MockOneTimeCodeRepo{ctrl: ctrl}
+	mock.recorder = &MockOneTimeCodeRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockOneTimeCodeRepo) EXPECT() *MockOneTimeCodeRepoMockRecorder {
+	return m.recorder
+}
+
+// Save mocks base method
+func (m *MockOneTimeCodeRepo) Save(arg0 context.Context, arg1 model.OneTimeCode) (model.OneTimeCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", arg0, arg1)
+	ret0, _ := ret[0].(model.OneTimeCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Save indicates an expected call of Save
+func (mr *MockOneTimeCodeRepoMockRecorder) Save(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockOneTimeCodeRepo)(nil).Save), arg0, arg1)
+}
+
+// Get mocks base method
+func (m *MockOneTimeCodeRepo) Get(arg0 context.Context, arg1 int, arg2 string) (model.OneTimeCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0, arg1, arg2)
+	ret0, _ := ret[0].(model.OneTimeCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockOneTimeCodeRepoMockRecorder) Get(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockOneTimeCodeRepo)(nil).Get), arg0, arg1, arg2)
+}
+
+// Attempt mocks base method
+func (m *MockOneTimeCodeRepo) Attempt(arg0 context.Context, arg1 int, arg2 string, arg3 int) (model.OneTimeCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Attempt", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(model.OneTimeCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Attempt indicates an expected call of Attempt
+func (mr *MockOneTimeCodeRepoMockRecorder) Attempt(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Attempt", reflect.TypeOf((*MockOneTimeCodeRepo)(nil).Attempt), arg0, arg1, arg2, arg3)
+}
+
+// Delete mocks base method
+func (m *MockOneTimeCodeRepo) Delete(arg0 context.Context, arg1 int, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockOneTimeCodeRepoMockRecorder) Delete(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockOneTimeCodeRepo)(nil).Delete), arg0, arg1, arg2)
+}
+
+// DeleteExpired mocks base method
+func (m *MockOneTimeCodeRepo) DeleteExpired(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpired", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired
+func (mr *MockOneTimeCodeRepoMockRecorder) DeleteExpired(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockOneTimeCodeRepo)(nil).DeleteExpired), arg0)
+}
+
+// MockEmailVerificationTokenRepo is a mock of EmailVerificationTokenRepo interface
+type MockEmailVerificationTokenRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockEmailVerificationTokenRepoMockRecorder
+}
+
+// MockEmailVerificationTokenRepoMockRecorder is the mock recorder for MockEmailVerificationTokenRepo
+type MockEmailVerificationTokenRepoMockRecorder struct {
+	mock *MockEmailVerificationTokenRepo
+}
+
+// NewMockEmailVerificationTokenRepo creates a new mock instance
+func NewMockEmailVerificationTokenRepo(ctrl *gomock.Controller) *MockEmailVerificationTokenRepo {
+	mock := &MockEmailVerificationTokenRepo{ctrl: ctrl}
+	mock.recorder = &MockEmailVerificationTokenRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockEmailVerificationTokenRepo) EXPECT() *MockEmailVerificationTokenRepoMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockEmailVerificationTokenRepo) Create(arg0 context.Context, arg1 model.EmailVerificationToken) (model.EmailVerificationToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(model.EmailVerificationToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockEmailVerificationTokenRepoMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockEmailVerificationTokenRepo)(nil).Create), arg0, arg1)
+}
+
+// GetByHash mocks base method
+func (m *MockEmailVerificationTokenRepo) GetByHash(arg0 context.Context, arg1 string) (model.EmailVerificationToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByHash", arg0, arg1)
+	ret0, _ := ret[0].(model.EmailVerificationToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByHash indicates an expected call of GetByHash
+func (mr *MockEmailVerificationTokenRepoMockRecorder) GetByHash(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByHash", reflect.TypeOf((*MockEmailVerificationTokenRepo)(nil).GetByHash), arg0, arg1)
+}
+
+// MarkUsed mocks base method
+func (m *MockEmailVerificationTokenRepo) MarkUsed(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkUsed", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkUsed indicates an expected call of MarkUsed
+func (mr *MockEmailVerificationTokenRepoMockRecorder) MarkUsed(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkUsed", reflect.TypeOf((*MockEmailVerificationTokenRepo)(nil).MarkUsed), arg0, arg1)
+}
+
+// DeleteExpired mocks base method
+func (m *MockEmailVerificationTokenRepo) DeleteExpired(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpired", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired
+func (mr *MockEmailVerificationTokenRepoMockRecorder) DeleteExpired(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockEmailVerificationTokenRepo)(nil).DeleteExpired), arg0)
+}
+
+// MockLoginThrottleRepo is a mock of LoginThrottleRepo interface
+type MockLoginThrottleRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginThrottleRepoMockRecorder
+}
+
+// MockLoginThrottleRepoMockRecorder is the mock recorder for MockLoginThrottleRepo
+type MockLoginThrottleRepoMockRecorder struct {
+	mock *MockLoginThrottleRepo
+}
+
+// NewMockLoginThrottleRepo creates a new mock instance
+func NewMockLoginThrottleRepo(ctrl *gomock.Controller) *MockLoginThrottleRepo {
+	mock := &MockLoginThrottleRepo{ctrl: ctrl}
+	mock.recorder = &MockLoginThrottleRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockLoginThrottleRepo) EXPECT() *MockLoginThrottleRepoMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method
+func (m *MockLoginThrottleRepo) Get(arg0 context.Context, arg1 string) (model.LoginThrottle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0, arg1)
+	ret0, _ := ret[0].(model.LoginThrottle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockLoginThrottleRepoMockRecorder) Get(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockLoginThrottleRepo)(nil).Get), arg0, arg1)
+}
+
+// RegisterFailure mocks base method
+func (m *MockLoginThrottleRepo) RegisterFailure(arg0 context.Context, arg1 string, arg2 time.Time) (model.LoginThrottle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterFailure", arg0, arg1, arg2)
+	ret0, _ := ret[0].(model.LoginThrottle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RegisterFailure indicates an expected call of RegisterFailure
+func (mr *MockLoginThrottleRepoMockRecorder) RegisterFailure(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterFailure", reflect.TypeOf((*MockLoginThrottleRepo)(nil).RegisterFailure), arg0, arg1, arg2)
+}
+
+// Lock mocks base method
+func (m *MockLoginThrottleRepo) Lock(arg0 context.Context, arg1 string, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lock", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Lock indicates an expected call of Lock
+func (mr *MockLoginThrottleRepoMockRecorder) Lock(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lock", reflect.TypeOf((*MockLoginThrottleRepo)(nil).Lock), arg0, arg1, arg2)
+}
+
+// Delete mocks base method
+func (m *MockLoginThrottleRepo) Delete(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockLoginThrottleRepoMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockLoginThrottleRepo)(nil).Delete), arg0, arg1)
+}
+
+// DeleteStale mocks base method
+func (m *MockLoginThrottleRepo) DeleteStale(arg0 context.Context, arg1 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteStale", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteStale indicates an expected call of DeleteStale
+func (mr *MockLoginThrottleRepoMockRecorder) DeleteStale(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStale", reflect.TypeOf((*MockLoginThrottleRepo)(nil).DeleteStale), arg0, arg1)
+}
+
+// MockRateLimitRepo is a mock of RateLimitRepo interface
+type MockRateLimitRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockRateLimitRepoMockRecorder
+}
+
+// MockRateLimitRepoMockRecorder is the mock recorder for MockRateLimitRepo
+type MockRateLimitRepoMockRecorder struct {
+	mock *MockRateLimitRepo
+}
+
+// NewMockRateLimitRepo creates a new mock instance
+func NewMockRateLimitRepo(ctrl *gomock.Controller) *MockRateLimitRepo {
+	mock := &MockRateLimitRepo{ctrl: ctrl}
+	mock.recorder = &MockRateLimitRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRateLimitRepo) EXPECT() *MockRateLimitRepoMockRecorder {
+	return m.recorder
+}
+
+// Take mocks base method
+func (m *MockRateLimitRepo) Take(arg0 context.Context, arg1 string, arg2 int, arg3 time.Duration) (float64, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Take", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Take indicates an expected call of Take
+func (mr *MockRateLimitRepoMockRecorder) Take(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Take", reflect.TypeOf((*MockRateLimitRepo)(nil).Take), arg0, arg1, arg2, arg3)
+}
+
+// DeleteStale mocks base method
+func (m *MockRateLimitRepo) DeleteStale(arg0 context.Context, arg1 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteStale", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteStale indicates an expected call of DeleteStale
+func (mr *MockRateLimitRepoMockRecorder) DeleteStale(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStale", reflect.TypeOf((*MockRateLimitRepo)(nil).DeleteStale), arg0, arg1)
+}
+
+// MockAuditEventRepo is a mock of AuditEventRepo interface
+type MockAuditEventRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditEventRepoMockRecorder
+}
+
+// MockAuditEventRepoMockRecorder is the mock recorder for MockAuditEventRepo
+type MockAuditEventRepoMockRecorder struct {
+	mock *MockAuditEventRepo
+}
+
+// NewMockAuditEventRepo creates a new mock instance
+func NewMockAuditEventRepo(ctrl *gomock.Controller) *MockAuditEventRepo {
+	mock := &MockAuditEventRepo{ctrl: ctrl}
+	mock.recorder = &MockAuditEventRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAuditEventRepo) EXPECT() *MockAuditEventRepoMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockAuditEventRepo) Create(arg0 context.Context, arg1 model.AuditEvent) (model.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(model.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockAuditEventRepoMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAuditEventRepo)(nil).Create), arg0, arg1)
+}
+
+// GetByUserID mocks base method
+func (m *MockAuditEventRepo) GetByUserID(arg0 context.Context, arg1 int) ([]model.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByUserID", arg0, arg1)
+	ret0, _ := ret[0].([]model.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByUserID indicates an expected call of GetByUserID
+func (mr *MockAuditEventRepoMockRecorder) GetByUserID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUserID", reflect.TypeOf((*MockAuditEventRepo)(nil).GetByUserID), arg0, arg1)
+}
+
+// MockTOTPSecretRepo is a mock of TOTPSecretRepo interface
+type MockTOTPSecretRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockTOTPSecretRepoMockRecorder
+}
+
+// MockTOTPSecretRepoMockRecorder is the mock recorder for MockTOTPSecretRepo
+type MockTOTPSecretRepoMockRecorder struct {
+	mock *MockTOTPSecretRepo
+}
+
+// NewMockTOTPSecretRepo creates a new mock instance
+func NewMockTOTPSecretRepo(ctrl *gomock.Controller) *MockTOTPSecretRepo {
+	mock := &MockTOTPSecretRepo{ctrl: ctrl}
+	mock.recorder = &MockTOTPSecretRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockTOTPSecretRepo) EXPECT() *MockTOTPSecretRepoMockRecorder {
+	return m.recorder
+}
+
+// Save mocks base method
+func (m *MockTOTPSecretRepo) Save(arg0 context.Context, arg1 model.TOTPSecret) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save
+func (mr *MockTOTPSecretRepoMockRecorder) Save(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockTOTPSecretRepo)(nil).Save), arg0, arg1)
+}
+
+// GetByUserID mocks base method
+func (m *MockTOTPSecretRepo) GetByUserID(arg0 context.Context, arg1 int) (model.TOTPSecret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByUserID", arg0, arg1)
+	ret0, _ := ret[0].(model.TOTPSecret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByUserID indicates an expected call of GetByUserID
+func (mr *MockTOTPSecretRepoMockRecorder) GetByUserID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUserID", reflect.TypeOf((*MockTOTPSecretRepo)(nil).GetByUserID), arg0, arg1)
+}
+
+// Confirm mocks base method
+func (m *MockTOTPSecretRepo) Confirm(arg0 context.Context, arg1 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Confirm", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Confirm indicates an expected call of Confirm
+func (mr *MockTOTPSecretRepoMockRecorder) Confirm(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Confirm", reflect.TypeOf((*MockTOTPSecretRepo)(nil).Confirm), arg0, arg1)
+}
+
+// MarkUsed mocks base method
+func (m *MockTOTPSecretRepo) MarkUsed(arg0 context.Context, arg1 int, arg2 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkUsed", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkUsed indicates an expected call of MarkUsed
+func (mr *MockTOTPSecretRepoMockRecorder) MarkUsed(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkUsed", reflect.TypeOf((*MockTOTPSecretRepo)(nil).MarkUsed), arg0, arg1, arg2)
+}
+
+// DeleteByUserID mocks base method
+func (m *MockTOTPSecretRepo) DeleteByUserID(arg0 context.Context, arg1 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByUserID", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByUserID indicates an expected call of DeleteByUserID
+func (mr *MockTOTPSecretRepoMockRecorder) DeleteByUserID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByUserID", reflect.TypeOf((*MockTOTPSecretRepo)(nil).DeleteByUserID), arg0, arg1)
+}
+
+// MockRecoveryCodeRepo is a mock of RecoveryCodeRepo interface
+type MockRecoveryCodeRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockRecoveryCodeRepoMockRecorder
+}
+
+// MockRecoveryCodeRepoMockRecorder is the mock recorder for MockRecoveryCodeRepo
+type MockRecoveryCodeRepoMockRecorder struct {
+	mock *MockRecoveryCodeRepo
+}
+
+// NewMockRecoveryCodeRepo creates a new mock instance
+func NewMockRecoveryCodeRepo(ctrl *gomock.Controller) *MockRecoveryCodeRepo {
+	mock := &MockRecoveryCodeRepo{ctrl: ctrl}
+	mock.recorder = &MockRecoveryCodeRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRecoveryCodeRepo) EXPECT() *MockRecoveryCodeRepoMockRecorder {
+	return m.recorder
+}
+
+// Replace mocks base method
+func (m *MockRecoveryCodeRepo) Replace(arg0 context.Context, arg1 int, arg2 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Replace", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Replace indicates an expected call of Replace
+func (mr *MockRecoveryCodeRepoMockRecorder) Replace(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Replace", reflect.TypeOf((*MockRecoveryCodeRepo)(nil).Replace), arg0, arg1, arg2)
+}
+
+// Use mocks base method
+func (m *MockRecoveryCodeRepo) Use(arg0 context.Context, arg1 int, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Use", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Use indicates an expected call of Use
+func (mr *MockRecoveryCodeRepoMockRecorder) Use(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Use", reflect.TypeOf((*MockRecoveryCodeRepo)(nil).Use), arg0, arg1, arg2)
+}
+
+// CountUnused mocks base method
+func (m *MockRecoveryCodeRepo) CountUnused(arg0 context.Context, arg1 int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUnused", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUnused indicates an expected call of CountUnused
+func (mr *MockRecoveryCodeRepoMockRecorder) CountUnused(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUnused", reflect.TypeOf((*MockRecoveryCodeRepo)(nil).CountUnused), arg0, arg1)
+}
+
+// DeleteByUserID mocks base method
+func (m *MockRecoveryCodeRepo) DeleteByUserID(arg0 context.Context, arg1 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByUserID", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByUserID indicates an expected call of DeleteByUserID
+func (mr *MockRecoveryCodeRepoMockRecorder) DeleteByUserID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByUserID", reflect.TypeOf((*MockRecoveryCodeRepo)(nil).DeleteByUserID), arg0, arg1)
+}
+
+// MockWebAuthnCredentialRepo is a mock of WebAuthnCredentialRepo interface
+type MockWebAuthnCredentialRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebAuthnCredentialRepoMockRecorder
+}
+
+// MockWebAuthnCredentialRepoMockRecorder is the mock recorder for MockWebAuthnCredentialRepo
+type MockWebAuthnCredentialRepoMockRecorder struct {
+	mock *MockWebAuthnCredentialRepo
+}
+
+// NewMockWebAuthnCredentialRepo creates a new mock instance
+func NewMockWebAuthnCredentialRepo(ctrl *gomock.Controller) *MockWebAuthnCredentialRepo {
+	mock := &MockWebAuthnCredentialRepo{ctrl: ctrl}
+	mock.recorder = &MockWebAuthnCredentialRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockWebAuthnCredentialRepo) EXPECT() *MockWebAuthnCredentialRepoMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockWebAuthnCredentialRepo) Create(arg0 context.Context, arg1 model.WebAuthnCredential) (model.WebAuthnCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(model.WebAuthnCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockWebAuthnCredentialRepoMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockWebAuthnCredentialRepo)(nil).Create), arg0, arg1)
+}
+
+// GetByID mocks base method
+func (m *MockWebAuthnCredentialRepo) GetByID(arg0 context.Context, arg1 string) (model.WebAuthnCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", arg0, arg1)
+	ret0, _ := ret[0].(model.WebAuthnCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID
+func (mr *MockWebAuthnCredentialRepoMockRecorder) GetByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockWebAuthnCredentialRepo)(nil).GetByID), arg0, arg1)
+}
+
+// GetByUserID mocks base method
+func (m *MockWebAuthnCredentialRepo) GetByUserID(arg0 context.Context, arg1 int) ([]model.WebAuthnCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByUserID", arg0, arg1)
+	ret0, _ := ret[0].([]model.WebAuthnCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByUserID indicates an expected call of GetByUserID
+func (mr *MockWebAuthnCredentialRepoMockRecorder) GetByUserID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUserID", reflect.TypeOf((*MockWebAuthnCredentialRepo)(nil).GetByUserID), arg0, arg1)
+}
+
+// MarkUsed mocks base method
+func (m *MockWebAuthnCredentialRepo) MarkUsed(arg0 context.Context, arg1 string, arg2 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkUsed", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkUsed indicates an expected call of MarkUsed
+func (mr *MockWebAuthnCredentialRepoMockRecorder) MarkUsed(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkUsed", reflect.TypeOf((*MockWebAuthnCredentialRepo)(nil).MarkUsed), arg0, arg1, arg2)
+}
+
+// Delete mocks base method
+func (m *MockWebAuthnCredentialRepo) Delete(arg0 context.Context, arg1 int, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockWebAuthnCredentialRepoMockRecorder) Delete(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByID", reflect.TypeOf((*MockUserRepo)(nil).DeleteByID), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockWebAuthnCredentialRepo)(nil).Delete), arg0, arg1, arg2)
 }
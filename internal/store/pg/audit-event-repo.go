@@ -0,0 +1,40 @@
+package pg
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+)
+
+// auditEventRepo is the audit event repository for PostgreSQL store.
+type auditEventRepo struct {
+	db *sqlx.DB
+}
+
+// newAuditEventRepo creates and returns a new auditEventRepo instance.
+func newAuditEventRepo(db *sqlx.DB) *auditEventRepo { return &auditEventRepo{db: db} }
+
+// Create creates and returns a new audit event.
+func (r *auditEventRepo) Create(ctx context.Context, e model.AuditEvent) (model.AuditEvent, error) {
+	query := "INSERT INTO audit_events (actor_id, action, user_id) VALUES ($1, $2, $3) "
+	query += "RETURNING id, created_at;"
+	row := r.db.QueryRowContext(ctx, query, e.ActorID, e.Action, e.UserID)
+	if err := row.Scan(&e.ID, &e.CreatedAt); err != nil {
+		return model.AuditEvent{}, wrapError("create audit event", err)
+	}
+
+	return e, nil
+}
+
+// GetByUserID returns audit events of the user with specific ID in order of creation.
+func (r *auditEventRepo) GetByUserID(ctx context.Context, userID int) ([]model.AuditEvent, error) {
+	events := []model.AuditEvent{}
+	query := "SELECT * FROM audit_events WHERE user_id = $1 ORDER BY id;"
+	if err := r.db.SelectContext(ctx, &events, query, userID); err != nil {
+		return []model.AuditEvent{}, wrapError("get audit events", err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,112 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+)
+
+func TestAuditEventRepo_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newAuditEventRepo(sqlx.NewDb(db, "postgres"))
+	createdAt := time.Now()
+
+	testcases := []struct {
+		name     string
+		mock     func(model.AuditEvent)
+		event    model.AuditEvent
+		expEvent model.AuditEvent
+		expError error
+	}{
+		{
+			name: "event is created",
+			mock: func(e model.AuditEvent) {
+				rows := sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, createdAt)
+				mock.ExpectQuery("INSERT INTO audit_events (.+) VALUES (.+) RETURNING (.+);").WithArgs(
+					e.ActorID, e.Action, e.UserID,
+				).WillReturnRows(rows)
+			},
+			event: model.AuditEvent{ActorID: 1, Action: model.AuditUserCreate, UserID: 2},
+			expEvent: model.AuditEvent{
+				ID: 1, ActorID: 1, Action: model.AuditUserCreate, UserID: 2, CreatedAt: createdAt,
+			},
+		},
+		{
+			name: "driver error is returned",
+			mock: func(e model.AuditEvent) {
+				mock.ExpectQuery("INSERT INTO audit_events (.+) VALUES (.+) RETURNING (.+);").WithArgs(
+					e.ActorID, e.Action, e.UserID,
+				).WillReturnError(errQueryCanceled)
+			},
+			event:    model.AuditEvent{ActorID: 1, Action: model.AuditUserCreate, UserID: 2},
+			expError: errQueryCanceled,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock(tc.event)
+
+		e, err := r.Create(context.Background(), tc.event)
+
+		if tc.expError == nil {
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expEvent, e)
+		} else {
+			assert.True(t, errors.Is(err, tc.expError), err)
+		}
+	}
+}
+
+func TestAuditEventRepo_GetByUserID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newAuditEventRepo(sqlx.NewDb(db, "postgres"))
+
+	testcases := []struct {
+		name      string
+		mock      func(int, []model.AuditEvent)
+		userID    int
+		expEvents []model.AuditEvent
+	}{
+		{
+			name: "events are retrieved",
+			mock: func(userID int, es []model.AuditEvent) {
+				rows := sqlmock.NewRows([]string{"id", "actor_id", "action", "user_id"})
+				for _, e := range es {
+					rows = rows.AddRow(e.ID, e.ActorID, e.Action, e.UserID)
+				}
+				mock.ExpectQuery(
+					"SELECT (.+) FROM audit_events WHERE user_id = (.+) ORDER BY id;",
+				).WithArgs(userID).WillReturnRows(rows)
+			},
+			userID: 2,
+			expEvents: []model.AuditEvent{
+				{ID: 1, ActorID: 1, Action: model.AuditUserCreate, UserID: 2},
+				{ID: 3, ActorID: 1, Action: model.AuditUserDisable, UserID: 2},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock(tc.userID, tc.expEvents)
+
+		events, err := r.GetByUserID(context.Background(), tc.userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expEvents, events)
+	}
+}
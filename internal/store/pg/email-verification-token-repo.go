@@ -0,0 +1,80 @@
+package pg
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// emailVerificationTokenRepo is the email verification token repository for PostgreSQL store.
+type emailVerificationTokenRepo struct {
+	db *sqlx.DB
+}
+
+// newEmailVerificationTokenRepo creates and returns a new emailVerificationTokenRepo instance.
+func newEmailVerificationTokenRepo(db *sqlx.DB) *emailVerificationTokenRepo {
+	return &emailVerificationTokenRepo{db: db}
+}
+
+// Create creates and returns a new email verification token.
+func (r *emailVerificationTokenRepo) Create(
+	ctx context.Context, t model.EmailVerificationToken,
+) (model.EmailVerificationToken, error) {
+	query := "INSERT INTO email_verification_tokens (hash, user_id, email, expires_at) "
+	query += "VALUES ($1, $2, $3, $4);"
+	if _, err := r.db.ExecContext(ctx, query, t.Hash, t.UserID, t.Email, t.ExpiresAt); err != nil {
+		return model.EmailVerificationToken{}, err
+	}
+
+	return t, nil
+}
+
+// GetByHash returns the email verification token with specific hash.
+func (r *emailVerificationTokenRepo) GetByHash(
+	ctx context.Context, hash string,
+) (model.EmailVerificationToken, error) {
+	t := model.EmailVerificationToken{}
+	query := "SELECT * FROM email_verification_tokens WHERE hash = $1;"
+	if err := r.db.GetContext(ctx, &t, query, hash); err != nil {
+		return model.EmailVerificationToken{}, wrapError("get email verification token", err)
+	}
+
+	return t, nil
+}
+
+// MarkUsed marks the email verification token with specific hash as used. It returns
+// store.ErrTokenIsUsed if the token has already been used before.
+func (r *emailVerificationTokenRepo) MarkUsed(ctx context.Context, hash string) error {
+	query := "UPDATE email_verification_tokens SET used_at = NOW() "
+	query += "WHERE hash = $1 AND used_at IS NULL;"
+	res, err := r.db.ExecContext(ctx, query, hash)
+	if err != nil {
+		return err
+	}
+
+	rowsCount, err := res.RowsAffected()
+	if err != nil {
+		return err
+	} else if rowsCount == 0 {
+		var exists bool
+		query := "SELECT EXISTS(SELECT 1 FROM email_verification_tokens WHERE hash = $1);"
+		if err := r.db.GetContext(ctx, &exists, query, hash); err != nil {
+			return err
+		} else if !exists {
+			return store.ErrNotFound
+		}
+		return store.ErrTokenIsUsed
+	}
+
+	return nil
+}
+
+// DeleteExpired deletes all email verification tokens which have already expired.
+func (r *emailVerificationTokenRepo) DeleteExpired(ctx context.Context) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM email_verification_tokens WHERE expires_at < NOW();")
+
+	return err
+}
@@ -0,0 +1,37 @@
+package pg
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// wrapError maps sql.ErrNoRows onto store.ErrNotFound, other driver errors are wrapped
+// with the operation, so they can still be inspected with errors.As.
+func wrapError(op string, err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return store.ErrNotFound
+	}
+
+	return fmt.Errorf("%s: %w", op, err)
+}
+
+// userError maps unique constraint errors of users table onto store errors, other
+// errors are wrapped with wrapError.
+func userError(op string, err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Constraint {
+		case "users_username_key":
+			return store.ErrUsernameIsTaken
+		case "users_email_key":
+			return store.ErrEmailIsTaken
+		}
+	}
+
+	return wrapError(op, err)
+}
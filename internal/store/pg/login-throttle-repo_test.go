@@ -0,0 +1,232 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+)
+
+func TestLoginThrottleRepo_Get(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newLoginThrottleRepo(sqlx.NewDb(db, "postgres"))
+
+	testcases := []struct {
+		name        string
+		mock        func(string)
+		key         string
+		expThrottle model.LoginThrottle
+		expError    bool
+	}{
+		{
+			name: "login throttle is retrieved by key",
+			mock: func(key string) {
+				rows := sqlmock.NewRows([]string{"key", "failures"}).AddRow(key, 3)
+				mock.ExpectQuery(
+					"SELECT (.+) FROM login_throttles WHERE key = (.+);",
+				).WithArgs(key).WillReturnRows(rows)
+			},
+			key:         "user:1",
+			expThrottle: model.LoginThrottle{Key: "user:1", Failures: 3},
+			expError:    false,
+		},
+		{
+			name: "login throttle without failures is returned",
+			mock: func(key string) {
+				mock.ExpectQuery(
+					"SELECT (.+) FROM login_throttles WHERE key = (.+);",
+				).WithArgs(key).WillReturnError(sql.ErrNoRows)
+			},
+			key:         "user:2",
+			expThrottle: model.LoginThrottle{Key: "user:2"},
+			expError:    false,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock(tc.key)
+
+		throttle, err := r.Get(context.Background(), tc.key)
+
+		if !tc.expError {
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expThrottle, throttle)
+		} else {
+			assert.Error(t, err)
+		}
+	}
+}
+
+func TestLoginThrottleRepo_RegisterFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newLoginThrottleRepo(sqlx.NewDb(db, "postgres"))
+
+	testcases := []struct {
+		name        string
+		mock        func(string, time.Time)
+		key         string
+		since       time.Time
+		expThrottle model.LoginThrottle
+		expError    bool
+	}{
+		{
+			name: "failure is registered",
+			mock: func(key string, since time.Time) {
+				rows := sqlmock.NewRows([]string{"key", "failures"}).AddRow(key, 2)
+				mock.ExpectQuery(
+					"INSERT INTO login_throttles (.+) ON CONFLICT (.+) RETURNING (.+);",
+				).WithArgs(key, since).WillReturnRows(rows)
+			},
+			key:         "user:1",
+			since:       time.Now(),
+			expThrottle: model.LoginThrottle{Key: "user:1", Failures: 2},
+			expError:    false,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock(tc.key, tc.since)
+
+		throttle, err := r.RegisterFailure(context.Background(), tc.key, tc.since)
+
+		if !tc.expError {
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expThrottle, throttle)
+		} else {
+			assert.Error(t, err)
+		}
+	}
+}
+
+func TestLoginThrottleRepo_Lock(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newLoginThrottleRepo(sqlx.NewDb(db, "postgres"))
+
+	testcases := []struct {
+		name     string
+		mock     func(string, time.Time)
+		key      string
+		until    time.Time
+		expError bool
+	}{
+		{
+			name: "login throttle is locked",
+			mock: func(key string, until time.Time) {
+				mock.ExpectExec(
+					"UPDATE login_throttles SET (.+) WHERE key = (.+);",
+				).WithArgs(key, until).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			key:      "user:1",
+			until:    time.Now(),
+			expError: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock(tc.key, tc.until)
+
+		err := r.Lock(context.Background(), tc.key, tc.until)
+
+		if !tc.expError {
+			assert.NoError(t, err)
+		} else {
+			assert.Error(t, err)
+		}
+	}
+}
+
+func TestLoginThrottleRepo_Delete(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newLoginThrottleRepo(sqlx.NewDb(db, "postgres"))
+
+	testcases := []struct {
+		name     string
+		mock     func(string)
+		key      string
+		expError bool
+	}{
+		{
+			name: "login throttle is deleted",
+			mock: func(key string) {
+				mock.ExpectExec(
+					"DELETE FROM login_throttles WHERE key = (.+);",
+				).WithArgs(key).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			key:      "user:1",
+			expError: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock(tc.key)
+
+		err := r.Delete(context.Background(), tc.key)
+
+		if !tc.expError {
+			assert.NoError(t, err)
+		} else {
+			assert.Error(t, err)
+		}
+	}
+}
+
+func TestLoginThrottleRepo_DeleteStale(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newLoginThrottleRepo(sqlx.NewDb(db, "postgres"))
+
+	testcases := []struct {
+		name     string
+		mock     func(time.Time)
+		before   time.Time
+		expError bool
+	}{
+		{
+			name: "stale login throttles are deleted",
+			mock: func(before time.Time) {
+				mock.ExpectExec(
+					"DELETE FROM login_throttles WHERE last_failure_at < (.+);",
+				).WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 2))
+			},
+			before:   time.Now(),
+			expError: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock(tc.before)
+
+		err := r.DeleteStale(context.Background(), tc.before)
+
+		if !tc.expError {
+			assert.NoError(t, err)
+		} else {
+			assert.Error(t, err)
+		}
+	}
+}
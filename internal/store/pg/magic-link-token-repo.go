@@ -0,0 +1,86 @@
+package pg
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// magicLinkTokenRepo is the magic link token repository for PostgreSQL store.
+type magicLinkTokenRepo struct {
+	db *sqlx.DB
+}
+
+// newMagicLinkTokenRepo creates and returns a new magicLinkTokenRepo instance.
+func newMagicLinkTokenRepo(db *sqlx.DB) *magicLinkTokenRepo {
+	return &magicLinkTokenRepo{db: db}
+}
+
+// Create creates and returns a new magic link token.
+func (r *magicLinkTokenRepo) Create(
+	ctx context.Context, t model.MagicLinkToken,
+) (model.MagicLinkToken, error) {
+	query := "INSERT INTO magic_link_tokens (hash, user_id, device_hash, expires_at) "
+	query += "VALUES ($1, $2, $3, $4);"
+	_, err := r.db.ExecContext(ctx, query, t.Hash, t.UserID, t.DeviceHash, t.ExpiresAt)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+		return model.MagicLinkToken{}, store.ErrNotFound
+	} else if err != nil {
+		return model.MagicLinkToken{}, wrapError("create magic link token", err)
+	}
+
+	return t, nil
+}
+
+// GetByHash returns the magic link token with specific hash.
+func (r *magicLinkTokenRepo) GetByHash(
+	ctx context.Context, hash string,
+) (model.MagicLinkToken, error) {
+	t := model.MagicLinkToken{}
+	query := "SELECT * FROM magic_link_tokens WHERE hash = $1;"
+	if err := r.db.GetContext(ctx, &t, query, hash); err != nil {
+		return model.MagicLinkToken{}, wrapError("get magic link token", err)
+	}
+
+	return t, nil
+}
+
+// MarkUsed marks the magic link token with specific hash as used. It returns
+// store.ErrTokenIsUsed if the token has already been used before.
+func (r *magicLinkTokenRepo) MarkUsed(ctx context.Context, hash string) error {
+	query := "UPDATE magic_link_tokens SET used_at = NOW() WHERE hash = $1 AND used_at IS NULL;"
+	res, err := r.db.ExecContext(ctx, query, hash)
+	if err != nil {
+		return wrapError("mark magic link token used", err)
+	}
+
+	rowsCount, err := res.RowsAffected()
+	if err != nil {
+		return wrapError("mark magic link token used", err)
+	} else if rowsCount == 0 {
+		var exists bool
+		query := "SELECT EXISTS(SELECT 1 FROM magic_link_tokens WHERE hash = $1);"
+		if err := r.db.GetContext(ctx, &exists, query, hash); err != nil {
+			return wrapError("mark magic link token used", err)
+		} else if !exists {
+			return store.ErrNotFound
+		}
+		return store.ErrTokenIsUsed
+	}
+
+	return nil
+}
+
+// DeleteExpired deletes all magic link tokens which have already expired.
+func (r *magicLinkTokenRepo) DeleteExpired(ctx context.Context) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM magic_link_tokens WHERE expires_at < NOW();")
+	if err != nil {
+		return wrapError("delete expired magic link tokens", err)
+	}
+
+	return nil
+}
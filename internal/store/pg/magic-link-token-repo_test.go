@@ -0,0 +1,134 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+func TestMagicLinkTokenRepo_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newMagicLinkTokenRepo(sqlx.NewDb(db, "postgres"))
+	token := model.MagicLinkToken{
+		Hash: "hash1", UserID: 1, DeviceHash: "device1", ExpiresAt: time.Now(),
+	}
+
+	testcases := []struct {
+		name     string
+		mock     func(model.MagicLinkToken)
+		expError error
+	}{
+		{
+			name: "magic link token is created",
+			mock: func(t model.MagicLinkToken) {
+				mock.ExpectExec("INSERT INTO magic_link_tokens (.+) VALUES (.+);").WithArgs(
+					t.Hash, t.UserID, t.DeviceHash, t.ExpiresAt,
+				).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "user is not found",
+			mock: func(t model.MagicLinkToken) {
+				mock.ExpectExec("INSERT INTO magic_link_tokens (.+) VALUES (.+);").WithArgs(
+					t.Hash, t.UserID, t.DeviceHash, t.ExpiresAt,
+				).WillReturnError(&pq.Error{Code: "23503"})
+			},
+			expError: store.ErrNotFound,
+		},
+		{
+			name: "driver error is returned",
+			mock: func(t model.MagicLinkToken) {
+				mock.ExpectExec("INSERT INTO magic_link_tokens (.+) VALUES (.+);").WithArgs(
+					t.Hash, t.UserID, t.DeviceHash, t.ExpiresAt,
+				).WillReturnError(errQueryCanceled)
+			},
+			expError: errQueryCanceled,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock(token)
+
+		got, err := r.Create(context.Background(), token)
+
+		if tc.expError == nil {
+			assert.NoError(t, err)
+			assert.Equal(t, token, got)
+		} else {
+			assert.True(t, errors.Is(err, tc.expError), err)
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestMagicLinkTokenRepo_MarkUsed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newMagicLinkTokenRepo(sqlx.NewDb(db, "postgres"))
+
+	testcases := []struct {
+		name     string
+		mock     func(string)
+		hash     string
+		expError error
+	}{
+		{
+			name: "magic link token is marked used",
+			mock: func(hash string) {
+				mock.ExpectExec("UPDATE magic_link_tokens SET used_at = (.+) WHERE (.+);").WithArgs(
+					hash,
+				).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			hash: "hash1",
+		},
+		{
+			name: "magic link token has already been used",
+			mock: func(hash string) {
+				mock.ExpectExec("UPDATE magic_link_tokens SET used_at = (.+) WHERE (.+);").WithArgs(
+					hash,
+				).WillReturnResult(sqlmock.NewResult(0, 0))
+				rows := sqlmock.NewRows([]string{"exists"}).AddRow(true)
+				mock.ExpectQuery("SELECT EXISTS(.+);").WithArgs(hash).WillReturnRows(rows)
+			},
+			hash:     "hash1",
+			expError: store.ErrTokenIsUsed,
+		},
+		{
+			name: "magic link token is not found",
+			mock: func(hash string) {
+				mock.ExpectExec("UPDATE magic_link_tokens SET used_at = (.+) WHERE (.+);").WithArgs(
+					hash,
+				).WillReturnResult(sqlmock.NewResult(0, 0))
+				rows := sqlmock.NewRows([]string{"exists"}).AddRow(false)
+				mock.ExpectQuery("SELECT EXISTS(.+);").WithArgs(hash).WillReturnRows(rows)
+			},
+			hash:     "hash2",
+			expError: store.ErrNotFound,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock(tc.hash)
+
+		err := r.MarkUsed(context.Background(), tc.hash)
+
+		assert.Equal(t, tc.expError, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
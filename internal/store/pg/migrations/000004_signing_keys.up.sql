@@ -0,0 +1,11 @@
+CREATE TABLE signing_keys (
+    id VARCHAR(128) PRIMARY KEY,
+    algorithm VARCHAR(16) NOT NULL,
+    material TEXT NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    activated_at TIMESTAMPTZ,
+    retired_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX signing_keys_active_idx ON signing_keys (status) WHERE status = 'active';
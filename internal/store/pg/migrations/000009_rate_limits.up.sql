@@ -0,0 +1,5 @@
+CREATE TABLE rate_limits (
+    key VARCHAR(256) PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,3 @@
+DROP INDEX users_created_at_idx;
+
+ALTER TABLE users DROP COLUMN created_at;
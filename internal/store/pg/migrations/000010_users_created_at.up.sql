@@ -0,0 +1,3 @@
+ALTER TABLE users ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+CREATE INDEX users_created_at_idx ON users (created_at, id);
@@ -0,0 +1,3 @@
+DROP TABLE audit_events;
+
+ALTER TABLE users DROP COLUMN disabled_at;
@@ -0,0 +1,11 @@
+ALTER TABLE users ADD COLUMN disabled_at TIMESTAMPTZ;
+
+CREATE TABLE audit_events (
+    id SERIAL PRIMARY KEY,
+    actor_id INTEGER NOT NULL,
+    action VARCHAR(64) NOT NULL,
+    user_id INTEGER NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX audit_events_user_id_idx ON audit_events (user_id, id);
@@ -0,0 +1,96 @@
+package pg
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// oneTimeCodeRepo is the one-time code repository for PostgreSQL store.
+type oneTimeCodeRepo struct {
+	db *sqlx.DB
+}
+
+// newOneTimeCodeRepo creates and returns a new oneTimeCodeRepo instance.
+func newOneTimeCodeRepo(db *sqlx.DB) *oneTimeCodeRepo { return &oneTimeCodeRepo{db: db} }
+
+// Save saves and returns the one-time code, the code of the user with the same purpose
+// is replaced and its attempts are reset.
+func (r *oneTimeCodeRepo) Save(
+	ctx context.Context, c model.OneTimeCode,
+) (model.OneTimeCode, error) {
+	query := "INSERT INTO one_time_codes (user_id, purpose, hash, expires_at) "
+	query += "VALUES ($1, $2, $3, $4) ON CONFLICT (user_id, purpose) DO UPDATE SET "
+	query += "hash = EXCLUDED.hash, attempts = 0, expires_at = EXCLUDED.expires_at, "
+	query += "created_at = NOW() RETURNING *;"
+	saved := model.OneTimeCode{}
+	err := r.db.GetContext(ctx, &saved, query, c.UserID, c.Purpose, c.Hash, c.ExpiresAt)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+		return model.OneTimeCode{}, store.ErrNotFound
+	} else if err != nil {
+		return model.OneTimeCode{}, wrapError("save one-time code", err)
+	}
+
+	return saved, nil
+}
+
+// Get returns the one-time code of the user with specific purpose.
+func (r *oneTimeCodeRepo) Get(
+	ctx context.Context, userID int, purpose string,
+) (model.OneTimeCode, error) {
+	c := model.OneTimeCode{}
+	query := "SELECT * FROM one_time_codes WHERE user_id = $1 AND purpose = $2;"
+	if err := r.db.GetContext(ctx, &c, query, userID, purpose); err != nil {
+		return model.OneTimeCode{}, wrapError("get one-time code", err)
+	}
+
+	return c, nil
+}
+
+// Attempt registers an attempt to use the one-time code of the user with specific
+// purpose and returns the code. It returns store.ErrNotFound if there is no code or
+// max attempts have already been made.
+func (r *oneTimeCodeRepo) Attempt(
+	ctx context.Context, userID int, purpose string, maxAttempts int,
+) (model.OneTimeCode, error) {
+	c := model.OneTimeCode{}
+	query := "UPDATE one_time_codes SET attempts = attempts + 1 "
+	query += "WHERE user_id = $1 AND purpose = $2 AND attempts < $3 RETURNING *;"
+	if err := r.db.GetContext(ctx, &c, query, userID, purpose, maxAttempts); err != nil {
+		return model.OneTimeCode{}, wrapError("attempt one-time code", err)
+	}
+
+	return c, nil
+}
+
+// Delete deletes the one-time code of the user with specific purpose.
+func (r *oneTimeCodeRepo) Delete(ctx context.Context, userID int, purpose string) error {
+	query := "DELETE FROM one_time_codes WHERE user_id = $1 AND purpose = $2;"
+	res, err := r.db.ExecContext(ctx, query, userID, purpose)
+	if err != nil {
+		return wrapError("delete one-time code", err)
+	}
+
+	rowsCount, err := res.RowsAffected()
+	if err != nil {
+		return wrapError("delete one-time code", err)
+	} else if rowsCount == 0 {
+		return store.ErrNotFound
+	}
+
+	return nil
+}
+
+// DeleteExpired deletes all one-time codes which have already expired.
+func (r *oneTimeCodeRepo) DeleteExpired(ctx context.Context) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM one_time_codes WHERE expires_at < NOW();")
+	if err != nil {
+		return wrapError("delete expired one-time codes", err)
+	}
+
+	return nil
+}
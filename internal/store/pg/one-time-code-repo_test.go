@@ -0,0 +1,129 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+func TestOneTimeCodeRepo_Save(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newOneTimeCodeRepo(sqlx.NewDb(db, "postgres"))
+	code := model.OneTimeCode{UserID: 1, Purpose: "login", Hash: "hash1", ExpiresAt: time.Now()}
+	columns := []string{"user_id", "purpose", "hash", "attempts", "expires_at", "created_at"}
+
+	testcases := []struct {
+		name     string
+		mock     func(model.OneTimeCode)
+		expError error
+	}{
+		{
+			name: "one-time code is saved",
+			mock: func(c model.OneTimeCode) {
+				rows := sqlmock.NewRows(columns).AddRow(
+					c.UserID, c.Purpose, c.Hash, 0, c.ExpiresAt, time.Now(),
+				)
+				mock.ExpectQuery("INSERT INTO one_time_codes (.+) ON CONFLICT (.+);").WithArgs(
+					c.UserID, c.Purpose, c.Hash, c.ExpiresAt,
+				).WillReturnRows(rows)
+			},
+		},
+		{
+			name: "user is not found",
+			mock: func(c model.OneTimeCode) {
+				mock.ExpectQuery("INSERT INTO one_time_codes (.+) ON CONFLICT (.+);").WithArgs(
+					c.UserID, c.Purpose, c.Hash, c.ExpiresAt,
+				).WillReturnError(&pq.Error{Code: "23503"})
+			},
+			expError: store.ErrNotFound,
+		},
+		{
+			name: "driver error is returned",
+			mock: func(c model.OneTimeCode) {
+				mock.ExpectQuery("INSERT INTO one_time_codes (.+) ON CONFLICT (.+);").WithArgs(
+					c.UserID, c.Purpose, c.Hash, c.ExpiresAt,
+				).WillReturnError(errQueryCanceled)
+			},
+			expError: errQueryCanceled,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock(code)
+
+		got, err := r.Save(context.Background(), code)
+
+		if tc.expError == nil {
+			assert.NoError(t, err)
+			assert.Equal(t, code.Hash, got.Hash)
+			assert.False(t, got.CreatedAt.IsZero())
+		} else {
+			assert.True(t, errors.Is(err, tc.expError), err)
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestOneTimeCodeRepo_Attempt(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newOneTimeCodeRepo(sqlx.NewDb(db, "postgres"))
+	columns := []string{"user_id", "purpose", "hash", "attempts", "expires_at", "created_at"}
+
+	testcases := []struct {
+		name        string
+		mock        func()
+		expAttempts int
+		expError    error
+	}{
+		{
+			name: "attempt is registered",
+			mock: func() {
+				rows := sqlmock.NewRows(columns).AddRow(1, "login", "hash1", 3, time.Now(), time.Now())
+				mock.ExpectQuery("UPDATE one_time_codes SET (.+) WHERE (.+);").WithArgs(
+					1, "login", 5,
+				).WillReturnRows(rows)
+			},
+			expAttempts: 3,
+		},
+		{
+			name: "code is out of attempts",
+			mock: func() {
+				mock.ExpectQuery("UPDATE one_time_codes SET (.+) WHERE (.+);").WithArgs(
+					1, "login", 5,
+				).WillReturnRows(sqlmock.NewRows(columns))
+			},
+			expError: store.ErrNotFound,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock()
+
+		got, err := r.Attempt(context.Background(), 1, "login", 5)
+
+		if tc.expError == nil {
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expAttempts, got.Attempts)
+		} else {
+			assert.Equal(t, tc.expError, err)
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
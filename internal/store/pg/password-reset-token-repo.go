@@ -0,0 +1,78 @@
+package pg
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// passwordResetTokenRepo is the password reset token repository for PostgreSQL store.
+type passwordResetTokenRepo struct {
+	db *sqlx.DB
+}
+
+// newPasswordResetTokenRepo creates and returns a new passwordResetTokenRepo instance.
+func newPasswordResetTokenRepo(db *sqlx.DB) *passwordResetTokenRepo {
+	return &passwordResetTokenRepo{db: db}
+}
+
+// Create creates and returns a new password reset token.
+func (r *passwordResetTokenRepo) Create(
+	ctx context.Context, t model.PasswordResetToken,
+) (model.PasswordResetToken, error) {
+	query := "INSERT INTO password_reset_tokens (hash, user_id, expires_at) VALUES ($1, $2, $3);"
+	if _, err := r.db.ExecContext(ctx, query, t.Hash, t.UserID, t.ExpiresAt); err != nil {
+		return model.PasswordResetToken{}, err
+	}
+
+	return t, nil
+}
+
+// GetByHash returns the password reset token with specific hash.
+func (r *passwordResetTokenRepo) GetByHash(
+	ctx context.Context, hash string,
+) (model.PasswordResetToken, error) {
+	t := model.PasswordResetToken{}
+	query := "SELECT * FROM password_reset_tokens WHERE hash = $1;"
+	if err := r.db.GetContext(ctx, &t, query, hash); err != nil {
+		return model.PasswordResetToken{}, wrapError("get password reset token", err)
+	}
+
+	return t, nil
+}
+
+// MarkUsed marks the password reset token with specific hash as used. It returns
+// store.ErrTokenIsUsed if the token has already been used before.
+func (r *passwordResetTokenRepo) MarkUsed(ctx context.Context, hash string) error {
+	query := "UPDATE password_reset_tokens SET used_at = NOW() WHERE hash = $1 AND used_at IS NULL;"
+	res, err := r.db.ExecContext(ctx, query, hash)
+	if err != nil {
+		return err
+	}
+
+	rowsCount, err := res.RowsAffected()
+	if err != nil {
+		return err
+	} else if rowsCount == 0 {
+		var exists bool
+		query := "SELECT EXISTS(SELECT 1 FROM password_reset_tokens WHERE hash = $1);"
+		if err := r.db.GetContext(ctx, &exists, query, hash); err != nil {
+			return err
+		} else if !exists {
+			return store.ErrNotFound
+		}
+		return store.ErrTokenIsUsed
+	}
+
+	return nil
+}
+
+// DeleteExpired deletes all password reset tokens which have already expired.
+func (r *passwordResetTokenRepo) DeleteExpired(ctx context.Context) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM password_reset_tokens WHERE expires_at < NOW();")
+
+	return err
+}
@@ -0,0 +1,179 @@
+package pg
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+func TestPasswordResetTokenRepo_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newPasswordResetTokenRepo(sqlx.NewDb(db, "postgres"))
+
+	testcases := []struct {
+		name     string
+		mock     func(model.PasswordResetToken)
+		token    model.PasswordResetToken
+		expError bool
+	}{
+		{
+			name: "password reset token is created",
+			mock: func(t model.PasswordResetToken) {
+				mock.ExpectExec("INSERT INTO password_reset_tokens (.+) VALUES (.+);").WithArgs(
+					t.Hash, t.UserID, t.ExpiresAt,
+				).WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			token:    model.PasswordResetToken{Hash: "hash1", UserID: 1, ExpiresAt: time.Now()},
+			expError: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock(tc.token)
+
+		token, err := r.Create(context.Background(), tc.token)
+
+		if !tc.expError {
+			assert.NoError(t, err)
+			assert.Equal(t, tc.token, token)
+		} else {
+			assert.Error(t, err)
+		}
+	}
+}
+
+func TestPasswordResetTokenRepo_GetByHash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newPasswordResetTokenRepo(sqlx.NewDb(db, "postgres"))
+
+	testcases := []struct {
+		name     string
+		mock     func(model.PasswordResetToken)
+		token    model.PasswordResetToken
+		expError bool
+	}{
+		{
+			name: "password reset token is retrieved by hash",
+			mock: func(t model.PasswordResetToken) {
+				rows := sqlmock.NewRows([]string{"hash", "user_id"}).AddRow(t.Hash, t.UserID)
+				mock.ExpectQuery(
+					"SELECT (.+) FROM password_reset_tokens WHERE hash = (.+);",
+				).WithArgs(t.Hash).WillReturnRows(rows)
+			},
+			token:    model.PasswordResetToken{Hash: "hash1", UserID: 1},
+			expError: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock(tc.token)
+
+		token, err := r.GetByHash(context.Background(), tc.token.Hash)
+
+		if !tc.expError {
+			assert.NoError(t, err)
+			assert.Equal(t, tc.token, token)
+		} else {
+			assert.Error(t, err)
+		}
+	}
+}
+
+func TestPasswordResetTokenRepo_MarkUsed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newPasswordResetTokenRepo(sqlx.NewDb(db, "postgres"))
+
+	testcases := []struct {
+		name     string
+		mock     func(string)
+		hash     string
+		expError error
+	}{
+		{
+			name: "password reset token is marked as used",
+			mock: func(hash string) {
+				mock.ExpectExec(
+					"UPDATE password_reset_tokens SET used_at = (.+) WHERE hash = (.+);",
+				).WithArgs(hash).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			hash:     "hash1",
+			expError: nil,
+		},
+		{
+			name: "password reset token has already been used",
+			mock: func(hash string) {
+				mock.ExpectExec(
+					"UPDATE password_reset_tokens SET used_at = (.+) WHERE hash = (.+);",
+				).WithArgs(hash).WillReturnResult(sqlmock.NewResult(0, 0))
+				rows := sqlmock.NewRows([]string{"exists"}).AddRow(true)
+				mock.ExpectQuery("SELECT EXISTS(.+);").WithArgs(hash).WillReturnRows(rows)
+			},
+			hash:     "hash1",
+			expError: store.ErrTokenIsUsed,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock(tc.hash)
+
+		err := r.MarkUsed(context.Background(), tc.hash)
+
+		assert.Equal(t, tc.expError, err)
+	}
+}
+
+func TestPasswordResetTokenRepo_DeleteExpired(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newPasswordResetTokenRepo(sqlx.NewDb(db, "postgres"))
+
+	testcases := []struct {
+		name     string
+		mock     func()
+		expError bool
+	}{
+		{
+			name: "expired password reset tokens are deleted",
+			mock: func() {
+				mock.ExpectExec(
+					"DELETE FROM password_reset_tokens WHERE expires_at < (.+);",
+				).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			expError: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock()
+
+		err := r.DeleteExpired(context.Background())
+
+		if !tc.expError {
+			assert.NoError(t, err)
+		} else {
+			assert.Error(t, err)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// refilledTokens is SQL expression of tokens in the bucket refilled since it was
+// updated last time, $2 is the capacity and $3 is the refill rate per second.
+const refilledTokens = "LEAST($2::float8, rate_limits.tokens + " +
+	"EXTRACT(EPOCH FROM NOW() - rate_limits.updated_at) * $3::float8)"
+
+// rateLimitRepo is the rate limit token bucket repository for PostgreSQL store.
+type rateLimitRepo struct {
+	db *sqlx.DB
+}
+
+// newRateLimitRepo creates and returns a new rateLimitRepo instance.
+func newRateLimitRepo(db *sqlx.DB) *rateLimitRepo { return &rateLimitRepo{db: db} }
+
+// Take takes a token from the bucket with specific key which holds up to limit tokens
+// and is refilled with limit tokens per period. It returns tokens left in the bucket
+// and whether the token was taken. The bucket is left untouched if it's empty.
+func (r *rateLimitRepo) Take(
+	ctx context.Context, key string, limit int, period time.Duration,
+) (float64, bool, error) {
+	perSecond := float64(limit) / period.Seconds()
+
+	query := "INSERT INTO rate_limits (key, tokens, updated_at) VALUES ($1, $2::float8 - 1, NOW()) "
+	query += "ON CONFLICT (key) DO UPDATE SET tokens = " + refilledTokens + " - 1, "
+	query += "updated_at = NOW() WHERE " + refilledTokens + " >= 1 RETURNING tokens;"
+	var tokens float64
+	err := r.db.GetContext(ctx, &tokens, query, key, limit, perSecond)
+	if err == nil {
+		return tokens, true, nil
+	} else if err != sql.ErrNoRows {
+		return 0, false, err
+	}
+
+	query = "SELECT " + refilledTokens + " FROM rate_limits WHERE key = $1;"
+	if err := r.db.GetContext(ctx, &tokens, query, key, limit, perSecond); err != nil {
+		return 0, false, err
+	}
+
+	return tokens, false, nil
+}
+
+// DeleteStale deletes buckets which haven't been updated since specific time.
+func (r *rateLimitRepo) DeleteStale(ctx context.Context, before time.Time) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM rate_limits WHERE updated_at < $1;", before)
+
+	return err
+}
@@ -0,0 +1,113 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitRepo_Take(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newRateLimitRepo(sqlx.NewDb(db, "postgres"))
+
+	testcases := []struct {
+		name       string
+		mock       func(string)
+		key        string
+		expTokens  float64
+		expAllowed bool
+		expError   bool
+	}{
+		{
+			name: "token is taken",
+			mock: func(key string) {
+				rows := sqlmock.NewRows([]string{"tokens"}).AddRow(4.5)
+				mock.ExpectQuery(
+					"INSERT INTO rate_limits (.+) ON CONFLICT (.+) RETURNING tokens;",
+				).WithArgs(key, 10, 10.0/60).WillReturnRows(rows)
+			},
+			key:        "auth:ip:127.0.0.1",
+			expTokens:  4.5,
+			expAllowed: true,
+			expError:   false,
+		},
+		{
+			name: "bucket is empty",
+			mock: func(key string) {
+				mock.ExpectQuery(
+					"INSERT INTO rate_limits (.+) ON CONFLICT (.+) RETURNING tokens;",
+				).WithArgs(key, 10, 10.0/60).WillReturnError(sql.ErrNoRows)
+				rows := sqlmock.NewRows([]string{"tokens"}).AddRow(0.5)
+				mock.ExpectQuery(
+					"SELECT (.+) FROM rate_limits WHERE key = (.+);",
+				).WithArgs(key, 10, 10.0/60).WillReturnRows(rows)
+			},
+			key:        "auth:ip:127.0.0.1",
+			expTokens:  0.5,
+			expAllowed: false,
+			expError:   false,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock(tc.key)
+
+		tokens, allowed, err := r.Take(context.Background(), tc.key, 10, time.Minute)
+
+		if !tc.expError {
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expTokens, tokens)
+			assert.Equal(t, tc.expAllowed, allowed)
+		} else {
+			assert.Error(t, err)
+		}
+	}
+}
+
+func TestRateLimitRepo_DeleteStale(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newRateLimitRepo(sqlx.NewDb(db, "postgres"))
+
+	testcases := []struct {
+		name     string
+		mock     func(time.Time)
+		before   time.Time
+		expError bool
+	}{
+		{
+			name: "stale buckets are deleted",
+			mock: func(before time.Time) {
+				mock.ExpectExec(
+					"DELETE FROM rate_limits WHERE updated_at < (.+);",
+				).WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 2))
+			},
+			before:   time.Now(),
+			expError: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock(tc.before)
+
+		err := r.DeleteStale(context.Background(), tc.before)
+
+		if !tc.expError {
+			assert.NoError(t, err)
+		} else {
+			assert.Error(t, err)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+func TestRecoveryCodeRepo_Replace(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newRecoveryCodeRepo(sqlx.NewDb(db, "postgres"))
+
+	testcases := []struct {
+		name     string
+		mock     func(int, []string)
+		userID   int
+		hashes   []string
+		expError error
+	}{
+		{
+			name: "codes are replaced",
+			mock: func(userID int, hashes []string) {
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM recovery_codes WHERE (.+);").WithArgs(
+					userID,
+				).WillReturnResult(sqlmock.NewResult(0, 2))
+				for _, hash := range hashes {
+					mock.ExpectExec("INSERT INTO recovery_codes (.+);").WithArgs(
+						userID, hash,
+					).WillReturnResult(sqlmock.NewResult(0, 1))
+				}
+				mock.ExpectCommit()
+			},
+			userID: 1,
+			hashes: []string{"hash1", "hash2"},
+		},
+		{
+			name: "user is not found",
+			mock: func(userID int, hashes []string) {
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM recovery_codes WHERE (.+);").WithArgs(
+					userID,
+				).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO recovery_codes (.+);").WithArgs(
+					userID, hashes[0],
+				).WillReturnError(&pq.Error{Code: "23503"})
+				mock.ExpectRollback()
+			},
+			userID:   2,
+			hashes:   []string{"hash1"},
+			expError: store.ErrNotFound,
+		},
+		{
+			name: "driver error is returned",
+			mock: func(userID int, hashes []string) {
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM recovery_codes WHERE (.+);").WithArgs(
+					userID,
+				).WillReturnError(errQueryCanceled)
+				mock.ExpectRollback()
+			},
+			userID:   1,
+			hashes:   []string{"hash1"},
+			expError: errQueryCanceled,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock(tc.userID, tc.hashes)
+
+		err := r.Replace(context.Background(), tc.userID, tc.hashes)
+
+		if tc.expError == nil {
+			assert.NoError(t, err)
+		} else {
+			assert.True(t, errors.Is(err, tc.expError), err)
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestRecoveryCodeRepo_Use(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newRecoveryCodeRepo(sqlx.NewDb(db, "postgres"))
+
+	testcases := []struct {
+		name     string
+		mock     func(int, string)
+		userID   int
+		hash     string
+		expError error
+	}{
+		{
+			name: "code is used",
+			mock: func(userID int, hash string) {
+				mock.ExpectExec("UPDATE recovery_codes SET used_at = (.+) WHERE (.+);").WithArgs(
+					userID, hash,
+				).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			userID: 1,
+			hash:   "hash1",
+		},
+		{
+			name: "code has already been used",
+			mock: func(userID int, hash string) {
+				mock.ExpectExec("UPDATE recovery_codes SET used_at = (.+) WHERE (.+);").WithArgs(
+					userID, hash,
+				).WillReturnResult(sqlmock.NewResult(0, 0))
+				rows := sqlmock.NewRows([]string{"exists"}).AddRow(true)
+				mock.ExpectQuery("SELECT EXISTS(.+);").WithArgs(userID, hash).WillReturnRows(rows)
+			},
+			userID:   1,
+			hash:     "hash1",
+			expError: store.ErrTokenIsUsed,
+		},
+		{
+			name: "code is not found",
+			mock: func(userID int, hash string) {
+				mock.ExpectExec("UPDATE recovery_codes SET used_at = (.+) WHERE (.+);").WithArgs(
+					userID, hash,
+				).WillReturnResult(sqlmock.NewResult(0, 0))
+				rows := sqlmock.NewRows([]string{"exists"}).AddRow(false)
+				mock.ExpectQuery("SELECT EXISTS(.+);").WithArgs(userID, hash).WillReturnRows(rows)
+			},
+			userID:   1,
+			hash:     "hash2",
+			expError: store.ErrNotFound,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock(tc.userID, tc.hash)
+
+		err := r.Use(context.Background(), tc.userID, tc.hash)
+
+		assert.Equal(t, tc.expError, err)
+	}
+}
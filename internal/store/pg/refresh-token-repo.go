@@ -0,0 +1,92 @@
+package pg
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// refreshTokenRepo is the refresh token repository for PostgreSQL store.
+type refreshTokenRepo struct {
+	db *sqlx.DB
+}
+
+// newRefreshTokenRepo creates and returns a new refreshTokenRepo instance.
+func newRefreshTokenRepo(db *sqlx.DB) *refreshTokenRepo { return &refreshTokenRepo{db: db} }
+
+// Create creates and returns a new refresh token.
+func (r *refreshTokenRepo) Create(
+	ctx context.Context, t model.RefreshToken,
+) (model.RefreshToken, error) {
+	query := "INSERT INTO refresh_tokens (id, family_id, user_id, expires_at) "
+	query += "VALUES ($1, $2, $3, $4);"
+	if _, err := r.db.ExecContext(ctx, query, t.ID, t.FamilyID, t.UserID, t.ExpiresAt); err != nil {
+		return model.RefreshToken{}, err
+	}
+
+	return t, nil
+}
+
+// GetByID returns the refresh token with specific ID.
+func (r *refreshTokenRepo) GetByID(ctx context.Context, id string) (model.RefreshToken, error) {
+	t := model.RefreshToken{}
+	if err := r.db.GetContext(ctx, &t, "SELECT * FROM refresh_tokens WHERE id = $1;", id); err != nil {
+		return model.RefreshToken{}, wrapError("get refresh token", err)
+	}
+
+	return t, nil
+}
+
+// MarkUsed marks the refresh token with specific ID as used. It returns
+// store.ErrTokenIsUsed if the token has already been used before.
+func (r *refreshTokenRepo) MarkUsed(ctx context.Context, id string) error {
+	query := "UPDATE refresh_tokens SET used_at = NOW() WHERE id = $1 AND used_at IS NULL;"
+	res, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsCount, err := res.RowsAffected()
+	if err != nil {
+		return err
+	} else if rowsCount == 0 {
+		var exists bool
+		query := "SELECT EXISTS(SELECT 1 FROM refresh_tokens WHERE id = $1);"
+		if err := r.db.GetContext(ctx, &exists, query, id); err != nil {
+			return err
+		} else if !exists {
+			return store.ErrNotFound
+		}
+		return store.ErrTokenIsUsed
+	}
+
+	return nil
+}
+
+// RevokeFamily revokes all refresh tokens of the family with specific ID.
+func (r *refreshTokenRepo) RevokeFamily(ctx context.Context, familyID string) error {
+	query := "UPDATE refresh_tokens SET revoked_at = NOW() "
+	query += "WHERE family_id = $1 AND revoked_at IS NULL;"
+	_, err := r.db.ExecContext(ctx, query, familyID)
+
+	return err
+}
+
+// RevokeByUserID revokes all refresh tokens of the user with specific ID.
+func (r *refreshTokenRepo) RevokeByUserID(ctx context.Context, userID int) error {
+	query := "UPDATE refresh_tokens SET revoked_at = NOW() "
+	query += "WHERE user_id = $1 AND revoked_at IS NULL;"
+	_, err := r.db.ExecContext(ctx, query, userID)
+
+	return err
+}
+
+// DeleteExpired deletes all refresh tokens which have already expired.
+func (r *refreshTokenRepo) DeleteExpired(ctx context.Context) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM refresh_tokens WHERE expires_at < NOW();")
+
+	return err
+}
@@ -0,0 +1,261 @@
+package pg
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+func TestRefreshTokenRepo_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newRefreshTokenRepo(sqlx.NewDb(db, "postgres"))
+
+	testcases := []struct {
+		name     string
+		mock     func(model.RefreshToken)
+		token    model.RefreshToken
+		expError bool
+	}{
+		{
+			name: "refresh token is created",
+			mock: func(t model.RefreshToken) {
+				mock.ExpectExec("INSERT INTO refresh_tokens (.+) VALUES (.+);").WithArgs(
+					t.ID, t.FamilyID, t.UserID, t.ExpiresAt,
+				).WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			token: model.RefreshToken{
+				ID: "token1", FamilyID: "family1", UserID: 1, ExpiresAt: time.Now(),
+			},
+			expError: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock(tc.token)
+
+		token, err := r.Create(context.Background(), tc.token)
+
+		if !tc.expError {
+			assert.NoError(t, err)
+			assert.Equal(t, tc.token, token)
+		} else {
+			assert.Error(t, err)
+		}
+	}
+}
+
+func TestRefreshTokenRepo_GetByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newRefreshTokenRepo(sqlx.NewDb(db, "postgres"))
+
+	testcases := []struct {
+		name     string
+		mock     func(model.RefreshToken)
+		token    model.RefreshToken
+		expError bool
+	}{
+		{
+			name: "refresh token is retrieved by ID",
+			mock: func(t model.RefreshToken) {
+				rows := sqlmock.NewRows([]string{"id", "family_id", "user_id"}).AddRow(
+					t.ID, t.FamilyID, t.UserID,
+				)
+				mock.ExpectQuery(
+					"SELECT (.+) FROM refresh_tokens WHERE id = (.+);",
+				).WithArgs(t.ID).WillReturnRows(rows)
+			},
+			token:    model.RefreshToken{ID: "token1", FamilyID: "family1", UserID: 1},
+			expError: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock(tc.token)
+
+		token, err := r.GetByID(context.Background(), tc.token.ID)
+
+		if !tc.expError {
+			assert.NoError(t, err)
+			assert.Equal(t, tc.token, token)
+		} else {
+			assert.Error(t, err)
+		}
+	}
+}
+
+func TestRefreshTokenRepo_MarkUsed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newRefreshTokenRepo(sqlx.NewDb(db, "postgres"))
+
+	testcases := []struct {
+		name     string
+		mock     func(string)
+		id       string
+		expError error
+	}{
+		{
+			name: "refresh token is marked as used",
+			mock: func(id string) {
+				mock.ExpectExec(
+					"UPDATE refresh_tokens SET used_at = (.+) WHERE id = (.+);",
+				).WithArgs(id).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			id:       "token1",
+			expError: nil,
+		},
+		{
+			name: "refresh token has already been used",
+			mock: func(id string) {
+				mock.ExpectExec(
+					"UPDATE refresh_tokens SET used_at = (.+) WHERE id = (.+);",
+				).WithArgs(id).WillReturnResult(sqlmock.NewResult(0, 0))
+				rows := sqlmock.NewRows([]string{"exists"}).AddRow(true)
+				mock.ExpectQuery("SELECT EXISTS(.+);").WithArgs(id).WillReturnRows(rows)
+			},
+			id:       "token1",
+			expError: store.ErrTokenIsUsed,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock(tc.id)
+
+		err := r.MarkUsed(context.Background(), tc.id)
+
+		assert.Equal(t, tc.expError, err)
+	}
+}
+
+func TestRefreshTokenRepo_RevokeFamily(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newRefreshTokenRepo(sqlx.NewDb(db, "postgres"))
+
+	testcases := []struct {
+		name     string
+		mock     func(string)
+		familyID string
+		expError bool
+	}{
+		{
+			name: "refresh token family is revoked",
+			mock: func(familyID string) {
+				mock.ExpectExec(
+					"UPDATE refresh_tokens SET revoked_at = (.+) WHERE family_id = (.+);",
+				).WithArgs(familyID).WillReturnResult(sqlmock.NewResult(0, 2))
+			},
+			familyID: "family1",
+			expError: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock(tc.familyID)
+
+		err := r.RevokeFamily(context.Background(), tc.familyID)
+
+		if !tc.expError {
+			assert.NoError(t, err)
+		} else {
+			assert.Error(t, err)
+		}
+	}
+}
+
+func TestRefreshTokenRepo_RevokeByUserID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newRefreshTokenRepo(sqlx.NewDb(db, "postgres"))
+
+	testcases := []struct {
+		name     string
+		mock     func(int)
+		userID   int
+		expError bool
+	}{
+		{
+			name: "user's refresh tokens are revoked",
+			mock: func(userID int) {
+				mock.ExpectExec(
+					"UPDATE refresh_tokens SET revoked_at = (.+) WHERE user_id = (.+);",
+				).WithArgs(userID).WillReturnResult(sqlmock.NewResult(0, 2))
+			},
+			userID:   1,
+			expError: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock(tc.userID)
+
+		err := r.RevokeByUserID(context.Background(), tc.userID)
+
+		if !tc.expError {
+			assert.NoError(t, err)
+		} else {
+			assert.Error(t, err)
+		}
+	}
+}
+
+func TestRefreshTokenRepo_DeleteExpired(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newRefreshTokenRepo(sqlx.NewDb(db, "postgres"))
+
+	testcases := []struct {
+		name     string
+		mock     func()
+		expError bool
+	}{
+		{
+			name: "expired refresh tokens are deleted",
+			mock: func() {
+				mock.ExpectExec(
+					"DELETE FROM refresh_tokens WHERE expires_at < (.+);",
+				).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			expError: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock()
+
+		err := r.DeleteExpired(context.Background())
+
+		if !tc.expError {
+			assert.NoError(t, err)
+		} else {
+			assert.Error(t, err)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package pg
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// revokedTokenRepo is the revoked token repository for PostgreSQL store.
+type revokedTokenRepo struct {
+	db *sqlx.DB
+}
+
+// newRevokedTokenRepo creates and returns a new revokedTokenRepo instance.
+func newRevokedTokenRepo(db *sqlx.DB) *revokedTokenRepo { return &revokedTokenRepo{db: db} }
+
+// Create revokes a token. Revoking already revoked token is not an error.
+func (r *revokedTokenRepo) Create(
+	ctx context.Context, t model.RevokedToken,
+) (model.RevokedToken, error) {
+	query := "INSERT INTO revoked_tokens (id, expires_at) VALUES ($1, $2) "
+	query += "ON CONFLICT (id) DO NOTHING;"
+	if _, err := r.db.ExecContext(ctx, query, t.ID, t.ExpiresAt); err != nil {
+		return model.RevokedToken{}, err
+	}
+
+	return t, nil
+}
+
+// Use revokes a single use token. It returns store.ErrTokenIsUsed if the token has
+// already been revoked, so the token can't be used concurrently twice.
+func (r *revokedTokenRepo) Use(ctx context.Context, t model.RevokedToken) error {
+	query := "INSERT INTO revoked_tokens (id, expires_at) VALUES ($1, $2) "
+	query += "ON CONFLICT (id) DO NOTHING;"
+	res, err := r.db.ExecContext(ctx, query, t.ID, t.ExpiresAt)
+	if err != nil {
+		return wrapError("use token", err)
+	}
+
+	rowsCount, err := res.RowsAffected()
+	if err != nil {
+		return wrapError("use token", err)
+	} else if rowsCount == 0 {
+		return store.ErrTokenIsUsed
+	}
+
+	return nil
+}
+
+// Exists reports whether the token with specific ID is revoked.
+func (r *revokedTokenRepo) Exists(ctx context.Context, id string) (bool, error) {
+	var exists bool
+	query := "SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE id = $1);"
+	if err := r.db.GetContext(ctx, &exists, query, id); err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// DeleteExpired deletes all revoked tokens which have already expired.
+func (r *revokedTokenRepo) DeleteExpired(ctx context.Context) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM revoked_tokens WHERE expires_at < NOW();")
+
+	return err
+}
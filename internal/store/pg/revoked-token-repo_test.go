@@ -0,0 +1,186 @@
+package pg
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+func TestRevokedTokenRepo_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newRevokedTokenRepo(sqlx.NewDb(db, "postgres"))
+
+	testcases := []struct {
+		name     string
+		mock     func(model.RevokedToken)
+		token    model.RevokedToken
+		expError bool
+	}{
+		{
+			name: "token is revoked",
+			mock: func(t model.RevokedToken) {
+				mock.ExpectExec("INSERT INTO revoked_tokens (.+) VALUES (.+)").WithArgs(
+					t.ID, t.ExpiresAt,
+				).WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			token:    model.RevokedToken{ID: "token1", ExpiresAt: time.Now()},
+			expError: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock(tc.token)
+
+		token, err := r.Create(context.Background(), tc.token)
+
+		if !tc.expError {
+			assert.NoError(t, err)
+			assert.Equal(t, tc.token, token)
+		} else {
+			assert.Error(t, err)
+		}
+	}
+}
+
+func TestRevokedTokenRepo_Use(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newRevokedTokenRepo(sqlx.NewDb(db, "postgres"))
+
+	testcases := []struct {
+		name     string
+		mock     func(model.RevokedToken)
+		token    model.RevokedToken
+		expError error
+	}{
+		{
+			name: "token is used",
+			mock: func(t model.RevokedToken) {
+				mock.ExpectExec("INSERT INTO revoked_tokens (.+) VALUES (.+)").WithArgs(
+					t.ID, t.ExpiresAt,
+				).WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			token: model.RevokedToken{ID: "token1", ExpiresAt: time.Now()},
+		},
+		{
+			name: "token has already been used",
+			mock: func(t model.RevokedToken) {
+				mock.ExpectExec("INSERT INTO revoked_tokens (.+) VALUES (.+)").WithArgs(
+					t.ID, t.ExpiresAt,
+				).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			token:    model.RevokedToken{ID: "token1", ExpiresAt: time.Now()},
+			expError: store.ErrTokenIsUsed,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock(tc.token)
+
+		err := r.Use(context.Background(), tc.token)
+
+		assert.Equal(t, tc.expError, err, tc.name)
+	}
+}
+
+func TestRevokedTokenRepo_Exists(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newRevokedTokenRepo(sqlx.NewDb(db, "postgres"))
+
+	testcases := []struct {
+		name      string
+		mock      func(string, bool)
+		id        string
+		expExists bool
+		expError  bool
+	}{
+		{
+			name: "token is revoked",
+			mock: func(id string, exists bool) {
+				rows := sqlmock.NewRows([]string{"exists"}).AddRow(exists)
+				mock.ExpectQuery("SELECT EXISTS(.+);").WithArgs(id).WillReturnRows(rows)
+			},
+			id:        "token1",
+			expExists: true,
+			expError:  false,
+		},
+		{
+			name: "token isn't revoked",
+			mock: func(id string, exists bool) {
+				rows := sqlmock.NewRows([]string{"exists"}).AddRow(exists)
+				mock.ExpectQuery("SELECT EXISTS(.+);").WithArgs(id).WillReturnRows(rows)
+			},
+			id:        "token2",
+			expExists: false,
+			expError:  false,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock(tc.id, tc.expExists)
+
+		exists, err := r.Exists(context.Background(), tc.id)
+
+		if !tc.expError {
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expExists, exists)
+		} else {
+			assert.Error(t, err)
+		}
+	}
+}
+
+func TestRevokedTokenRepo_DeleteExpired(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newRevokedTokenRepo(sqlx.NewDb(db, "postgres"))
+
+	testcases := []struct {
+		name     string
+		mock     func()
+		expError bool
+	}{
+		{
+			name: "expired tokens are deleted",
+			mock: func() {
+				mock.ExpectExec(
+					"DELETE FROM revoked_tokens WHERE expires_at < (.+);",
+				).WillReturnResult(sqlmock.NewResult(0, 3))
+			},
+			expError: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock()
+
+		err := r.DeleteExpired(context.Background())
+
+		if !tc.expError {
+			assert.NoError(t, err)
+		} else {
+			assert.Error(t, err)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package pg
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// roleRepo is the role repository for PostgreSQL store.
+type roleRepo struct {
+	db *sqlx.DB
+}
+
+// newRoleRepo creates and returns a new roleRepo instance.
+func newRoleRepo(db *sqlx.DB) *roleRepo { return &roleRepo{db: db} }
+
+// roleRow is the role with permissions aggregated into an array.
+type roleRow struct {
+	ID          int            `db:"id"`
+	Name        string         `db:"name"`
+	Permissions pq.StringArray `db:"permissions"`
+}
+
+// rolesQuery selects roles with their permissions.
+const rolesQuery = "SELECT roles.id, roles.name, " +
+	"COALESCE(array_agg(permissions.name ORDER BY permissions.name) " +
+	"FILTER (WHERE permissions.name IS NOT NULL), '{}') AS permissions FROM roles " +
+	"LEFT JOIN role_permissions ON role_permissions.role_id = roles.id " +
+	"LEFT JOIN permissions ON permissions.id = role_permissions.permission_id "
+
+// toRoles converts role rows to roles.
+func toRoles(rows []roleRow) []model.Role {
+	roles := make([]model.Role, 0, len(rows))
+	for _, r := range rows {
+		roles = append(roles, model.Role{ID: r.ID, Name: r.Name, Permissions: []string(r.Permissions)})
+	}
+
+	return roles
+}
+
+// GetAll returns all roles.
+func (r *roleRepo) GetAll(ctx context.Context) ([]model.Role, error) {
+	rows := []roleRow{}
+	query := rolesQuery + "GROUP BY roles.id ORDER BY roles.name;"
+	if err := r.db.SelectContext(ctx, &rows, query); err != nil {
+		return []model.Role{}, err
+	}
+
+	return toRoles(rows), nil
+}
+
+// GetByUserID returns all roles of the user with specific ID.
+func (r *roleRepo) GetByUserID(ctx context.Context, userID int) ([]model.Role, error) {
+	rows := []roleRow{}
+	query := rolesQuery + "JOIN user_roles ON user_roles.role_id = roles.id "
+	query += "WHERE user_roles.user_id = $1 GROUP BY roles.id ORDER BY roles.name;"
+	if err := r.db.SelectContext(ctx, &rows, query, userID); err != nil {
+		return []model.Role{}, err
+	}
+
+	return toRoles(rows), nil
+}
+
+// AssignToUser assigns the role with specific name to the user with specific ID.
+func (r *roleRepo) AssignToUser(ctx context.Context, userID int, role string) error {
+	query := "INSERT INTO user_roles (user_id, role_id) SELECT $1, id FROM roles WHERE name = $2 "
+	query += "ON CONFLICT DO NOTHING;"
+	res, err := r.db.ExecContext(ctx, query, userID, role)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+		return store.ErrNotFound
+	} else if err != nil {
+		return err
+	}
+
+	rowsCount, err := res.RowsAffected()
+	if err != nil {
+		return err
+	} else if rowsCount == 0 {
+		var exists bool
+		if err := r.db.GetContext(
+			ctx, &exists, "SELECT EXISTS(SELECT 1 FROM roles WHERE name = $1);", role,
+		); err != nil {
+			return err
+		} else if !exists {
+			return store.ErrNotFound
+		}
+	}
+
+	return nil
+}
+
+// RemoveFromUser removes the role with specific name from the user with specific ID.
+func (r *roleRepo) RemoveFromUser(ctx context.Context, userID int, role string) error {
+	query := "DELETE FROM user_roles WHERE user_id = $1 "
+	query += "AND role_id = (SELECT id FROM roles WHERE name = $2);"
+	res, err := r.db.ExecContext(ctx, query, userID, role)
+	if err != nil {
+		return err
+	}
+
+	rowsCount, err := res.RowsAffected()
+	if err != nil {
+		return err
+	} else if rowsCount == 0 {
+		return store.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,197 @@
+package pg
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+)
+
+func TestRoleRepo_GetAll(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newRoleRepo(sqlx.NewDb(db, "postgres"))
+
+	testcases := []struct {
+		name     string
+		mock     func()
+		expRoles []model.Role
+		expError bool
+	}{
+		{
+			name: "roles are retrieved",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id", "name", "permissions"}).
+					AddRow(1, "admin", "{users:read,users:write}").
+					AddRow(2, "user", "{}")
+				mock.ExpectQuery("SELECT (.+) FROM roles (.+) GROUP BY (.+);").WillReturnRows(rows)
+			},
+			expRoles: []model.Role{
+				{ID: 1, Name: "admin", Permissions: []string{"users:read", "users:write"}},
+				{ID: 2, Name: "user", Permissions: []string{}},
+			},
+			expError: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock()
+
+		roles, err := r.GetAll(context.Background())
+
+		if !tc.expError {
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expRoles, roles)
+		} else {
+			assert.Error(t, err)
+		}
+	}
+}
+
+func TestRoleRepo_GetByUserID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newRoleRepo(sqlx.NewDb(db, "postgres"))
+
+	testcases := []struct {
+		name     string
+		mock     func(int)
+		userID   int
+		expRoles []model.Role
+		expError bool
+	}{
+		{
+			name: "user's roles are retrieved",
+			mock: func(userID int) {
+				rows := sqlmock.NewRows([]string{"id", "name", "permissions"}).
+					AddRow(1, "admin", "{users:read}")
+				mock.ExpectQuery(
+					"SELECT (.+) FROM roles (.+) WHERE user_roles.user_id = (.+);",
+				).WithArgs(userID).WillReturnRows(rows)
+			},
+			userID: 1,
+			expRoles: []model.Role{
+				{ID: 1, Name: "admin", Permissions: []string{"users:read"}},
+			},
+			expError: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock(tc.userID)
+
+		roles, err := r.GetByUserID(context.Background(), tc.userID)
+
+		if !tc.expError {
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expRoles, roles)
+		} else {
+			assert.Error(t, err)
+		}
+	}
+}
+
+func TestRoleRepo_AssignToUser(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newRoleRepo(sqlx.NewDb(db, "postgres"))
+
+	testcases := []struct {
+		name     string
+		mock     func(int, string)
+		userID   int
+		role     string
+		expError bool
+	}{
+		{
+			name: "role is assigned",
+			mock: func(userID int, role string) {
+				mock.ExpectExec(
+					"INSERT INTO user_roles (.+) SELECT (.+) FROM roles WHERE name = (.+)",
+				).WithArgs(userID, role).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			userID:   1,
+			role:     "admin",
+			expError: false,
+		},
+		{
+			name: "role isn't found",
+			mock: func(userID int, role string) {
+				mock.ExpectExec(
+					"INSERT INTO user_roles (.+) SELECT (.+) FROM roles WHERE name = (.+)",
+				).WithArgs(userID, role).WillReturnResult(sqlmock.NewResult(0, 0))
+				rows := sqlmock.NewRows([]string{"exists"}).AddRow(false)
+				mock.ExpectQuery("SELECT EXISTS(.+);").WithArgs(role).WillReturnRows(rows)
+			},
+			userID:   1,
+			role:     "unknown",
+			expError: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock(tc.userID, tc.role)
+
+		err := r.AssignToUser(context.Background(), tc.userID, tc.role)
+
+		if !tc.expError {
+			assert.NoError(t, err)
+		} else {
+			assert.Error(t, err)
+		}
+	}
+}
+
+func TestRoleRepo_RemoveFromUser(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newRoleRepo(sqlx.NewDb(db, "postgres"))
+
+	testcases := []struct {
+		name     string
+		mock     func(int, string)
+		userID   int
+		role     string
+		expError bool
+	}{
+		{
+			name: "role is removed",
+			mock: func(userID int, role string) {
+				mock.ExpectExec(
+					"DELETE FROM user_roles WHERE user_id = (.+) AND role_id = (.+);",
+				).WithArgs(userID, role).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			userID:   1,
+			role:     "admin",
+			expError: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock(tc.userID, tc.role)
+
+		err := r.RemoveFromUser(context.Background(), tc.userID, tc.role)
+
+		if !tc.expError {
+			assert.NoError(t, err)
+		} else {
+			assert.Error(t, err)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// signingKeyRepo is the signing key repository for PostgreSQL store.
+type signingKeyRepo struct {
+	db *sqlx.DB
+}
+
+// newSigningKeyRepo creates and returns a new signingKeyRepo instance.
+func newSigningKeyRepo(db *sqlx.DB) *signingKeyRepo { return &signingKeyRepo{db: db} }
+
+// GetAll returns all signing keys.
+func (r *signingKeyRepo) GetAll(ctx context.Context) ([]model.SigningKey, error) {
+	keys := []model.SigningKey{}
+	query := "SELECT * FROM signing_keys ORDER BY created_at;"
+	if err := r.db.SelectContext(ctx, &keys, query); err != nil {
+		return []model.SigningKey{}, err
+	}
+
+	return keys, nil
+}
+
+// Create creates and returns a new signing key.
+func (r *signingKeyRepo) Create(ctx context.Context, k model.SigningKey) (model.SigningKey, error) {
+	query := "INSERT INTO signing_keys (id, algorithm, material, status, activated_at) "
+	query += "VALUES ($1, $2, $3, $4, $5) RETURNING created_at;"
+	row := r.db.QueryRowContext(ctx, query, k.ID, k.Algorithm, k.Material, k.Status, k.ActivatedAt)
+	if err := row.Scan(&k.CreatedAt); err != nil {
+		return model.SigningKey{}, err
+	}
+
+	return k, nil
+}
+
+// Activate makes the signing key with specific ID active, the previously active
+// key is retired.
+func (r *signingKeyRepo) Activate(ctx context.Context, id string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := "UPDATE signing_keys SET status = 'retired', retired_at = NOW() "
+	query += "WHERE status = 'active' AND id <> $1;"
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		return err
+	}
+
+	query = "UPDATE signing_keys SET status = 'active', activated_at = NOW(), retired_at = NULL "
+	query += "WHERE id = $1;"
+	res, err := tx.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsCount, err := res.RowsAffected()
+	if err != nil {
+		return err
+	} else if rowsCount == 0 {
+		return store.ErrNotFound
+	}
+
+	return tx.Commit()
+}
+
+// Retire retires the pending signing key with specific ID.
+func (r *signingKeyRepo) Retire(ctx context.Context, id string) error {
+	query := "UPDATE signing_keys SET status = 'retired', retired_at = NOW() "
+	query += "WHERE id = $1 AND status = 'pending';"
+	res, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsCount, err := res.RowsAffected()
+	if err != nil {
+		return err
+	} else if rowsCount == 0 {
+		return store.ErrNotFound
+	}
+
+	return nil
+}
+
+// DeleteRetiredBefore deletes all signing keys retired before specific time.
+func (r *signingKeyRepo) DeleteRetiredBefore(ctx context.Context, t time.Time) error {
+	query := "DELETE FROM signing_keys WHERE status = 'retired' AND retired_at < $1;"
+	_, err := r.db.ExecContext(ctx, query, t)
+
+	return err
+}
@@ -0,0 +1,245 @@
+package pg
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+)
+
+func TestSigningKeyRepo_GetAll(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newSigningKeyRepo(sqlx.NewDb(db, "postgres"))
+
+	testcases := []struct {
+		name     string
+		mock     func([]model.SigningKey)
+		expKeys  []model.SigningKey
+		expError bool
+	}{
+		{
+			name: "signing keys are retrieved",
+			mock: func(ks []model.SigningKey) {
+				rows := sqlmock.NewRows([]string{"id", "algorithm", "status"})
+				for _, k := range ks {
+					rows = rows.AddRow(k.ID, k.Algorithm, k.Status)
+				}
+				mock.ExpectQuery("SELECT (.+) FROM signing_keys (.+);").WillReturnRows(rows)
+			},
+			expKeys: []model.SigningKey{
+				{ID: "key1", Algorithm: "ES256", Status: model.SigningKeyRetired},
+				{ID: "key2", Algorithm: "ES256", Status: model.SigningKeyActive},
+			},
+			expError: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock(tc.expKeys)
+
+		ks, err := r.GetAll(context.Background())
+
+		if !tc.expError {
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expKeys, ks)
+		} else {
+			assert.Error(t, err)
+		}
+	}
+}
+
+func TestSigningKeyRepo_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newSigningKeyRepo(sqlx.NewDb(db, "postgres"))
+	createdAt := time.Now()
+
+	testcases := []struct {
+		name     string
+		mock     func(model.SigningKey)
+		key      model.SigningKey
+		expKey   model.SigningKey
+		expError bool
+	}{
+		{
+			name: "signing key is created",
+			mock: func(k model.SigningKey) {
+				rows := sqlmock.NewRows([]string{"created_at"}).AddRow(createdAt)
+				mock.ExpectQuery("INSERT INTO signing_keys (.+) VALUES (.+) RETURNING created_at;").WithArgs(
+					k.ID, k.Algorithm, k.Material, k.Status, k.ActivatedAt,
+				).WillReturnRows(rows)
+			},
+			key: model.SigningKey{
+				ID: "key1", Algorithm: "HS256", Material: "secret", Status: model.SigningKeyPending,
+			},
+			expKey: model.SigningKey{
+				ID: "key1", Algorithm: "HS256", Material: "secret", Status: model.SigningKeyPending,
+				CreatedAt: createdAt,
+			},
+			expError: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock(tc.key)
+
+		k, err := r.Create(context.Background(), tc.key)
+
+		if !tc.expError {
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expKey, k)
+		} else {
+			assert.Error(t, err)
+		}
+	}
+}
+
+func TestSigningKeyRepo_Activate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newSigningKeyRepo(sqlx.NewDb(db, "postgres"))
+
+	testcases := []struct {
+		name     string
+		mock     func(string)
+		id       string
+		expError bool
+	}{
+		{
+			name: "signing key is activated",
+			mock: func(id string) {
+				mock.ExpectBegin()
+				mock.ExpectExec(
+					"UPDATE signing_keys SET status = 'retired'(.+) WHERE status = 'active'(.+);",
+				).WithArgs(id).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(
+					"UPDATE signing_keys SET status = 'active'(.+) WHERE id = (.+);",
+				).WithArgs(id).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			id:       "key2",
+			expError: false,
+		},
+		{
+			name: "signing key isn't found",
+			mock: func(id string) {
+				mock.ExpectBegin()
+				mock.ExpectExec(
+					"UPDATE signing_keys SET status = 'retired'(.+) WHERE status = 'active'(.+);",
+				).WithArgs(id).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(
+					"UPDATE signing_keys SET status = 'active'(.+) WHERE id = (.+);",
+				).WithArgs(id).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			id:       "key3",
+			expError: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock(tc.id)
+
+		err := r.Activate(context.Background(), tc.id)
+
+		if !tc.expError {
+			assert.NoError(t, err)
+		} else {
+			assert.Error(t, err)
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestSigningKeyRepo_Retire(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newSigningKeyRepo(sqlx.NewDb(db, "postgres"))
+
+	testcases := []struct {
+		name     string
+		mock     func(string)
+		id       string
+		expError bool
+	}{
+		{
+			name: "signing key is retired",
+			mock: func(id string) {
+				mock.ExpectExec(
+					"UPDATE signing_keys SET status = 'retired'(.+) WHERE id = (.+);",
+				).WithArgs(id).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			id:       "key1",
+			expError: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock(tc.id)
+
+		err := r.Retire(context.Background(), tc.id)
+
+		if !tc.expError {
+			assert.NoError(t, err)
+		} else {
+			assert.Error(t, err)
+		}
+	}
+}
+
+func TestSigningKeyRepo_DeleteRetiredBefore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newSigningKeyRepo(sqlx.NewDb(db, "postgres"))
+
+	testcases := []struct {
+		name     string
+		mock     func(time.Time)
+		before   time.Time
+		expError bool
+	}{
+		{
+			name: "retired signing keys are deleted",
+			mock: func(before time.Time) {
+				mock.ExpectExec(
+					"DELETE FROM signing_keys WHERE status = 'retired' AND retired_at < (.+);",
+				).WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			before:   time.Now(),
+			expError: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock(tc.before)
+
+		err := r.DeleteRetiredBefore(context.Background(), tc.before)
+
+		if !tc.expError {
+			assert.NoError(t, err)
+		} else {
+			assert.Error(t, err)
+		}
+	}
+}
@@ -20,11 +20,28 @@ var (
 	once sync.Once
 )
 
+// migrationsURL is the source of migrations relative to the working directory.
+var migrationsURL = "file://internal/store/pg/migrations"
+
 // Store is PostgreSQL store.
 type Store struct {
-	config   *config.PostgreSQL
-	db       *sqlx.DB
-	userRepo *userRepo
+	config           *config.PostgreSQL
+	db               *sqlx.DB
+	userRepo         *userRepo
+	refreshTokenRepo *refreshTokenRepo
+	revokedTokenRepo *revokedTokenRepo
+	signingKeyRepo   *signingKeyRepo
+	roleRepo         *roleRepo
+	resetTokenRepo   *passwordResetTokenRepo
+	verifyTokenRepo  *emailVerificationTokenRepo
+	throttleRepo     *loginThrottleRepo
+	rateLimitRepo    *rateLimitRepo
+	auditEventRepo   *auditEventRepo
+	totpSecretRepo   *totpSecretRepo
+	recoveryCodeRepo *recoveryCodeRepo
+	credentialRepo   *webAuthnCredentialRepo
+	magicLinkRepo    *magicLinkTokenRepo
+	oneTimeCodeRepo  *oneTimeCodeRepo
 }
 
 // Get creates store instance once and returns it.
@@ -55,9 +72,7 @@ func (s *Store) Open() error {
 
 	// Running migrations.
 	driver, err := postgres.WithInstance(db.DB, &postgres.Config{})
-	m, err := migrate.NewWithDatabaseInstance(
-		"file://internal/store/pg/migrations", "postgres", driver,
-	)
+	m, err := migrate.NewWithDatabaseInstance(migrationsURL, "postgres", driver)
 	if err != nil {
 		return err
 	}
@@ -80,6 +95,132 @@ func (s *Store) Users() store.UserRepo {
 	return s.userRepo
 }
 
+// RefreshTokens returns the refresh tokens repository.
+func (s *Store) RefreshTokens() store.RefreshTokenRepo {
+	if s.refreshTokenRepo == nil {
+		s.refreshTokenRepo = newRefreshTokenRepo(s.db)
+	}
+
+	return s.refreshTokenRepo
+}
+
+// RevokedTokens returns the revoked tokens repository.
+func (s *Store) RevokedTokens() store.RevokedTokenRepo {
+	if s.revokedTokenRepo == nil {
+		s.revokedTokenRepo = newRevokedTokenRepo(s.db)
+	}
+
+	return s.revokedTokenRepo
+}
+
+// SigningKeys returns the signing keys repository.
+func (s *Store) SigningKeys() store.SigningKeyRepo {
+	if s.signingKeyRepo == nil {
+		s.signingKeyRepo = newSigningKeyRepo(s.db)
+	}
+
+	return s.signingKeyRepo
+}
+
+// Roles returns the roles repository.
+func (s *Store) Roles() store.RoleRepo {
+	if s.roleRepo == nil {
+		s.roleRepo = newRoleRepo(s.db)
+	}
+
+	return s.roleRepo
+}
+
+// PasswordResetTokens returns the password reset tokens repository.
+func (s *Store) PasswordResetTokens() store.PasswordResetTokenRepo {
+	if s.resetTokenRepo == nil {
+		s.resetTokenRepo = newPasswordResetTokenRepo(s.db)
+	}
+
+	return s.resetTokenRepo
+}
+
+// EmailVerificationTokens returns the email verification tokens repository.
+func (s *Store) EmailVerificationTokens() store.EmailVerificationTokenRepo {
+	if s.verifyTokenRepo == nil {
+		s.verifyTokenRepo = newEmailVerificationTokenRepo(s.db)
+	}
+
+	return s.verifyTokenRepo
+}
+
+// LoginThrottles returns the login throttles repository.
+func (s *Store) LoginThrottles() store.LoginThrottleRepo {
+	if s.throttleRepo == nil {
+		s.throttleRepo = newLoginThrottleRepo(s.db)
+	}
+
+	return s.throttleRepo
+}
+
+// RateLimits returns the rate limit token buckets repository.
+func (s *Store) RateLimits() store.RateLimitRepo {
+	if s.rateLimitRepo == nil {
+		s.rateLimitRepo = newRateLimitRepo(s.db)
+	}
+
+	return s.rateLimitRepo
+}
+
+// AuditEvents returns the audit events repository.
+func (s *Store) AuditEvents() store.AuditEventRepo {
+	if s.auditEventRepo == nil {
+		s.auditEventRepo = newAuditEventRepo(s.db)
+	}
+
+	return s.auditEventRepo
+}
+
+// TOTPSecrets returns the TOTP secrets repository.
+func (s *Store) TOTPSecrets() store.TOTPSecretRepo {
+	if s.totpSecretRepo == nil {
+		s.totpSecretRepo = newTOTPSecretRepo(s.db)
+	}
+
+	return s.totpSecretRepo
+}
+
+// RecoveryCodes returns the MFA recovery codes repository.
+func (s *Store) RecoveryCodes() store.RecoveryCodeRepo {
+	if s.recoveryCodeRepo == nil {
+		s.recoveryCodeRepo = newRecoveryCodeRepo(s.db)
+	}
+
+	return s.recoveryCodeRepo
+}
+
+// WebAuthnCredentials returns the WebAuthn credentials repository.
+func (s *Store) WebAuthnCredentials() store.WebAuthnCredentialRepo {
+	if s.credentialRepo == nil {
+		s.credentialRepo = newWebAuthnCredentialRepo(s.db)
+	}
+
+	return s.credentialRepo
+}
+
+// MagicLinkTokens returns the magic link tokens repository.
+func (s *Store) MagicLinkTokens() store.MagicLinkTokenRepo {
+	if s.magicLinkRepo == nil {
+		s.magicLinkRepo = newMagicLinkTokenRepo(s.db)
+	}
+
+	return s.magicLinkRepo
+}
+
+// OneTimeCodes returns the one-time codes repository.
+func (s *Store) OneTimeCodes() store.OneTimeCodeRepo {
+	if s.oneTimeCodeRepo == nil {
+		s.oneTimeCodeRepo = newOneTimeCodeRepo(s.db)
+	}
+
+	return s.oneTimeCodeRepo
+}
+
 // Close closes a connection with PostgreSQL.
 func (s *Store) Close() error {
 	return s.db.Close()
@@ -1,9 +1,15 @@
 package pg
 
 import (
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imarrche/jwt-auth-example/internal/config"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+	"github.com/imarrche/jwt-auth-example/internal/store/storetest"
 )
 
 func TestStore_Get(t *testing.T) {
@@ -13,3 +19,45 @@ func TestStore_Get(t *testing.T) {
 func TestStore_Users(t *testing.T) {
 	assert.Equal(t, newUserRepo(nil), Get(nil).Users())
 }
+
+func TestStore_RefreshTokens(t *testing.T) {
+	assert.Equal(t, newRefreshTokenRepo(nil), Get(nil).RefreshTokens())
+}
+
+func TestStore_RevokedTokens(t *testing.T) {
+	assert.Equal(t, newRevokedTokenRepo(nil), Get(nil).RevokedTokens())
+}
+
+func TestStore_SigningKeys(t *testing.T) {
+	assert.Equal(t, newSigningKeyRepo(nil), Get(nil).SigningKeys())
+}
+
+func TestStore_Roles(t *testing.T) {
+	assert.Equal(t, newRoleRepo(nil), Get(nil).Roles())
+}
+
+// TestStore_Conformance runs against PostgreSQL set with POSTGRES_* variables. It's
+// skipped unless POSTGRES_TEST_DBNAME names a database which may be wiped.
+func TestStore_Conformance(t *testing.T) {
+	dbName := os.Getenv("POSTGRES_TEST_DBNAME")
+	if dbName == "" {
+		t.Skip("POSTGRES_TEST_DBNAME isn't set")
+	}
+	c := *config.Get().PostgreSQL
+	c.DBName = dbName
+	migrationsURL = "file://migrations"
+
+	storetest.Run(t, func(t *testing.T) store.Store {
+		s := &Store{config: &c}
+		require.NoError(t, s.Open())
+		t.Cleanup(func() { s.Close() })
+
+		query := "TRUNCATE users, refresh_tokens, revoked_tokens, signing_keys, "
+		query += "password_reset_tokens, email_verification_tokens, login_throttles, rate_limits, "
+		query += "audit_events RESTART IDENTITY CASCADE;"
+		_, err := s.db.Exec(query)
+		require.NoError(t, err)
+
+		return s
+	})
+}
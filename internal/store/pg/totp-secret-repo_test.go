@@ -0,0 +1,135 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+func TestTOTPSecretRepo_Save(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newTOTPSecretRepo(sqlx.NewDb(db, "postgres"))
+
+	testcases := []struct {
+		name     string
+		mock     func(model.TOTPSecret)
+		secret   model.TOTPSecret
+		expError error
+	}{
+		{
+			name: "secret is saved",
+			mock: func(s model.TOTPSecret) {
+				mock.ExpectExec("INSERT INTO totp_secrets (.+) ON CONFLICT (.+);").WithArgs(
+					s.UserID, s.Secret, s.ConfirmedAt, s.LastUsedStep,
+				).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			secret: model.TOTPSecret{UserID: 1, Secret: []byte("secret")},
+		},
+		{
+			name: "user is not found",
+			mock: func(s model.TOTPSecret) {
+				mock.ExpectExec("INSERT INTO totp_secrets (.+) ON CONFLICT (.+);").WithArgs(
+					s.UserID, s.Secret, s.ConfirmedAt, s.LastUsedStep,
+				).WillReturnError(&pq.Error{Code: "23503"})
+			},
+			secret:   model.TOTPSecret{UserID: 2, Secret: []byte("secret")},
+			expError: store.ErrNotFound,
+		},
+		{
+			name: "driver error is returned",
+			mock: func(s model.TOTPSecret) {
+				mock.ExpectExec("INSERT INTO totp_secrets (.+) ON CONFLICT (.+);").WithArgs(
+					s.UserID, s.Secret, s.ConfirmedAt, s.LastUsedStep,
+				).WillReturnError(errQueryCanceled)
+			},
+			secret:   model.TOTPSecret{UserID: 1, Secret: []byte("secret")},
+			expError: errQueryCanceled,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock(tc.secret)
+
+		err := r.Save(context.Background(), tc.secret)
+
+		if tc.expError == nil {
+			assert.NoError(t, err)
+		} else {
+			assert.True(t, errors.Is(err, tc.expError), err)
+		}
+	}
+}
+
+func TestTOTPSecretRepo_MarkUsed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newTOTPSecretRepo(sqlx.NewDb(db, "postgres"))
+
+	testcases := []struct {
+		name     string
+		mock     func(int, int64)
+		userID   int
+		step     int64
+		expError error
+	}{
+		{
+			name: "step is marked as used",
+			mock: func(userID int, step int64) {
+				mock.ExpectExec("UPDATE totp_secrets SET last_used_step = (.+) WHERE (.+);").WithArgs(
+					userID, step,
+				).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			userID: 1,
+			step:   10,
+		},
+		{
+			name: "step has already been used",
+			mock: func(userID int, step int64) {
+				mock.ExpectExec("UPDATE totp_secrets SET last_used_step = (.+) WHERE (.+);").WithArgs(
+					userID, step,
+				).WillReturnResult(sqlmock.NewResult(0, 0))
+				rows := sqlmock.NewRows([]string{"exists"}).AddRow(true)
+				mock.ExpectQuery("SELECT EXISTS(.+);").WithArgs(userID).WillReturnRows(rows)
+			},
+			userID:   1,
+			step:     10,
+			expError: store.ErrTokenIsUsed,
+		},
+		{
+			name: "secret is not found",
+			mock: func(userID int, step int64) {
+				mock.ExpectExec("UPDATE totp_secrets SET last_used_step = (.+) WHERE (.+);").WithArgs(
+					userID, step,
+				).WillReturnResult(sqlmock.NewResult(0, 0))
+				rows := sqlmock.NewRows([]string{"exists"}).AddRow(false)
+				mock.ExpectQuery("SELECT EXISTS(.+);").WithArgs(userID).WillReturnRows(rows)
+			},
+			userID:   2,
+			step:     10,
+			expError: store.ErrNotFound,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock(tc.userID, tc.step)
+
+		err := r.MarkUsed(context.Background(), tc.userID, tc.step)
+
+		assert.Equal(t, tc.expError, err)
+	}
+}
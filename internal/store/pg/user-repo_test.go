@@ -1,15 +1,24 @@
 package pg
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
 )
 
+// errQueryCanceled is a driver error which isn't mapped onto store errors.
+var errQueryCanceled = &pq.Error{Code: "57014", Message: "canceling statement due to user request"}
+
 func TestUserRepo_GetAll(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -22,7 +31,7 @@ func TestUserRepo_GetAll(t *testing.T) {
 		name     string
 		mock     func([]model.User)
 		expUsers []model.User
-		expError bool
+		expError error
 	}{
 		{
 			name: "users are retrieved",
@@ -36,20 +45,19 @@ func TestUserRepo_GetAll(t *testing.T) {
 			expUsers: []model.User{
 				{ID: 1, Username: "user1"}, {ID: 2, Username: "user2"},
 			},
-			expError: false,
 		},
 	}
 
 	for _, tc := range testcases {
 		tc.mock(tc.expUsers)
 
-		us, err := r.GetAll()
+		us, err := r.GetAll(context.Background())
 
-		if !tc.expError {
+		if tc.expError == nil {
 			assert.NoError(t, err)
 			assert.Equal(t, tc.expUsers, us)
 		} else {
-			assert.Error(t, err)
+			assert.True(t, errors.Is(err, tc.expError), err)
 		}
 	}
 }
@@ -61,38 +69,68 @@ func TestUserRepo_Create(t *testing.T) {
 	}
 	defer db.Close()
 	r := newUserRepo(sqlx.NewDb(db, "postgres"))
+	createdAt := time.Now()
 
 	testcases := []struct {
 		name     string
 		mock     func(model.User)
 		user     model.User
 		expUser  model.User
-		expError bool
+		expError error
 	}{
 		{
 			name: "user is created",
 			mock: func(u model.User) {
-				rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
-				mock.ExpectQuery("INSERT INTO users (.+) VALUES (.+) RETURNING id;").WithArgs(
+				rows := sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, createdAt)
+				mock.ExpectQuery("INSERT INTO users (.+) VALUES (.+) RETURNING id, created_at;").WithArgs(
 					u.Username, u.Email, u.FirstName, u.SecondName, u.PasswordHash,
 				).WillReturnRows(rows)
 			},
+			user:    model.User{Username: "user1"},
+			expUser: model.User{ID: 1, Username: "user1", CreatedAt: createdAt},
+		},
+		{
+			name: "username is taken",
+			mock: func(u model.User) {
+				mock.ExpectQuery("INSERT INTO users (.+) VALUES (.+) RETURNING id, created_at;").WithArgs(
+					u.Username, u.Email, u.FirstName, u.SecondName, u.PasswordHash,
+				).WillReturnError(&pq.Error{Code: "23505", Constraint: "users_username_key"})
+			},
+			user:     model.User{Username: "user1"},
+			expError: store.ErrUsernameIsTaken,
+		},
+		{
+			name: "email is taken",
+			mock: func(u model.User) {
+				mock.ExpectQuery("INSERT INTO users (.+) VALUES (.+) RETURNING id, created_at;").WithArgs(
+					u.Username, u.Email, u.FirstName, u.SecondName, u.PasswordHash,
+				).WillReturnError(&pq.Error{Code: "23505", Constraint: "users_email_key"})
+			},
+			user:     model.User{Email: "user1@test.com"},
+			expError: store.ErrEmailIsTaken,
+		},
+		{
+			name: "driver error is returned",
+			mock: func(u model.User) {
+				mock.ExpectQuery("INSERT INTO users (.+) VALUES (.+) RETURNING id, created_at;").WithArgs(
+					u.Username, u.Email, u.FirstName, u.SecondName, u.PasswordHash,
+				).WillReturnError(errQueryCanceled)
+			},
 			user:     model.User{Username: "user1"},
-			expUser:  model.User{ID: 1, Username: "user1"},
-			expError: false,
+			expError: errQueryCanceled,
 		},
 	}
 
 	for _, tc := range testcases {
 		tc.mock(tc.user)
 
-		u, err := r.Create(tc.user)
+		u, err := r.Create(context.Background(), tc.user)
 
-		if !tc.expError {
+		if tc.expError == nil {
 			assert.NoError(t, err)
 			assert.Equal(t, tc.expUser, u)
 		} else {
-			assert.Error(t, err)
+			assert.True(t, errors.Is(err, tc.expError), err)
 		}
 	}
 }
@@ -110,7 +148,7 @@ func TestUserRepo_GetByID(t *testing.T) {
 		mock     func(model.User)
 		user     model.User
 		expUser  model.User
-		expError bool
+		expError error
 	}{
 		{
 			name: "user is retrieved by ID",
@@ -122,22 +160,31 @@ func TestUserRepo_GetByID(t *testing.T) {
 					"SELECT (.+) FROM users WHERE id = (.+);",
 				).WithArgs(u.ID).WillReturnRows(rows)
 			},
-			user:     model.User{ID: 1, Username: "user1"},
-			expUser:  model.User{ID: 1, Username: "user1"},
-			expError: false,
+			user:    model.User{ID: 1, Username: "user1"},
+			expUser: model.User{ID: 1, Username: "user1"},
+		},
+		{
+			name: "user is not found",
+			mock: func(u model.User) {
+				mock.ExpectQuery(
+					"SELECT (.+) FROM users WHERE id = (.+);",
+				).WithArgs(u.ID).WillReturnError(sql.ErrNoRows)
+			},
+			user:     model.User{ID: 2},
+			expError: store.ErrNotFound,
 		},
 	}
 
 	for _, tc := range testcases {
 		tc.mock(tc.user)
 
-		u, err := r.GetByID(tc.user.ID)
+		u, err := r.GetByID(context.Background(), tc.user.ID)
 
-		if !tc.expError {
+		if tc.expError == nil {
 			assert.NoError(t, err)
 			assert.Equal(t, tc.expUser, u)
 		} else {
-			assert.Error(t, err)
+			assert.True(t, errors.Is(err, tc.expError), err)
 		}
 	}
 }
@@ -155,7 +202,7 @@ func TestUserRepo_GetByEmail(t *testing.T) {
 		mock     func(model.User)
 		user     model.User
 		expUser  model.User
-		expError bool
+		expError error
 	}{
 		{
 			name: "user is retrieved by email",
@@ -167,22 +214,31 @@ func TestUserRepo_GetByEmail(t *testing.T) {
 					"SELECT (.+) FROM users WHERE email = (.+);",
 				).WithArgs(u.Email).WillReturnRows(rows)
 			},
-			user:     model.User{Email: "user1@test.com", Username: "user1"},
-			expUser:  model.User{Email: "user1@test.com", Username: "user1"},
-			expError: false,
+			user:    model.User{Email: "user1@test.com", Username: "user1"},
+			expUser: model.User{Email: "user1@test.com", Username: "user1"},
+		},
+		{
+			name: "user is not found",
+			mock: func(u model.User) {
+				mock.ExpectQuery(
+					"SELECT (.+) FROM users WHERE email = (.+);",
+				).WithArgs(u.Email).WillReturnError(sql.ErrNoRows)
+			},
+			user:     model.User{Email: "user2@test.com"},
+			expError: store.ErrNotFound,
 		},
 	}
 
 	for _, tc := range testcases {
 		tc.mock(tc.user)
 
-		u, err := r.GetByEmail(tc.user.Email)
+		u, err := r.GetByEmail(context.Background(), tc.user.Email)
 
-		if !tc.expError {
+		if tc.expError == nil {
 			assert.NoError(t, err)
 			assert.Equal(t, tc.expUser, u)
 		} else {
-			assert.Error(t, err)
+			assert.True(t, errors.Is(err, tc.expError), err)
 		}
 	}
 }
@@ -200,37 +256,132 @@ func TestUserRepo_Update(t *testing.T) {
 		mock     func(model.User)
 		user     model.User
 		expUser  model.User
-		expError bool
+		expError error
 	}{
 		{
 			name: "user is updated",
 			mock: func(u model.User) {
-				mock.ExpectExec(
-					"UPDATE users SET (.+) WHERE id = (.+);",
+				mock.ExpectQuery(
+					"UPDATE users SET (.+) WHERE id = (.+) RETURNING (.+);",
+				).WithArgs(
+					u.Username, u.Email, u.FirstName, u.SecondName, u.ID,
+				).WillReturnRows(
+					sqlmock.NewRows([]string{"id", "username", "password_hash"}).AddRow(
+						u.ID, u.Username, "hash",
+					),
+				)
+			},
+			user:    model.User{ID: 1, Username: "updated_user1"},
+			expUser: model.User{ID: 1, Username: "updated_user1", PasswordHash: "hash"},
+		},
+		{
+			name: "user is not found",
+			mock: func(u model.User) {
+				mock.ExpectQuery(
+					"UPDATE users SET (.+) WHERE id = (.+) RETURNING (.+);",
+				).WithArgs(
+					u.Username, u.Email, u.FirstName, u.SecondName, u.ID,
+				).WillReturnError(sql.ErrNoRows)
+			},
+			user:     model.User{ID: 2, Username: "user2"},
+			expError: store.ErrNotFound,
+		},
+		{
+			name: "username is taken",
+			mock: func(u model.User) {
+				mock.ExpectQuery(
+					"UPDATE users SET (.+) WHERE id = (.+) RETURNING (.+);",
 				).WithArgs(
-					u.Username, u.Email, u.FirstName, u.SecondName, u.PasswordHash, u.ID,
-				).WillReturnResult(sqlmock.NewResult(1, 1))
+					u.Username, u.Email, u.FirstName, u.SecondName, u.ID,
+				).WillReturnError(&pq.Error{Code: "23505", Constraint: "users_username_key"})
 			},
-			user:     model.User{ID: 1, Username: "updated_user1"},
-			expUser:  model.User{ID: 1, Username: "updated_user1"},
-			expError: false,
+			user:     model.User{ID: 2, Username: "user1"},
+			expError: store.ErrUsernameIsTaken,
 		},
 	}
 
 	for _, tc := range testcases {
 		tc.mock(tc.user)
 
-		u, err := r.Update(tc.user)
+		u, err := r.Update(context.Background(), tc.user)
 
-		if !tc.expError {
+		if tc.expError == nil {
 			assert.NoError(t, err)
 			assert.Equal(t, tc.expUser, u)
 		} else {
-			assert.Error(t, err)
+			assert.True(t, errors.Is(err, tc.expError), err)
 		}
 	}
 }
 
+func TestUserRepo_Set(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newUserRepo(sqlx.NewDb(db, "postgres"))
+	now := time.Now()
+
+	testcases := []struct {
+		name     string
+		mock     func()
+		set      func() error
+		expError error
+	}{
+		{
+			name: "password hash is set",
+			mock: func() {
+				mock.ExpectExec(
+					"UPDATE users SET password_hash = (.+) WHERE id = (.+);",
+				).WithArgs("hash", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			set: func() error { return r.SetPasswordHash(context.Background(), 1, "hash") },
+		},
+		{
+			name: "email verification time is set",
+			mock: func() {
+				mock.ExpectExec(
+					"UPDATE users SET email_verified_at = (.+) WHERE id = (.+);",
+				).WithArgs(&now, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			set: func() error { return r.SetEmailVerifiedAt(context.Background(), 1, &now) },
+		},
+		{
+			name: "disabled time is set",
+			mock: func() {
+				mock.ExpectExec(
+					"UPDATE users SET disabled_at = (.+) WHERE id = (.+);",
+				).WithArgs(nil, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			set: func() error { return r.SetDisabledAt(context.Background(), 1, nil) },
+		},
+		{
+			name: "user is not found",
+			mock: func() {
+				mock.ExpectExec(
+					"UPDATE users SET disabled_at = (.+) WHERE id = (.+);",
+				).WithArgs(&now, 2).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			set:      func() error { return r.SetDisabledAt(context.Background(), 2, &now) },
+			expError: store.ErrNotFound,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock()
+
+		err := tc.set()
+
+		if tc.expError == nil {
+			assert.NoError(t, err, tc.name)
+		} else {
+			assert.True(t, errors.Is(err, tc.expError), err)
+		}
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestUserRepo_DeleteByID(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -243,7 +394,7 @@ func TestUserRepo_DeleteByID(t *testing.T) {
 		name     string
 		mock     func(model.User)
 		user     model.User
-		expError bool
+		expError error
 	}{
 		{
 			name: "user is deleted",
@@ -252,20 +403,29 @@ func TestUserRepo_DeleteByID(t *testing.T) {
 					"DELETE FROM users WHERE id = (.+);",
 				).WithArgs(u.ID).WillReturnResult(sqlmock.NewResult(1, 1))
 			},
-			user:     model.User{ID: 1, Username: "user1"},
-			expError: false,
+			user: model.User{ID: 1, Username: "user1"},
+		},
+		{
+			name: "user is not found",
+			mock: func(u model.User) {
+				mock.ExpectExec(
+					"DELETE FROM users WHERE id = (.+);",
+				).WithArgs(u.ID).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			user:     model.User{ID: 2},
+			expError: store.ErrNotFound,
 		},
 	}
 
 	for _, tc := range testcases {
 		tc.mock(tc.user)
 
-		err := r.DeleteByID(tc.user.ID)
+		err := r.DeleteByID(context.Background(), tc.user.ID)
 
-		if !tc.expError {
+		if tc.expError == nil {
 			assert.NoError(t, err)
 		} else {
-			assert.Error(t, err)
+			assert.True(t, errors.Is(err, tc.expError), err)
 		}
 	}
 }
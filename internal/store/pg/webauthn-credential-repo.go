@@ -0,0 +1,113 @@
+package pg
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// webAuthnCredentialRepo is the WebAuthn credential repository for PostgreSQL store.
+type webAuthnCredentialRepo struct {
+	db *sqlx.DB
+}
+
+// newWebAuthnCredentialRepo creates and returns a new webAuthnCredentialRepo instance.
+func newWebAuthnCredentialRepo(db *sqlx.DB) *webAuthnCredentialRepo {
+	return &webAuthnCredentialRepo{db: db}
+}
+
+// Create creates and returns a new WebAuthn credential.
+func (r *webAuthnCredentialRepo) Create(
+	ctx context.Context, c model.WebAuthnCredential,
+) (model.WebAuthnCredential, error) {
+	query := "INSERT INTO webauthn_credentials (id, user_id, name, public_key, sign_count) "
+	query += "VALUES ($1, $2, $3, $4, $5) RETURNING created_at;"
+	row := r.db.QueryRowContext(ctx, query, c.ID, c.UserID, c.Name, c.PublicKey, c.SignCount)
+	err := row.Scan(&c.CreatedAt)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+		return model.WebAuthnCredential{}, store.ErrNotFound
+	} else if ok && pqErr.Code == "23505" {
+		return model.WebAuthnCredential{}, store.ErrCredentialIsTaken
+	} else if err != nil {
+		return model.WebAuthnCredential{}, wrapError("create WebAuthn credential", err)
+	}
+
+	return c, nil
+}
+
+// GetByID returns the WebAuthn credential with specific ID.
+func (r *webAuthnCredentialRepo) GetByID(
+	ctx context.Context, id string,
+) (model.WebAuthnCredential, error) {
+	c := model.WebAuthnCredential{}
+	query := "SELECT * FROM webauthn_credentials WHERE id = $1;"
+	if err := r.db.GetContext(ctx, &c, query, id); err != nil {
+		return model.WebAuthnCredential{}, wrapError("get WebAuthn credential", err)
+	}
+
+	return c, nil
+}
+
+// GetByUserID returns WebAuthn credentials of the user with specific ID in order of
+// creation.
+func (r *webAuthnCredentialRepo) GetByUserID(
+	ctx context.Context, userID int,
+) ([]model.WebAuthnCredential, error) {
+	credentials := []model.WebAuthnCredential{}
+	query := "SELECT * FROM webauthn_credentials WHERE user_id = $1 ORDER BY created_at, id;"
+	if err := r.db.SelectContext(ctx, &credentials, query, userID); err != nil {
+		return []model.WebAuthnCredential{}, wrapError("get WebAuthn credentials", err)
+	}
+
+	return credentials, nil
+}
+
+// MarkUsed sets the sign count of the WebAuthn credential with specific ID. It returns
+// store.ErrTokenIsUsed if the sign count doesn't grow, counters which stay at zero are
+// accepted since some authenticators don't count signatures.
+func (r *webAuthnCredentialRepo) MarkUsed(ctx context.Context, id string, signCount int64) error {
+	query := "UPDATE webauthn_credentials SET sign_count = $2, last_used_at = NOW() "
+	query += "WHERE id = $1 AND (sign_count < $2 OR sign_count = 0 AND $2 = 0);"
+	res, err := r.db.ExecContext(ctx, query, id, signCount)
+	if err != nil {
+		return wrapError("mark WebAuthn credential used", err)
+	}
+
+	rowsCount, err := res.RowsAffected()
+	if err != nil {
+		return wrapError("mark WebAuthn credential used", err)
+	} else if rowsCount == 0 {
+		var exists bool
+		query := "SELECT EXISTS(SELECT 1 FROM webauthn_credentials WHERE id = $1);"
+		if err := r.db.GetContext(ctx, &exists, query, id); err != nil {
+			return wrapError("mark WebAuthn credential used", err)
+		} else if !exists {
+			return store.ErrNotFound
+		}
+		return store.ErrTokenIsUsed
+	}
+
+	return nil
+}
+
+// Delete deletes the WebAuthn credential with specific ID of the user with specific ID.
+func (r *webAuthnCredentialRepo) Delete(ctx context.Context, userID int, id string) error {
+	query := "DELETE FROM webauthn_credentials WHERE id = $1 AND user_id = $2;"
+	res, err := r.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return wrapError("delete WebAuthn credential", err)
+	}
+
+	rowsCount, err := res.RowsAffected()
+	if err != nil {
+		return wrapError("delete WebAuthn credential", err)
+	} else if rowsCount == 0 {
+		return store.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+func TestWebAuthnCredentialRepo_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newWebAuthnCredentialRepo(sqlx.NewDb(db, "postgres"))
+	c := model.WebAuthnCredential{ID: "aWQ", UserID: 1, Name: "Passkey", PublicKey: []byte{1}}
+
+	testcases := []struct {
+		name     string
+		mock     func()
+		expError error
+	}{
+		{
+			name: "credential is created",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now())
+				mock.ExpectQuery("INSERT INTO webauthn_credentials (.+) RETURNING created_at;").WithArgs(
+					c.ID, c.UserID, c.Name, c.PublicKey, c.SignCount,
+				).WillReturnRows(rows)
+			},
+		},
+		{
+			name: "credential is already registered",
+			mock: func() {
+				mock.ExpectQuery("INSERT INTO webauthn_credentials (.+) RETURNING created_at;").WithArgs(
+					c.ID, c.UserID, c.Name, c.PublicKey, c.SignCount,
+				).WillReturnError(&pq.Error{Code: "23505"})
+			},
+			expError: store.ErrCredentialIsTaken,
+		},
+		{
+			name: "user is not found",
+			mock: func() {
+				mock.ExpectQuery("INSERT INTO webauthn_credentials (.+) RETURNING created_at;").WithArgs(
+					c.ID, c.UserID, c.Name, c.PublicKey, c.SignCount,
+				).WillReturnError(&pq.Error{Code: "23503"})
+			},
+			expError: store.ErrNotFound,
+		},
+		{
+			name: "driver error is returned",
+			mock: func() {
+				mock.ExpectQuery("INSERT INTO webauthn_credentials (.+) RETURNING created_at;").WithArgs(
+					c.ID, c.UserID, c.Name, c.PublicKey, c.SignCount,
+				).WillReturnError(errQueryCanceled)
+			},
+			expError: errQueryCanceled,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock()
+
+		cred, err := r.Create(context.Background(), c)
+
+		if tc.expError == nil {
+			assert.NoError(t, err)
+			assert.Equal(t, c.ID, cred.ID)
+			assert.False(t, cred.CreatedAt.IsZero())
+		} else {
+			assert.True(t, errors.Is(err, tc.expError), err)
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestWebAuthnCredentialRepo_MarkUsed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := newWebAuthnCredentialRepo(sqlx.NewDb(db, "postgres"))
+
+	testcases := []struct {
+		name     string
+		mock     func(string, int64)
+		id       string
+		count    int64
+		expError error
+	}{
+		{
+			name: "sign count is updated",
+			mock: func(id string, count int64) {
+				mock.ExpectExec("UPDATE webauthn_credentials SET (.+) WHERE (.+);").WithArgs(
+					id, count,
+				).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			id:    "aWQ",
+			count: 2,
+		},
+		{
+			name: "sign count didn't grow",
+			mock: func(id string, count int64) {
+				mock.ExpectExec("UPDATE webauthn_credentials SET (.+) WHERE (.+);").WithArgs(
+					id, count,
+				).WillReturnResult(sqlmock.NewResult(0, 0))
+				rows := sqlmock.NewRows([]string{"exists"}).AddRow(true)
+				mock.ExpectQuery("SELECT EXISTS(.+);").WithArgs(id).WillReturnRows(rows)
+			},
+			id:       "aWQ",
+			count:    1,
+			expError: store.ErrTokenIsUsed,
+		},
+		{
+			name: "credential is not found",
+			mock: func(id string, count int64) {
+				mock.ExpectExec("UPDATE webauthn_credentials SET (.+) WHERE (.+);").WithArgs(
+					id, count,
+				).WillReturnResult(sqlmock.NewResult(0, 0))
+				rows := sqlmock.NewRows([]string{"exists"}).AddRow(false)
+				mock.ExpectQuery("SELECT EXISTS(.+);").WithArgs(id).WillReturnRows(rows)
+			},
+			id:       "b3RoZXI",
+			count:    1,
+			expError: store.ErrNotFound,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc.mock(tc.id, tc.count)
+
+		err := r.MarkUsed(context.Background(), tc.id, tc.count)
+
+		assert.Equal(t, tc.expError, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
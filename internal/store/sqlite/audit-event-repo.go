@@ -0,0 +1,45 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+)
+
+// auditEventRepo is the audit event repository for SQLite store.
+type auditEventRepo struct {
+	db *sqlx.DB
+}
+
+// newAuditEventRepo creates and returns a new auditEventRepo instance.
+func newAuditEventRepo(db *sqlx.DB) *auditEventRepo { return &auditEventRepo{db: db} }
+
+// Create creates and returns a new audit event.
+func (r *auditEventRepo) Create(ctx context.Context, e model.AuditEvent) (model.AuditEvent, error) {
+	e.CreatedAt = now()
+	query := "INSERT INTO audit_events (actor_id, action, user_id, created_at) VALUES (?, ?, ?, ?);"
+	res, err := r.db.ExecContext(ctx, query, e.ActorID, e.Action, e.UserID, e.CreatedAt)
+	if err != nil {
+		return model.AuditEvent{}, wrapError("create audit event", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return model.AuditEvent{}, wrapError("create audit event", err)
+	}
+	e.ID = int(id)
+
+	return e, nil
+}
+
+// GetByUserID returns audit events of the user with specific ID in order of creation.
+func (r *auditEventRepo) GetByUserID(ctx context.Context, userID int) ([]model.AuditEvent, error) {
+	events := []model.AuditEvent{}
+	query := "SELECT * FROM audit_events WHERE user_id = ? ORDER BY id;"
+	if err := r.db.SelectContext(ctx, &events, query, userID); err != nil {
+		return []model.AuditEvent{}, wrapError("get audit events", err)
+	}
+
+	return events, nil
+}
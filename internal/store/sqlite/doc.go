@@ -0,0 +1,2 @@
+// Package sqlite provides SQLite store, it's meant for small single node deployments.
+package sqlite
@@ -0,0 +1,52 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mattn/go-sqlite3"
+
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// wrapError maps sql.ErrNoRows onto store.ErrNotFound, other driver errors are wrapped
+// with the operation, so they can still be inspected with errors.As.
+func wrapError(op string, err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return store.ErrNotFound
+	}
+
+	return fmt.Errorf("%s: %w", op, err)
+}
+
+// userError maps unique constraint errors of users table onto store errors, other
+// errors are wrapped with wrapError.
+func userError(op string, err error) error {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+		if strings.Contains(sqliteErr.Error(), "users.username") {
+			return store.ErrUsernameIsTaken
+		} else if strings.Contains(sqliteErr.Error(), "users.email") {
+			return store.ErrEmailIsTaken
+		}
+	}
+
+	return wrapError(op, err)
+}
+
+// isForeignKeyError reports whether the error is a foreign key constraint error.
+func isForeignKeyError(err error) bool {
+	var sqliteErr sqlite3.Error
+
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintForeignKey
+}
+
+// isUniqueError reports whether the error is a unique or primary key constraint error.
+func isUniqueError(err error) bool {
+	var sqliteErr sqlite3.Error
+
+	return errors.As(err, &sqliteErr) && (sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique ||
+		sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey)
+}
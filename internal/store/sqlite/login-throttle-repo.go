@@ -0,0 +1,92 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+)
+
+// loginThrottleRepo is the login throttle repository for SQLite store.
+type loginThrottleRepo struct {
+	db *sqlx.DB
+}
+
+// newLoginThrottleRepo creates and returns a new loginThrottleRepo instance.
+func newLoginThrottleRepo(db *sqlx.DB) *loginThrottleRepo { return &loginThrottleRepo{db: db} }
+
+// Get returns the login throttle with specific key. A throttle without failures is
+// returned if there were no failures.
+func (r *loginThrottleRepo) Get(ctx context.Context, key string) (model.LoginThrottle, error) {
+	t := model.LoginThrottle{}
+	err := r.db.GetContext(ctx, &t, "SELECT * FROM login_throttles WHERE key = ?;", key)
+	if err == sql.ErrNoRows {
+		return model.LoginThrottle{Key: key}, nil
+	} else if err != nil {
+		return model.LoginThrottle{}, err
+	}
+
+	return t, nil
+}
+
+// RegisterFailure registers a failed sign in attempt and returns the updated login
+// throttle. Failures registered before since are forgotten.
+func (r *loginThrottleRepo) RegisterFailure(
+	ctx context.Context, key string, since time.Time,
+) (model.LoginThrottle, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return model.LoginThrottle{}, err
+	}
+	defer tx.Rollback()
+
+	// SQLite in use doesn't support RETURNING, so the throttle is selected in the
+	// same transaction.
+	query := "INSERT INTO login_throttles (key, failures, last_failure_at) VALUES (?1, 1, ?3) "
+	query += "ON CONFLICT (key) DO UPDATE SET failures = CASE "
+	query += "WHEN login_throttles.last_failure_at < ?2 THEN 1 "
+	query += "ELSE login_throttles.failures + 1 END, last_failure_at = ?3;"
+	if _, err := tx.ExecContext(ctx, query, key, since.UTC(), now()); err != nil {
+		return model.LoginThrottle{}, err
+	}
+
+	t := model.LoginThrottle{}
+	if err := tx.GetContext(ctx, &t, "SELECT * FROM login_throttles WHERE key = ?;", key); err != nil {
+		return model.LoginThrottle{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return model.LoginThrottle{}, err
+	}
+
+	return t, nil
+}
+
+// Lock locks the login throttle with specific key until specific time, its failures
+// are reset.
+func (r *loginThrottleRepo) Lock(ctx context.Context, key string, until time.Time) error {
+	query := "UPDATE login_throttles SET failures = 0, locked_until = ? WHERE key = ?;"
+	_, err := r.db.ExecContext(ctx, query, until.UTC(), key)
+
+	return err
+}
+
+// Delete deletes the login throttle with specific key, so its failures and lock are
+// forgotten.
+func (r *loginThrottleRepo) Delete(ctx context.Context, key string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM login_throttles WHERE key = ?;", key)
+
+	return err
+}
+
+// DeleteStale deletes unlocked login throttles without failures since specific time.
+func (r *loginThrottleRepo) DeleteStale(ctx context.Context, before time.Time) error {
+	query := "DELETE FROM login_throttles WHERE last_failure_at < ? "
+	query += "AND (locked_until IS NULL OR locked_until < ?);"
+	_, err := r.db.ExecContext(ctx, query, before.UTC(), now())
+
+	return err
+}
@@ -0,0 +1,12 @@
+DROP TABLE rate_limits;
+DROP TABLE login_throttles;
+DROP TABLE email_verification_tokens;
+DROP TABLE password_reset_tokens;
+DROP TABLE user_roles;
+DROP TABLE role_permissions;
+DROP TABLE permissions;
+DROP TABLE roles;
+DROP TABLE signing_keys;
+DROP TABLE revoked_tokens;
+DROP TABLE refresh_tokens;
+DROP TABLE users;
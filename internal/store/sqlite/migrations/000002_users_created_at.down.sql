@@ -0,0 +1,4 @@
+DROP INDEX users_created_at_idx;
+
+-- SQLite in use can't drop columns and rebuilding users would cascade deletes to its
+-- dependent tables, so created_at is left in place.
@@ -0,0 +1,5 @@
+ALTER TABLE users ADD COLUMN created_at TIMESTAMP;
+
+UPDATE users SET created_at = strftime('%Y-%m-%d %H:%M:%S+00:00', 'now');
+
+CREATE INDEX users_created_at_idx ON users (created_at, id);
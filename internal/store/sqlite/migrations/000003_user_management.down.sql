@@ -0,0 +1,3 @@
+DROP TABLE audit_events;
+
+-- SQLite in use can't drop columns, so disabled_at is left in place.
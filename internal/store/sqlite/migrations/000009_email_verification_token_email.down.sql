@@ -0,0 +1,15 @@
+-- SQLite in use can't drop columns, nothing depends on email_verification_tokens, so
+-- it's rebuilt without email.
+CREATE TABLE email_verification_tokens_old (
+    hash VARCHAR(64) PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP
+);
+
+INSERT INTO email_verification_tokens_old (hash, user_id, expires_at, used_at)
+SELECT hash, user_id, expires_at, used_at FROM email_verification_tokens;
+
+DROP TABLE email_verification_tokens;
+
+ALTER TABLE email_verification_tokens_old RENAME TO email_verification_tokens;
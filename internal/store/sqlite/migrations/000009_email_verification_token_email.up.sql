@@ -0,0 +1,4 @@
+-- Tokens issued before the address was recorded can't be tied to it, so they're dropped.
+DELETE FROM email_verification_tokens;
+
+ALTER TABLE email_verification_tokens ADD COLUMN email VARCHAR(100) NOT NULL DEFAULT '';
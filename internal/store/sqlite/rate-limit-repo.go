@@ -0,0 +1,70 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// rateLimitRepo is the rate limit token bucket repository for SQLite store.
+type rateLimitRepo struct {
+	db *sqlx.DB
+}
+
+// newRateLimitRepo creates and returns a new rateLimitRepo instance.
+func newRateLimitRepo(db *sqlx.DB) *rateLimitRepo { return &rateLimitRepo{db: db} }
+
+// bucketRow is the token bucket as it's stored.
+type bucketRow struct {
+	Tokens    float64   `db:"tokens"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// Take takes a token from the bucket with specific key which holds up to limit tokens
+// and is refilled with limit tokens per period. It returns tokens left in the bucket
+// and whether the token was taken. The bucket is left untouched if it's empty.
+func (r *rateLimitRepo) Take(
+	ctx context.Context, key string, limit int, period time.Duration,
+) (float64, bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, false, err
+	}
+	defer tx.Rollback()
+
+	t := now()
+	tokens := float64(limit)
+	b := bucketRow{}
+	err = tx.GetContext(ctx, &b, "SELECT tokens, updated_at FROM rate_limits WHERE key = ?;", key)
+	if err == nil {
+		perSecond := float64(limit) / period.Seconds()
+		tokens = math.Min(tokens, b.Tokens+t.Sub(b.UpdatedAt).Seconds()*perSecond)
+	} else if err != sql.ErrNoRows {
+		return 0, false, err
+	}
+	if tokens < 1 {
+		return tokens, false, nil
+	}
+
+	query := "INSERT INTO rate_limits (key, tokens, updated_at) VALUES (?1, ?2, ?3) "
+	query += "ON CONFLICT (key) DO UPDATE SET tokens = ?2, updated_at = ?3;"
+	if _, err := tx.ExecContext(ctx, query, key, tokens-1, t); err != nil {
+		return 0, false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, false, err
+	}
+
+	return tokens - 1, true, nil
+}
+
+// DeleteStale deletes buckets which haven't been updated since specific time.
+func (r *rateLimitRepo) DeleteStale(ctx context.Context, before time.Time) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM rate_limits WHERE updated_at < ?;", before.UTC())
+
+	return err
+}
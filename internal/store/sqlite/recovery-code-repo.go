@@ -0,0 +1,95 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// recoveryCodeRepo is the MFA recovery code repository for SQLite store.
+type recoveryCodeRepo struct {
+	db *sqlx.DB
+}
+
+// newRecoveryCodeRepo creates and returns a new recoveryCodeRepo instance.
+func newRecoveryCodeRepo(db *sqlx.DB) *recoveryCodeRepo { return &recoveryCodeRepo{db: db} }
+
+// Replace replaces all recovery codes of the user with specific ID with the codes
+// having specific hashes.
+func (r *recoveryCodeRepo) Replace(ctx context.Context, userID int, hashes []string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return wrapError("replace recovery codes", err)
+	}
+	defer tx.Rollback()
+
+	query := "DELETE FROM recovery_codes WHERE user_id = ?;"
+	if _, err := tx.ExecContext(ctx, query, userID); err != nil {
+		return wrapError("replace recovery codes", err)
+	}
+	query = "INSERT INTO recovery_codes (user_id, hash) VALUES (?, ?);"
+	for _, hash := range hashes {
+		_, err := tx.ExecContext(ctx, query, userID, hash)
+		if isForeignKeyError(err) {
+			return store.ErrNotFound
+		} else if err != nil {
+			return wrapError("replace recovery codes", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return wrapError("replace recovery codes", err)
+	}
+
+	return nil
+}
+
+// Use marks the recovery code with specific hash of the user with specific ID as used.
+// It returns store.ErrTokenIsUsed if the code has already been used.
+func (r *recoveryCodeRepo) Use(ctx context.Context, userID int, hash string) error {
+	query := "UPDATE recovery_codes SET used_at = ? "
+	query += "WHERE user_id = ? AND hash = ? AND used_at IS NULL;"
+	res, err := r.db.ExecContext(ctx, query, now(), userID, hash)
+	if err != nil {
+		return wrapError("use recovery code", err)
+	}
+
+	rowsCount, err := res.RowsAffected()
+	if err != nil {
+		return wrapError("use recovery code", err)
+	} else if rowsCount == 0 {
+		var exists bool
+		query := "SELECT EXISTS(SELECT 1 FROM recovery_codes WHERE user_id = ? AND hash = ?);"
+		if err := r.db.GetContext(ctx, &exists, query, userID, hash); err != nil {
+			return wrapError("use recovery code", err)
+		} else if !exists {
+			return store.ErrNotFound
+		}
+		return store.ErrTokenIsUsed
+	}
+
+	return nil
+}
+
+// CountUnused returns the number of unused recovery codes of the user with specific ID.
+func (r *recoveryCodeRepo) CountUnused(ctx context.Context, userID int) (int, error) {
+	var count int
+	query := "SELECT COUNT(*) FROM recovery_codes WHERE user_id = ? AND used_at IS NULL;"
+	if err := r.db.GetContext(ctx, &count, query, userID); err != nil {
+		return 0, wrapError("count recovery codes", err)
+	}
+
+	return count, nil
+}
+
+// DeleteByUserID deletes all recovery codes of the user with specific ID.
+func (r *recoveryCodeRepo) DeleteByUserID(ctx context.Context, userID int) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM recovery_codes WHERE user_id = ?;", userID)
+	if err != nil {
+		return wrapError("delete recovery codes", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// refreshTokenRepo is the refresh token repository for SQLite store.
+type refreshTokenRepo struct {
+	db *sqlx.DB
+}
+
+// newRefreshTokenRepo creates and returns a new refreshTokenRepo instance.
+func newRefreshTokenRepo(db *sqlx.DB) *refreshTokenRepo { return &refreshTokenRepo{db: db} }
+
+// Create creates and returns a new refresh token.
+func (r *refreshTokenRepo) Create(
+	ctx context.Context, t model.RefreshToken,
+) (model.RefreshToken, error) {
+	t.ExpiresAt = t.ExpiresAt.UTC()
+	query := "INSERT INTO refresh_tokens (id, family_id, user_id, expires_at) VALUES (?, ?, ?, ?);"
+	if _, err := r.db.ExecContext(ctx, query, t.ID, t.FamilyID, t.UserID, t.ExpiresAt); err != nil {
+		return model.RefreshToken{}, err
+	}
+
+	return t, nil
+}
+
+// GetByID returns the refresh token with specific ID.
+func (r *refreshTokenRepo) GetByID(ctx context.Context, id string) (model.RefreshToken, error) {
+	t := model.RefreshToken{}
+	if err := r.db.GetContext(ctx, &t, "SELECT * FROM refresh_tokens WHERE id = ?;", id); err != nil {
+		return model.RefreshToken{}, wrapError("get refresh token", err)
+	}
+
+	return t, nil
+}
+
+// MarkUsed marks the refresh token with specific ID as used. It returns
+// store.ErrTokenIsUsed if the token has already been used before.
+func (r *refreshTokenRepo) MarkUsed(ctx context.Context, id string) error {
+	query := "UPDATE refresh_tokens SET used_at = ? WHERE id = ? AND used_at IS NULL;"
+	res, err := r.db.ExecContext(ctx, query, now(), id)
+	if err != nil {
+		return err
+	}
+
+	rowsCount, err := res.RowsAffected()
+	if err != nil {
+		return err
+	} else if rowsCount == 0 {
+		var exists bool
+		query := "SELECT EXISTS(SELECT 1 FROM refresh_tokens WHERE id = ?);"
+		if err := r.db.GetContext(ctx, &exists, query, id); err != nil {
+			return err
+		} else if !exists {
+			return store.ErrNotFound
+		}
+		return store.ErrTokenIsUsed
+	}
+
+	return nil
+}
+
+// RevokeFamily revokes all refresh tokens of the family with specific ID.
+func (r *refreshTokenRepo) RevokeFamily(ctx context.Context, familyID string) error {
+	query := "UPDATE refresh_tokens SET revoked_at = ? WHERE family_id = ? AND revoked_at IS NULL;"
+	_, err := r.db.ExecContext(ctx, query, now(), familyID)
+
+	return err
+}
+
+// RevokeByUserID revokes all refresh tokens of the user with specific ID.
+func (r *refreshTokenRepo) RevokeByUserID(ctx context.Context, userID int) error {
+	query := "UPDATE refresh_tokens SET revoked_at = ? WHERE user_id = ? AND revoked_at IS NULL;"
+	_, err := r.db.ExecContext(ctx, query, now(), userID)
+
+	return err
+}
+
+// DeleteExpired deletes all refresh tokens which have already expired.
+func (r *refreshTokenRepo) DeleteExpired(ctx context.Context) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM refresh_tokens WHERE expires_at < ?;", now())
+
+	return err
+}
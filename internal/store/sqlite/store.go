@@ -0,0 +1,215 @@
+package sqlite
+
+import (
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/sqlite3"
+	_ "github.com/golang-migrate/migrate/v4/source/file" // Migrate driver.
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3" // SQLite driver.
+
+	"github.com/imarrche/jwt-auth-example/internal/config"
+	"github.com/imarrche/jwt-auth-example/internal/logger"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// Store is SQLite store.
+type Store struct {
+	config           *config.SQLite
+	migrations       string
+	db               *sqlx.DB
+	userRepo         *userRepo
+	refreshTokenRepo *refreshTokenRepo
+	revokedTokenRepo *revokedTokenRepo
+	signingKeyRepo   *signingKeyRepo
+	roleRepo         *roleRepo
+	resetTokenRepo   *passwordResetTokenRepo
+	verifyTokenRepo  *emailVerificationTokenRepo
+	throttleRepo     *loginThrottleRepo
+	rateLimitRepo    *rateLimitRepo
+	auditEventRepo   *auditEventRepo
+	totpSecretRepo   *totpSecretRepo
+	recoveryCodeRepo *recoveryCodeRepo
+	credentialRepo   *webAuthnCredentialRepo
+	magicLinkRepo    *magicLinkTokenRepo
+	oneTimeCodeRepo  *oneTimeCodeRepo
+}
+
+// New returns new Store instance.
+func New(c *config.SQLite) *Store {
+	return &Store{config: c, migrations: "file://internal/store/sqlite/migrations"}
+}
+
+// Open opens the database file, it's created if it doesn't exist.
+func (s *Store) Open() error {
+	// Connecting. SQLite allows a single writer, so a single connection is used to
+	// avoid busy errors.
+	db, err := sqlx.Connect("sqlite3", s.config.Path+"?_foreign_keys=1&_busy_timeout=5000")
+	if err != nil {
+		return err
+	}
+	db.SetMaxOpenConns(1)
+
+	// Running migrations.
+	driver, err := sqlite3.WithInstance(db.DB, &sqlite3.Config{})
+	if err != nil {
+		return err
+	}
+	m, err := migrate.NewWithDatabaseInstance(s.migrations, "sqlite3", driver)
+	if err != nil {
+		return err
+	}
+	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
+		return err
+	}
+	logger.Get().Info("migrated SQLite")
+
+	s.db = db
+
+	return nil
+}
+
+// Users returns the users repository.
+func (s *Store) Users() store.UserRepo {
+	if s.userRepo == nil {
+		s.userRepo = newUserRepo(s.db)
+	}
+
+	return s.userRepo
+}
+
+// RefreshTokens returns the refresh tokens repository.
+func (s *Store) RefreshTokens() store.RefreshTokenRepo {
+	if s.refreshTokenRepo == nil {
+		s.refreshTokenRepo = newRefreshTokenRepo(s.db)
+	}
+
+	return s.refreshTokenRepo
+}
+
+// RevokedTokens returns the revoked tokens repository.
+func (s *Store) RevokedTokens() store.RevokedTokenRepo {
+	if s.revokedTokenRepo == nil {
+		s.revokedTokenRepo = newRevokedTokenRepo(s.db)
+	}
+
+	return s.revokedTokenRepo
+}
+
+// SigningKeys returns the signing keys repository.
+func (s *Store) SigningKeys() store.SigningKeyRepo {
+	if s.signingKeyRepo == nil {
+		s.signingKeyRepo = newSigningKeyRepo(s.db)
+	}
+
+	return s.signingKeyRepo
+}
+
+// Roles returns the roles repository.
+func (s *Store) Roles() store.RoleRepo {
+	if s.roleRepo == nil {
+		s.roleRepo = newRoleRepo(s.db)
+	}
+
+	return s.roleRepo
+}
+
+// PasswordResetTokens returns the password reset tokens repository.
+func (s *Store) PasswordResetTokens() store.PasswordResetTokenRepo {
+	if s.resetTokenRepo == nil {
+		s.resetTokenRepo = newPasswordResetTokenRepo(s.db)
+	}
+
+	return s.resetTokenRepo
+}
+
+// EmailVerificationTokens returns the email verification tokens repository.
+func (s *Store) EmailVerificationTokens() store.EmailVerificationTokenRepo {
+	if s.verifyTokenRepo == nil {
+		s.verifyTokenRepo = newEmailVerificationTokenRepo(s.db)
+	}
+
+	return s.verifyTokenRepo
+}
+
+// LoginThrottles returns the login throttles repository.
+func (s *Store) LoginThrottles() store.LoginThrottleRepo {
+	if s.throttleRepo == nil {
+		s.throttleRepo = newLoginThrottleRepo(s.db)
+	}
+
+	return s.throttleRepo
+}
+
+// RateLimits returns the rate limit token buckets repository.
+func (s *Store) RateLimits() store.RateLimitRepo {
+	if s.rateLimitRepo == nil {
+		s.rateLimitRepo = newRateLimitRepo(s.db)
+	}
+
+	return s.rateLimitRepo
+}
+
+// AuditEvents returns the audit events repository.
+func (s *Store) AuditEvents() store.AuditEventRepo {
+	if s.auditEventRepo == nil {
+		s.auditEventRepo = newAuditEventRepo(s.db)
+	}
+
+	return s.auditEventRepo
+}
+
+// TOTPSecrets returns the TOTP secrets repository.
+func (s *Store) TOTPSecrets() store.TOTPSecretRepo {
+	if s.totpSecretRepo == nil {
+		s.totpSecretRepo = newTOTPSecretRepo(s.db)
+	}
+
+	return s.totpSecretRepo
+}
+
+// RecoveryCodes returns the MFA recovery codes repository.
+func (s *Store) RecoveryCodes() store.RecoveryCodeRepo {
+	if s.recoveryCodeRepo == nil {
+		s.recoveryCodeRepo = newRecoveryCodeRepo(s.db)
+	}
+
+	return s.recoveryCodeRepo
+}
+
+// WebAuthnCredentials returns the WebAuthn credentials repository.
+func (s *Store) WebAuthnCredentials() store.WebAuthnCredentialRepo {
+	if s.credentialRepo == nil {
+		s.credentialRepo = newWebAuthnCredentialRepo(s.db)
+	}
+
+	return s.credentialRepo
+}
+
+// MagicLinkTokens returns the magic link tokens repository.
+func (s *Store) MagicLinkTokens() store.MagicLinkTokenRepo {
+	if s.magicLinkRepo == nil {
+		s.magicLinkRepo = newMagicLinkTokenRepo(s.db)
+	}
+
+	return s.magicLinkRepo
+}
+
+// OneTimeCodes returns the one-time codes repository.
+func (s *Store) OneTimeCodes() store.OneTimeCodeRepo {
+	if s.oneTimeCodeRepo == nil {
+		s.oneTimeCodeRepo = newOneTimeCodeRepo(s.db)
+	}
+
+	return s.oneTimeCodeRepo
+}
+
+// Close closes the database file.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// now returns current time in UTC. Times are stored as text, so they must share the
+// same time zone to be compared.
+func now() time.Time { return time.Now().UTC() }
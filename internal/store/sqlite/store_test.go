@@ -0,0 +1,41 @@
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imarrche/jwt-auth-example/internal/config"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+	"github.com/imarrche/jwt-auth-example/internal/store/storetest"
+)
+
+// newTestStore returns opened store backed by a temporary database file.
+func newTestStore(t *testing.T) *Store {
+	s := New(&config.SQLite{Path: filepath.Join(t.TempDir(), "test.db")})
+	s.migrations = "file://migrations"
+	require.NoError(t, s.Open())
+	t.Cleanup(func() { s.Close() })
+
+	return s
+}
+
+func TestStore(t *testing.T) {
+	var s store.Store = New(&config.SQLite{Path: filepath.Join(t.TempDir(), "test.db")})
+	s.(*Store).migrations = "file://migrations"
+
+	assert.NoError(t, s.Open())
+	assert.Equal(t, s.Users(), s.Users())
+	assert.Equal(t, s.Roles(), s.Roles())
+	assert.NoError(t, s.Close())
+
+	// Migrations aren't applied twice.
+	assert.NoError(t, s.Open())
+	assert.NoError(t, s.Close())
+}
+
+func TestStore_Conformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.Store { return newTestStore(t) })
+}
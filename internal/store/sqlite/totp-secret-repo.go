@@ -0,0 +1,111 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// totpSecretRepo is the TOTP secret repository for SQLite store.
+type totpSecretRepo struct {
+	db *sqlx.DB
+}
+
+// newTOTPSecretRepo creates and returns a new totpSecretRepo instance.
+func newTOTPSecretRepo(db *sqlx.DB) *totpSecretRepo { return &totpSecretRepo{db: db} }
+
+// Save creates the TOTP secret of the user or replaces the existing one.
+func (r *totpSecretRepo) Save(ctx context.Context, s model.TOTPSecret) error {
+	if s.ConfirmedAt != nil {
+		confirmedAt := s.ConfirmedAt.UTC()
+		s.ConfirmedAt = &confirmedAt
+	}
+	query := "INSERT INTO totp_secrets (user_id, secret, confirmed_at, last_used_step) "
+	query += "VALUES (?, ?, ?, ?) ON CONFLICT (user_id) DO UPDATE SET "
+	query += "secret = excluded.secret, confirmed_at = excluded.confirmed_at, "
+	query += "last_used_step = excluded.last_used_step;"
+	_, err := r.db.ExecContext(ctx, query, s.UserID, s.Secret, s.ConfirmedAt, s.LastUsedStep)
+	if isForeignKeyError(err) {
+		return store.ErrNotFound
+	} else if err != nil {
+		return wrapError("save TOTP secret", err)
+	}
+
+	return nil
+}
+
+// GetByUserID returns the TOTP secret of the user with specific ID.
+func (r *totpSecretRepo) GetByUserID(ctx context.Context, userID int) (model.TOTPSecret, error) {
+	s := model.TOTPSecret{}
+	query := "SELECT * FROM totp_secrets WHERE user_id = ?;"
+	if err := r.db.GetContext(ctx, &s, query, userID); err != nil {
+		return model.TOTPSecret{}, wrapError("get TOTP secret", err)
+	}
+
+	return s, nil
+}
+
+// Confirm confirms the TOTP secret of the user with specific ID.
+func (r *totpSecretRepo) Confirm(ctx context.Context, userID int) error {
+	query := "UPDATE totp_secrets SET confirmed_at = ? WHERE user_id = ?;"
+	res, err := r.db.ExecContext(ctx, query, now(), userID)
+	if err != nil {
+		return wrapError("confirm TOTP secret", err)
+	}
+
+	rowsCount, err := res.RowsAffected()
+	if err != nil {
+		return wrapError("confirm TOTP secret", err)
+	} else if rowsCount == 0 {
+		return store.ErrNotFound
+	}
+
+	return nil
+}
+
+// MarkUsed marks the time step of the code accepted for the user with specific ID as
+// used. It returns store.ErrTokenIsUsed if the same or a later step has already been
+// used before.
+func (r *totpSecretRepo) MarkUsed(ctx context.Context, userID int, step int64) error {
+	query := "UPDATE totp_secrets SET last_used_step = ?2 WHERE user_id = ?1 AND last_used_step < ?2;"
+	res, err := r.db.ExecContext(ctx, query, userID, step)
+	if err != nil {
+		return wrapError("mark TOTP step used", err)
+	}
+
+	rowsCount, err := res.RowsAffected()
+	if err != nil {
+		return wrapError("mark TOTP step used", err)
+	} else if rowsCount == 0 {
+		var exists bool
+		query := "SELECT EXISTS(SELECT 1 FROM totp_secrets WHERE user_id = ?);"
+		if err := r.db.GetContext(ctx, &exists, query, userID); err != nil {
+			return wrapError("mark TOTP step used", err)
+		} else if !exists {
+			return store.ErrNotFound
+		}
+		return store.ErrTokenIsUsed
+	}
+
+	return nil
+}
+
+// DeleteByUserID deletes the TOTP secret of the user with specific ID.
+func (r *totpSecretRepo) DeleteByUserID(ctx context.Context, userID int) error {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM totp_secrets WHERE user_id = ?;", userID)
+	if err != nil {
+		return wrapError("delete TOTP secret", err)
+	}
+
+	rowsCount, err := res.RowsAffected()
+	if err != nil {
+		return wrapError("delete TOTP secret", err)
+	} else if rowsCount == 0 {
+		return store.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,261 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// userRepo is the user repository for SQLite store.
+type userRepo struct {
+	db *sqlx.DB
+}
+
+// newUserRepo creates and returns a new userRepo instance.
+func newUserRepo(db *sqlx.DB) *userRepo { return &userRepo{db: db} }
+
+// userSortColumns maps sort fields onto columns.
+var userSortColumns = map[string]string{
+	model.UserSortID:        "id",
+	model.UserSortUsername:  "username",
+	model.UserSortEmail:     "email",
+	model.UserSortCreatedAt: "created_at",
+}
+
+// GetAll returns all users.
+func (r *userRepo) GetAll(ctx context.Context) ([]model.User, error) {
+	users := []model.User{}
+	if err := r.db.SelectContext(ctx, &users, "SELECT * FROM users ORDER BY id;"); err != nil {
+		return []model.User{}, wrapError("get users", err)
+	}
+
+	return users, nil
+}
+
+// Create creates and returns a new user, the user's email isn't verified and the
+// user isn't disabled.
+func (r *userRepo) Create(ctx context.Context, u model.User) (model.User, error) {
+	u.CreatedAt = now()
+	query := "INSERT INTO users (username, email, first_name, second_name, password_hash, "
+	query += "created_at) VALUES (?, ?, ?, ?, ?, ?);"
+	res, err := r.db.ExecContext(
+		ctx, query, u.Username, u.Email, u.FirstName, u.SecondName, u.PasswordHash, u.CreatedAt,
+	)
+	if err != nil {
+		return model.User{}, userError("create user", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return model.User{}, wrapError("create user", err)
+	}
+	u.ID = int(id)
+	u.EmailVerifiedAt, u.DisabledAt = nil, nil
+
+	return u, nil
+}
+
+// List returns users matching the query and the total number of users matching its
+// filters.
+func (r *userRepo) List(ctx context.Context, q model.UserQuery) ([]model.User, int, error) {
+	column, ok := userSortColumns[q.Sort]
+	if !ok {
+		return []model.User{}, 0, fmt.Errorf("unknown sort field: %s", q.Sort)
+	}
+
+	// LIKE is case insensitive in SQLite.
+	where, args := []string{"1"}, []interface{}{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("?%d", len(args))
+	}
+	if q.EmailPrefix != "" {
+		where = append(where, "email LIKE "+arg(likePrefix(q.EmailPrefix))+` ESCAPE '\'`)
+	}
+	if q.UsernamePrefix != "" {
+		where = append(where, "username LIKE "+arg(likePrefix(q.UsernamePrefix))+` ESCAPE '\'`)
+	}
+	if q.CreatedAfter != nil {
+		where = append(where, "created_at > "+arg(q.CreatedAfter.UTC()))
+	}
+	if q.CreatedBefore != nil {
+		where = append(where, "created_at < "+arg(q.CreatedBefore.UTC()))
+	}
+	if q.Verified != nil && *q.Verified {
+		where = append(where, "email_verified_at IS NOT NULL")
+	} else if q.Verified != nil {
+		where = append(where, "email_verified_at IS NULL")
+	}
+
+	var total int
+	query := "SELECT COUNT(*) FROM users WHERE " + strings.Join(where, " AND ") + ";"
+	if err := r.db.GetContext(ctx, &total, query, args...); err != nil {
+		return []model.User{}, 0, wrapError("count users", err)
+	}
+
+	order, cmp := "ASC", ">"
+	if q.Desc {
+		order, cmp = "DESC", "<"
+	}
+	if q.After != nil && q.Sort == model.UserSortID {
+		where = append(where, "id "+cmp+" "+arg(q.After.ID))
+	} else if q.After != nil {
+		v, id := arg(userSortValue(q.Sort, *q.After)), arg(q.After.ID)
+		where = append(where, fmt.Sprintf(
+			"(%[1]s %[2]s %[3]s OR (%[1]s = %[3]s AND id %[2]s %[4]s))", column, cmp, v, id,
+		))
+	}
+	query = "SELECT * FROM users WHERE " + strings.Join(where, " AND ")
+	query += fmt.Sprintf(" ORDER BY %s %s, id %s", column, order, order)
+	if q.Limit > 0 {
+		query += " LIMIT " + arg(q.Limit)
+	}
+
+	users := []model.User{}
+	if err := r.db.SelectContext(ctx, &users, query+";", args...); err != nil {
+		return []model.User{}, 0, wrapError("list users", err)
+	}
+
+	return users, total, nil
+}
+
+// GetByID returns the user with specific ID.
+func (r *userRepo) GetByID(ctx context.Context, id int) (model.User, error) {
+	u := model.User{}
+	if err := r.db.GetContext(ctx, &u, "SELECT * FROM users WHERE id = ?;", id); err != nil {
+		return model.User{}, wrapError("get user", err)
+	}
+
+	return u, nil
+}
+
+// GetByEmail returns the user with specific email.
+func (r *userRepo) GetByEmail(ctx context.Context, email string) (model.User, error) {
+	u := model.User{}
+	if err := r.db.GetContext(ctx, &u, "SELECT * FROM users WHERE email = ?;", email); err != nil {
+		return model.User{}, wrapError("get user", err)
+	}
+
+	return u, nil
+}
+
+// Update updates username, email, first and second name of the user and returns the
+// updated user, email verification is reset if the email is changed.
+func (r *userRepo) Update(ctx context.Context, u model.User) (model.User, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return model.User{}, wrapError("update user", err)
+	}
+	defer tx.Rollback()
+
+	// SQLite in use doesn't support RETURNING, so the user is selected in the same
+	// transaction.
+	query := "UPDATE users SET username = ?1, email = ?2, first_name = ?3, second_name = ?4, "
+	query += "email_verified_at = CASE WHEN email = ?2 THEN email_verified_at END WHERE id = ?5;"
+	res, err := tx.ExecContext(ctx, query, u.Username, u.Email, u.FirstName, u.SecondName, u.ID)
+	if err != nil {
+		return model.User{}, userError("update user", err)
+	}
+	if rowsCount, err := res.RowsAffected(); err != nil {
+		return model.User{}, wrapError("update user", err)
+	} else if rowsCount == 0 {
+		return model.User{}, store.ErrNotFound
+	}
+
+	updated := model.User{}
+	if err := tx.GetContext(ctx, &updated, "SELECT * FROM users WHERE id = ?;", u.ID); err != nil {
+		return model.User{}, wrapError("update user", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return model.User{}, wrapError("update user", err)
+	}
+
+	return updated, nil
+}
+
+// SetPasswordHash sets password hash of the user with specific ID.
+func (r *userRepo) SetPasswordHash(ctx context.Context, id int, hash string) error {
+	return r.set(ctx, "password_hash", id, hash)
+}
+
+// SetEmailVerifiedAt sets email verification time of the user with specific ID, nil
+// marks the email as not verified.
+func (r *userRepo) SetEmailVerifiedAt(ctx context.Context, id int, at *time.Time) error {
+	return r.set(ctx, "email_verified_at", id, utc(at))
+}
+
+// SetDisabledAt sets disabled time of the user with specific ID, nil enables the user.
+func (r *userRepo) SetDisabledAt(ctx context.Context, id int, at *time.Time) error {
+	return r.set(ctx, "disabled_at", id, utc(at))
+}
+
+// set sets the column of the user with specific ID.
+func (r *userRepo) set(ctx context.Context, column string, id int, value interface{}) error {
+	query := fmt.Sprintf("UPDATE users SET %s = ? WHERE id = ?;", column)
+	res, err := r.db.ExecContext(ctx, query, value, id)
+	if err != nil {
+		return wrapError("update user", err)
+	}
+
+	rowsCount, err := res.RowsAffected()
+	if err != nil {
+		return wrapError("update user", err)
+	} else if rowsCount == 0 {
+		return store.ErrNotFound
+	}
+
+	return nil
+}
+
+// utc returns the time in UTC, nil is returned as is.
+func utc(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+
+	return &u
+}
+
+// DeleteByID deletes the user with specific ID.
+func (r *userRepo) DeleteByID(ctx context.Context, id int) error {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM users WHERE id = ?;", id)
+	if err != nil {
+		return wrapError("delete user", err)
+	}
+
+	rowsCount, err := res.RowsAffected()
+	if err != nil {
+		return wrapError("delete user", err)
+	} else if rowsCount == 0 {
+		return store.ErrNotFound
+	}
+
+	return nil
+}
+
+// userSortValue returns the value of the sort field of the user.
+func userSortValue(field string, u model.User) interface{} {
+	switch field {
+	case model.UserSortUsername:
+		return u.Username
+	case model.UserSortEmail:
+		return u.Email
+	case model.UserSortCreatedAt:
+		return u.CreatedAt.UTC()
+	}
+
+	return u.ID
+}
+
+// likePrefix returns LIKE pattern matching strings with the prefix, wildcards of the
+// prefix are escaped with backslash.
+func likePrefix(prefix string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(prefix) + "%"
+}
@@ -0,0 +1,44 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+)
+
+func testAuditEventRepo(t *testing.T, newStore Factory) {
+	ctx := context.Background()
+	s := newStore(t)
+	u, err := s.Users().Create(ctx, model.User{Username: "user1", Email: "user1@test.com"})
+	require.NoError(t, err)
+	r := s.AuditEvents()
+
+	events, err := r.GetByUserID(ctx, u.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+
+	e1, err := r.Create(ctx, model.AuditEvent{ActorID: 1, Action: model.AuditUserCreate, UserID: u.ID})
+	require.NoError(t, err)
+	assert.NotZero(t, e1.ID)
+	assert.False(t, e1.CreatedAt.IsZero())
+	e2, err := r.Create(ctx, model.AuditEvent{ActorID: 1, Action: model.AuditUserDelete, UserID: u.ID})
+	require.NoError(t, err)
+	assert.Greater(t, e2.ID, e1.ID)
+	_, err = r.Create(ctx, model.AuditEvent{ActorID: 1, Action: model.AuditUserCreate, UserID: u.ID + 1})
+	require.NoError(t, err)
+
+	// Events outlive the user.
+	require.NoError(t, s.Users().DeleteByID(ctx, u.ID))
+	events, err = r.GetByUserID(ctx, u.ID)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	for i, e := range []model.AuditEvent{e1, e2} {
+		assertTime(t, e.CreatedAt, events[i].CreatedAt)
+		events[i].CreatedAt = e.CreatedAt
+		assert.Equal(t, e, events[i])
+	}
+}
@@ -0,0 +1,58 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+func testEmailVerificationTokenRepo(t *testing.T, newStore Factory) {
+	ctx := context.Background()
+	s := newStore(t)
+	u, err := s.Users().Create(ctx, model.User{Username: "user1", Email: "user1@test.com"})
+	require.NoError(t, err)
+	r := s.EmailVerificationTokens()
+
+	token := model.EmailVerificationToken{
+		Hash: "hash1", UserID: u.ID, Email: u.Email, ExpiresAt: timestamp(-time.Minute),
+	}
+	_, err = r.Create(ctx, token)
+	require.NoError(t, err)
+	_, err = r.Create(ctx, model.EmailVerificationToken{
+		Hash: "hash2", UserID: u.ID, ExpiresAt: timestamp(time.Hour),
+	})
+	require.NoError(t, err)
+	_, err = r.Create(ctx, token)
+	assert.Error(t, err)
+	_, err = r.Create(ctx, model.EmailVerificationToken{
+		Hash: "hash3", UserID: u.ID + 1, ExpiresAt: timestamp(time.Hour),
+	})
+	assert.Error(t, err)
+
+	got, err := r.GetByHash(ctx, "hash1")
+	require.NoError(t, err)
+	assertTime(t, token.ExpiresAt, got.ExpiresAt)
+	got.ExpiresAt = token.ExpiresAt
+	assert.Equal(t, token, got)
+	_, err = r.GetByHash(ctx, "hash3")
+	assertNotFound(t, err)
+
+	assert.NoError(t, r.MarkUsed(ctx, "hash1"))
+	assert.Equal(t, store.ErrTokenIsUsed, r.MarkUsed(ctx, "hash1"))
+	assertNotFound(t, r.MarkUsed(ctx, "hash3"))
+	got, err = r.GetByHash(ctx, "hash1")
+	assert.NoError(t, err)
+	assert.NotNil(t, got.UsedAt)
+
+	assert.NoError(t, r.DeleteExpired(ctx))
+	_, err = r.GetByHash(ctx, "hash1")
+	assertNotFound(t, err)
+	_, err = r.GetByHash(ctx, "hash2")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,60 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testLoginThrottleRepo(t *testing.T, newStore Factory) {
+	ctx := context.Background()
+	r := newStore(t).LoginThrottles()
+
+	lt, err := r.Get(ctx, "ip:192.0.2.1")
+	assert.NoError(t, err)
+	assert.Equal(t, "ip:192.0.2.1", lt.Key)
+	assert.Zero(t, lt.Failures)
+	assert.Nil(t, lt.LockedUntil)
+
+	lt, err = r.RegisterFailure(ctx, "ip:192.0.2.1", timestamp(-time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, "ip:192.0.2.1", lt.Key)
+	assert.Equal(t, 1, lt.Failures)
+	lt, err = r.RegisterFailure(ctx, "ip:192.0.2.1", timestamp(-time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, lt.Failures)
+	lt, err = r.RegisterFailure(ctx, "ip:192.0.2.1", timestamp(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, lt.Failures)
+	lt, err = r.Get(ctx, "ip:192.0.2.1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, lt.Failures)
+
+	until := timestamp(time.Hour)
+	assert.NoError(t, r.Lock(ctx, "ip:192.0.2.1", until))
+	lt, err = r.Get(ctx, "ip:192.0.2.1")
+	assert.NoError(t, err)
+	assert.Zero(t, lt.Failures)
+	require.NotNil(t, lt.LockedUntil)
+	assertTime(t, until, *lt.LockedUntil)
+
+	// Locked throttles aren't stale.
+	_, err = r.RegisterFailure(ctx, "ip:192.0.2.2", timestamp(-time.Minute))
+	require.NoError(t, err)
+	assert.NoError(t, r.DeleteStale(ctx, timestamp(time.Minute)))
+	lt, err = r.Get(ctx, "ip:192.0.2.1")
+	assert.NoError(t, err)
+	assert.NotNil(t, lt.LockedUntil)
+	lt, err = r.Get(ctx, "ip:192.0.2.2")
+	assert.NoError(t, err)
+	assert.Zero(t, lt.Failures)
+
+	assert.NoError(t, r.Delete(ctx, "ip:192.0.2.1"))
+	lt, err = r.Get(ctx, "ip:192.0.2.1")
+	assert.NoError(t, err)
+	assert.Nil(t, lt.LockedUntil)
+	assert.NoError(t, r.Delete(ctx, "ip:192.0.2.1"))
+}
@@ -0,0 +1,81 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+)
+
+func testOneTimeCodeRepo(t *testing.T, newStore Factory) {
+	ctx := context.Background()
+	s := newStore(t)
+	u, err := s.Users().Create(ctx, model.User{Username: "user1", Email: "user1@test.com"})
+	require.NoError(t, err)
+	r := s.OneTimeCodes()
+
+	code := model.OneTimeCode{
+		UserID: u.ID, Purpose: "login", Hash: "hash1", ExpiresAt: timestamp(-time.Minute),
+	}
+	saved, err := r.Save(ctx, code)
+	require.NoError(t, err)
+	assert.Equal(t, 0, saved.Attempts)
+	assert.False(t, saved.CreatedAt.IsZero())
+	_, err = r.Save(ctx, model.OneTimeCode{
+		UserID: u.ID, Purpose: "step_up", Hash: "hash2", ExpiresAt: timestamp(time.Hour),
+	})
+	require.NoError(t, err)
+	_, err = r.Save(ctx, model.OneTimeCode{
+		UserID: u.ID + 1, Purpose: "login", Hash: "hash3", ExpiresAt: timestamp(time.Hour),
+	})
+	assertNotFound(t, err)
+
+	got, err := r.Get(ctx, u.ID, "login")
+	require.NoError(t, err)
+	assertTime(t, saved.ExpiresAt, got.ExpiresAt)
+	assertTime(t, saved.CreatedAt, got.CreatedAt)
+	got.ExpiresAt, got.CreatedAt = saved.ExpiresAt, saved.CreatedAt
+	assert.Equal(t, saved, got)
+	_, err = r.Get(ctx, u.ID, "other")
+	assertNotFound(t, err)
+
+	got, err = r.Attempt(ctx, u.ID, "login", 2)
+	require.NoError(t, err)
+	assert.Equal(t, 1, got.Attempts)
+	assert.Equal(t, "hash1", got.Hash)
+	got, err = r.Attempt(ctx, u.ID, "login", 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, got.Attempts)
+	_, err = r.Attempt(ctx, u.ID, "login", 2)
+	assertNotFound(t, err)
+	_, err = r.Attempt(ctx, u.ID, "other", 2)
+	assertNotFound(t, err)
+
+	// Saving a new code replaces the old one and resets its attempts.
+	code.Hash = "hash4"
+	_, err = r.Save(ctx, code)
+	require.NoError(t, err)
+	got, err = r.Attempt(ctx, u.ID, "login", 2)
+	require.NoError(t, err)
+	assert.Equal(t, "hash4", got.Hash)
+	assert.Equal(t, 1, got.Attempts)
+
+	assert.NoError(t, r.DeleteExpired(ctx))
+	_, err = r.Get(ctx, u.ID, "login")
+	assertNotFound(t, err)
+	_, err = r.Get(ctx, u.ID, "step_up")
+	assert.NoError(t, err)
+
+	assert.NoError(t, r.Delete(ctx, u.ID, "step_up"))
+	assertNotFound(t, r.Delete(ctx, u.ID, "step_up"))
+
+	_, err = r.Save(ctx, code)
+	require.NoError(t, err)
+	require.NoError(t, s.Users().DeleteByID(ctx, u.ID))
+	_, err = r.Get(ctx, u.ID, "login")
+	assertNotFound(t, err)
+}
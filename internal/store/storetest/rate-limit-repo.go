@@ -0,0 +1,50 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testRateLimitRepo(t *testing.T, newStore Factory) {
+	ctx := context.Background()
+	r := newStore(t).RateLimits()
+
+	tokens, allowed, err := r.Take(ctx, "key1", 2, time.Hour)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+	assert.InDelta(t, 1, tokens, 0.01)
+	tokens, allowed, err = r.Take(ctx, "key1", 2, time.Hour)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+	assert.InDelta(t, 0, tokens, 0.01)
+	tokens, allowed, err = r.Take(ctx, "key1", 2, time.Hour)
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+	assert.InDelta(t, 0, tokens, 0.01)
+
+	// Buckets are independent.
+	_, allowed, err = r.Take(ctx, "key2", 2, time.Hour)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	// Buckets are refilled over time.
+	_, allowed, err = r.Take(ctx, "key3", 1, 50*time.Millisecond)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+	time.Sleep(100 * time.Millisecond)
+	_, allowed, err = r.Take(ctx, "key3", 1, 50*time.Millisecond)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	assert.NoError(t, r.DeleteStale(ctx, timestamp(-time.Minute)))
+	_, allowed, err = r.Take(ctx, "key1", 2, time.Hour)
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+	assert.NoError(t, r.DeleteStale(ctx, timestamp(time.Minute)))
+	_, allowed, err = r.Take(ctx, "key1", 2, time.Hour)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+}
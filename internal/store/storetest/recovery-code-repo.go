@@ -0,0 +1,83 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+func testRecoveryCodeRepo(t *testing.T, newStore Factory) {
+	t.Run("Replace", func(t *testing.T) {
+		ctx := context.Background()
+		s := newStore(t)
+		u, err := s.Users().Create(ctx, model.User{Username: "user1", Email: "user1@test.com"})
+		require.NoError(t, err)
+		r := s.RecoveryCodes()
+
+		count, err := r.CountUnused(ctx, u.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+		require.NoError(t, r.Replace(ctx, u.ID, []string{"hash1", "hash2", "hash3"}))
+		count, err = r.CountUnused(ctx, u.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+
+		// Replacing invalidates the old codes, used ones included.
+		require.NoError(t, r.Use(ctx, u.ID, "hash1"))
+		require.NoError(t, r.Replace(ctx, u.ID, []string{"hash4", "hash5"}))
+		count, err = r.CountUnused(ctx, u.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+		assertNotFound(t, r.Use(ctx, u.ID, "hash2"))
+
+		assertNotFound(t, r.Replace(ctx, u.ID+1, []string{"hash1"}))
+	})
+
+	t.Run("Use", func(t *testing.T) {
+		ctx := context.Background()
+		s := newStore(t)
+		u1, err := s.Users().Create(ctx, model.User{Username: "user1", Email: "user1@test.com"})
+		require.NoError(t, err)
+		u2, err := s.Users().Create(ctx, model.User{Username: "user2", Email: "user2@test.com"})
+		require.NoError(t, err)
+		r := s.RecoveryCodes()
+		require.NoError(t, r.Replace(ctx, u1.ID, []string{"hash1", "hash2"}))
+
+		assert.NoError(t, r.Use(ctx, u1.ID, "hash1"))
+		assert.Equal(t, store.ErrTokenIsUsed, r.Use(ctx, u1.ID, "hash1"))
+		count, err := r.CountUnused(ctx, u1.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		// Codes of other users can't be used.
+		assertNotFound(t, r.Use(ctx, u2.ID, "hash2"))
+		assertNotFound(t, r.Use(ctx, u1.ID, "hash3"))
+	})
+
+	t.Run("DeleteByUserID", func(t *testing.T) {
+		ctx := context.Background()
+		s := newStore(t)
+		u1, err := s.Users().Create(ctx, model.User{Username: "user1", Email: "user1@test.com"})
+		require.NoError(t, err)
+		u2, err := s.Users().Create(ctx, model.User{Username: "user2", Email: "user2@test.com"})
+		require.NoError(t, err)
+		r := s.RecoveryCodes()
+		require.NoError(t, r.Replace(ctx, u1.ID, []string{"hash1"}))
+		require.NoError(t, r.Replace(ctx, u2.ID, []string{"hash1"}))
+
+		assert.NoError(t, r.DeleteByUserID(ctx, u1.ID))
+		assertNotFound(t, r.Use(ctx, u1.ID, "hash1"))
+		assert.NoError(t, r.DeleteByUserID(ctx, u1.ID))
+
+		// Codes are deleted along with the user.
+		require.NoError(t, s.Users().DeleteByID(ctx, u2.ID))
+		count, err := r.CountUnused(ctx, u2.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+}
@@ -0,0 +1,116 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+func testRefreshTokenRepo(t *testing.T, newStore Factory) {
+	t.Run("Create and get", func(t *testing.T) {
+		ctx := context.Background()
+		s := newStore(t)
+		u, err := s.Users().Create(ctx, model.User{Username: "user1", Email: "user1@test.com"})
+		require.NoError(t, err)
+		r := s.RefreshTokens()
+
+		token := model.RefreshToken{
+			ID: "token1", FamilyID: "token1", UserID: u.ID, ExpiresAt: timestamp(time.Hour),
+		}
+		_, err = r.Create(ctx, token)
+		require.NoError(t, err)
+		_, err = r.Create(ctx, token)
+		assert.Error(t, err)
+		_, err = r.Create(ctx, model.RefreshToken{
+			ID: "token2", FamilyID: "token2", UserID: u.ID + 1, ExpiresAt: timestamp(time.Hour),
+		})
+		assert.Error(t, err)
+
+		got, err := r.GetByID(ctx, "token1")
+		require.NoError(t, err)
+		assertTime(t, token.ExpiresAt, got.ExpiresAt)
+		got.ExpiresAt = token.ExpiresAt
+		assert.Equal(t, token, got)
+		_, err = r.GetByID(ctx, "token2")
+		assertNotFound(t, err)
+	})
+
+	t.Run("MarkUsed", func(t *testing.T) {
+		ctx := context.Background()
+		s := newStore(t)
+		u, err := s.Users().Create(ctx, model.User{Username: "user1", Email: "user1@test.com"})
+		require.NoError(t, err)
+		r := s.RefreshTokens()
+		_, err = r.Create(ctx, model.RefreshToken{
+			ID: "token1", FamilyID: "token1", UserID: u.ID, ExpiresAt: timestamp(time.Hour),
+		})
+		require.NoError(t, err)
+
+		assert.NoError(t, r.MarkUsed(ctx, "token1"))
+		assert.Equal(t, store.ErrTokenIsUsed, r.MarkUsed(ctx, "token1"))
+		assertNotFound(t, r.MarkUsed(ctx, "token2"))
+		got, err := r.GetByID(ctx, "token1")
+		assert.NoError(t, err)
+		assert.NotNil(t, got.UsedAt)
+	})
+
+	t.Run("Revoke", func(t *testing.T) {
+		ctx := context.Background()
+		s := newStore(t)
+		u1, err := s.Users().Create(ctx, model.User{Username: "user1", Email: "user1@test.com"})
+		require.NoError(t, err)
+		u2, err := s.Users().Create(ctx, model.User{Username: "user2", Email: "user2@test.com"})
+		require.NoError(t, err)
+		r := s.RefreshTokens()
+		for _, token := range []model.RefreshToken{
+			{ID: "token1", FamilyID: "family1", UserID: u1.ID, ExpiresAt: timestamp(time.Hour)},
+			{ID: "token2", FamilyID: "family1", UserID: u1.ID, ExpiresAt: timestamp(time.Hour)},
+			{ID: "token3", FamilyID: "family2", UserID: u1.ID, ExpiresAt: timestamp(time.Hour)},
+			{ID: "token4", FamilyID: "family3", UserID: u2.ID, ExpiresAt: timestamp(time.Hour)},
+		} {
+			_, err := r.Create(ctx, token)
+			require.NoError(t, err)
+		}
+
+		assert.NoError(t, r.RevokeFamily(ctx, "family1"))
+		for id, revoked := range map[string]bool{"token1": true, "token2": true, "token3": false} {
+			got, err := r.GetByID(ctx, id)
+			assert.NoError(t, err)
+			assert.Equal(t, revoked, got.RevokedAt != nil, id)
+		}
+
+		assert.NoError(t, r.RevokeByUserID(ctx, u1.ID))
+		for id, revoked := range map[string]bool{"token3": true, "token4": false} {
+			got, err := r.GetByID(ctx, id)
+			assert.NoError(t, err)
+			assert.Equal(t, revoked, got.RevokedAt != nil, id)
+		}
+	})
+
+	t.Run("DeleteExpired", func(t *testing.T) {
+		ctx := context.Background()
+		s := newStore(t)
+		u, err := s.Users().Create(ctx, model.User{Username: "user1", Email: "user1@test.com"})
+		require.NoError(t, err)
+		r := s.RefreshTokens()
+		for _, token := range []model.RefreshToken{
+			{ID: "token1", FamilyID: "token1", UserID: u.ID, ExpiresAt: timestamp(-time.Minute)},
+			{ID: "token2", FamilyID: "token2", UserID: u.ID, ExpiresAt: timestamp(time.Hour)},
+		} {
+			_, err := r.Create(ctx, token)
+			require.NoError(t, err)
+		}
+
+		assert.NoError(t, r.DeleteExpired(ctx))
+		_, err = r.GetByID(ctx, "token1")
+		assertNotFound(t, err)
+		_, err = r.GetByID(ctx, "token2")
+		assert.NoError(t, err)
+	})
+}
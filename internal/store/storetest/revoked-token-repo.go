@@ -0,0 +1,48 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+func testRevokedTokenRepo(t *testing.T, newStore Factory) {
+	ctx := context.Background()
+	r := newStore(t).RevokedTokens()
+
+	for _, token := range []model.RevokedToken{
+		{ID: "token1", ExpiresAt: timestamp(-time.Minute)},
+		{ID: "token1", ExpiresAt: timestamp(-time.Minute)},
+		{ID: "token2", ExpiresAt: timestamp(time.Hour)},
+	} {
+		_, err := r.Create(ctx, token)
+		require.NoError(t, err)
+	}
+
+	for id, revoked := range map[string]bool{"token1": true, "token2": true, "token3": false} {
+		exists, err := r.Exists(ctx, id)
+		assert.NoError(t, err)
+		assert.Equal(t, revoked, exists, id)
+	}
+
+	assert.NoError(t, r.Use(ctx, model.RevokedToken{ID: "token3", ExpiresAt: timestamp(time.Hour)}))
+	assert.Equal(t, store.ErrTokenIsUsed, r.Use(ctx, model.RevokedToken{
+		ID: "token3", ExpiresAt: timestamp(time.Hour),
+	}))
+	assert.Equal(t, store.ErrTokenIsUsed, r.Use(ctx, model.RevokedToken{
+		ID: "token2", ExpiresAt: timestamp(time.Hour),
+	}))
+
+	assert.NoError(t, r.DeleteExpired(ctx))
+	for id, revoked := range map[string]bool{"token1": false, "token2": true} {
+		exists, err := r.Exists(ctx, id)
+		assert.NoError(t, err)
+		assert.Equal(t, revoked, exists, id)
+	}
+}
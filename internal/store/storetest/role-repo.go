@@ -0,0 +1,60 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+)
+
+func testRoleRepo(t *testing.T, newStore Factory) {
+	t.Run("GetAll", func(t *testing.T) {
+		roles, err := newStore(t).Roles().GetAll(context.Background())
+		require.NoError(t, err)
+		require.Len(t, roles, 2)
+		assert.Equal(t, "admin", roles[0].Name)
+		assert.Equal(t, []string{"roles:write", "users:read", "users:write"}, roles[0].Permissions)
+		assert.Equal(t, "user", roles[1].Name)
+		assert.Empty(t, roles[1].Permissions)
+	})
+
+	t.Run("Assign and remove", func(t *testing.T) {
+		ctx := context.Background()
+		s := newStore(t)
+		u, err := s.Users().Create(ctx, model.User{Username: "user1", Email: "user1@test.com"})
+		require.NoError(t, err)
+		r := s.Roles()
+		roles, err := r.GetByUserID(ctx, u.ID)
+		assert.NoError(t, err)
+		assert.Empty(t, roles)
+
+		assert.NoError(t, r.AssignToUser(ctx, u.ID, "user"))
+		assert.NoError(t, r.AssignToUser(ctx, u.ID, "admin"))
+		assert.NoError(t, r.AssignToUser(ctx, u.ID, "admin"))
+		assertNotFound(t, r.AssignToUser(ctx, u.ID, "owner"))
+		assertNotFound(t, r.AssignToUser(ctx, u.ID+1, "user"))
+
+		roles, err = r.GetByUserID(ctx, u.ID)
+		require.NoError(t, err)
+		require.Len(t, roles, 2)
+		assert.Equal(t, "admin", roles[0].Name)
+		assert.Equal(t, []string{"roles:write", "users:read", "users:write"}, roles[0].Permissions)
+		assert.Equal(t, "user", roles[1].Name)
+		// Returned permissions aren't shared with the store.
+		roles[0].Permissions[0] = "changed"
+		roles, err = r.GetByUserID(ctx, u.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "roles:write", roles[0].Permissions[0])
+
+		assert.NoError(t, r.RemoveFromUser(ctx, u.ID, "admin"))
+		assertNotFound(t, r.RemoveFromUser(ctx, u.ID, "admin"))
+		assertNotFound(t, r.RemoveFromUser(ctx, u.ID, "owner"))
+		roles, err = r.GetByUserID(ctx, u.ID)
+		require.NoError(t, err)
+		require.Len(t, roles, 1)
+		assert.Equal(t, "user", roles[0].Name)
+	})
+}
@@ -0,0 +1,82 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+)
+
+func testSigningKeyRepo(t *testing.T, newStore Factory) {
+	t.Run("Create and get", func(t *testing.T) {
+		ctx := context.Background()
+		r := newStore(t).SigningKeys()
+		keys, err := r.GetAll(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, keys)
+
+		activatedAt := timestamp(0)
+		k, err := r.Create(ctx, model.SigningKey{
+			ID: "kid1", Algorithm: "HS256", Material: "secret", Status: model.SigningKeyActive,
+			ActivatedAt: &activatedAt,
+		})
+		require.NoError(t, err)
+		assert.False(t, k.CreatedAt.IsZero())
+		_, err = r.Create(ctx, model.SigningKey{ID: "kid1", Status: model.SigningKeyPending})
+		assert.Error(t, err)
+
+		keys, err = r.GetAll(ctx)
+		require.NoError(t, err)
+		require.Len(t, keys, 1)
+		assert.Equal(t, "kid1", keys[0].ID)
+		assert.Equal(t, "HS256", keys[0].Algorithm)
+		assert.Equal(t, "secret", keys[0].Material)
+		assert.Equal(t, model.SigningKeyActive, keys[0].Status)
+		require.NotNil(t, keys[0].ActivatedAt)
+		assertTime(t, activatedAt, *keys[0].ActivatedAt)
+		assert.Nil(t, keys[0].RetiredAt)
+	})
+
+	t.Run("Activate and retire", func(t *testing.T) {
+		ctx := context.Background()
+		r := newStore(t).SigningKeys()
+		for _, k := range []model.SigningKey{
+			{ID: "kid1", Algorithm: "HS256", Status: model.SigningKeyActive},
+			{ID: "kid2", Algorithm: "HS256", Status: model.SigningKeyPending},
+			{ID: "kid3", Algorithm: "HS256", Status: model.SigningKeyPending},
+		} {
+			_, err := r.Create(ctx, k)
+			require.NoError(t, err)
+		}
+
+		assert.NoError(t, r.Activate(ctx, "kid2"))
+		assertNotFound(t, r.Activate(ctx, "kid4"))
+		assert.NoError(t, r.Retire(ctx, "kid3"))
+		assert.Error(t, r.Retire(ctx, "kid2"))
+		assertNotFound(t, r.Retire(ctx, "kid4"))
+
+		keys, err := r.GetAll(ctx)
+		require.NoError(t, err)
+		require.Len(t, keys, 3)
+		assert.Equal(t, []string{"kid1", "kid2", "kid3"}, []string{keys[0].ID, keys[1].ID, keys[2].ID})
+		assert.Equal(t, model.SigningKeyRetired, keys[0].Status)
+		assert.NotNil(t, keys[0].RetiredAt)
+		assert.Equal(t, model.SigningKeyActive, keys[1].Status)
+		assert.NotNil(t, keys[1].ActivatedAt)
+		assert.Equal(t, model.SigningKeyRetired, keys[2].Status)
+
+		assert.NoError(t, r.DeleteRetiredBefore(ctx, timestamp(-time.Minute)))
+		keys, err = r.GetAll(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, keys, 3)
+		assert.NoError(t, r.DeleteRetiredBefore(ctx, timestamp(time.Minute)))
+		keys, err = r.GetAll(ctx)
+		require.NoError(t, err)
+		require.Len(t, keys, 1)
+		assert.Equal(t, "kid2", keys[0].ID)
+	})
+}
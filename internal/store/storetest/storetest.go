@@ -0,0 +1,56 @@
+// Package storetest provides conformance tests every store must pass, so all stores
+// behave identically.
+package storetest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+// Factory returns a new opened store without data but seeded roles. It's called once
+// per test, the store must be cleaned up with t.Cleanup.
+type Factory func(t *testing.T) store.Store
+
+// Run runs conformance tests of all repositories against stores returned by newStore.
+func Run(t *testing.T, newStore Factory) {
+	t.Run("Users", func(t *testing.T) { testUserRepo(t, newStore) })
+	t.Run("RefreshTokens", func(t *testing.T) { testRefreshTokenRepo(t, newStore) })
+	t.Run("RevokedTokens", func(t *testing.T) { testRevokedTokenRepo(t, newStore) })
+	t.Run("SigningKeys", func(t *testing.T) { testSigningKeyRepo(t, newStore) })
+	t.Run("Roles", func(t *testing.T) { testRoleRepo(t, newStore) })
+	t.Run("PasswordResetTokens", func(t *testing.T) { testPasswordResetTokenRepo(t, newStore) })
+	t.Run("EmailVerificationTokens", func(t *testing.T) {
+		testEmailVerificationTokenRepo(t, newStore)
+	})
+	t.Run("LoginThrottles", func(t *testing.T) { testLoginThrottleRepo(t, newStore) })
+	t.Run("RateLimits", func(t *testing.T) { testRateLimitRepo(t, newStore) })
+	t.Run("AuditEvents", func(t *testing.T) { testAuditEventRepo(t, newStore) })
+	t.Run("TOTPSecrets", func(t *testing.T) { testTOTPSecretRepo(t, newStore) })
+	t.Run("RecoveryCodes", func(t *testing.T) { testRecoveryCodeRepo(t, newStore) })
+	t.Run("WebAuthnCredentials", func(t *testing.T) { testWebAuthnCredentialRepo(t, newStore) })
+	t.Run("MagicLinkTokens", func(t *testing.T) { testMagicLinkTokenRepo(t, newStore) })
+	t.Run("OneTimeCodes", func(t *testing.T) { testOneTimeCodeRepo(t, newStore) })
+}
+
+// timestamp returns time which survives a round trip through every store, databases
+// keep microseconds at most.
+func timestamp(d time.Duration) time.Time {
+	return time.Now().Add(d).Truncate(time.Microsecond)
+}
+
+// assertNotFound asserts that the error is store.ErrNotFound.
+func assertNotFound(t *testing.T, err error) {
+	t.Helper()
+	assert.True(t, errors.Is(err, store.ErrNotFound), "expected store.ErrNotFound, actual %v", err)
+}
+
+// assertTime asserts that times are the same instant regardless of their location.
+func assertTime(t *testing.T, expected, actual time.Time) {
+	t.Helper()
+	assert.True(t, expected.Equal(actual), "expected %s, actual %s", expected, actual)
+}
@@ -0,0 +1,96 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+func testTOTPSecretRepo(t *testing.T, newStore Factory) {
+	t.Run("Save", func(t *testing.T) {
+		ctx := context.Background()
+		s := newStore(t)
+		u, err := s.Users().Create(ctx, model.User{Username: "user1", Email: "user1@test.com"})
+		require.NoError(t, err)
+		r := s.TOTPSecrets()
+
+		_, err = r.GetByUserID(ctx, u.ID)
+		assertNotFound(t, err)
+		require.NoError(t, r.Save(ctx, model.TOTPSecret{UserID: u.ID, Secret: []byte("secret1")}))
+		got, err := r.GetByUserID(ctx, u.ID)
+		require.NoError(t, err)
+		assert.Equal(t, model.TOTPSecret{UserID: u.ID, Secret: []byte("secret1")}, got)
+
+		// Saving replaces the secret along with its state.
+		require.NoError(t, r.Confirm(ctx, u.ID))
+		require.NoError(t, r.MarkUsed(ctx, u.ID, 10))
+		require.NoError(t, r.Save(ctx, model.TOTPSecret{UserID: u.ID, Secret: []byte("secret2")}))
+		got, err = r.GetByUserID(ctx, u.ID)
+		require.NoError(t, err)
+		assert.Equal(t, model.TOTPSecret{UserID: u.ID, Secret: []byte("secret2")}, got)
+
+		assertNotFound(t, r.Save(ctx, model.TOTPSecret{UserID: u.ID + 1, Secret: []byte("secret")}))
+	})
+
+	t.Run("Confirm", func(t *testing.T) {
+		ctx := context.Background()
+		s := newStore(t)
+		u, err := s.Users().Create(ctx, model.User{Username: "user1", Email: "user1@test.com"})
+		require.NoError(t, err)
+		r := s.TOTPSecrets()
+		require.NoError(t, r.Save(ctx, model.TOTPSecret{UserID: u.ID, Secret: []byte("secret")}))
+
+		require.NoError(t, r.Confirm(ctx, u.ID))
+		got, err := r.GetByUserID(ctx, u.ID)
+		require.NoError(t, err)
+		assert.NotNil(t, got.ConfirmedAt)
+
+		assertNotFound(t, r.Confirm(ctx, u.ID+1))
+	})
+
+	t.Run("MarkUsed", func(t *testing.T) {
+		ctx := context.Background()
+		s := newStore(t)
+		u, err := s.Users().Create(ctx, model.User{Username: "user1", Email: "user1@test.com"})
+		require.NoError(t, err)
+		r := s.TOTPSecrets()
+		require.NoError(t, r.Save(ctx, model.TOTPSecret{UserID: u.ID, Secret: []byte("secret")}))
+
+		assert.NoError(t, r.MarkUsed(ctx, u.ID, 10))
+		assert.Equal(t, store.ErrTokenIsUsed, r.MarkUsed(ctx, u.ID, 10))
+		assert.Equal(t, store.ErrTokenIsUsed, r.MarkUsed(ctx, u.ID, 9))
+		assert.NoError(t, r.MarkUsed(ctx, u.ID, 11))
+		got, err := r.GetByUserID(ctx, u.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(11), got.LastUsedStep)
+
+		assertNotFound(t, r.MarkUsed(ctx, u.ID+1, 10))
+	})
+
+	t.Run("DeleteByUserID", func(t *testing.T) {
+		ctx := context.Background()
+		s := newStore(t)
+		u1, err := s.Users().Create(ctx, model.User{Username: "user1", Email: "user1@test.com"})
+		require.NoError(t, err)
+		u2, err := s.Users().Create(ctx, model.User{Username: "user2", Email: "user2@test.com"})
+		require.NoError(t, err)
+		r := s.TOTPSecrets()
+		require.NoError(t, r.Save(ctx, model.TOTPSecret{UserID: u1.ID, Secret: []byte("secret")}))
+		require.NoError(t, r.Save(ctx, model.TOTPSecret{UserID: u2.ID, Secret: []byte("secret")}))
+
+		assert.NoError(t, r.DeleteByUserID(ctx, u1.ID))
+		_, err = r.GetByUserID(ctx, u1.ID)
+		assertNotFound(t, err)
+		assertNotFound(t, r.DeleteByUserID(ctx, u1.ID))
+
+		// Secrets are deleted along with the user.
+		require.NoError(t, s.Users().DeleteByID(ctx, u2.ID))
+		_, err = r.GetByUserID(ctx, u2.ID)
+		assertNotFound(t, err)
+	})
+}
@@ -0,0 +1,381 @@
+package storetest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+func testUserRepo(t *testing.T, newStore Factory) {
+	t.Run("Create", func(t *testing.T) {
+		ctx := context.Background()
+		r := newStore(t).Users()
+
+		u1, err := r.Create(ctx, model.User{
+			Username: "user1", Email: "user1@test.com", FirstName: "First", SecondName: "Second",
+			PasswordHash: "hash",
+		})
+		require.NoError(t, err)
+		assert.NotZero(t, u1.ID)
+		u2, err := r.Create(ctx, model.User{Username: "user2", Email: "user2@test.com"})
+		require.NoError(t, err)
+		assert.NotEqual(t, u1.ID, u2.ID)
+
+		got, err := r.GetByID(ctx, u1.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, u1, got)
+	})
+
+	t.Run("Create doesn't store password", func(t *testing.T) {
+		ctx := context.Background()
+		r := newStore(t).Users()
+
+		u, err := r.Create(ctx, model.User{
+			Username: "user1", Email: "user1@test.com", Password: "password", PasswordHash: "hash",
+		})
+		require.NoError(t, err)
+		got, err := r.GetByID(ctx, u.ID)
+		assert.NoError(t, err)
+		assert.Empty(t, got.Password)
+		assert.Equal(t, "hash", got.PasswordHash)
+	})
+
+	t.Run("Create ignores verification and disabled time", func(t *testing.T) {
+		ctx := context.Background()
+		r := newStore(t).Users()
+		verifiedAt, disabledAt := timestamp(0), timestamp(time.Minute)
+
+		u, err := r.Create(ctx, model.User{
+			Username: "user1", Email: "user1@test.com",
+			EmailVerifiedAt: &verifiedAt, DisabledAt: &disabledAt,
+		})
+		require.NoError(t, err)
+		assert.Nil(t, u.EmailVerifiedAt)
+		assert.Nil(t, u.DisabledAt)
+
+		got, err := r.GetByID(ctx, u.ID)
+		assert.NoError(t, err)
+		assert.Nil(t, got.EmailVerifiedAt)
+		assert.Nil(t, got.DisabledAt)
+	})
+
+	t.Run("Create uniqueness", func(t *testing.T) {
+		ctx := context.Background()
+		r := newStore(t).Users()
+		_, err := r.Create(ctx, model.User{Username: "user1", Email: "user1@test.com"})
+		require.NoError(t, err)
+
+		_, err = r.Create(ctx, model.User{Username: "user1", Email: "user2@test.com"})
+		assert.Equal(t, store.ErrUsernameIsTaken, err)
+		_, err = r.Create(ctx, model.User{Username: "user2", Email: "user1@test.com"})
+		assert.Equal(t, store.ErrEmailIsTaken, err)
+
+		users, err := r.GetAll(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, users, 1)
+	})
+
+	t.Run("Create uniqueness concurrently", func(t *testing.T) {
+		ctx := context.Background()
+		r := newStore(t).Users()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				r.Create(ctx, model.User{Username: fmt.Sprintf("user%d", i), Email: "user@test.com"})
+			}(i)
+		}
+		wg.Wait()
+
+		users, err := r.GetAll(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, users, 1)
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		ctx := context.Background()
+		r := newStore(t).Users()
+		users, err := r.GetAll(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, users)
+		u1, err := r.Create(ctx, model.User{Username: "user1", Email: "user1@test.com"})
+		require.NoError(t, err)
+		u2, err := r.Create(ctx, model.User{Username: "user2", Email: "user2@test.com"})
+		require.NoError(t, err)
+
+		got, err := r.GetByID(ctx, u2.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, u2, got)
+		got, err = r.GetByEmail(ctx, u1.Email)
+		assert.NoError(t, err)
+		assert.Equal(t, u1, got)
+		users, err = r.GetAll(ctx)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []model.User{u1, u2}, users)
+	})
+
+	t.Run("Get not found", func(t *testing.T) {
+		ctx := context.Background()
+		r := newStore(t).Users()
+		u, err := r.Create(ctx, model.User{Username: "user1", Email: "user1@test.com"})
+		require.NoError(t, err)
+
+		_, err = r.GetByID(ctx, u.ID+1)
+		assertNotFound(t, err)
+		_, err = r.GetByEmail(ctx, "user2@test.com")
+		assertNotFound(t, err)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		ctx := context.Background()
+		r := newStore(t).Users()
+		u, err := r.Create(ctx, model.User{
+			Username: "user1", Email: "user1@test.com", PasswordHash: "hash",
+		})
+		require.NoError(t, err)
+		disabledAt := timestamp(0)
+		require.NoError(t, r.SetDisabledAt(ctx, u.ID, &disabledAt))
+
+		update := u
+		update.Username, update.Email, update.FirstName = "user2", "user2@test.com", "Name"
+		update.PasswordHash, update.DisabledAt = "", nil
+		updated, err := r.Update(ctx, update)
+		require.NoError(t, err)
+		assert.Equal(t, "user2", updated.Username)
+		assert.Equal(t, "user2@test.com", updated.Email)
+		assert.Equal(t, "Name", updated.FirstName)
+		assert.Equal(t, "hash", updated.PasswordHash)
+		require.NotNil(t, updated.DisabledAt)
+		assertTime(t, disabledAt, *updated.DisabledAt)
+
+		got, err := r.GetByID(ctx, u.ID)
+		require.NoError(t, err)
+		assert.Equal(t, updated, got)
+		_, err = r.GetByEmail(ctx, "user1@test.com")
+		assertNotFound(t, err)
+	})
+
+	t.Run("Update resets email verification", func(t *testing.T) {
+		ctx := context.Background()
+		r := newStore(t).Users()
+		u, err := r.Create(ctx, model.User{Username: "user1", Email: "user1@test.com"})
+		require.NoError(t, err)
+		verifiedAt := timestamp(0)
+		require.NoError(t, r.SetEmailVerifiedAt(ctx, u.ID, &verifiedAt))
+
+		u.FirstName = "Name"
+		u, err = r.Update(ctx, u)
+		require.NoError(t, err)
+		require.NotNil(t, u.EmailVerifiedAt)
+		assertTime(t, verifiedAt, *u.EmailVerifiedAt)
+
+		u.Email = "user2@test.com"
+		u, err = r.Update(ctx, u)
+		require.NoError(t, err)
+		assert.Nil(t, u.EmailVerifiedAt)
+		got, err := r.GetByID(ctx, u.ID)
+		require.NoError(t, err)
+		assert.Nil(t, got.EmailVerifiedAt)
+	})
+
+	t.Run("Update uniqueness", func(t *testing.T) {
+		ctx := context.Background()
+		r := newStore(t).Users()
+		_, err := r.Create(ctx, model.User{Username: "user1", Email: "user1@test.com"})
+		require.NoError(t, err)
+		u, err := r.Create(ctx, model.User{Username: "user2", Email: "user2@test.com"})
+		require.NoError(t, err)
+
+		u.Username = "user1"
+		_, err = r.Update(ctx, u)
+		assert.Equal(t, store.ErrUsernameIsTaken, err)
+		u.Username, u.Email = "user2", "user1@test.com"
+		_, err = r.Update(ctx, u)
+		assert.Equal(t, store.ErrEmailIsTaken, err)
+
+		got, err := r.GetByID(ctx, u.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "user2@test.com", got.Email)
+	})
+
+	t.Run("Update not found", func(t *testing.T) {
+		ctx := context.Background()
+		r := newStore(t).Users()
+
+		_, err := r.Update(ctx, model.User{ID: 1, Username: "user1", Email: "user1@test.com"})
+		assertNotFound(t, err)
+	})
+
+	t.Run("Set", func(t *testing.T) {
+		ctx := context.Background()
+		r := newStore(t).Users()
+		u, err := r.Create(ctx, model.User{Username: "user1", Email: "user1@test.com"})
+		require.NoError(t, err)
+
+		verifiedAt, disabledAt := timestamp(0), timestamp(time.Minute)
+		assert.NoError(t, r.SetPasswordHash(ctx, u.ID, "hash"))
+		assert.NoError(t, r.SetEmailVerifiedAt(ctx, u.ID, &verifiedAt))
+		assert.NoError(t, r.SetDisabledAt(ctx, u.ID, &disabledAt))
+		got, err := r.GetByID(ctx, u.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "hash", got.PasswordHash)
+		require.NotNil(t, got.EmailVerifiedAt)
+		assertTime(t, verifiedAt, *got.EmailVerifiedAt)
+		require.NotNil(t, got.DisabledAt)
+		assertTime(t, disabledAt, *got.DisabledAt)
+		assert.Equal(t, u.Username, got.Username)
+
+		assert.NoError(t, r.SetEmailVerifiedAt(ctx, u.ID, nil))
+		assert.NoError(t, r.SetDisabledAt(ctx, u.ID, nil))
+		got, err = r.GetByID(ctx, u.ID)
+		require.NoError(t, err)
+		assert.Nil(t, got.EmailVerifiedAt)
+		assert.Nil(t, got.DisabledAt)
+	})
+
+	t.Run("Set not found", func(t *testing.T) {
+		ctx := context.Background()
+		r := newStore(t).Users()
+		at := timestamp(0)
+
+		assertNotFound(t, r.SetPasswordHash(ctx, 1, "hash"))
+		assertNotFound(t, r.SetEmailVerifiedAt(ctx, 1, &at))
+		assertNotFound(t, r.SetDisabledAt(ctx, 1, &at))
+	})
+
+	t.Run("DeleteByID", func(t *testing.T) {
+		ctx := context.Background()
+		s := newStore(t)
+		u, err := s.Users().Create(ctx, model.User{Username: "user1", Email: "user1@test.com"})
+		require.NoError(t, err)
+		require.NoError(t, s.Roles().AssignToUser(ctx, u.ID, "user"))
+
+		assert.NoError(t, s.Users().DeleteByID(ctx, u.ID))
+		_, err = s.Users().GetByID(ctx, u.ID)
+		assertNotFound(t, err)
+		roles, err := s.Roles().GetByUserID(ctx, u.ID)
+		assert.NoError(t, err)
+		assert.Empty(t, roles)
+
+		assertNotFound(t, s.Users().DeleteByID(ctx, u.ID))
+	})
+
+	t.Run("List", func(t *testing.T) {
+		ctx := context.Background()
+		r := newStore(t).Users()
+		var users []model.User
+		for _, name := range []string{"bob", "alice", "Bobby", "carol"} {
+			u, err := r.Create(ctx, model.User{Username: name, Email: name + "@test.com"})
+			require.NoError(t, err)
+			users = append(users, u)
+		}
+		verifiedAt := timestamp(0)
+		require.NoError(t, r.SetEmailVerifiedAt(ctx, users[1].ID, &verifiedAt))
+		verified, unverified := true, false
+
+		testcases := []struct {
+			name     string
+			query    model.UserQuery
+			expIDs   []int
+			expTotal int
+		}{
+			{
+				name:     "all users are listed by ID",
+				query:    model.UserQuery{Sort: model.UserSortID},
+				expIDs:   []int{users[0].ID, users[1].ID, users[2].ID, users[3].ID},
+				expTotal: 4,
+			},
+			{
+				name:     "users are sorted by username in descending order",
+				query:    model.UserQuery{Sort: model.UserSortUsername, Desc: true},
+				expIDs:   []int{users[3].ID, users[0].ID, users[1].ID, users[2].ID},
+				expTotal: 4,
+			},
+			{
+				name:     "users are filtered by username prefix case insensitively",
+				query:    model.UserQuery{UsernamePrefix: "BOB", Sort: model.UserSortEmail},
+				expIDs:   []int{users[2].ID, users[0].ID},
+				expTotal: 2,
+			},
+			{
+				name:     "wildcards in prefix are matched literally",
+				query:    model.UserQuery{EmailPrefix: "%", Sort: model.UserSortID},
+				expIDs:   []int{},
+				expTotal: 0,
+			},
+			{
+				name:     "users are filtered by email prefix",
+				query:    model.UserQuery{EmailPrefix: "carol@", Sort: model.UserSortID},
+				expIDs:   []int{users[3].ID},
+				expTotal: 1,
+			},
+			{
+				name: "users are filtered by creation date",
+				query: model.UserQuery{
+					CreatedAfter: &users[0].CreatedAt, CreatedBefore: &users[3].CreatedAt,
+					Sort: model.UserSortCreatedAt,
+				},
+				expIDs:   []int{users[1].ID, users[2].ID},
+				expTotal: 2,
+			},
+			{
+				name:     "verified users are listed",
+				query:    model.UserQuery{Verified: &verified, Sort: model.UserSortID},
+				expIDs:   []int{users[1].ID},
+				expTotal: 1,
+			},
+			{
+				name:     "unverified users are listed",
+				query:    model.UserQuery{Verified: &unverified, Sort: model.UserSortID, Limit: 2},
+				expIDs:   []int{users[0].ID, users[2].ID},
+				expTotal: 3,
+			},
+			{
+				name: "users are listed after the cursor",
+				query: model.UserQuery{
+					Sort: model.UserSortUsername, After: &users[1], Limit: 2,
+				},
+				expIDs:   []int{users[0].ID, users[3].ID},
+				expTotal: 4,
+			},
+			{
+				name: "users are listed after the cursor in descending order",
+				query: model.UserQuery{
+					Sort: model.UserSortCreatedAt, Desc: true, After: &users[2],
+				},
+				expIDs:   []int{users[1].ID, users[0].ID},
+				expTotal: 4,
+			},
+		}
+
+		for _, tc := range testcases {
+			t.Run(tc.name, func(t *testing.T) {
+				got, total, err := r.List(ctx, tc.query)
+				require.NoError(t, err)
+				ids := []int{}
+				for _, u := range got {
+					ids = append(ids, u.ID)
+				}
+				assert.Equal(t, tc.expIDs, ids)
+				assert.Equal(t, tc.expTotal, total)
+			})
+		}
+	})
+
+	t.Run("List unknown sort field", func(t *testing.T) {
+		r := newStore(t).Users()
+
+		_, _, err := r.List(context.Background(), model.UserQuery{Sort: "password_hash"})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,121 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imarrche/jwt-auth-example/internal/model"
+	"github.com/imarrche/jwt-auth-example/internal/store"
+)
+
+func testWebAuthnCredentialRepo(t *testing.T, newStore Factory) {
+	t.Run("Create", func(t *testing.T) {
+		ctx := context.Background()
+		s := newStore(t)
+		u, err := s.Users().Create(ctx, model.User{Username: "user1", Email: "user1@test.com"})
+		require.NoError(t, err)
+		r := s.WebAuthnCredentials()
+
+		c, err := r.Create(ctx, model.WebAuthnCredential{
+			ID: "cred1", UserID: u.ID, Name: "key", PublicKey: []byte("key"), SignCount: 1,
+		})
+		require.NoError(t, err)
+		assert.False(t, c.CreatedAt.IsZero())
+		got, err := r.GetByID(ctx, "cred1")
+		require.NoError(t, err)
+		assert.Equal(t, "cred1", got.ID)
+		assert.Equal(t, u.ID, got.UserID)
+		assert.Equal(t, "key", got.Name)
+		assert.Equal(t, []byte("key"), got.PublicKey)
+		assert.Equal(t, int64(1), got.SignCount)
+		assert.Nil(t, got.LastUsedAt)
+
+		_, err = r.Create(ctx, model.WebAuthnCredential{ID: "cred1", UserID: u.ID, PublicKey: []byte("k")})
+		assert.Equal(t, store.ErrCredentialIsTaken, err)
+		_, err = r.Create(ctx, model.WebAuthnCredential{ID: "cred2", UserID: 0, PublicKey: []byte("k")})
+		assertNotFound(t, err)
+		_, err = r.GetByID(ctx, "cred2")
+		assertNotFound(t, err)
+	})
+
+	t.Run("GetByUserID", func(t *testing.T) {
+		ctx := context.Background()
+		s := newStore(t)
+		u1, err := s.Users().Create(ctx, model.User{Username: "user1", Email: "user1@test.com"})
+		require.NoError(t, err)
+		u2, err := s.Users().Create(ctx, model.User{Username: "user2", Email: "user2@test.com"})
+		require.NoError(t, err)
+		r := s.WebAuthnCredentials()
+		for _, c := range []model.WebAuthnCredential{
+			{ID: "cred1", UserID: u1.ID, PublicKey: []byte("key")},
+			{ID: "cred2", UserID: u2.ID, PublicKey: []byte("key")},
+			{ID: "cred3", UserID: u1.ID, PublicKey: []byte("key")},
+		} {
+			_, err := r.Create(ctx, c)
+			require.NoError(t, err)
+		}
+
+		got, err := r.GetByUserID(ctx, u1.ID)
+		require.NoError(t, err)
+		require.Len(t, got, 2)
+		assert.Equal(t, "cred1", got[0].ID)
+		assert.Equal(t, "cred3", got[1].ID)
+
+		// Credentials are deleted along with the user.
+		require.NoError(t, s.Users().DeleteByID(ctx, u1.ID))
+		got, err = r.GetByUserID(ctx, u1.ID)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("MarkUsed", func(t *testing.T) {
+		ctx := context.Background()
+		s := newStore(t)
+		u, err := s.Users().Create(ctx, model.User{Username: "user1", Email: "user1@test.com"})
+		require.NoError(t, err)
+		r := s.WebAuthnCredentials()
+		for _, c := range []model.WebAuthnCredential{
+			{ID: "counting", UserID: u.ID, PublicKey: []byte("key"), SignCount: 5},
+			{ID: "not-counting", UserID: u.ID, PublicKey: []byte("key")},
+		} {
+			_, err := r.Create(ctx, c)
+			require.NoError(t, err)
+		}
+
+		assert.NoError(t, r.MarkUsed(ctx, "counting", 6))
+		assert.Equal(t, store.ErrTokenIsUsed, r.MarkUsed(ctx, "counting", 6))
+		assert.Equal(t, store.ErrTokenIsUsed, r.MarkUsed(ctx, "counting", 0))
+		got, err := r.GetByID(ctx, "counting")
+		require.NoError(t, err)
+		assert.Equal(t, int64(6), got.SignCount)
+		assert.NotNil(t, got.LastUsedAt)
+
+		assert.NoError(t, r.MarkUsed(ctx, "not-counting", 0))
+		assert.NoError(t, r.MarkUsed(ctx, "not-counting", 0))
+
+		assertNotFound(t, r.MarkUsed(ctx, "unknown", 1))
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		ctx := context.Background()
+		s := newStore(t)
+		u1, err := s.Users().Create(ctx, model.User{Username: "user1", Email: "user1@test.com"})
+		require.NoError(t, err)
+		u2, err := s.Users().Create(ctx, model.User{Username: "user2", Email: "user2@test.com"})
+		require.NoError(t, err)
+		r := s.WebAuthnCredentials()
+		c := model.WebAuthnCredential{ID: "cred1", UserID: u1.ID, PublicKey: []byte("key")}
+		_, err = r.Create(ctx, c)
+		require.NoError(t, err)
+
+		// Credentials of other users can't be deleted.
+		assertNotFound(t, r.Delete(ctx, u2.ID, "cred1"))
+		assert.NoError(t, r.Delete(ctx, u1.ID, "cred1"))
+		_, err = r.GetByID(ctx, "cred1")
+		assertNotFound(t, err)
+		assertNotFound(t, r.Delete(ctx, u1.ID, "cred1"))
+	})
+}